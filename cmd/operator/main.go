@@ -27,6 +27,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+	storage "google.golang.org/api/storage/v1"
 	corev1 "k8s.io/api/core/v1"
 	extsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/apimachinery/pkg/util/uuid"
@@ -116,12 +117,22 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to build cloud sql admin api client: %v", err)
 	}
+	// Create a client for the Google Cloud Storage API.
+	storageClient, err := googleutil.NewStorageClient(config.GCP.AdminServiceAccountKeyPath)
+	if err != nil {
+		log.Fatalf("failed to build google cloud storage api client: %v", err)
+	}
 
+	// Create a shared informer factory for our API types to be used by the admission webhook.
+	// The admission webhook runs regardless of whether the current instance is the leader, and hence cannot share the informer factory used by the controllers.
+	webhookInformerFactory := externalversions.NewSharedInformerFactory(selfClient, time.Duration(config.Controllers.ResyncPeriodSeconds)*time.Second)
 	// Create an instance of the admission webhook.
-	w, err := admission.NewWebhook(kubeClient, selfClient, cloudsqlClient, config)
+	w, err := admission.NewWebhook(kubeClient, selfClient, webhookInformerFactory.Cloudsql().V1alpha1().PostgresqlInstances(), cloudsqlClient, config)
 	if err != nil {
 		log.Fatalf("failed to create the admission webhook: %v", err)
 	}
+	// Start the shared informer factory used by the admission webhook.
+	webhookInformerFactory.Start(stopCh)
 	// Register the admission webhook.
 	if err := w.Register(kubeClient, config); err != nil {
 		log.Fatalf("failed to register the admission webhook: %v", err)
@@ -181,7 +192,7 @@ func main() {
 					<-stopCh
 					fn()
 				}()
-				run(ctx, config, kubeClient, extsClient, selfClient, er, cloudsqlClient)
+				run(ctx, config, kubeClient, extsClient, selfClient, er, cloudsqlClient, storageClient)
 			},
 			OnStoppedLeading: func() {
 				// We've stopped leading, so we must exit immediately.
@@ -195,8 +206,8 @@ func main() {
 	})
 }
 
-// run creates or updates our CRDs, starts the controller for Postgresql
-func run(ctx context.Context, config configuration.Configuration, kubeClient kubernetes.Interface, extsClient extsclientset.Interface, selfClient selfclient.Interface, er record.EventRecorder, cloudsqlClient *cloudsqladmin.Service) {
+// run creates or updates our CRDs, starts the controllers for our API types
+func run(ctx context.Context, config configuration.Configuration, kubeClient kubernetes.Interface, extsClient extsclientset.Interface, selfClient selfclient.Interface, er record.EventRecorder, cloudsqlClient *cloudsqladmin.Service, storageClient *storage.Service) {
 	// Create or update our CRDs.
 	if err := crds.CreateOrUpdateCRDs(extsClient); err != nil {
 		log.Fatalf("failed to create or update crds: %v", err)
@@ -204,7 +215,23 @@ func run(ctx context.Context, config configuration.Configuration, kubeClient kub
 	// Create a shared informer factory for our API types.
 	selfInformerFactory := externalversions.NewSharedInformerFactory(selfClient, time.Duration(config.Controllers.ResyncPeriodSeconds)*time.Second)
 	// Create an instance of the controller for PostgresqlInstance resources.
-	postgresqlInstanceController := controllers.NewPostgresqlInstanceController(config, kubeClient, selfClient, er, selfInformerFactory.Cloudsql().V1alpha1().PostgresqlInstances(), cloudsqlClient)
+	postgresqlInstanceController := controllers.NewPostgresqlInstanceController(config, kubeClient, selfClient, er, selfInformerFactory.Cloudsql().V1alpha1().PostgresqlInstances(), selfInformerFactory.Cloudsql().V1alpha1().PostgresqlReplicas(), selfInformerFactory.Cloudsql().V1alpha1().PostgresqlClientCertificates(), cloudsqlClient)
+	// Create an instance of the controller for PostgresqlDatabase resources.
+	postgresqlDatabaseController := controllers.NewPostgresqlDatabaseController(config, selfClient, er, selfInformerFactory.Cloudsql().V1alpha1().PostgresqlDatabases(), selfInformerFactory.Cloudsql().V1alpha1().PostgresqlInstances(), cloudsqlClient)
+	// Create an instance of the controller for PostgresqlReplica resources.
+	postgresqlReplicaController := controllers.NewPostgresqlReplicaController(config, selfClient, er, selfInformerFactory.Cloudsql().V1alpha1().PostgresqlReplicas(), selfInformerFactory.Cloudsql().V1alpha1().PostgresqlInstances(), cloudsqlClient)
+	// Create an instance of the controller for PostgresqlUser resources.
+	postgresqlUserController := controllers.NewPostgresqlUserController(config, kubeClient, selfClient, er, selfInformerFactory.Cloudsql().V1alpha1().PostgresqlUsers(), selfInformerFactory.Cloudsql().V1alpha1().PostgresqlInstances(), cloudsqlClient)
+	// Create an instance of the controller for PostgresqlBackup resources.
+	postgresqlBackupController := controllers.NewPostgresqlBackupController(config, selfClient, er, selfInformerFactory.Cloudsql().V1alpha1().PostgresqlBackups(), selfInformerFactory.Cloudsql().V1alpha1().PostgresqlInstances(), cloudsqlClient)
+	// Create an instance of the controller for PostgresqlRestore resources.
+	postgresqlRestoreController := controllers.NewPostgresqlRestoreController(config, selfClient, er, selfInformerFactory.Cloudsql().V1alpha1().PostgresqlRestores(), selfInformerFactory.Cloudsql().V1alpha1().PostgresqlBackups(), selfInformerFactory.Cloudsql().V1alpha1().PostgresqlInstances(), cloudsqlClient)
+	// Create an instance of the controller for PostgresqlExport resources.
+	postgresqlExportController := controllers.NewPostgresqlExportController(config, selfClient, er, selfInformerFactory.Cloudsql().V1alpha1().PostgresqlExports(), selfInformerFactory.Cloudsql().V1alpha1().PostgresqlInstances(), cloudsqlClient, storageClient)
+	// Create an instance of the controller for PostgresqlImport resources.
+	postgresqlImportController := controllers.NewPostgresqlImportController(config, selfClient, er, selfInformerFactory.Cloudsql().V1alpha1().PostgresqlImports(), selfInformerFactory.Cloudsql().V1alpha1().PostgresqlInstances(), cloudsqlClient)
+	// Create an instance of the controller for PostgresqlClientCertificate resources.
+	postgresqlClientCertificateController := controllers.NewPostgresqlClientCertificateController(config, kubeClient, selfClient, er, selfInformerFactory.Cloudsql().V1alpha1().PostgresqlClientCertificates(), selfInformerFactory.Cloudsql().V1alpha1().PostgresqlInstances(), cloudsqlClient)
 	// Start the shared informer factory.
 	selfInformerFactory.Start(ctx.Done())
 
@@ -216,6 +243,70 @@ func run(ctx context.Context, config configuration.Configuration, kubeClient kub
 			log.Error(err)
 		}
 	}()
+	// Start the controller for PostgresqlDatabase resources.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := postgresqlDatabaseController.Run(ctx); err != nil {
+			log.Error(err)
+		}
+	}()
+	// Start the controller for PostgresqlReplica resources.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := postgresqlReplicaController.Run(ctx); err != nil {
+			log.Error(err)
+		}
+	}()
+	// Start the controller for PostgresqlUser resources.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := postgresqlUserController.Run(ctx); err != nil {
+			log.Error(err)
+		}
+	}()
+	// Start the controller for PostgresqlBackup resources.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := postgresqlBackupController.Run(ctx); err != nil {
+			log.Error(err)
+		}
+	}()
+	// Start the controller for PostgresqlRestore resources.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := postgresqlRestoreController.Run(ctx); err != nil {
+			log.Error(err)
+		}
+	}()
+	// Start the controller for PostgresqlExport resources.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := postgresqlExportController.Run(ctx); err != nil {
+			log.Error(err)
+		}
+	}()
+	// Start the controller for PostgresqlImport resources.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := postgresqlImportController.Run(ctx); err != nil {
+			log.Error(err)
+		}
+	}()
+	// Start the controller for PostgresqlClientCertificate resources.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := postgresqlClientCertificateController.Run(ctx); err != nil {
+			log.Error(err)
+		}
+	}()
 
 	// Wait for all goroutines to terminate.
 	wg.Wait()
@@ -35,7 +35,7 @@ require (
 	golang.org/x/net v0.0.0-20190509222800-a4d6f7feada5 // indirect
 	golang.org/x/oauth2 v0.0.0-20190402181905-9f3314589c9a
 	golang.org/x/time v0.0.0-20190308202827-9d24e82272b4 // indirect
-	google.golang.org/api v0.5.0
+	google.golang.org/api v0.110.0
 	gopkg.in/inf.v0 v0.9.1 // indirect
 	k8s.io/api v0.0.0-20190512063542-eae0ddcf85ba
 	k8s.io/apiextensions-apiserver v0.0.0-20190514064203-3f96d5001990
@@ -0,0 +1,147 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+)
+
+const (
+	// denyPeriodDateLayout is the layout of the start and end dates of a deny period that happens once.
+	denyPeriodDateLayout = "2006-01-02"
+	// denyPeriodMaxDuration is the maximum duration of a deny period allowed by Cloud SQL.
+	denyPeriodMaxDuration = 90 * 24 * time.Hour
+	// denyPeriodRecurringDateLayout is the layout of the start and end dates of a deny period that recurs every year.
+	denyPeriodRecurringDateLayout = "01-02"
+	// denyPeriodReferenceYear is the (leap) year used to represent deny periods that recur every year, so that "02-29" is a valid date.
+	denyPeriodReferenceYear = 2000
+)
+
+var (
+	// denyPeriodTimeRegex is the regular expression used to match the time of the day at which a deny period starts and ends.
+	denyPeriodTimeRegex = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]:[0-5][0-9]$`)
+)
+
+// denyPeriod represents the interval of time covered by a deny period for periodic maintenance.
+type denyPeriod struct {
+	// start is the time at which the deny period starts.
+	start time.Time
+	// end is the time at which the deny period ends.
+	end time.Time
+	// recurring indicates whether the deny period recurs every year, in which case start is in denyPeriodReferenceYear.
+	recurring bool
+}
+
+// in returns the start and end of the occurrence of the deny period starting in the specified year.
+// Deny periods that happen once are returned unchanged.
+func (p denyPeriod) in(year int) (time.Time, time.Time) {
+	if !p.recurring {
+		return p.start, p.end
+	}
+	return p.start.AddDate(year-denyPeriodReferenceYear, 0, 0), p.end.AddDate(year-denyPeriodReferenceYear, 0, 0)
+}
+
+// parseDenyPeriod parses the provided deny period for periodic maintenance, whose time must have already been defaulted.
+func parseDenyPeriod(v v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriod) (denyPeriod, error) {
+	// Make sure that the time contains a valid value, and compute the offset it represents.
+	if !denyPeriodTimeRegex.MatchString(*v.Time) {
+		return denyPeriod{}, fmt.Errorf("the time of deny periods for periodic maintenance must be a valid time of the day in the \"hh:mm:ss\" format (got %q)", *v.Time)
+	}
+	t, _ := time.Parse("15:04:05", *v.Time)
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	// Parse the start and end dates, which must either both include the year (for deny periods that happen once) or both omit it (for deny periods that recur every year).
+	var (
+		r                denyPeriod
+		start, end       time.Time
+		errStart, errEnd error
+	)
+	switch {
+	case len(v.StartDate) == len(denyPeriodDateLayout) && len(v.EndDate) == len(denyPeriodDateLayout):
+		start, errStart = time.Parse(denyPeriodDateLayout, v.StartDate)
+		end, errEnd = time.Parse(denyPeriodDateLayout, v.EndDate)
+	case len(v.StartDate) == len(denyPeriodRecurringDateLayout) && len(v.EndDate) == len(denyPeriodRecurringDateLayout):
+		r.recurring = true
+		start, errStart = time.Parse(denyPeriodDateLayout, fmt.Sprintf("%d-%s", denyPeriodReferenceYear, v.StartDate))
+		end, errEnd = time.Parse(denyPeriodDateLayout, fmt.Sprintf("%d-%s", denyPeriodReferenceYear, v.EndDate))
+	default:
+		errStart = fmt.Errorf("mismatched date formats")
+	}
+	if errStart != nil || errEnd != nil {
+		return denyPeriod{}, fmt.Errorf("the start and end dates of deny periods for periodic maintenance must both be in the \"YYYY-MM-DD\" format or both be in the \"MM-DD\" format (got %q and %q)", v.StartDate, v.EndDate)
+	}
+	r.start = start.Add(offset)
+	r.end = end.Add(offset)
+	// Deny periods that recur every year may span the end of the year, in which case they end on the following year.
+	if r.recurring && r.end.Before(r.start) {
+		r.end = r.end.AddDate(1, 0, 0)
+	}
+	// Make sure that the deny period ends after it starts and does not exceed the maximum duration allowed by Cloud SQL.
+	if !r.end.After(r.start) {
+		return denyPeriod{}, fmt.Errorf("the end date of deny periods for periodic maintenance must be after the start date (got %q and %q)", v.StartDate, v.EndDate)
+	}
+	if r.end.Sub(r.start) > denyPeriodMaxDuration {
+		return denyPeriod{}, fmt.Errorf("deny periods for periodic maintenance cannot be longer than %d days (got %q to %q)", denyPeriodMaxDuration/(24*time.Hour), v.StartDate, v.EndDate)
+	}
+	return r, nil
+}
+
+// validateDenyPeriods validates the provided list of deny periods for periodic maintenance, whose times must have already been defaulted.
+func validateDenyPeriods(v v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList) error {
+	periods := make([]denyPeriod, 0, len(v))
+	for _, dp := range v {
+		p, err := parseDenyPeriod(dp)
+		if err != nil {
+			return err
+		}
+		periods = append(periods, p)
+	}
+	// Compute the range of years in which the deny periods may overlap.
+	// Deny periods that recur every year are compared in every year covered by deny periods that happen once, as well as in the surrounding years.
+	minYear, maxYear := denyPeriodReferenceYear, denyPeriodReferenceYear
+	found := false
+	for _, p := range periods {
+		if p.recurring {
+			continue
+		}
+		if !found || p.start.Year() < minYear {
+			minYear = p.start.Year()
+		}
+		if !found || p.end.Year() > maxYear {
+			maxYear = p.end.Year()
+		}
+		found = true
+	}
+	// Make sure that no two deny periods overlap.
+	for i := 0; i < len(periods); i++ {
+		for j := i + 1; j < len(periods); j++ {
+			for yi := minYear - 1; yi <= maxYear+1; yi++ {
+				for yj := yi - 1; yj <= yi+1; yj++ {
+					si, ei := periods[i].in(yi)
+					sj, ej := periods[j].in(yj)
+					if si.Before(ej) && sj.Before(ei) {
+						return fmt.Errorf("deny periods for periodic maintenance cannot overlap (got %q to %q and %q to %q)", v[i].StartDate, v[i].EndDate, v[j].StartDate, v[j].EndDate)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+)
+
+const (
+	// databaseFlagTypeBoolean is the type of database flags that accept a boolean value.
+	databaseFlagTypeBoolean = "BOOLEAN"
+	// databaseFlagTypeFloat is the type of database flags that accept a floating-point value.
+	databaseFlagTypeFloat = "FLOAT"
+	// databaseFlagTypeInteger is the type of database flags that accept an integer value.
+	databaseFlagTypeInteger = "INTEGER"
+	// databaseFlagTypeNone is the type of database flags that do not accept a value.
+	databaseFlagTypeNone = "NONE"
+	// databaseFlagTypeString is the type of database flags that accept a string value.
+	databaseFlagTypeString = "STRING"
+)
+
+var (
+	// databaseFlagBooleanValues is the list of values accepted by database flags of type "BOOLEAN".
+	databaseFlagBooleanValues = []string{"on", "off"}
+)
+
+// validateDatabaseFlags validates the provided sets of database flags.
+// If checkCatalog is true, the flags are additionally validated against the catalog of database flags supported by the specified version of Cloud SQL for PostgreSQL.
+func (w *Webhook) validateDatabaseFlags(flags v1alpha1.PostgresqlInstanceSpecFlags, databaseFlags v1alpha1.PostgresqlInstanceSpecDatabaseFlags, version v1alpha1.PostgresqlInstanceSpecVersion, checkCatalog bool) error {
+	// Build the full set of database flags, making sure that flags specified in the legacy format are well-formed and that no flag is specified more than once.
+	all := make(map[string]string, len(flags)+len(databaseFlags))
+	for _, flag := range flags {
+		parts := strings.SplitN(flag, PostgresqlInstanceSpecFlagsSeparator, 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("flags must be specified in the \"<name>=<value>\" format (got %q)", flag)
+		}
+		if _, exists := all[parts[0]]; exists {
+			return fmt.Errorf("the %q flag cannot be specified more than once", parts[0])
+		}
+		all[parts[0]] = parts[1]
+	}
+	for name, value := range databaseFlags {
+		if name == "" {
+			return fmt.Errorf("the name of a flag cannot be empty")
+		}
+		if _, exists := all[name]; exists {
+			return fmt.Errorf("the %q flag cannot be specified more than once", name)
+		}
+		all[name] = value
+	}
+	// If validation against the catalog has not been requested, or if there are no flags to validate, there's nothing else to check.
+	if !checkCatalog || len(all) == 0 {
+		return nil
+	}
+	// Make sure that every flag is supported by the target version, and that its value is valid.
+	v := version.APIValue()
+	catalog, err := w.databaseFlagsCatalog.Get(v)
+	if err != nil {
+		return err
+	}
+	for name, value := range all {
+		flag, exists := catalog[name]
+		if !exists {
+			return fmt.Errorf("the %q flag is not supported by cloud sql for %s", name, v)
+		}
+		if err := validateDatabaseFlagValue(flag, value, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateDatabaseFlagValue validates the provided value against the definition of a database flag, and makes sure that the flag applies to the specified Cloud SQL database version.
+func validateDatabaseFlagValue(flag *cloudsqladmin.Flag, value, version string) error {
+	// Make sure that the flag applies to the specified version.
+	if len(flag.AppliesTo) > 0 && !containsString(flag.AppliesTo, version) {
+		return fmt.Errorf("the %q flag is not supported by %s (supported by %s)", flag.Name, version, strings.Join(flag.AppliesTo, ", "))
+	}
+	// Make sure that the value matches the type of the flag.
+	switch flag.Type {
+	case databaseFlagTypeBoolean:
+		if !containsString(databaseFlagBooleanValues, value) {
+			return fmt.Errorf("the value of the %q flag must be one of %q (got %q)", flag.Name, databaseFlagBooleanValues, value)
+		}
+	case databaseFlagTypeFloat:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("the value of the %q flag must be a number (got %q)", flag.Name, value)
+		}
+	case databaseFlagTypeInteger:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("the value of the %q flag must be an integer (got %q)", flag.Name, value)
+		}
+		if (flag.MinValue != 0 || flag.MaxValue != 0) && (i < flag.MinValue || i > flag.MaxValue) {
+			return fmt.Errorf("the value of the %q flag must be between %d and %d (got %d)", flag.Name, flag.MinValue, flag.MaxValue, i)
+		}
+	case databaseFlagTypeNone:
+		if value != "" {
+			return fmt.Errorf("the %q flag does not accept a value (got %q)", flag.Name, value)
+		}
+	case databaseFlagTypeString:
+		if len(flag.AllowedStringValues) > 0 && !containsString(flag.AllowedStringValues, value) {
+			return fmt.Errorf("the value of the %q flag must be one of %q (got %q)", flag.Name, flag.AllowedStringValues, value)
+		}
+	default:
+		// The type of the flag is not known, so we cannot validate its value.
+	}
+	return nil
+}
+
+// containsString returns whether the provided slice contains the specified string.
+func containsString(s []string, v string) bool {
+	for _, item := range s {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -49,9 +49,21 @@ const (
 	PguserEnvVarName = "PGUSER"
 	// PgpassfileEnvVarName is the name of the "PGPASSFILE" environment variable injected in each container.
 	PgpassfileEnvVarName = "PGPASSFILE"
+	// PgsslcertEnvVarName is the name of the "PGSSLCERT" environment variable injected in each container of pods using a client certificate.
+	PgsslcertEnvVarName = "PGSSLCERT"
+	// PgsslkeyEnvVarName is the name of the "PGSSLKEY" environment variable injected in each container of pods using a client certificate.
+	PgsslkeyEnvVarName = "PGSSLKEY"
+	// PgsslmodeEnvVarName is the name of the "PGSSLMODE" environment variable injected in each container of pods using a client certificate.
+	PgsslmodeEnvVarName = "PGSSLMODE"
+	// PgsslrootcertEnvVarName is the name of the "PGSSLROOTCERT" environment variable injected in each container of pods using a client certificate.
+	PgsslrootcertEnvVarName = "PGSSLROOTCERT"
 )
 
 const (
+	// clientCertificateSecretVolumeMountPath is the path where the secret containing a client certificate will be mounted.
+	clientCertificateSecretVolumeMountPath = "/ssl"
+	// clientCertificateSecretVolumeName is the name of the volume containing a client certificate.
+	clientCertificateSecretVolumeName = "client-certificate"
 	// clientServiceAccountKeyKey is the name of the key containing the JSON credentials for the IAM service account with the "roles/cloudsql.client" role.
 	clientServiceAccountKeyKey = "credentials.json"
 	// cloudSQLProxyContainerPortMinValue is the maximum value to use when drawing a random port number for the Cloud SQL proxy container.
@@ -72,13 +84,21 @@ const (
 	pgpassConfKey = "pgpass.conf"
 	// pgpassConfValueFormatString is the format string used when creating the file containing the username and password combination for the CSQLP instance.
 	pgpassConfValueFormatString = "*:*:*:%s:%s"
+	// pgsslmodeEnvVarValue is the value of the "PGSSLMODE" environment variable injected in each container of pods using a client certificate.
+	pgsslmodeEnvVarValue = "verify-ca"
 	// secretNameFormatString is the name of the secret used to store the credentials for connecting to the CSQLP instance.
 	secretNameFormatString = "%s-cloud-sql-proxy"
 )
 
 // mutatePodInternal checks whether the provided Pod resource is requesting access to a CSQLP instance, and performs injection of the Cloud SQL proxy sidecar.
+// Pods requesting a client certificate have it mounted instead, so that they can connect directly to the CSQLP instance.
 func (w *Webhook) mutatePod(namespace string, currentObj *corev1.Pod) (*corev1.Pod, error) {
 	pod, err := func() (*corev1.Pod, error) {
+		// Check whether we have been asked to use a client certificate in order to connect directly to a CSQLP instance.
+		if v, exists := currentObj.Annotations[constants.PostgresqlClientCertificateNameAnnotationKey]; exists && v != "" {
+			return w.mountClientCertificate(namespace, currentObj, v)
+		}
+
 		// Check whether we have been asked to connect to a CSQLP instance.
 		v, exists := currentObj.Annotations[constants.PostgresqlInstanceNameAnnotationKey]
 		if !exists || v == "" {
@@ -89,7 +109,7 @@ func (w *Webhook) mutatePod(namespace string, currentObj *corev1.Pod) (*corev1.P
 		mutatedObj := currentObj.DeepCopy()
 
 		// Check whether the referenced PostgresqlInstance resource exists or not.
-		postgresqlInstance, err := w.selfClient.CloudsqlV1alpha1().PostgresqlInstances().Get(v, metav1.GetOptions{})
+		postgresqlInstance, err := w.postgresqlInstanceLister.Get(v)
 		if err != nil {
 			if kubeerrors.IsNotFound(err) {
 				return nil, fmt.Errorf("postgresqlinstance %q does not exist: %v", v, err)
@@ -111,6 +131,30 @@ func (w *Webhook) mutatePod(namespace string, currentObj *corev1.Pod) (*corev1.P
 			return nil, fmt.Errorf("failed to get the connection name associated with postgresqlinstance %q: %v", postgresqlInstance.Name, err)
 		}
 
+		// Connect to the CSQLP instance itself unless we have been asked to connect to one of its read replicas.
+		// If one of the CSQLP instance's read replicas has been promoted (e.g. as part of a disaster recovery procedure), the reported connection name is the one of said read replica.
+		connectionName := postgresqlInstance.Status.ConnectionName
+
+		if r, exists := currentObj.Annotations[constants.PostgresqlReplicaNameAnnotationKey]; exists && r != "" {
+			// Check whether the referenced PostgresqlReplica resource exists or not.
+			postgresqlReplica, err := w.selfClient.CloudsqlV1alpha1().PostgresqlReplicas().Get(r, metav1.GetOptions{})
+			if err != nil {
+				if kubeerrors.IsNotFound(err) {
+					return nil, fmt.Errorf("postgresqlreplica %q does not exist: %v", r, err)
+				}
+				return nil, fmt.Errorf("failed to get postgresql replica %q: %v", r, err)
+			}
+			// Make sure that the PostgresqlReplica resource is a read replica of the referenced PostgresqlInstance resource, as its credentials are the ones being used.
+			if postgresqlReplica.Spec.Primary != postgresqlInstance.Name {
+				return nil, fmt.Errorf("postgresqlreplica %q is not a read replica of postgresqlinstance %q", postgresqlReplica.Name, postgresqlInstance.Name)
+			}
+			// Make sure that the connection name for the PostgresqlReplica has already been reported.
+			if postgresqlReplica.Status.ConnectionName == "" {
+				return nil, fmt.Errorf("the connection name associated with postgresqlreplica %q has not been reported yet", postgresqlReplica.Name)
+			}
+			connectionName = postgresqlReplica.Status.ConnectionName
+		}
+
 		// Build the Secret object that represents the desired state of the namespace-local secret containing "pgpass.conf" for the PostgresqlInstance resource.
 		localPostgresqlInstanceSecretName := fmt.Sprintf(secretNameFormatString, postgresqlInstance.Name)
 		localPostgresqlInstanceSecret := w.buildLocalPostgresqlInstanceSecret(namespace, localPostgresqlInstanceSecretName, postgresqlInstance, postgresqlInstanceSecret)
@@ -181,7 +225,7 @@ func (w *Webhook) mutatePod(namespace string, currentObj *corev1.Pod) (*corev1.P
 		}
 
 		// Inject the Cloud SQL proxy container.
-		mutatedObj.Spec.Containers = append(mutatedObj.Spec.Containers, w.buildCloudSQLProxyContainer(postgresqlInstance, port))
+		mutatedObj.Spec.Containers = append(mutatedObj.Spec.Containers, w.buildCloudSQLProxyContainer(postgresqlInstance, connectionName, port))
 
 		// Signal that the Cloud SQL proxy sidecar has been injected and return.
 		mutatedObj.Annotations[constants.ProxyInjectedAnnotationKey] = "true"
@@ -198,7 +242,8 @@ func (w *Webhook) mutatePod(namespace string, currentObj *corev1.Pod) (*corev1.P
 }
 
 // buildCloudSQLProxyContainer builds the Cloud SQL proxy container to inject.
-func (w *Webhook) buildCloudSQLProxyContainer(postgresqlInstance *v1alpha1api.PostgresqlInstance, port int32) corev1.Container {
+// connectionName is the connection name of either the CSQLP instance itself or of one of its read replicas.
+func (w *Webhook) buildCloudSQLProxyContainer(postgresqlInstance *v1alpha1api.PostgresqlInstance, connectionName string, port int32) corev1.Container {
 	ipAddressTypes := make([]string, 0)
 	if *postgresqlInstance.Spec.Networking.PublicIP.Enabled {
 		ipAddressTypes = append(ipAddressTypes, ipAddressTypePublic)
@@ -212,7 +257,7 @@ func (w *Webhook) buildCloudSQLProxyContainer(postgresqlInstance *v1alpha1api.Po
 		Command: []string{
 			"/cloud_sql_proxy",
 			fmt.Sprintf("-credential_file=%s", path.Join(credentialsSecretVolumeMountPath, clientServiceAccountKeyKey)),
-			fmt.Sprintf("-instances=%s=tcp:%d", postgresqlInstance.Status.ConnectionName, port),
+			fmt.Sprintf("-instances=%s=tcp:%d", connectionName, port),
 			fmt.Sprintf("-ip_address_types=%s", strings.Join(ipAddressTypes, ",")),
 		},
 		Ports: []corev1.ContainerPort{
@@ -262,6 +307,82 @@ func (w *Webhook) buildLocalPostgresqlInstanceSecret(namespace, name string, pos
 	return s
 }
 
+// mountClientCertificate mounts the secret containing the client certificate represented by the specified PostgresqlClientCertificate resource in the provided pod, and injects the required "PGSSL*" variables.
+// Pods using a client certificate connect directly to the CSQLP instance, and hence the Cloud SQL proxy is not injected.
+// Client certificates are issued asynchronously by the PostgresqlClientCertificate controller rather than during admission.
+// Hence, until the controller has written the client certificate to the secret and set the "Ready" condition of the PostgresqlClientCertificate resource to "True", a "not ready" error is returned and the pod is rejected.
+// Pods managed by a controller (e.g. a deployment) are created again by the latter with a backoff, and are admitted as soon as the client certificate is ready.
+func (w *Webhook) mountClientCertificate(namespace string, currentObj *corev1.Pod, name string) (*corev1.Pod, error) {
+	// Connections established through the Cloud SQL proxy are already encrypted, and the Cloud SQL proxy does not accept SSL connections.
+	if v, exists := currentObj.Annotations[constants.PostgresqlInstanceNameAnnotationKey]; exists && v != "" {
+		return nil, fmt.Errorf("the %q and %q annotations cannot be used together", constants.PostgresqlClientCertificateNameAnnotationKey, constants.PostgresqlInstanceNameAnnotationKey)
+	}
+
+	// Check whether the referenced PostgresqlClientCertificate resource exists or not.
+	postgresqlClientCertificate, err := w.selfClient.CloudsqlV1alpha1().PostgresqlClientCertificates(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if kubeerrors.IsNotFound(err) {
+			return nil, fmt.Errorf("postgresqlclientcertificate %q does not exist: %v", name, err)
+		}
+		return nil, fmt.Errorf("failed to get postgresql client certificate %q: %v", name, err)
+	}
+	// Make sure that the client certificate has already been written to the secret.
+	ready := false
+	for _, cdn := range postgresqlClientCertificate.Status.Conditions {
+		if cdn.Type == v1alpha1api.PostgresqlClientCertificateStatusConditionTypeReady && cdn.Status == corev1.ConditionTrue {
+			ready = true
+		}
+	}
+	if !ready || postgresqlClientCertificate.Spec.SecretName == nil {
+		return nil, fmt.Errorf("postgresqlclientcertificate %q is not ready: the client certificate has not been issued yet, please retry once its \"Ready\" condition is \"True\"", name)
+	}
+
+	// Clone the current object so that we can safely mutate it.
+	mutatedObj := currentObj.DeepCopy()
+
+	// Add the secret containing the client certificate as a volume.
+	mutatedObj.Spec.Volumes = append(mutatedObj.Spec.Volumes, corev1.Volume{
+		Name: clientCertificateSecretVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				// Use 0400 as the default mode for files created as a result of mounting the secret.
+				DefaultMode: pointers.NewInt32(256),
+				Optional:    pointers.NewBool(false),
+				SecretName:  *postgresqlClientCertificate.Spec.SecretName,
+			},
+		},
+	})
+
+	// Modify existing containers in order to mount the secret as a volume and to inject the required "PGSSL*" variables.
+	for idx := range mutatedObj.Spec.Containers {
+		c := &mutatedObj.Spec.Containers[idx]
+		c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+			MountPath: clientCertificateSecretVolumeMountPath,
+			Name:      clientCertificateSecretVolumeName,
+			ReadOnly:  true,
+		})
+		c.Env = append(c.Env, []corev1.EnvVar{
+			{
+				Name:  PgsslcertEnvVarName,
+				Value: path.Join(clientCertificateSecretVolumeMountPath, constants.ClientCertificateKey),
+			},
+			{
+				Name:  PgsslkeyEnvVarName,
+				Value: path.Join(clientCertificateSecretVolumeMountPath, constants.ClientPrivateKeyKey),
+			},
+			{
+				Name:  PgsslrootcertEnvVarName,
+				Value: path.Join(clientCertificateSecretVolumeMountPath, constants.ServerCACertificateKey),
+			},
+			{
+				Name:  PgsslmodeEnvVarName,
+				Value: pgsslmodeEnvVarValue,
+			},
+		}...)
+	}
+	return mutatedObj, nil
+}
+
 // getFreeRandomPort returns a random port drawn from the random port range (49152-65535) that is not already in use in the provided pod.
 func getFreeRandomPort(pod *corev1.Pod) int32 {
 	// Build the map of used ports by iterating over every container.
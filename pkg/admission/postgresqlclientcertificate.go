@@ -0,0 +1,119 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"fmt"
+	"strings"
+
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+)
+
+// postgresqlClientCertificateWebhookOperation represents a validation/mutation operation performed by the admission webhook on PostgresqlClientCertificate resources.
+type postgresqlClientCertificateWebhookOperation func(mutatedObj, previousObj *v1alpha1.PostgresqlClientCertificate) error
+
+// validateAndMutatePostgresqlClientCertificate validates and mutates the provided PostgresqlClientCertificate object.
+// If the current request is a CREATE request, only currentObj is populated.
+// If the current request is an UPDATE request, both currentObj and previousObj are populated.
+// If the current request is a DELETE request, only previousObj is populated.
+func (w *Webhook) validateAndMutatePostgresqlClientCertificate(currentObj, previousObj *v1alpha1.PostgresqlClientCertificate) (*v1alpha1.PostgresqlClientCertificate, error) {
+	// Check whether the current request is a DELETE request and act accordingly.
+	// In this case, we allow the request if and only if the "cloudsql.travelaudience.com/allow-deletion" annotation is present on the resource and set to "true".
+	if currentObj == nil && previousObj != nil {
+		if v, exists := previousObj.Annotations[constants.AllowDeletionAnnotationKey]; !exists || v != v1alpha1.True {
+			return nil, fmt.Errorf("the resource cannot be deleted unless the %q annotation is set to %q", constants.AllowDeletionAnnotationKey, v1alpha1.True)
+		}
+		return nil, nil
+	}
+
+	// At this point we know the current request is either a CREATE or UPDATE request.
+
+	// Clone the current object so that we can safely mutate it if necessary.
+	mutatedObj := currentObj.DeepCopy()
+
+	// Perform the required validation/mutation steps.
+	for _, fn := range []postgresqlClientCertificateWebhookOperation{
+		mutatePostgresqlClientCertificateMetadataAnnotations,
+		w.validatePostgresqlClientCertificateSpecInstance,
+		validateAndMutatePostgresqlClientCertificateSpecSecretName,
+	} {
+		if err := fn(mutatedObj, previousObj); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return the (possibly) mutated object so a patch can be created if necessary.
+	return mutatedObj, nil
+}
+
+// mutatePostgresqlClientCertificateMetadataAnnotations injects annotations on the specified PostgresqlClientCertificate resource.
+func mutatePostgresqlClientCertificateMetadataAnnotations(mutatedObj, _ *v1alpha1.PostgresqlClientCertificate) error {
+	// Make sure that the map of annotations is initialized on the cloned object.
+	if mutatedObj.Annotations == nil {
+		mutatedObj.Annotations = make(map[string]string, 1)
+	}
+	// Inject the "cloudsql.travelaudience.com/allow-deletion" annotation with a value of "false" if the annotation is not present or is empty.
+	if v, exists := mutatedObj.Annotations[constants.AllowDeletionAnnotationKey]; !exists || v == "" {
+		mutatedObj.Annotations[constants.AllowDeletionAnnotationKey] = v1alpha1.False
+	}
+	return nil
+}
+
+// validatePostgresqlClientCertificateSpecInstance validates the value of ".spec.instance".
+func (w *Webhook) validatePostgresqlClientCertificateSpecInstance(mutatedObj, previousObj *v1alpha1.PostgresqlClientCertificate) error {
+	// If the current request is an UPDATE request, make sure that ".spec.instance" is not being changed/removed.
+	if previousObj != nil && mutatedObj.Spec.Instance != previousObj.Spec.Instance {
+		return fmt.Errorf("the instance of the client certificate cannot be changed (had %q, got %q)", previousObj.Spec.Instance, mutatedObj.Spec.Instance)
+	}
+	// Make sure that ".spec.instance" is not empty.
+	if mutatedObj.Spec.Instance == "" {
+		return fmt.Errorf("the instance of the client certificate cannot be empty")
+	}
+	// If the current request is a CREATE request, make sure that ".spec.instance" references an existing PostgresqlInstance resource.
+	if previousObj == nil {
+		_, err := w.selfClient.CloudsqlV1alpha1().PostgresqlInstances().Get(mutatedObj.Spec.Instance, metav1.GetOptions{})
+		if err != nil {
+			if kubeerrors.IsNotFound(err) {
+				return fmt.Errorf("postgresqlinstance %q does not exist", mutatedObj.Spec.Instance)
+			}
+			return fmt.Errorf("failed to get postgresqlinstance %q: %v", mutatedObj.Spec.Instance, err)
+		}
+	}
+	return nil
+}
+
+// validateAndMutatePostgresqlClientCertificateSpecSecretName validates and mutates the value of ".spec.secretName".
+func validateAndMutatePostgresqlClientCertificateSpecSecretName(mutatedObj, previousObj *v1alpha1.PostgresqlClientCertificate) error {
+	// If no value for ".spec.secretName" has been provided, use the name of the PostgresqlClientCertificate resource.
+	if mutatedObj.Spec.SecretName == nil {
+		mutatedObj.Spec.SecretName = &mutatedObj.Name
+	}
+	// If the current request is an UPDATE request, make sure that ".spec.secretName" is not being changed.
+	if previousObj != nil && previousObj.Spec.SecretName != nil && *mutatedObj.Spec.SecretName != *previousObj.Spec.SecretName {
+		return fmt.Errorf("the name of the secret cannot be changed (had %q, got %q)", *previousObj.Spec.SecretName, *mutatedObj.Spec.SecretName)
+	}
+	// Make sure that ".spec.secretName" is a valid name for a secret.
+	if errs := validation.IsDNS1123Subdomain(*mutatedObj.Spec.SecretName); len(errs) > 0 {
+		return fmt.Errorf("the name of the secret is invalid (got %q): %s", *mutatedObj.Spec.SecretName, strings.Join(errs, ", "))
+	}
+	return nil
+}
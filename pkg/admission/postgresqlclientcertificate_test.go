@@ -0,0 +1,200 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	selfclientfake "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/clientset/versioned/fake"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/pointers"
+)
+
+// newPostgresqlClientCertificate returns a PostgresqlClientCertificate resource with the specified name and secret name, whose "Ready" condition has the specified status.
+func newPostgresqlClientCertificate(name string, secretName *string, ready corev1.ConditionStatus) *v1alpha1.PostgresqlClientCertificate {
+	return &v1alpha1.PostgresqlClientCertificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: v1alpha1.PostgresqlClientCertificateSpec{
+			Instance:   "postgresql-instance-0",
+			SecretName: secretName,
+		},
+		Status: v1alpha1.PostgresqlClientCertificateStatus{
+			Conditions: []v1alpha1.PostgresqlClientCertificateStatusCondition{
+				{
+					Type:   v1alpha1.PostgresqlClientCertificateStatusConditionTypeReady,
+					Status: ready,
+				},
+			},
+		},
+	}
+}
+
+func TestValidateAndMutatePostgresqlClientCertificateSpecSecretName(t *testing.T) {
+	tests := []struct {
+		description        string
+		mutatedObj         *v1alpha1.PostgresqlClientCertificate
+		previousObj        *v1alpha1.PostgresqlClientCertificate
+		expectError        bool
+		expectedSecretName string
+	}{
+		{
+			description:        "secret name defaults to the name of the resource",
+			mutatedObj:         newPostgresqlClientCertificate("client-certificate-0", nil, corev1.ConditionFalse),
+			expectedSecretName: "client-certificate-0",
+		},
+		{
+			description:        "secret name is kept if specified",
+			mutatedObj:         newPostgresqlClientCertificate("client-certificate-0", pointers.NewString("secret-0"), corev1.ConditionFalse),
+			expectedSecretName: "secret-0",
+		},
+		{
+			description: "invalid secret name",
+			mutatedObj:  newPostgresqlClientCertificate("client-certificate-0", pointers.NewString("Secret_0"), corev1.ConditionFalse),
+			expectError: true,
+		},
+		{
+			description:        "unchanged secret name",
+			mutatedObj:         newPostgresqlClientCertificate("client-certificate-0", pointers.NewString("secret-0"), corev1.ConditionFalse),
+			previousObj:        newPostgresqlClientCertificate("client-certificate-0", pointers.NewString("secret-0"), corev1.ConditionFalse),
+			expectedSecretName: "secret-0",
+		},
+		{
+			description: "changed secret name",
+			mutatedObj:  newPostgresqlClientCertificate("client-certificate-0", pointers.NewString("secret-1"), corev1.ConditionFalse),
+			previousObj: newPostgresqlClientCertificate("client-certificate-0", pointers.NewString("secret-0"), corev1.ConditionFalse),
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		err := validateAndMutatePostgresqlClientCertificateSpecSecretName(test.mutatedObj, test.previousObj)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.description)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.description, err)
+			continue
+		}
+		if *test.mutatedObj.Spec.SecretName != test.expectedSecretName {
+			t.Errorf("%s: expected the secret name to be %q, got %q", test.description, test.expectedSecretName, *test.mutatedObj.Spec.SecretName)
+		}
+	}
+}
+
+func TestMountClientCertificate(t *testing.T) {
+	w := &Webhook{
+		selfClient: selfclientfake.NewSimpleClientset(
+			newPostgresqlClientCertificate("ready", pointers.NewString("ready-secret"), corev1.ConditionTrue),
+			newPostgresqlClientCertificate("not-ready", pointers.NewString("not-ready-secret"), corev1.ConditionFalse),
+		),
+	}
+	newPod := func(annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: annotations,
+				Name:        "pod-0",
+				Namespace:   "default",
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "app-0"},
+					{Name: "app-1"},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		description   string
+		name          string
+		annotations   map[string]string
+		expectedError string
+	}{
+		{
+			description: "ready client certificate",
+			name:        "ready",
+		},
+		{
+			description:   "client certificate not issued yet",
+			name:          "not-ready",
+			expectedError: "is not ready",
+		},
+		{
+			description:   "non-existing client certificate",
+			name:          "missing",
+			expectedError: "does not exist",
+		},
+		{
+			description:   "client certificate combined with the cloud sql proxy",
+			name:          "ready",
+			annotations:   map[string]string{constants.PostgresqlInstanceNameAnnotationKey: "postgresql-instance-0"},
+			expectedError: "cannot be used together",
+		},
+	}
+	for _, test := range tests {
+		pod := newPod(test.annotations)
+		mutatedObj, err := w.mountClientCertificate("default", pod, test.name)
+		if test.expectedError != "" {
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("%s: expected an error containing %q, got %v", test.description, test.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.description, err)
+			continue
+		}
+		// Make sure that the provided pod has not been modified.
+		if len(pod.Spec.Volumes) != 0 {
+			t.Errorf("%s: the provided pod has been modified", test.description)
+		}
+		// Make sure that the secret has been added as a volume and mounted in every container together with the "PGSSL*" variables.
+		if len(mutatedObj.Spec.Volumes) != 1 || mutatedObj.Spec.Volumes[0].Secret == nil || mutatedObj.Spec.Volumes[0].Secret.SecretName != "ready-secret" {
+			t.Errorf("%s: expected the %q secret to be added as a volume, got %v", test.description, "ready-secret", mutatedObj.Spec.Volumes)
+			continue
+		}
+		for _, c := range mutatedObj.Spec.Containers {
+			if len(c.VolumeMounts) != 1 || c.VolumeMounts[0].MountPath != clientCertificateSecretVolumeMountPath {
+				t.Errorf("%s: expected the secret to be mounted at %q in container %q, got %v", test.description, clientCertificateSecretVolumeMountPath, c.Name, c.VolumeMounts)
+			}
+			env := make(map[string]string, len(c.Env))
+			for _, e := range c.Env {
+				env[e.Name] = e.Value
+			}
+			for name, value := range map[string]string{
+				PgsslcertEnvVarName:     path.Join(clientCertificateSecretVolumeMountPath, constants.ClientCertificateKey),
+				PgsslkeyEnvVarName:      path.Join(clientCertificateSecretVolumeMountPath, constants.ClientPrivateKeyKey),
+				PgsslrootcertEnvVarName: path.Join(clientCertificateSecretVolumeMountPath, constants.ServerCACertificateKey),
+				PgsslmodeEnvVarName:     pgsslmodeEnvVarValue,
+			} {
+				if env[name] != value {
+					t.Errorf("%s: expected %q to be %q in container %q, got %q", test.description, name, value, c.Name, env[name])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"fmt"
+	"regexp"
+
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+	googleutil "github.com/travelaudience/cloudsql-postgres-operator/pkg/util/google"
+)
+
+var (
+	// postgresqlDatabaseSpecNameRegex is the regular expression used to validate the ".spec.name" field of a PostgresqlDatabase resource.
+	postgresqlDatabaseSpecNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]{0,62}$`)
+	// postgresqlDatabaseSpecNameReserved is the set of names which cannot be used as the value of the ".spec.name" field of a PostgresqlDatabase resource.
+	postgresqlDatabaseSpecNameReserved = []string{
+		"cloudsqladmin",
+		"postgres",
+		"template0",
+		"template1",
+	}
+)
+
+var (
+	// PostgresqlDatabaseSpecCharsetDefault is the default value for the ".spec.charset" field of a PostgresqlDatabase resource.
+	PostgresqlDatabaseSpecCharsetDefault = "UTF8"
+	// PostgresqlDatabaseSpecCollationDefault is the default value for the ".spec.collation" field of a PostgresqlDatabase resource.
+	PostgresqlDatabaseSpecCollationDefault = "en_US.UTF8"
+)
+
+// postgresqlDatabaseWebhookOperation represents a validation/mutation operation performed by the admission webhook on PostgresqlDatabase resources.
+type postgresqlDatabaseWebhookOperation func(mutatedObj, previousObj *v1alpha1.PostgresqlDatabase) error
+
+// validateAndMutatePostgresqlDatabase validates and mutates the provided PostgresqlDatabase object.
+// If the current request is a CREATE request, only currentObj is populated.
+// If the current request is an UPDATE request, both currentObj and previousObj are populated.
+// If the current request is a DELETE request, only previousObj is populated.
+func (w *Webhook) validateAndMutatePostgresqlDatabase(currentObj, previousObj *v1alpha1.PostgresqlDatabase) (*v1alpha1.PostgresqlDatabase, error) {
+	// Check whether the current request is a DELETE request and act accordingly.
+	// In this case, we allow the request if and only if the "cloudsql.travelaudience.com/allow-deletion" annotation is present on the resource and set to "true".
+	if currentObj == nil && previousObj != nil {
+		if v, exists := previousObj.Annotations[constants.AllowDeletionAnnotationKey]; !exists || v != v1alpha1.True {
+			return nil, fmt.Errorf("the resource cannot be deleted unless the %q annotation is set to %q", constants.AllowDeletionAnnotationKey, v1alpha1.True)
+		}
+		return nil, nil
+	}
+
+	// At this point we know the current request is either a CREATE or UPDATE request.
+
+	// Clone the current object so that we can safely mutate it if necessary.
+	mutatedObj := currentObj.DeepCopy()
+
+	// Perform the required validation/mutation steps.
+	for _, fn := range []postgresqlDatabaseWebhookOperation{
+		mutatePostgresqlDatabaseMetadataAnnotations,
+		validateAndMutatePostgresqlDatabaseSpecCharsetAndCollation,
+		w.validatePostgresqlDatabaseSpecInstance,
+		w.validatePostgresqlDatabaseSpecName,
+	} {
+		if err := fn(mutatedObj, previousObj); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return the (possibly) mutated object so a patch can be created if necessary.
+	return mutatedObj, nil
+}
+
+// mutatePostgresqlDatabaseMetadataAnnotations injects annotations on the specified PostgresqlDatabase resource.
+func mutatePostgresqlDatabaseMetadataAnnotations(mutatedObj, _ *v1alpha1.PostgresqlDatabase) error {
+	// Make sure that the map of annotations is initialized on the cloned object.
+	if mutatedObj.Annotations == nil {
+		mutatedObj.Annotations = make(map[string]string, 1)
+	}
+	// Inject the "cloudsql.travelaudience.com/allow-deletion" annotation with a value of "false" if the annotation is not present or is empty.
+	if v, exists := mutatedObj.Annotations[constants.AllowDeletionAnnotationKey]; !exists || v == "" {
+		mutatedObj.Annotations[constants.AllowDeletionAnnotationKey] = v1alpha1.False
+	}
+	return nil
+}
+
+// validateAndMutatePostgresqlDatabaseSpecCharsetAndCollation validates and mutates the values of ".spec.charset" and ".spec.collation".
+func validateAndMutatePostgresqlDatabaseSpecCharsetAndCollation(mutatedObj, previousObj *v1alpha1.PostgresqlDatabase) error {
+	// If no value for ".spec.charset" has been provided, use the default one.
+	if mutatedObj.Spec.Charset == nil {
+		mutatedObj.Spec.Charset = &PostgresqlDatabaseSpecCharsetDefault
+	}
+	// If no value for ".spec.collation" has been provided, use the default one.
+	if mutatedObj.Spec.Collation == nil {
+		mutatedObj.Spec.Collation = &PostgresqlDatabaseSpecCollationDefault
+	}
+	// If the current request is an UPDATE request, make sure that ".spec.charset" is not being changed.
+	if previousObj != nil && previousObj.Spec.Charset != nil && *mutatedObj.Spec.Charset != *previousObj.Spec.Charset {
+		return fmt.Errorf("the charset of the database cannot be changed (had %q, got %q)", *previousObj.Spec.Charset, *mutatedObj.Spec.Charset)
+	}
+	// If the current request is an UPDATE request, make sure that ".spec.collation" is not being changed.
+	if previousObj != nil && previousObj.Spec.Collation != nil && *mutatedObj.Spec.Collation != *previousObj.Spec.Collation {
+		return fmt.Errorf("the collation of the database cannot be changed (had %q, got %q)", *previousObj.Spec.Collation, *mutatedObj.Spec.Collation)
+	}
+	// Make sure that ".spec.charset" is not empty.
+	if *mutatedObj.Spec.Charset == "" {
+		return fmt.Errorf("the charset of the database cannot be empty")
+	}
+	// Make sure that ".spec.collation" is not empty.
+	if *mutatedObj.Spec.Collation == "" {
+		return fmt.Errorf("the collation of the database cannot be empty")
+	}
+	return nil
+}
+
+// validatePostgresqlDatabaseSpecInstance validates the value of ".spec.instance".
+func (w *Webhook) validatePostgresqlDatabaseSpecInstance(mutatedObj, previousObj *v1alpha1.PostgresqlDatabase) error {
+	// If the current request is an UPDATE request, make sure that ".spec.instance" is not being changed/removed.
+	if previousObj != nil && mutatedObj.Spec.Instance != previousObj.Spec.Instance {
+		return fmt.Errorf("the instance of the database cannot be changed (had %q, got %q)", previousObj.Spec.Instance, mutatedObj.Spec.Instance)
+	}
+	// Make sure that ".spec.instance" is not empty.
+	if mutatedObj.Spec.Instance == "" {
+		return fmt.Errorf("the instance of the database cannot be empty")
+	}
+	// If the current request is a CREATE request, make sure that ".spec.instance" references an existing PostgresqlInstance resource.
+	if previousObj == nil {
+		_, err := w.selfClient.CloudsqlV1alpha1().PostgresqlInstances().Get(mutatedObj.Spec.Instance, metav1.GetOptions{})
+		if err != nil {
+			if kubeerrors.IsNotFound(err) {
+				return fmt.Errorf("postgresqlinstance %q does not exist", mutatedObj.Spec.Instance)
+			}
+			return fmt.Errorf("failed to get postgresqlinstance %q: %v", mutatedObj.Spec.Instance, err)
+		}
+	}
+	return nil
+}
+
+// validatePostgresqlDatabaseSpecName validates the value of ".spec.name".
+func (w *Webhook) validatePostgresqlDatabaseSpecName(mutatedObj, previousObj *v1alpha1.PostgresqlDatabase) error {
+	// If the current request is an UPDATE request, make sure that ".spec.name" is not being changed/removed.
+	if previousObj != nil && mutatedObj.Spec.Name != previousObj.Spec.Name {
+		return fmt.Errorf("the name of the database cannot be changed (had %q, got %q)", previousObj.Spec.Name, mutatedObj.Spec.Name)
+	}
+	// Make sure that ".spec.name" is not empty.
+	if mutatedObj.Spec.Name == "" {
+		return fmt.Errorf("the name of the database cannot be empty")
+	}
+	// Make sure that ".spec.name" matches the required format.
+	if !postgresqlDatabaseSpecNameRegex.MatchString(mutatedObj.Spec.Name) {
+		return fmt.Errorf("the name of the database must match the %q regular expression (got %q)", postgresqlDatabaseSpecNameRegex, mutatedObj.Spec.Name)
+	}
+	// Make sure that ".spec.name" is not a reserved name.
+	for _, name := range postgresqlDatabaseSpecNameReserved {
+		if mutatedObj.Spec.Name == name {
+			return fmt.Errorf("the name %q is reserved and cannot be used as a database name", mutatedObj.Spec.Name)
+		}
+	}
+	// If the current request is a CREATE request, make sure that ".spec.name" is neither claimed by another PostgresqlDatabase resource nor in use by a pre-existing database.
+	// Otherwise, the database would end up being dropped (together with all its data) whenever one of the PostgresqlDatabase resources referencing it is deleted.
+	if previousObj == nil {
+		databases, err := w.selfClient.CloudsqlV1alpha1().PostgresqlDatabases().List(metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list postgresqldatabases: %v", err)
+		}
+		for _, d := range databases.Items {
+			if d.Name != mutatedObj.Name && d.Spec.Instance == mutatedObj.Spec.Instance && d.Spec.Name == mutatedObj.Spec.Name {
+				return fmt.Errorf("the name %q is already claimed by postgresqldatabase %q", mutatedObj.Spec.Name, d.Name)
+			}
+		}
+		instance, err := w.selfClient.CloudsqlV1alpha1().PostgresqlInstances().Get(mutatedObj.Spec.Instance, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get postgresqlinstance %q: %v", mutatedObj.Spec.Instance, err)
+		}
+		_, err = w.cloudsqlClient.Databases.Get(w.projectID, instance.Spec.Name, mutatedObj.Spec.Name).Do()
+		if err == nil {
+			// No error has been returned, which means that ".spec.name" is already being used.
+			return fmt.Errorf("the name %q is already in use by a database in instance %q", mutatedObj.Spec.Name, instance.Spec.Name)
+		}
+		if !googleutil.IsNotFound(err) {
+			// An error has been returned, but it is not a "404 NOT FOUND" one.
+			return fmt.Errorf("failed to check whether %q can be used as a database name: %v", mutatedObj.Spec.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/cron"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/pointers"
+)
+
+const (
+	// postgresqlExportSpecDestinationPrefix is the prefix that the value of the ".spec.destination" field of a PostgresqlExport resource must have.
+	postgresqlExportSpecDestinationPrefix = "gs://"
+)
+
+var (
+	// PostgresqlExportSpecFileTypeDefault is the default value for the ".spec.fileType" field of a PostgresqlExport resource.
+	PostgresqlExportSpecFileTypeDefault = v1alpha1.PostgresqlExportSpecFileTypeSQL
+	// PostgresqlExportSpecRetentionDefault is the default value for the ".spec.retention" field of a PostgresqlExport resource.
+	PostgresqlExportSpecRetentionDefault = int32(0)
+)
+
+// postgresqlExportWebhookOperation represents a validation/mutation operation performed by the admission webhook on PostgresqlExport resources.
+type postgresqlExportWebhookOperation func(mutatedObj, previousObj *v1alpha1.PostgresqlExport) error
+
+// validateAndMutatePostgresqlExport validates and mutates the provided PostgresqlExport object.
+// If the current request is a CREATE request, only currentObj is populated.
+// If the current request is an UPDATE request, both currentObj and previousObj are populated.
+// If the current request is a DELETE request, only previousObj is populated.
+func (w *Webhook) validateAndMutatePostgresqlExport(currentObj, previousObj *v1alpha1.PostgresqlExport) (*v1alpha1.PostgresqlExport, error) {
+	// Check whether the current request is a DELETE request and act accordingly.
+	// Deleting a PostgresqlExport resource does not delete any export files, so we always allow the request.
+	if currentObj == nil && previousObj != nil {
+		return nil, nil
+	}
+
+	// At this point we know the current request is either a CREATE or UPDATE request.
+
+	// Clone the current object so that we can safely mutate it if necessary.
+	mutatedObj := currentObj.DeepCopy()
+
+	// Perform the required validation/mutation steps.
+	for _, fn := range []postgresqlExportWebhookOperation{
+		validatePostgresqlExportSpecDatabases,
+		validatePostgresqlExportSpecDestination,
+		validateAndMutatePostgresqlExportSpecFileType,
+		w.validatePostgresqlExportSpecInstance,
+		validateAndMutatePostgresqlExportSpecRetention,
+		validatePostgresqlExportSpecSchedule,
+	} {
+		if err := fn(mutatedObj, previousObj); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return the (possibly) mutated object so a patch can be created if necessary.
+	return mutatedObj, nil
+}
+
+// validatePostgresqlExportSpecDatabases validates the value of ".spec.databases".
+func validatePostgresqlExportSpecDatabases(mutatedObj, _ *v1alpha1.PostgresqlExport) error {
+	// Make sure that ".spec.databases" is not empty.
+	if len(mutatedObj.Spec.Databases) == 0 {
+		return fmt.Errorf("at least one database to export must be specified")
+	}
+	// Make sure that no database is empty or specified more than once.
+	seen := make(map[string]bool, len(mutatedObj.Spec.Databases))
+	for _, database := range mutatedObj.Spec.Databases {
+		if database == "" {
+			return fmt.Errorf("the name of a database to export cannot be empty")
+		}
+		if seen[database] {
+			return fmt.Errorf("database %q is specified more than once", database)
+		}
+		seen[database] = true
+	}
+	return nil
+}
+
+// validatePostgresqlExportSpecDestination validates the value of ".spec.destination".
+func validatePostgresqlExportSpecDestination(mutatedObj, _ *v1alpha1.PostgresqlExport) error {
+	// Make sure that ".spec.destination" is a Google Cloud Storage URI that includes the name of a bucket.
+	if !strings.HasPrefix(mutatedObj.Spec.Destination, postgresqlExportSpecDestinationPrefix) || strings.TrimPrefix(mutatedObj.Spec.Destination, postgresqlExportSpecDestinationPrefix) == "" {
+		return fmt.Errorf("the destination of the export must be a google cloud storage uri in the \"gs://<bucket>/<path>\" format (got %q)", mutatedObj.Spec.Destination)
+	}
+	return nil
+}
+
+// validateAndMutatePostgresqlExportSpecFileType validates and mutates the values of ".spec.fileType" and ".spec.csv".
+func validateAndMutatePostgresqlExportSpecFileType(mutatedObj, _ *v1alpha1.PostgresqlExport) error {
+	// If no value for ".spec.fileType" has been provided, use the default one.
+	if mutatedObj.Spec.FileType == nil {
+		mutatedObj.Spec.FileType = &PostgresqlExportSpecFileTypeDefault
+	}
+	// Make sure that ".spec.fileType" contains a valid value, and that ".spec.csv" is consistent with it.
+	switch *mutatedObj.Spec.FileType {
+	case v1alpha1.PostgresqlExportSpecFileTypeCSV:
+		if mutatedObj.Spec.CSV == nil || mutatedObj.Spec.CSV.SelectQuery == "" {
+			return fmt.Errorf("the query used to select the data to export must be specified when exporting in %q format", v1alpha1.PostgresqlExportSpecFileTypeCSV)
+		}
+	case v1alpha1.PostgresqlExportSpecFileTypeSQL:
+		if mutatedObj.Spec.CSV != nil {
+			return fmt.Errorf("csv options cannot be specified when exporting in %q format", v1alpha1.PostgresqlExportSpecFileTypeSQL)
+		}
+	default:
+		return fmt.Errorf("the file type of the export must be one of %q or %q (got %q)", v1alpha1.PostgresqlExportSpecFileTypeSQL, v1alpha1.PostgresqlExportSpecFileTypeCSV, *mutatedObj.Spec.FileType)
+	}
+	return nil
+}
+
+// validatePostgresqlExportSpecInstance validates the value of ".spec.instance".
+func (w *Webhook) validatePostgresqlExportSpecInstance(mutatedObj, previousObj *v1alpha1.PostgresqlExport) error {
+	// If the current request is an UPDATE request, make sure that ".spec.instance" is not being changed/removed.
+	if previousObj != nil {
+		if mutatedObj.Spec.Instance != previousObj.Spec.Instance {
+			return fmt.Errorf("the instance of the export cannot be changed (had %q, got %q)", previousObj.Spec.Instance, mutatedObj.Spec.Instance)
+		}
+		return nil
+	}
+	// Make sure that ".spec.instance" is not empty.
+	if mutatedObj.Spec.Instance == "" {
+		return fmt.Errorf("the instance of the export cannot be empty")
+	}
+	// Make sure that ".spec.instance" references an existing PostgresqlInstance resource.
+	return w.checkPostgresqlInstanceExists(mutatedObj.Spec.Instance)
+}
+
+// validateAndMutatePostgresqlExportSpecRetention validates and mutates the value of ".spec.retention".
+func validateAndMutatePostgresqlExportSpecRetention(mutatedObj, _ *v1alpha1.PostgresqlExport) error {
+	// If no value for ".spec.retention" has been provided, use the default one.
+	if mutatedObj.Spec.Retention == nil {
+		mutatedObj.Spec.Retention = pointers.NewInt32(PostgresqlExportSpecRetentionDefault)
+	}
+	// Make sure that ".spec.retention" is not negative.
+	if *mutatedObj.Spec.Retention < 0 {
+		return fmt.Errorf("the number of export runs to retain cannot be negative (got %d)", *mutatedObj.Spec.Retention)
+	}
+	return nil
+}
+
+// validatePostgresqlExportSpecSchedule validates the value of ".spec.schedule".
+func validatePostgresqlExportSpecSchedule(mutatedObj, _ *v1alpha1.PostgresqlExport) error {
+	// An empty schedule means that a single export run is performed.
+	if mutatedObj.Spec.Schedule == "" {
+		return nil
+	}
+	// Make sure that ".spec.schedule" is a valid cron expression.
+	if _, err := cron.Parse(mutatedObj.Spec.Schedule); err != nil {
+		return fmt.Errorf("the schedule of the export must be a valid cron expression (got %q): %v", mutatedObj.Spec.Schedule, err)
+	}
+	return nil
+}
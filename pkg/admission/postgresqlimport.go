@@ -0,0 +1,133 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+)
+
+const (
+	// postgresqlImportSpecSourcePrefix is the prefix that the value of the ".spec.source" field of a PostgresqlImport resource must have.
+	postgresqlImportSpecSourcePrefix = "gs://"
+)
+
+var (
+	// PostgresqlImportSpecFileTypeDefault is the default value for the ".spec.fileType" field of a PostgresqlImport resource.
+	PostgresqlImportSpecFileTypeDefault = v1alpha1.PostgresqlImportSpecFileTypeSQL
+)
+
+// postgresqlImportWebhookOperation represents a validation/mutation operation performed by the admission webhook on PostgresqlImport resources.
+type postgresqlImportWebhookOperation func(mutatedObj, previousObj *v1alpha1.PostgresqlImport) error
+
+// validateAndMutatePostgresqlImport validates and mutates the provided PostgresqlImport object.
+// If the current request is a CREATE request, only currentObj is populated.
+// If the current request is an UPDATE request, both currentObj and previousObj are populated.
+// If the current request is a DELETE request, only previousObj is populated.
+func (w *Webhook) validateAndMutatePostgresqlImport(currentObj, previousObj *v1alpha1.PostgresqlImport) (*v1alpha1.PostgresqlImport, error) {
+	// Check whether the current request is a DELETE request and act accordingly.
+	// Deleting a PostgresqlImport resource has no effect on the target CSQLP instance, so we always allow the request.
+	if currentObj == nil && previousObj != nil {
+		return nil, nil
+	}
+
+	// At this point we know the current request is either a CREATE or UPDATE request.
+
+	// Clone the current object so that we can safely mutate it if necessary.
+	mutatedObj := currentObj.DeepCopy()
+
+	// Perform the required validation/mutation steps.
+	for _, fn := range []postgresqlImportWebhookOperation{
+		validateAndMutatePostgresqlImportSpecFileType,
+		validatePostgresqlImportSpecDatabase,
+		w.validatePostgresqlImportSpecInstance,
+		validatePostgresqlImportSpecSource,
+		validatePostgresqlImportSpecIsImmutable,
+	} {
+		if err := fn(mutatedObj, previousObj); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return the (possibly) mutated object so a patch can be created if necessary.
+	return mutatedObj, nil
+}
+
+// validateAndMutatePostgresqlImportSpecFileType validates and mutates the values of ".spec.fileType" and ".spec.csv".
+func validateAndMutatePostgresqlImportSpecFileType(mutatedObj, _ *v1alpha1.PostgresqlImport) error {
+	// If no value for ".spec.fileType" has been provided, use the default one.
+	if mutatedObj.Spec.FileType == nil {
+		mutatedObj.Spec.FileType = &PostgresqlImportSpecFileTypeDefault
+	}
+	// Make sure that ".spec.fileType" contains a valid value, and that ".spec.csv" is consistent with it.
+	switch *mutatedObj.Spec.FileType {
+	case v1alpha1.PostgresqlImportSpecFileTypeCSV:
+		if mutatedObj.Spec.CSV == nil || mutatedObj.Spec.CSV.Table == "" {
+			return fmt.Errorf("the table into which to import the file must be specified when importing a file in %q format", v1alpha1.PostgresqlImportSpecFileTypeCSV)
+		}
+	case v1alpha1.PostgresqlImportSpecFileTypeSQL:
+		if mutatedObj.Spec.CSV != nil {
+			return fmt.Errorf("csv options cannot be specified when importing a file in %q format", v1alpha1.PostgresqlImportSpecFileTypeSQL)
+		}
+	default:
+		return fmt.Errorf("the file type of the import must be one of %q or %q (got %q)", v1alpha1.PostgresqlImportSpecFileTypeSQL, v1alpha1.PostgresqlImportSpecFileTypeCSV, *mutatedObj.Spec.FileType)
+	}
+	return nil
+}
+
+// validatePostgresqlImportSpecDatabase validates the value of ".spec.database".
+func validatePostgresqlImportSpecDatabase(mutatedObj, _ *v1alpha1.PostgresqlImport) error {
+	// Make sure that ".spec.database" is not empty.
+	if mutatedObj.Spec.Database == "" {
+		return fmt.Errorf("the database into which to import the file cannot be empty")
+	}
+	return nil
+}
+
+// validatePostgresqlImportSpecInstance validates the value of ".spec.instance".
+func (w *Webhook) validatePostgresqlImportSpecInstance(mutatedObj, previousObj *v1alpha1.PostgresqlImport) error {
+	// Make sure that ".spec.instance" is not empty.
+	if mutatedObj.Spec.Instance == "" {
+		return fmt.Errorf("the instance of the import cannot be empty")
+	}
+	// If the current request is a CREATE request, make sure that ".spec.instance" references an existing PostgresqlInstance resource.
+	if previousObj == nil {
+		return w.checkPostgresqlInstanceExists(mutatedObj.Spec.Instance)
+	}
+	return nil
+}
+
+// validatePostgresqlImportSpecSource validates the value of ".spec.source".
+func validatePostgresqlImportSpecSource(mutatedObj, _ *v1alpha1.PostgresqlImport) error {
+	// Make sure that ".spec.source" is a Google Cloud Storage URI that includes the name of a bucket and of an object.
+	if parts := strings.SplitN(strings.TrimPrefix(mutatedObj.Spec.Source, postgresqlImportSpecSourcePrefix), "/", 2); !strings.HasPrefix(mutatedObj.Spec.Source, postgresqlImportSpecSourcePrefix) || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("the source of the import must be a google cloud storage uri in the \"gs://<bucket>/<object>\" format (got %q)", mutatedObj.Spec.Source)
+	}
+	return nil
+}
+
+// validatePostgresqlImportSpecIsImmutable makes sure that the specification of a PostgresqlImport resource is not changed after it has been created.
+func validatePostgresqlImportSpecIsImmutable(mutatedObj, previousObj *v1alpha1.PostgresqlImport) error {
+	// Each PostgresqlImport resource results in a single import operation, so changing its specification would have no effect.
+	if previousObj != nil && !reflect.DeepEqual(mutatedObj.Spec, previousObj.Spec) {
+		return fmt.Errorf("the specification of the import cannot be changed")
+	}
+	return nil
+}
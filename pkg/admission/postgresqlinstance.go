@@ -18,19 +18,46 @@ package admission
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
+	"time"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
 	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/cron"
 	googleutil "github.com/travelaudience/cloudsql-postgres-operator/pkg/util/google"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/pointers"
+	stringsutil "github.com/travelaudience/cloudsql-postgres-operator/pkg/util/strings"
 )
 
 const (
+	// postgresqlInstanceSpecBackupsPointInTimeRecoveryTransactionLogRetentionDaysLowerBound is the lower bound on the value of the ".spec.backups.pointInTimeRecovery.transactionLogRetentionDays" field of a PostgresqlInstance resource.
+	postgresqlInstanceSpecBackupsPointInTimeRecoveryTransactionLogRetentionDaysLowerBound = 1
+	// postgresqlInstanceSpecBackupsPointInTimeRecoveryTransactionLogRetentionDaysUpperBound is the upper bound on the value of the ".spec.backups.pointInTimeRecovery.transactionLogRetentionDays" field of a PostgresqlInstance resource.
+	postgresqlInstanceSpecBackupsPointInTimeRecoveryTransactionLogRetentionDaysUpperBound = 7
+	// postgresqlInstanceSpecBackupsRetainedBackupsLowerBound is the lower bound on the value of the ".spec.backups.retainedBackups" field of a PostgresqlInstance resource.
+	postgresqlInstanceSpecBackupsRetainedBackupsLowerBound = 1
+	// postgresqlInstanceSpecBackupsRetainedBackupsUpperBound is the upper bound on the value of the ".spec.backups.retainedBackups" field of a PostgresqlInstance resource.
+	postgresqlInstanceSpecBackupsRetainedBackupsUpperBound = 365
+	// postgresqlInstanceSpecInsightsQueryStringLengthLowerBound is the lower bound on the value of the ".spec.insights.queryStringLength" field of a PostgresqlInstance resource.
+	postgresqlInstanceSpecInsightsQueryStringLengthLowerBound = 256
+	// postgresqlInstanceSpecInsightsQueryStringLengthUpperBound is the upper bound on the value of the ".spec.insights.queryStringLength" field of a PostgresqlInstance resource.
+	postgresqlInstanceSpecInsightsQueryStringLengthUpperBound = 4500
 	// postgresqlInstanceSpecResourcesDiskSizeMinimumGbLowerBound is the lower bound on the value of the ".spec.resources.disk.sizeMinimumGb" field of a PostgresqlInstance resource.
 	postgresqlInstanceSpecResourcesDiskSizeMinimumGbLowerBound = 10
+	// postgresqlInstanceSpecFinalBackupDestinationPrefix is the prefix that the value of the ".spec.finalBackup.destination" field of a PostgresqlInstance resource must have.
+	postgresqlInstanceSpecFinalBackupDestinationPrefix = "gs://"
 	// PostgresqlInstanceSpecFlagsSeparator is the separator that must be used between "<name>" and "<value>" in each item present in the ".spec.flags" field of a PostgresqlInstance resource.
 	PostgresqlInstanceSpecFlagsSeparator = "="
+	// postgresqlInstanceSpecNamePrefixSuffixAlphabet is the alphabet used to build the random suffix appended to the value of the ".spec.namePrefix" field of a PostgresqlInstance resource in order to generate a unique name.
+	postgresqlInstanceSpecNamePrefixSuffixAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+	// postgresqlInstanceSpecNamePrefixSuffixLength is the length of the random suffix appended to the value of the ".spec.namePrefix" field of a PostgresqlInstance resource in order to generate a unique name.
+	postgresqlInstanceSpecNamePrefixSuffixLength = 8
 	// postgresqlInstanceSpecNameProjectIDMaxLength is the maximum length that the ".spec.name" field of a PostgresqlInstance resource may have when concatenated with the Google Cloud Platform project's ID.
 	postgresqlInstanceSpecNameProjectIDMaxLength = 97
 	// PostgresqlInstanceSpecLabelsOwnerName is the name of the "owner" label set injected on the ".spec.labels" field of a PostgresqlInstance resource.
@@ -40,29 +67,63 @@ const (
 var (
 	// hourOfTheDayRegex is the regular expression used to match hours of the day in 24-hour format.
 	hourOfTheDayRegex = regexp.MustCompile(`^([01][0-9]|2[0-3]):00$`)
+	// postgresqlInstanceSpecBackupsLocationRegex is the regular expression used to validate the ".spec.backups.location" field of a PostgresqlInstance resource.
+	postgresqlInstanceSpecBackupsLocationRegex = regexp.MustCompile(`^[a-z]+(-[a-z]+[0-9]+)?$`)
+	// postgresqlInstanceSpecNamePrefixRegex is the regular expression used to validate the ".spec.namePrefix" field of a PostgresqlInstance resource.
+	postgresqlInstanceSpecNamePrefixRegex = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
 	// postgresqlInstanceSpecNameRegex is the regular expression used to validate the ".spec.name" field of a PostgresqlInstance resource.
 	postgresqlInstanceSpecNameRegex = regexp.MustCompile(`^[a-z][a-z0-9-]+[a-z0-9]$`)
 )
 
 var (
+	// PostgresqlInstanceSpecActivationPolicyDefault is the default value for the ".spec.activationPolicy" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecActivationPolicyDefault = v1alpha1.PostgresqlInstanceSpecActivationPolicyAlways
 	// PostgresqlInstanceSpecAvailabilityTypeDefault is the default value for the ".spec.availability.type" field of a PostgresqlInstance resource.
 	PostgresqlInstanceSpecAvailabilityTypeDefault = v1alpha1.PostgresqlInstanceSpecAvailabilityTypeZonal
 	// PostgresqlInstanceSpecBackupsDailyEnabledDefault is the default value for the ".spec.backups.daily.enabled" field of a PostgresqlInstance resource.
 	PostgresqlInstanceSpecBackupsDailyEnabledDefault = true
 	// PostgresqlInstanceSpecBackupsDailyStartTimeDefault is the default value for the ".spec.backups.daily.startTime" field of a PostgresqlInstance resource.
 	PostgresqlInstanceSpecBackupsDailyStartTimeDefault = "00:00"
+	// PostgresqlInstanceSpecBackupsPointInTimeRecoveryEnabledDefault is the default value for the ".spec.backups.pointInTimeRecovery.enabled" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecBackupsPointInTimeRecoveryEnabledDefault = false
+	// PostgresqlInstanceSpecBackupsPointInTimeRecoveryTransactionLogRetentionDaysDefault is the default value for the ".spec.backups.pointInTimeRecovery.transactionLogRetentionDays" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecBackupsPointInTimeRecoveryTransactionLogRetentionDaysDefault = int32(7)
+	// PostgresqlInstanceSpecBackupsRetainedBackupsDefault is the default value for the ".spec.backups.retainedBackups" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecBackupsRetainedBackupsDefault = int32(7)
+	// PostgresqlInstanceSpecDeletionPolicyDefault is the default value for the ".spec.deletionPolicy" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecDeletionPolicyDefault = v1alpha1.PostgresqlInstanceSpecDeletionPolicyDelete
+	// PostgresqlInstanceSpecDeletionProtectionDefault is the default value for the ".spec.deletionProtection" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecDeletionProtectionDefault = false
+	// PostgresqlInstanceSpecInsightsEnabledDefault is the default value for the ".spec.insights.enabled" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecInsightsEnabledDefault = false
+	// PostgresqlInstanceSpecInsightsQueryStringLengthDefault is the default value for the ".spec.insights.queryStringLength" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecInsightsQueryStringLengthDefault = int32(1024)
+	// PostgresqlInstanceSpecInsightsRecordApplicationTagsDefault is the default value for the ".spec.insights.recordApplicationTags" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecInsightsRecordApplicationTagsDefault = false
+	// PostgresqlInstanceSpecInsightsRecordClientAddressDefault is the default value for the ".spec.insights.recordClientAddress" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecInsightsRecordClientAddressDefault = false
 	// PostgresqlInstanceSpecLocationRegionDefault is the default value for the ".spec.location.region" field of a PostgresqlInstance resource.
 	PostgresqlInstanceSpecLocationRegionDefault = "europe-west1"
 	// PostgresqlInstanceSpecLocationZoneDefault is the default value for the ".spec.location.zone" field of a PostgresqlInstance resource.
 	PostgresqlInstanceSpecLocationZoneDefault = v1alpha1.PostgresqlInstanceSpecLocationZoneAny
 	// PostgresqlInstanceSpecMaintenanceDayDefault is the default value for the ".spec.maintenance.day" field of a PostgresqlInstance resource.
 	PostgresqlInstanceSpecMaintenanceDayDefault = v1alpha1.PostgresqlInstanceSpecMaintenanceDayAny
+	// PostgresqlInstanceSpecMaintenanceDenyPeriodsTimeDefault is the default value for the ".spec.maintenance.denyPeriods[*].time" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecMaintenanceDenyPeriodsTimeDefault = "00:00:00"
+	// PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyDefault is the default value for the ".spec.maintenance.disruptiveUpdatePolicy" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyDefault = v1alpha1.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyImmediate
+	// PostgresqlInstanceSpecMaintenanceUpdateTrackDefault is the default value for the ".spec.maintenance.updateTrack" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecMaintenanceUpdateTrackDefault = v1alpha1.PostgresqlInstanceSpecMaintenanceUpdateTrackStable
 	// PostgresqlInstanceSpecNetworkingPrivateIPEnabledDefault is the default value for the ".spec.networking.privateIp.enabled" field of a PostgresqlInstance resource.
 	PostgresqlInstanceSpecNetworkingPrivateIPEnabledDefault = false
 	// PostgresqlInstanceSpecNetworkingPrivateIPNetworkDefault is the default value for the ".spec.networking.privateIp.network" field of a PostgresqlInstance resource.
 	PostgresqlInstanceSpecNetworkingPrivateIPNetworkDefault = ""
 	// PostgresqlInstanceSpecNetworkingPublicIPEnabledDefault is the default value for the ".spec.networking.publicIp.enabled" field of a PostgresqlInstance resource.
 	PostgresqlInstanceSpecNetworkingPublicIPEnabledDefault = false
+	// PostgresqlInstanceSpecNetworkingRequireSslDefault is the default value for the ".spec.networking.requireSsl" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecNetworkingRequireSslDefault = false
+	// PostgresqlInstanceSpecNetworkingServerCAAutoRotateDefault is the default value for the ".spec.networking.serverCa.autoRotate" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecNetworkingServerCAAutoRotateDefault = false
 	// PostgresqlInstanceSpecResourcesDiskSizeMaximumGbDefault is the default value for the ".spec.resources.disk.sizeMaximumGb" field of a PostgresqlInstance resource.
 	PostgresqlInstanceSpecResourcesDiskSizeMaximumGbDefault = int32(0)
 	// PostgresqlInstanceSpecResourcesDiskSizeMinimumGbDefault is the default value for the ".spec.resources.disk.sizeMinimumGb" field of a PostgresqlInstance resource.
@@ -71,6 +132,8 @@ var (
 	PostgresqlInstanceSpecResourcesDiskTypeDefault = v1alpha1.PostgresqlInstanceSpecResourceDiskTypeSSD
 	// PostgresqlInstanceSpecResourcesInstanceTypeDefault is the default value for the ".spec.resources.instanceType" field of a PostgresqlInstance resource.
 	PostgresqlInstanceSpecResourcesInstanceTypeDefault = "db-custom-1-3840"
+	// PostgresqlInstanceSpecScheduleTimeZoneDefault is the default value for the ".spec.schedule.timeZone" field of a PostgresqlInstance resource.
+	PostgresqlInstanceSpecScheduleTimeZoneDefault = "UTC"
 	// PostgresqlInstanceSpecVersionDefault is the default value for the ".spec.version" field of a PostgresqlInstance resource.
 	PostgresqlInstanceSpecVersionDefault = v1alpha1.PostgresqlInstanceSpecVersion96
 )
@@ -100,16 +163,30 @@ func (w *Webhook) validateAndMutatePostgresqlInstance(currentObj, previousObj *v
 	// Perform the required validation/mutation steps.
 	for _, fn := range []postgresqlInstanceWebhookOperation{
 		mutatePostgresqlInstanceMetadataAnnotations,
+		validatePostgresqlInstanceMetadataAnnotations,
+		// The name of the instance must be validated before any defaults are applied, as the unset fields of an adopted instance are taken from the pre-existing CSQLP instance.
+		w.validateAndMutatePostgresqlInstanceSpecName,
+		validateAndMutatePostgresqlInstanceSpecActivationPolicy,
+		validatePostgresqlInstanceSpecAdopt,
 		validateAndMutatePostgresqlInstanceSpecAvailability,
 		validateAndMutatePostgresqlInstanceSpecDailyBackups,
-		validateAndMutatePostgresqlInstanceSpecFlags,
+		validateAndMutatePostgresqlInstanceSpecBackupsLocation,
+		validateAndMutatePostgresqlInstanceSpecBackupsRetainedBackups,
+		// Point-in-time recovery must be validated after daily backups and the number of retained backups, as it depends on both.
+		validateAndMutatePostgresqlInstanceSpecBackupsPointInTimeRecovery,
+		validateAndMutatePostgresqlInstanceSpecDeletionPolicy,
+		validateAndMutatePostgresqlInstanceSpecDeletionProtection,
+		validateAndMutatePostgresqlInstanceSpecInsights,
 		validateAndMutatePostgresqlInstanceSpecLabels,
 		validateAndMutatePostgresqlInstanceSpecLocation,
 		validateAndMutatePostgresqlInstanceSpecMaintenance,
-		w.validatePostgresqlInstanceSpecName,
 		w.validateAndMutatePostgresqlInstanceSpecNetworking,
 		validateAndMutatePostgresqlInstanceSpecResources,
+		validateAndMutatePostgresqlInstanceSpecSchedule,
+		w.validateAndMutatePostgresqlInstanceSpecSource,
 		validateAndMutatePostgresqlInstanceSpecVersion,
+		// Database flags must be validated after ".spec.version" has been defaulted or copied from the source instance, as the set of supported flags depends on it.
+		w.validateAndMutatePostgresqlInstanceSpecFlags,
 	} {
 		if err := fn(mutatedObj, previousObj); err != nil {
 			return nil, err
@@ -133,6 +210,68 @@ func mutatePostgresqlInstanceMetadataAnnotations(mutatedObj, _ *v1alpha1.Postgre
 	return nil
 }
 
+// validatePostgresqlInstanceMetadataAnnotations validates the annotations used to request actions on the specified PostgresqlInstance resource.
+func validatePostgresqlInstanceMetadataAnnotations(mutatedObj, previousObj *v1alpha1.PostgresqlInstance) error {
+	// Make sure that the "cloudsql.travelaudience.com/restart-requested-at", "cloudsql.travelaudience.com/failover-requested-at" and "cloudsql.travelaudience.com/disruptive-update-approved-at" annotations, if present, contain a timestamp in RFC 3339 format.
+	for _, key := range []string{constants.DisruptiveUpdateApprovedAtAnnotationKey, constants.FailoverRequestedAtAnnotationKey, constants.RestartRequestedAtAnnotationKey} {
+		if v, exists := mutatedObj.Annotations[key]; exists {
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				return fmt.Errorf("the value of the %q annotation must be a timestamp in rfc 3339 format (got %q)", key, v)
+			}
+		}
+	}
+	// If a failover is being requested, make sure that the CSQLP instance is regional, as only regional instances have a standby to fail over to.
+	v, exists := mutatedObj.Annotations[constants.FailoverRequestedAtAnnotationKey]
+	if !exists || (previousObj != nil && previousObj.Annotations[constants.FailoverRequestedAtAnnotationKey] == v) {
+		return nil
+	}
+	if mutatedObj.Spec.Availability == nil || mutatedObj.Spec.Availability.Type == nil || *mutatedObj.Spec.Availability.Type != v1alpha1.PostgresqlInstanceSpecAvailabilityTypeRegional {
+		return fmt.Errorf("a failover can only be requested for instances whose availability type is %q", v1alpha1.PostgresqlInstanceSpecAvailabilityTypeRegional)
+	}
+	return nil
+}
+
+// validateAndMutatePostgresqlInstanceSpecActivationPolicy validates and mutates the value of ".spec.activationPolicy".
+func validateAndMutatePostgresqlInstanceSpecActivationPolicy(mutatedObj, _ *v1alpha1.PostgresqlInstance) error {
+	// If no value for ".spec.activationPolicy" has been provided, use the default one.
+	if mutatedObj.Spec.ActivationPolicy == nil {
+		mutatedObj.Spec.ActivationPolicy = &PostgresqlInstanceSpecActivationPolicyDefault
+	}
+	// Make sure that ".spec.activationPolicy" contains a valid value.
+	switch *mutatedObj.Spec.ActivationPolicy {
+	case v1alpha1.PostgresqlInstanceSpecActivationPolicyAlways, v1alpha1.PostgresqlInstanceSpecActivationPolicyNever:
+		// The value is valid.
+	default:
+		return fmt.Errorf("the activation policy of the instance must be one of %q or %q (got %q)", v1alpha1.PostgresqlInstanceSpecActivationPolicyAlways, v1alpha1.PostgresqlInstanceSpecActivationPolicyNever, *mutatedObj.Spec.ActivationPolicy)
+	}
+	return nil
+}
+
+// validatePostgresqlInstanceSpecAdopt validates the value of ".spec.adopt".
+func validatePostgresqlInstanceSpecAdopt(mutatedObj, previousObj *v1alpha1.PostgresqlInstance) error {
+	// If the current request is an UPDATE request, make sure that ".spec.adopt" is not being changed.
+	// Adoption only happens when the PostgresqlInstance resource is created, so there's nothing else to check in this case.
+	if previousObj != nil {
+		if mutatedObj.Spec.Adopt != previousObj.Spec.Adopt {
+			return fmt.Errorf("whether the instance is adopted cannot be changed")
+		}
+		return nil
+	}
+	// If no pre-existing CSQLP instance is being adopted, there's nothing else to check.
+	if !mutatedObj.Spec.Adopt {
+		return nil
+	}
+	// Make sure that adoption has been explicitly confirmed, since the CSQLP instance may be deleted together with the PostgresqlInstance resource.
+	if v, exists := mutatedObj.Annotations[constants.ConfirmAdoptionAnnotationKey]; !exists || v != v1alpha1.True {
+		return fmt.Errorf("the instance cannot be adopted unless the %q annotation is set to %q", constants.ConfirmAdoptionAnnotationKey, v1alpha1.True)
+	}
+	// Make sure that the CSQLP instance is not being cloned at the same time.
+	if mutatedObj.Spec.Source != nil {
+		return fmt.Errorf("the instance cannot be both adopted and created as a clone")
+	}
+	return nil
+}
+
 // validateAndMutatePostgresqlInstanceSpecAvailability validates and mutates the value of ".spec.availability.type".
 func validateAndMutatePostgresqlInstanceSpecAvailability(mutatedObj, _ *v1alpha1.PostgresqlInstance) error {
 	// Make sure that ".spec.availability" is initialized.
@@ -178,18 +317,138 @@ func validateAndMutatePostgresqlInstanceSpecDailyBackups(mutatedObj, _ *v1alpha1
 	return nil
 }
 
-// validateAndMutateInstanceSpecFlags validates and mutates the value of ".spec.flags".
-func validateAndMutatePostgresqlInstanceSpecFlags(mutatedObj, _ *v1alpha1.PostgresqlInstance) error {
+// validateAndMutatePostgresqlInstanceSpecBackupsLocation validates the value of ".spec.backups.location".
+func validateAndMutatePostgresqlInstanceSpecBackupsLocation(mutatedObj, _ *v1alpha1.PostgresqlInstance) error {
+	// If no value for ".spec.backups.location" has been provided, backups are stored in the multi-region closest to the CSQLP instance, so there's nothing else to check.
+	if mutatedObj.Spec.Backups.Location == nil {
+		return nil
+	}
+	// Make sure that the value of ".spec.backups.location" matches the required format.
+	if !postgresqlInstanceSpecBackupsLocationRegex.MatchString(*mutatedObj.Spec.Backups.Location) {
+		return fmt.Errorf("the location for backups of the instance must be a region or multi-region matching the %q regular expression (got %q)", postgresqlInstanceSpecBackupsLocationRegex, *mutatedObj.Spec.Backups.Location)
+	}
+	return nil
+}
+
+// validateAndMutatePostgresqlInstanceSpecBackupsPointInTimeRecovery validates and mutates the value of ".spec.backups.pointInTimeRecovery".
+func validateAndMutatePostgresqlInstanceSpecBackupsPointInTimeRecovery(mutatedObj, _ *v1alpha1.PostgresqlInstance) error {
+	// Make sure that ".spec.backups.pointInTimeRecovery" is initialized.
+	if mutatedObj.Spec.Backups.PointInTimeRecovery == nil {
+		mutatedObj.Spec.Backups.PointInTimeRecovery = &v1alpha1.PostgresqlInstanceSpecBackupsPointInTimeRecovery{}
+	}
+	// If no value for ".spec.backups.pointInTimeRecovery.enabled" has been provided, use the default one.
+	if mutatedObj.Spec.Backups.PointInTimeRecovery.Enabled == nil {
+		mutatedObj.Spec.Backups.PointInTimeRecovery.Enabled = &PostgresqlInstanceSpecBackupsPointInTimeRecoveryEnabledDefault
+	}
+	// If no value for ".spec.backups.pointInTimeRecovery.transactionLogRetentionDays" has been provided, use the default one.
+	if mutatedObj.Spec.Backups.PointInTimeRecovery.TransactionLogRetentionDays == nil {
+		mutatedObj.Spec.Backups.PointInTimeRecovery.TransactionLogRetentionDays = &PostgresqlInstanceSpecBackupsPointInTimeRecoveryTransactionLogRetentionDaysDefault
+	}
+	// Make sure that ".spec.backups.pointInTimeRecovery.transactionLogRetentionDays" is within the bounds allowed by Cloud SQL.
+	if v := *mutatedObj.Spec.Backups.PointInTimeRecovery.TransactionLogRetentionDays; v < postgresqlInstanceSpecBackupsPointInTimeRecoveryTransactionLogRetentionDaysLowerBound || v > postgresqlInstanceSpecBackupsPointInTimeRecoveryTransactionLogRetentionDaysUpperBound {
+		return fmt.Errorf("the number of days of transaction logs to retain for the instance must be between %d and %d (got \"%d\")", postgresqlInstanceSpecBackupsPointInTimeRecoveryTransactionLogRetentionDaysLowerBound, postgresqlInstanceSpecBackupsPointInTimeRecoveryTransactionLogRetentionDaysUpperBound, v)
+	}
+	// Make sure that ".spec.backups.pointInTimeRecovery.transactionLogRetentionDays" does not exceed ".spec.backups.retainedBackups", as Cloud SQL rejects such settings.
+	if *mutatedObj.Spec.Backups.PointInTimeRecovery.TransactionLogRetentionDays > *mutatedObj.Spec.Backups.RetainedBackups {
+		return fmt.Errorf("the number of days of transaction logs to retain for the instance cannot exceed the number of retained backups (got \"%d\" and \"%d\")", *mutatedObj.Spec.Backups.PointInTimeRecovery.TransactionLogRetentionDays, *mutatedObj.Spec.Backups.RetainedBackups)
+	}
+	// Make sure that daily backups are enabled if point-in-time recovery is enabled, as Cloud SQL requires the former for the latter.
+	if *mutatedObj.Spec.Backups.PointInTimeRecovery.Enabled && !*mutatedObj.Spec.Backups.Daily.Enabled {
+		return fmt.Errorf("point-in-time recovery cannot be enabled for the instance unless daily backups are enabled")
+	}
+	return nil
+}
+
+// validateAndMutatePostgresqlInstanceSpecBackupsRetainedBackups validates and mutates the value of ".spec.backups.retainedBackups".
+func validateAndMutatePostgresqlInstanceSpecBackupsRetainedBackups(mutatedObj, _ *v1alpha1.PostgresqlInstance) error {
+	// If no value for ".spec.backups.retainedBackups" has been provided, use the default one.
+	if mutatedObj.Spec.Backups.RetainedBackups == nil {
+		mutatedObj.Spec.Backups.RetainedBackups = &PostgresqlInstanceSpecBackupsRetainedBackupsDefault
+	}
+	// Make sure that ".spec.backups.retainedBackups" is within the bounds allowed by Cloud SQL.
+	if v := *mutatedObj.Spec.Backups.RetainedBackups; v < postgresqlInstanceSpecBackupsRetainedBackupsLowerBound || v > postgresqlInstanceSpecBackupsRetainedBackupsUpperBound {
+		return fmt.Errorf("the number of backups to retain for the instance must be between %d and %d (got \"%d\")", postgresqlInstanceSpecBackupsRetainedBackupsLowerBound, postgresqlInstanceSpecBackupsRetainedBackupsUpperBound, v)
+	}
+	return nil
+}
+
+// validateAndMutatePostgresqlInstanceSpecDeletionPolicy validates and mutates the values of ".spec.deletionPolicy" and ".spec.finalBackup".
+func validateAndMutatePostgresqlInstanceSpecDeletionPolicy(mutatedObj, _ *v1alpha1.PostgresqlInstance) error {
+	// If no value for ".spec.deletionPolicy" has been provided, use the default one.
+	if mutatedObj.Spec.DeletionPolicy == nil {
+		mutatedObj.Spec.DeletionPolicy = &PostgresqlInstanceSpecDeletionPolicyDefault
+	}
+	// Make sure that ".spec.deletionPolicy" contains a valid value.
+	switch *mutatedObj.Spec.DeletionPolicy {
+	case v1alpha1.PostgresqlInstanceSpecDeletionPolicyBackupThenDelete:
+		// The value is valid, but a destination for the final backup must be provided.
+		if mutatedObj.Spec.FinalBackup == nil {
+			return fmt.Errorf("the destination of the final backup must be specified when the deletion policy of the instance is %q", v1alpha1.PostgresqlInstanceSpecDeletionPolicyBackupThenDelete)
+		}
+	case v1alpha1.PostgresqlInstanceSpecDeletionPolicyDelete, v1alpha1.PostgresqlInstanceSpecDeletionPolicyRetain:
+		// The value is valid.
+	default:
+		return fmt.Errorf("the deletion policy of the instance must be one of %q, %q or %q (got %q)", v1alpha1.PostgresqlInstanceSpecDeletionPolicyBackupThenDelete, v1alpha1.PostgresqlInstanceSpecDeletionPolicyDelete, v1alpha1.PostgresqlInstanceSpecDeletionPolicyRetain, *mutatedObj.Spec.DeletionPolicy)
+	}
+	// If ".spec.finalBackup" has been provided, make sure that ".spec.finalBackup.destination" is a Google Cloud Storage URI that includes the name of a bucket.
+	if mutatedObj.Spec.FinalBackup != nil {
+		if d := mutatedObj.Spec.FinalBackup.Destination; !strings.HasPrefix(d, postgresqlInstanceSpecFinalBackupDestinationPrefix) || strings.TrimPrefix(d, postgresqlInstanceSpecFinalBackupDestinationPrefix) == "" {
+			return fmt.Errorf("the destination of the final backup must be a google cloud storage uri in the \"gs://<bucket>/<path>\" format (got %q)", d)
+		}
+	}
+	return nil
+}
+
+// validateAndMutatePostgresqlInstanceSpecDeletionProtection validates and mutates the value of ".spec.deletionProtection".
+func validateAndMutatePostgresqlInstanceSpecDeletionProtection(mutatedObj, _ *v1alpha1.PostgresqlInstance) error {
+	// If no value for ".spec.deletionProtection" has been provided, use the default one.
+	if mutatedObj.Spec.DeletionProtection == nil {
+		mutatedObj.Spec.DeletionProtection = &PostgresqlInstanceSpecDeletionProtectionDefault
+	}
+	return nil
+}
+
+// validateAndMutatePostgresqlInstanceSpecFlags validates and mutates the values of ".spec.flags" and ".spec.databaseFlags".
+func (w *Webhook) validateAndMutatePostgresqlInstanceSpecFlags(mutatedObj, previousObj *v1alpha1.PostgresqlInstance) error {
 	// Make sure that ".spec.flags" is initialized.
 	if mutatedObj.Spec.Flags == nil {
 		mutatedObj.Spec.Flags = make([]string, 0)
 	}
-	// Iterate over the list of flags and validate the format of each item.
-	for _, flag := range mutatedObj.Spec.Flags {
-		parts := strings.Split(flag, PostgresqlInstanceSpecFlagsSeparator)
-		if len(parts) != 2 {
-			return fmt.Errorf("flags must be specified in the \"<name>=<value>\" format (got %q)", flag)
-		}
+	// ".spec.version" has already been validated and defaulted (or copied from the source instance) at this point.
+	version := *mutatedObj.Spec.Version
+	// Validate the flags against the catalog of database flags only if the current request is a CREATE request or if the flags or the version are being changed, so that existing resources are not affected by changes to the catalog.
+	checkCatalog := previousObj == nil ||
+		!reflect.DeepEqual(mutatedObj.Spec.Flags, previousObj.Spec.Flags) ||
+		!reflect.DeepEqual(mutatedObj.Spec.DatabaseFlags, previousObj.Spec.DatabaseFlags) ||
+		previousObj.Spec.Version == nil || version != *previousObj.Spec.Version
+	return w.validateDatabaseFlags(mutatedObj.Spec.Flags, mutatedObj.Spec.DatabaseFlags, version, checkCatalog)
+}
+
+// validateAndMutatePostgresqlInstanceSpecInsights validates and mutates the value of ".spec.insights".
+func validateAndMutatePostgresqlInstanceSpecInsights(mutatedObj, _ *v1alpha1.PostgresqlInstance) error {
+	// Make sure that ".spec.insights" is initialized.
+	if mutatedObj.Spec.Insights == nil {
+		mutatedObj.Spec.Insights = &v1alpha1.PostgresqlInstanceSpecInsights{}
+	}
+	// If no value for ".spec.insights.enabled" has been provided, use the default one.
+	if mutatedObj.Spec.Insights.Enabled == nil {
+		mutatedObj.Spec.Insights.Enabled = &PostgresqlInstanceSpecInsightsEnabledDefault
+	}
+	// If no value for ".spec.insights.queryStringLength" has been provided, use the default one.
+	if mutatedObj.Spec.Insights.QueryStringLength == nil {
+		mutatedObj.Spec.Insights.QueryStringLength = &PostgresqlInstanceSpecInsightsQueryStringLengthDefault
+	}
+	// If no value for ".spec.insights.recordApplicationTags" has been provided, use the default one.
+	if mutatedObj.Spec.Insights.RecordApplicationTags == nil {
+		mutatedObj.Spec.Insights.RecordApplicationTags = &PostgresqlInstanceSpecInsightsRecordApplicationTagsDefault
+	}
+	// If no value for ".spec.insights.recordClientAddress" has been provided, use the default one.
+	if mutatedObj.Spec.Insights.RecordClientAddress == nil {
+		mutatedObj.Spec.Insights.RecordClientAddress = &PostgresqlInstanceSpecInsightsRecordClientAddressDefault
+	}
+	// Make sure that ".spec.insights.queryStringLength" is within the bounds allowed by Cloud SQL.
+	if v := *mutatedObj.Spec.Insights.QueryStringLength; v < postgresqlInstanceSpecInsightsQueryStringLengthLowerBound || v > postgresqlInstanceSpecInsightsQueryStringLengthUpperBound {
+		return fmt.Errorf("the maximum length of the query strings recorded by query insights must be between %d and %d (got \"%d\")", postgresqlInstanceSpecInsightsQueryStringLengthLowerBound, postgresqlInstanceSpecInsightsQueryStringLengthUpperBound, v)
 	}
 	return nil
 }
@@ -266,15 +525,67 @@ func validateAndMutatePostgresqlInstanceSpecMaintenance(mutatedObj, _ *v1alpha1.
 	default:
 		return fmt.Errorf("the hour of the day for periodic maintenance may be %q if and only if the day of the week is %q", v1alpha1.PostgresqlInstanceSpecMaintenanceHourAny, v1alpha1.PostgresqlInstanceSpecMaintenanceDayAny)
 	}
-	return nil
+	// If no value for ".spec.maintenance.disruptiveUpdatePolicy" has been provided, use the default one.
+	if mutatedObj.Spec.Maintenance.DisruptiveUpdatePolicy == nil {
+		mutatedObj.Spec.Maintenance.DisruptiveUpdatePolicy = &PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyDefault
+	}
+	// Make sure that ".spec.maintenance.disruptiveUpdatePolicy" contains a valid value.
+	switch *mutatedObj.Spec.Maintenance.DisruptiveUpdatePolicy {
+	case v1alpha1.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyImmediate, v1alpha1.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyOnApproval:
+		// The value is valid.
+	case v1alpha1.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow:
+		// Make sure that a maintenance window has actually been defined.
+		if *mutatedObj.Spec.Maintenance.Day == v1alpha1.PostgresqlInstanceSpecMaintenanceDayAny {
+			return fmt.Errorf("disruptive updates can only be applied in the maintenance window if the day of the week for periodic maintenance is not %q", v1alpha1.PostgresqlInstanceSpecMaintenanceDayAny)
+		}
+	default:
+		return fmt.Errorf("the policy for disruptive updates must be one of %q, %q or %q (got %q)", v1alpha1.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyImmediate, v1alpha1.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow, v1alpha1.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyOnApproval, *mutatedObj.Spec.Maintenance.DisruptiveUpdatePolicy)
+	}
+	// If no value for ".spec.maintenance.updateTrack" has been provided, use the default one.
+	if mutatedObj.Spec.Maintenance.UpdateTrack == nil {
+		mutatedObj.Spec.Maintenance.UpdateTrack = &PostgresqlInstanceSpecMaintenanceUpdateTrackDefault
+	}
+	// Make sure that ".spec.maintenance.updateTrack" contains a valid value.
+	switch *mutatedObj.Spec.Maintenance.UpdateTrack {
+	case v1alpha1.PostgresqlInstanceSpecMaintenanceUpdateTrackCanary, v1alpha1.PostgresqlInstanceSpecMaintenanceUpdateTrackStable:
+		// The value is valid.
+	default:
+		return fmt.Errorf("the update track for periodic maintenance must be either %q or %q (got %q)", v1alpha1.PostgresqlInstanceSpecMaintenanceUpdateTrackCanary, v1alpha1.PostgresqlInstanceSpecMaintenanceUpdateTrackStable, *mutatedObj.Spec.Maintenance.UpdateTrack)
+	}
+	// If no value for ".spec.maintenance.denyPeriods[*].time" has been provided, use the default one.
+	for idx := range mutatedObj.Spec.Maintenance.DenyPeriods {
+		if mutatedObj.Spec.Maintenance.DenyPeriods[idx].Time == nil {
+			mutatedObj.Spec.Maintenance.DenyPeriods[idx].Time = &PostgresqlInstanceSpecMaintenanceDenyPeriodsTimeDefault
+		}
+	}
+	// Make sure that ".spec.maintenance.denyPeriods" contains valid deny periods which do not overlap and are within the limits imposed by Cloud SQL.
+	return validateDenyPeriods(mutatedObj.Spec.Maintenance.DenyPeriods)
 }
 
-// validatePostgresqlInstanceSpecName validates the value of ".spec.name".
-func (w *Webhook) validatePostgresqlInstanceSpecName(mutatedObj, previousObj *v1alpha1.PostgresqlInstance) error {
-	// If the current request is an UPDATE request, make sure that ".spec.name" is not being changed/removed.
+// validateAndMutatePostgresqlInstanceSpecName validates and mutates the values of ".spec.name" and ".spec.namePrefix".
+func (w *Webhook) validateAndMutatePostgresqlInstanceSpecName(mutatedObj, previousObj *v1alpha1.PostgresqlInstance) error {
+	// If the current request is an UPDATE request, make sure that neither ".spec.name" nor ".spec.namePrefix" are being changed/removed.
 	if previousObj != nil && mutatedObj.Spec.Name != previousObj.Spec.Name {
 		return fmt.Errorf("the name of the instance cannot be changed (had %q, got %q)", previousObj.Spec.Name, mutatedObj.Spec.Name)
 	}
+	if previousObj != nil && mutatedObj.Spec.NamePrefix != previousObj.Spec.NamePrefix {
+		return fmt.Errorf("the name prefix of the instance cannot be changed (had %q, got %q)", previousObj.Spec.NamePrefix, mutatedObj.Spec.NamePrefix)
+	}
+	// If the current request is a CREATE request and ".spec.namePrefix" has been provided, generate a unique value for ".spec.name" based on it.
+	// Since Cloud SQL prevents the names of recently deleted CSQLP instances from being reused, this allows for re-creating a CSQLP instance right away.
+	// If the webhook is invoked again for the same request (e.g. because another mutating webhook has modified the resource), ".spec.name" has already been generated and is kept.
+	if previousObj == nil && mutatedObj.Spec.NamePrefix != "" && !isGeneratedPostgresqlInstanceSpecName(mutatedObj.Spec.Name, mutatedObj.Spec.NamePrefix) {
+		if mutatedObj.Spec.Name != "" {
+			return fmt.Errorf("the name and the name prefix of the instance cannot be specified at the same time")
+		}
+		if !postgresqlInstanceSpecNamePrefixRegex.MatchString(mutatedObj.Spec.NamePrefix) {
+			return fmt.Errorf("the name prefix of the instance must match the %q regular expression (got %q)", postgresqlInstanceSpecNamePrefixRegex, mutatedObj.Spec.NamePrefix)
+		}
+		if maxLength := postgresqlInstanceSpecNameProjectIDMaxLength - len(w.projectID) - postgresqlInstanceSpecNamePrefixSuffixLength - 1; len(mutatedObj.Spec.NamePrefix) > maxLength {
+			return fmt.Errorf("the name prefix of the instance must not exceed %d characters (got %q)", maxLength, mutatedObj.Spec.NamePrefix)
+		}
+		mutatedObj.Spec.Name = strings.TrimSuffix(mutatedObj.Spec.NamePrefix, "-") + "-" + stringsutil.RandomStringWithLength(postgresqlInstanceSpecNamePrefixSuffixLength, postgresqlInstanceSpecNamePrefixSuffixAlphabet)
+	}
 	// Make sure that ".spec.name" is not empty.
 	if mutatedObj.Spec.Name == "" {
 		return fmt.Errorf("the name of the instance cannot be empty")
@@ -287,22 +598,266 @@ func (w *Webhook) validatePostgresqlInstanceSpecName(mutatedObj, previousObj *v1
 	if len(mutatedObj.Spec.Name)+len(w.projectID) > postgresqlInstanceSpecNameProjectIDMaxLength {
 		return fmt.Errorf("the name of the instance must not exceed %d characters (got %q)", postgresqlInstanceSpecNameProjectIDMaxLength-len(w.projectID), mutatedObj.Spec.Name)
 	}
-	// If the current request is a CREATE request, make sure that ".spec.name" does not clash with the name of a pre-existing CSQLP instance.
+	// If the current request is a CREATE request, make sure that ".spec.name" does not clash with the name of a pre-existing CSQLP instance, unless said CSQLP instance is being adopted.
 	if previousObj == nil {
-		_, err := w.cloudsqlClient.Instances.Get(w.projectID, mutatedObj.Spec.Name).Do()
+		instance, err := w.cloudsqlClient.Instances.Get(w.projectID, mutatedObj.Spec.Name).Do()
 		if err == nil {
 			// No error has been returned, which means that ".spec.name" is already being used.
-			return fmt.Errorf("the name %q is already in use by an instance", mutatedObj.Spec.Name)
+			if !mutatedObj.Spec.Adopt {
+				return fmt.Errorf("the name %q is already in use by an instance", mutatedObj.Spec.Name)
+			}
+			// Make sure that the pre-existing CSQLP instance can be managed by cloudsql-postgres-operator.
+			if !strings.HasPrefix(instance.DatabaseVersion, constants.DatabaseInstanceDatabaseVersionPostgresPrefix) {
+				return fmt.Errorf("the instance %q cannot be adopted as it is not a postgresql instance (got %q)", mutatedObj.Spec.Name, instance.DatabaseVersion)
+			}
+			if instance.InstanceType != constants.DatabaseInstanceTypeCloudSQLInstance {
+				return fmt.Errorf("the instance %q cannot be adopted as it is not a primary instance (got %q)", mutatedObj.Spec.Name, instance.InstanceType)
+			}
+			// Take the value of any unset fields from the pre-existing CSQLP instance, so that adopting it does not result in differences that would otherwise have to be resolved by hand.
+			return mutatePostgresqlInstanceSpecFromDatabaseInstance(mutatedObj, instance)
 		}
 		if !googleutil.IsNotFound(err) {
 			// An error has been returned, but it is not a "404 NOT FOUND" one.
 			return fmt.Errorf("failed to check whether %q can be used as an instance name: %v", mutatedObj.Spec.Name, err)
 		}
+		// At this point we know that ".spec.name" is not being used, and hence there is no CSQLP instance to adopt.
+		if mutatedObj.Spec.Adopt {
+			return fmt.Errorf("the instance %q cannot be adopted as it does not exist", mutatedObj.Spec.Name)
+		}
 		return nil
 	}
 	return nil
 }
 
+// isGeneratedPostgresqlInstanceSpecName indicates whether the specified value of ".spec.name" has been generated based on the specified value of ".spec.namePrefix".
+func isGeneratedPostgresqlInstanceSpecName(name, namePrefix string) bool {
+	p := strings.TrimSuffix(namePrefix, "-") + "-"
+	if !strings.HasPrefix(name, p) || len(name) != len(p)+postgresqlInstanceSpecNamePrefixSuffixLength {
+		return false
+	}
+	for _, r := range strings.TrimPrefix(name, p) {
+		if !strings.ContainsRune(postgresqlInstanceSpecNamePrefixSuffixAlphabet, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// mutatePostgresqlInstanceSpecFromDatabaseInstance sets the unset fields of the specified PostgresqlInstance resource according to the settings of the pre-existing CSQLP instance being adopted.
+// Fields that are already set are kept, and hence any differences between them and the settings of the CSQLP instance are still reported by the controller.
+func mutatePostgresqlInstanceSpecFromDatabaseInstance(mutatedObj *v1alpha1.PostgresqlInstance, instance *cloudsqladmin.DatabaseInstance) error {
+	if mutatedObj.Spec.Version == nil {
+		for _, version := range v1alpha1.PostgresqlInstanceSpecVersions {
+			if version.APIValue() == instance.DatabaseVersion {
+				v := version
+				mutatedObj.Spec.Version = &v
+				break
+			}
+		}
+		// Make sure that the version of the CSQLP instance is supported, as it would otherwise be (unsuccessfully) downgraded to the default one.
+		if mutatedObj.Spec.Version == nil {
+			return fmt.Errorf("the instance %q cannot be adopted as its version is not supported (got %q)", mutatedObj.Spec.Name, instance.DatabaseVersion)
+		}
+	}
+	if mutatedObj.Spec.Location == nil {
+		mutatedObj.Spec.Location = &v1alpha1.PostgresqlInstanceSpecLocation{}
+	}
+	if mutatedObj.Spec.Location.Region == nil && instance.Region != "" {
+		mutatedObj.Spec.Location.Region = pointers.NewString(instance.Region)
+	}
+	// Settings are not returned for CSQLP instances which are being created or have failed, in which case the defaults apply.
+	if instance.Settings == nil {
+		return nil
+	}
+	settings := instance.Settings
+	if mutatedObj.Spec.ActivationPolicy == nil {
+		switch settings.ActivationPolicy {
+		case constants.DatabaseInstanceActivationPolicyAlways:
+			mutatedObj.Spec.ActivationPolicy = &v1alpha1.PostgresqlInstanceSpecActivationPolicyAlways
+		case constants.DatabaseInstanceActivationPolicyNever:
+			mutatedObj.Spec.ActivationPolicy = &v1alpha1.PostgresqlInstanceSpecActivationPolicyNever
+		}
+	}
+	if mutatedObj.Spec.Availability == nil {
+		mutatedObj.Spec.Availability = &v1alpha1.PostgresqlInstanceSpecAvailability{}
+	}
+	if mutatedObj.Spec.Availability.Type == nil {
+		switch settings.AvailabilityType {
+		case v1alpha1.PostgresqlInstanceSpecAvailabilityTypeRegional.APIValue():
+			mutatedObj.Spec.Availability.Type = &v1alpha1.PostgresqlInstanceSpecAvailabilityTypeRegional
+		case v1alpha1.PostgresqlInstanceSpecAvailabilityTypeZonal.APIValue():
+			mutatedObj.Spec.Availability.Type = &v1alpha1.PostgresqlInstanceSpecAvailabilityTypeZonal
+		}
+	}
+	if mutatedObj.Spec.Backups == nil {
+		mutatedObj.Spec.Backups = &v1alpha1.PostgresqlInstanceSpecBackups{}
+	}
+	if mutatedObj.Spec.Backups.Daily == nil {
+		mutatedObj.Spec.Backups.Daily = &v1alpha1.PostgresqlInstancSpecBackupsDaily{}
+	}
+	if settings.BackupConfiguration != nil {
+		if mutatedObj.Spec.Backups.Daily.Enabled == nil {
+			mutatedObj.Spec.Backups.Daily.Enabled = pointers.NewBool(settings.BackupConfiguration.Enabled)
+		}
+		// Start times which are not on the hour cannot be represented in ".spec.backups.daily.startTime", in which case the default applies.
+		if mutatedObj.Spec.Backups.Daily.StartTime == nil && hourOfTheDayRegex.MatchString(settings.BackupConfiguration.StartTime) {
+			mutatedObj.Spec.Backups.Daily.StartTime = pointers.NewString(settings.BackupConfiguration.StartTime)
+		}
+		if mutatedObj.Spec.Backups.Location == nil && settings.BackupConfiguration.Location != "" {
+			mutatedObj.Spec.Backups.Location = pointers.NewString(settings.BackupConfiguration.Location)
+		}
+		if mutatedObj.Spec.Backups.PointInTimeRecovery == nil {
+			mutatedObj.Spec.Backups.PointInTimeRecovery = &v1alpha1.PostgresqlInstanceSpecBackupsPointInTimeRecovery{}
+		}
+		if mutatedObj.Spec.Backups.PointInTimeRecovery.Enabled == nil {
+			mutatedObj.Spec.Backups.PointInTimeRecovery.Enabled = pointers.NewBool(settings.BackupConfiguration.PointInTimeRecoveryEnabled)
+		}
+		if mutatedObj.Spec.Backups.PointInTimeRecovery.TransactionLogRetentionDays == nil && settings.BackupConfiguration.TransactionLogRetentionDays > 0 {
+			mutatedObj.Spec.Backups.PointInTimeRecovery.TransactionLogRetentionDays = pointers.NewInt32(int32(settings.BackupConfiguration.TransactionLogRetentionDays))
+		}
+		if mutatedObj.Spec.Backups.RetainedBackups == nil && settings.BackupConfiguration.BackupRetentionSettings != nil && settings.BackupConfiguration.BackupRetentionSettings.RetainedBackups > 0 {
+			mutatedObj.Spec.Backups.RetainedBackups = pointers.NewInt32(int32(settings.BackupConfiguration.BackupRetentionSettings.RetainedBackups))
+		}
+	}
+	if mutatedObj.Spec.Flags == nil && mutatedObj.Spec.DatabaseFlags == nil && len(settings.DatabaseFlags) > 0 {
+		mutatedObj.Spec.DatabaseFlags = make(v1alpha1.PostgresqlInstanceSpecDatabaseFlags, len(settings.DatabaseFlags))
+		for _, flag := range settings.DatabaseFlags {
+			mutatedObj.Spec.DatabaseFlags[flag.Name] = flag.Value
+		}
+	}
+	if mutatedObj.Spec.DeletionProtection == nil {
+		mutatedObj.Spec.DeletionProtection = pointers.NewBool(settings.DeletionProtectionEnabled)
+	}
+	if mutatedObj.Spec.Insights == nil {
+		mutatedObj.Spec.Insights = &v1alpha1.PostgresqlInstanceSpecInsights{}
+	}
+	if settings.InsightsConfig != nil {
+		if mutatedObj.Spec.Insights.Enabled == nil {
+			mutatedObj.Spec.Insights.Enabled = pointers.NewBool(settings.InsightsConfig.QueryInsightsEnabled)
+		}
+		if mutatedObj.Spec.Insights.QueryStringLength == nil && settings.InsightsConfig.QueryStringLength > 0 {
+			mutatedObj.Spec.Insights.QueryStringLength = pointers.NewInt32(int32(settings.InsightsConfig.QueryStringLength))
+		}
+		if mutatedObj.Spec.Insights.RecordApplicationTags == nil {
+			mutatedObj.Spec.Insights.RecordApplicationTags = pointers.NewBool(settings.InsightsConfig.RecordApplicationTags)
+		}
+		if mutatedObj.Spec.Insights.RecordClientAddress == nil {
+			mutatedObj.Spec.Insights.RecordClientAddress = pointers.NewBool(settings.InsightsConfig.RecordClientAddress)
+		}
+	}
+	if mutatedObj.Spec.Maintenance == nil {
+		mutatedObj.Spec.Maintenance = &v1alpha1.PostgresqlInstanceSpecMaintenance{}
+	}
+	if mutatedObj.Spec.Maintenance.DenyPeriods == nil && len(settings.DenyMaintenancePeriods) > 0 {
+		mutatedObj.Spec.Maintenance.DenyPeriods = make(v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList, 0, len(settings.DenyMaintenancePeriods))
+		for _, dp := range settings.DenyMaintenancePeriods {
+			p := v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriod{
+				EndDate:   dp.EndDate,
+				StartDate: dp.StartDate,
+			}
+			if dp.Time != "" {
+				p.Time = pointers.NewString(dp.Time)
+			}
+			mutatedObj.Spec.Maintenance.DenyPeriods = append(mutatedObj.Spec.Maintenance.DenyPeriods, p)
+		}
+	}
+	if settings.MaintenanceWindow != nil {
+		// The maintenance hour is only taken from the CSQLP instance together with the maintenance day, as it is meaningless otherwise.
+		if mutatedObj.Spec.Maintenance.Day == nil {
+			day := v1alpha1.PostgresqlInstanceSpecMaintenanceDayAny
+			for _, d := range []v1alpha1.PostgresqlInstanceSpecMaintenanceDay{
+				v1alpha1.PostgresqlInstanceSpecMaintenanceDayMonday,
+				v1alpha1.PostgresqlInstanceSpecMaintenanceDayTuesday,
+				v1alpha1.PostgresqlInstanceSpecMaintenanceDayWednesday,
+				v1alpha1.PostgresqlInstanceSpecMaintenanceDayThursday,
+				v1alpha1.PostgresqlInstanceSpecMaintenanceDayFriday,
+				v1alpha1.PostgresqlInstanceSpecMaintenanceDaySaturday,
+				v1alpha1.PostgresqlInstanceSpecMaintenanceDaySunday,
+			} {
+				if d.APIValue() == settings.MaintenanceWindow.Day {
+					day = d
+					break
+				}
+			}
+			mutatedObj.Spec.Maintenance.Day = &day
+			if mutatedObj.Spec.Maintenance.Hour == nil && day != v1alpha1.PostgresqlInstanceSpecMaintenanceDayAny {
+				hour := v1alpha1.PostgresqlInstanceSpecMaintenanceHour(fmt.Sprintf("%02d:00", settings.MaintenanceWindow.Hour))
+				mutatedObj.Spec.Maintenance.Hour = &hour
+			}
+		}
+		if mutatedObj.Spec.Maintenance.UpdateTrack == nil {
+			switch settings.MaintenanceWindow.UpdateTrack {
+			case v1alpha1.PostgresqlInstanceSpecMaintenanceUpdateTrackCanary.APIValue():
+				mutatedObj.Spec.Maintenance.UpdateTrack = &v1alpha1.PostgresqlInstanceSpecMaintenanceUpdateTrackCanary
+			case v1alpha1.PostgresqlInstanceSpecMaintenanceUpdateTrackStable.APIValue():
+				mutatedObj.Spec.Maintenance.UpdateTrack = &v1alpha1.PostgresqlInstanceSpecMaintenanceUpdateTrackStable
+			}
+		}
+	}
+	if mutatedObj.Spec.Networking == nil {
+		mutatedObj.Spec.Networking = &v1alpha1.PostgresqlInstanceSpecNetworking{}
+	}
+	if mutatedObj.Spec.Networking.PrivateIP == nil {
+		mutatedObj.Spec.Networking.PrivateIP = &v1alpha1.PostgresqlInstanceSpecNetworkingPrivateIP{}
+	}
+	if mutatedObj.Spec.Networking.PublicIP == nil {
+		mutatedObj.Spec.Networking.PublicIP = &v1alpha1.PostgresqlInstanceSpecNetworkingPublicIP{}
+	}
+	if settings.IpConfiguration != nil {
+		if mutatedObj.Spec.Networking.PrivateIP.Enabled == nil && mutatedObj.Spec.Networking.PrivateIP.Network == nil {
+			mutatedObj.Spec.Networking.PrivateIP.Enabled = pointers.NewBool(settings.IpConfiguration.PrivateNetwork != "")
+			mutatedObj.Spec.Networking.PrivateIP.Network = pointers.NewString(settings.IpConfiguration.PrivateNetwork)
+		}
+		if mutatedObj.Spec.Networking.PublicIP.Enabled == nil {
+			mutatedObj.Spec.Networking.PublicIP.Enabled = pointers.NewBool(settings.IpConfiguration.Ipv4Enabled)
+		}
+		if mutatedObj.Spec.Networking.PublicIP.AuthorizedNetworks == nil && len(settings.IpConfiguration.AuthorizedNetworks) > 0 {
+			mutatedObj.Spec.Networking.PublicIP.AuthorizedNetworks = make(v1alpha1.PostgresqlInstanceSpecNetworkingPublicIPAuthorizedNetworkList, 0, len(settings.IpConfiguration.AuthorizedNetworks))
+			for _, ae := range settings.IpConfiguration.AuthorizedNetworks {
+				an := v1alpha1.PostgresqlInstanceSpecNetworkingPublicIPAuthorizedNetwork{
+					Cidr: ae.Value,
+				}
+				if ae.Name != "" {
+					an.Name = pointers.NewString(ae.Name)
+				}
+				mutatedObj.Spec.Networking.PublicIP.AuthorizedNetworks = append(mutatedObj.Spec.Networking.PublicIP.AuthorizedNetworks, an)
+			}
+		}
+		if mutatedObj.Spec.Networking.RequireSsl == nil {
+			mutatedObj.Spec.Networking.RequireSsl = pointers.NewBool(settings.IpConfiguration.RequireSsl)
+		}
+	}
+	if mutatedObj.Spec.Resources == nil {
+		mutatedObj.Spec.Resources = &v1alpha1.PostgresqlInstanceSpecResources{}
+	}
+	if mutatedObj.Spec.Resources.Disk == nil {
+		mutatedObj.Spec.Resources.Disk = &v1alpha1.PostgresqlInstanceSpecResourcesDisk{}
+	}
+	if mutatedObj.Spec.Resources.Disk.Type == nil {
+		switch settings.DataDiskType {
+		case v1alpha1.PostgresqlInstanceSpecResourceDiskTypeHDD.APIValue():
+			mutatedObj.Spec.Resources.Disk.Type = &v1alpha1.PostgresqlInstanceSpecResourceDiskTypeHDD
+		case v1alpha1.PostgresqlInstanceSpecResourceDiskTypeSSD.APIValue():
+			mutatedObj.Spec.Resources.Disk.Type = &v1alpha1.PostgresqlInstanceSpecResourceDiskTypeSSD
+		}
+	}
+	if mutatedObj.Spec.Resources.Disk.SizeMinimumGb == nil && settings.DataDiskSizeGb > 0 {
+		mutatedObj.Spec.Resources.Disk.SizeMinimumGb = pointers.NewInt32(int32(settings.DataDiskSizeGb))
+	}
+	// The maximum size is only taken from the CSQLP instance together with the minimum size, as the two are validated against each other.
+	if mutatedObj.Spec.Resources.Disk.SizeMaximumGb == nil && mutatedObj.Spec.Resources.Disk.SizeMinimumGb != nil && int64(*mutatedObj.Spec.Resources.Disk.SizeMinimumGb) == settings.DataDiskSizeGb {
+		if settings.StorageAutoResize != nil && !*settings.StorageAutoResize {
+			mutatedObj.Spec.Resources.Disk.SizeMaximumGb = pointers.NewInt32(int32(settings.DataDiskSizeGb))
+		} else {
+			mutatedObj.Spec.Resources.Disk.SizeMaximumGb = pointers.NewInt32(int32(settings.StorageAutoResizeLimit))
+		}
+	}
+	if mutatedObj.Spec.Resources.InstanceType == nil && settings.Tier != "" {
+		mutatedObj.Spec.Resources.InstanceType = pointers.NewString(settings.Tier)
+	}
+	return nil
+}
+
 // validateAndMutatePostgresqlInstanceSpecNetworking validates and mutates the value of ".spec.networking".
 func (w *Webhook) validateAndMutatePostgresqlInstanceSpecNetworking(mutatedObj, previousObj *v1alpha1.PostgresqlInstance) error {
 	// Make sure that ".spec.networking" is initialized.
@@ -333,6 +888,18 @@ func (w *Webhook) validateAndMutatePostgresqlInstanceSpecNetworking(mutatedObj,
 	if mutatedObj.Spec.Networking.PublicIP.AuthorizedNetworks == nil {
 		mutatedObj.Spec.Networking.PublicIP.AuthorizedNetworks = make([]v1alpha1.PostgresqlInstanceSpecNetworkingPublicIPAuthorizedNetwork, 0)
 	}
+	// If no value for ".spec.networking.requireSsl" has been provided, use the default one.
+	if mutatedObj.Spec.Networking.RequireSsl == nil {
+		mutatedObj.Spec.Networking.RequireSsl = &PostgresqlInstanceSpecNetworkingRequireSslDefault
+	}
+	// Make sure that ".spec.networking.serverCa" is initialized.
+	if mutatedObj.Spec.Networking.ServerCA == nil {
+		mutatedObj.Spec.Networking.ServerCA = &v1alpha1.PostgresqlInstanceSpecNetworkingServerCA{}
+	}
+	// If no value for ".spec.networking.serverCa.autoRotate" has been provided, use the default one.
+	if mutatedObj.Spec.Networking.ServerCA.AutoRotate == nil {
+		mutatedObj.Spec.Networking.ServerCA.AutoRotate = &PostgresqlInstanceSpecNetworkingServerCAAutoRotateDefault
+	}
 	// If the current request is an UPDATE request, make sure that ".spec.networking.privateIp.enabled" is not being changed from true to false.
 	if previousObj != nil && *previousObj.Spec.Networking.PrivateIP.Enabled && !*mutatedObj.Spec.Networking.PrivateIP.Enabled {
 		return fmt.Errorf("private ip access to the instance cannot be disabled after having been enabled")
@@ -404,26 +971,129 @@ func validateAndMutatePostgresqlInstanceSpecResources(mutatedObj, previousObj *v
 	return nil
 }
 
+// validateAndMutatePostgresqlInstanceSpecSchedule validates and mutates the value of ".spec.schedule".
+func validateAndMutatePostgresqlInstanceSpecSchedule(mutatedObj, _ *v1alpha1.PostgresqlInstance) error {
+	// If no value for ".spec.schedule" has been provided, there's nothing else to check.
+	if mutatedObj.Spec.Schedule == nil {
+		return nil
+	}
+	// If no value for ".spec.schedule.timeZone" has been provided, use the default one.
+	if mutatedObj.Spec.Schedule.TimeZone == nil {
+		mutatedObj.Spec.Schedule.TimeZone = &PostgresqlInstanceSpecScheduleTimeZoneDefault
+	}
+	// Make sure that ".spec.schedule.timeZone" is a known time zone.
+	loc, err := time.LoadLocation(*mutatedObj.Spec.Schedule.TimeZone)
+	if err != nil {
+		return fmt.Errorf("the time zone of the schedule must be a valid iana time zone name (got %q)", *mutatedObj.Spec.Schedule.TimeZone)
+	}
+	// Make sure that ".spec.schedule.start" and ".spec.schedule.stop" are valid cron expressions.
+	if _, err := cron.ParseInLocation(mutatedObj.Spec.Schedule.Start, loc); err != nil {
+		return fmt.Errorf("the start schedule of the instance must be a valid cron expression (got %q): %v", mutatedObj.Spec.Schedule.Start, err)
+	}
+	if _, err := cron.ParseInLocation(mutatedObj.Spec.Schedule.Stop, loc); err != nil {
+		return fmt.Errorf("the stop schedule of the instance must be a valid cron expression (got %q): %v", mutatedObj.Spec.Schedule.Stop, err)
+	}
+	return nil
+}
+
+// validateAndMutatePostgresqlInstanceSpecSource validates and mutates the value of ".spec.source".
+func (w *Webhook) validateAndMutatePostgresqlInstanceSpecSource(mutatedObj, previousObj *v1alpha1.PostgresqlInstance) error {
+	// If the current request is an UPDATE request, make sure that ".spec.source" is not being changed/removed.
+	// Cloning only happens when the CSQLP instance is created, so there's nothing else to check in this case.
+	if previousObj != nil {
+		if !reflect.DeepEqual(mutatedObj.Spec.Source, previousObj.Spec.Source) {
+			return fmt.Errorf("the source of the instance cannot be changed")
+		}
+		return nil
+	}
+	// If no value for ".spec.source" has been provided, there's nothing else to check.
+	if mutatedObj.Spec.Source == nil {
+		return nil
+	}
+	// Make sure that ".spec.source.instance" is not empty and does not reference the current resource.
+	if mutatedObj.Spec.Source.Instance == "" {
+		return fmt.Errorf("the instance to clone cannot be empty")
+	}
+	if mutatedObj.Spec.Source.Instance == mutatedObj.Name {
+		return fmt.Errorf("the instance cannot be a clone of itself")
+	}
+	// Make sure that ".spec.source.pointInTime" is not in the future.
+	if mutatedObj.Spec.Source.PointInTime != nil && mutatedObj.Spec.Source.PointInTime.Time.After(time.Now()) {
+		return fmt.Errorf("the point in time at which to clone the instance cannot be in the future (got %q)", mutatedObj.Spec.Source.PointInTime.UTC().Format(time.RFC3339))
+	}
+	// Make sure that ".spec.source.instance" references an existing PostgresqlInstance resource.
+	source, err := w.selfClient.CloudsqlV1alpha1().PostgresqlInstances().Get(mutatedObj.Spec.Source.Instance, metav1.GetOptions{})
+	if err != nil {
+		if kubeerrors.IsNotFound(err) {
+			return fmt.Errorf("postgresqlinstance %q does not exist", mutatedObj.Spec.Source.Instance)
+		}
+		return fmt.Errorf("failed to get postgresqlinstance %q: %v", mutatedObj.Spec.Source.Instance, err)
+	}
+	// Cloning at a given point in time requires point-in-time recovery to be enabled on the CSQLP instance to clone.
+	if mutatedObj.Spec.Source.PointInTime != nil && (source.Spec.Backups == nil || source.Spec.Backups.PointInTimeRecovery == nil || source.Spec.Backups.PointInTimeRecovery.Enabled == nil || !*source.Spec.Backups.PointInTimeRecovery.Enabled) {
+		return fmt.Errorf("the instance cannot be cloned at a given point in time since point-in-time recovery is not enabled on postgresqlinstance %q", mutatedObj.Spec.Source.Instance)
+	}
+	// A clone always has the same version as the CSQLP instance it was cloned from.
+	// Hence, if no value for ".spec.version" has been provided, use the version of the source instance, and otherwise make sure that both versions match.
+	if source.Spec.Version == nil {
+		return nil
+	}
+	if mutatedObj.Spec.Version == nil {
+		v := *source.Spec.Version
+		mutatedObj.Spec.Version = &v
+		return nil
+	}
+	if *mutatedObj.Spec.Version != *source.Spec.Version {
+		return fmt.Errorf("the version of the instance must match the version of the instance to clone (expected %q, got %q)", *source.Spec.Version, *mutatedObj.Spec.Version)
+	}
+	return nil
+}
+
 // validateAndMutatePostgresqlInstanceSpecVersion validates and mutates the value of ".spec.version".
 func validateAndMutatePostgresqlInstanceSpecVersion(mutatedObj, previousObj *v1alpha1.PostgresqlInstance) error {
-	// If the current operation is an UPDATE request, make sure that ".spec.version" is not being changed/removed.
-	if previousObj != nil && *mutatedObj.Spec.Version != *previousObj.Spec.Version {
-		return fmt.Errorf("the version of the instance cannot be changed")
-	}
 	// If no value for ".spec.version" has been provided, use the default one.
 	if mutatedObj.Spec.Version == nil {
 		mutatedObj.Spec.Version = &PostgresqlInstanceSpecVersionDefault
 	}
 	// Make sure that ".spec.version" contains a valid value.
-	switch *mutatedObj.Spec.Version {
-	case v1alpha1.PostgresqlInstanceSpecVersion96:
-		// The value is valid.
-	default:
-		return fmt.Errorf("the version of the instance must be %q (got %q)", v1alpha1.PostgresqlInstanceSpecVersion96, *mutatedObj.Spec.Version)
+	idx := postgresqlInstanceSpecVersionIndex(*mutatedObj.Spec.Version)
+	if idx < 0 {
+		return fmt.Errorf("the version of the instance must be one of %s (got %q)", formatPostgresqlInstanceSpecVersions(), *mutatedObj.Spec.Version)
+	}
+	// If the current operation is not an UPDATE request or ".spec.version" is not being changed, there's nothing else to check.
+	if previousObj == nil || previousObj.Spec.Version == nil || *mutatedObj.Spec.Version == *previousObj.Spec.Version {
+		return nil
+	}
+	// Make sure that ".spec.version" is not being downgraded.
+	if idx < postgresqlInstanceSpecVersionIndex(*previousObj.Spec.Version) {
+		return fmt.Errorf("the version of the instance cannot be downgraded (had %q, got %q)", *previousObj.Spec.Version, *mutatedObj.Spec.Version)
+	}
+	// Make sure that the major version upgrade has been explicitly allowed using the "cloudsql.travelaudience.com/allow-major-version-upgrade" annotation.
+	if v, exists := mutatedObj.Annotations[constants.AllowMajorVersionUpgradeAnnotationKey]; !exists || v != v1alpha1.True {
+		return fmt.Errorf("the version of the instance cannot be upgraded unless the %q annotation is set to %q", constants.AllowMajorVersionUpgradeAnnotationKey, v1alpha1.True)
 	}
 	return nil
 }
 
+// postgresqlInstanceSpecVersionIndex returns the position of the provided value in the list of supported values for the ".spec.version" field of a PostgresqlInstance resource, or -1 if the value is not supported.
+func postgresqlInstanceSpecVersionIndex(v v1alpha1.PostgresqlInstanceSpecVersion) int {
+	for idx, version := range v1alpha1.PostgresqlInstanceSpecVersions {
+		if v == version {
+			return idx
+		}
+	}
+	return -1
+}
+
+// formatPostgresqlInstanceSpecVersions returns a human-readable list of the supported values for the ".spec.version" field of a PostgresqlInstance resource.
+func formatPostgresqlInstanceSpecVersions() string {
+	r := make([]string, 0, len(v1alpha1.PostgresqlInstanceSpecVersions))
+	for _, version := range v1alpha1.PostgresqlInstanceSpecVersions {
+		r = append(r, fmt.Sprintf("%q", version))
+	}
+	return strings.Join(r, ", ")
+}
+
 // PostgresqlInstanceSpecMaintenanceHourDefault returns the default value for the ".spec.maintenance.hour" field of a PostgresqlInstance resource based on the provided maintenance hour.
 func PostgresqlInstanceSpecMaintenanceHourDefault(v v1alpha1.PostgresqlInstanceSpecMaintenanceDay) v1alpha1.PostgresqlInstanceSpecMaintenanceHour {
 	if v == v1alpha1.PostgresqlInstanceSpecMaintenanceDayAny {
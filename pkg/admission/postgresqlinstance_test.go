@@ -0,0 +1,519 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	selfclientfake "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/clientset/versioned/fake"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/pointers"
+)
+
+// newDatabaseInstance returns a DatabaseInstance object representing a pre-existing CSQLP instance whose settings differ from the defaults.
+func newDatabaseInstance() *cloudsqladmin.DatabaseInstance {
+	return &cloudsqladmin.DatabaseInstance{
+		DatabaseVersion: "POSTGRES_11",
+		Region:          "us-central1",
+		Settings: &cloudsqladmin.Settings{
+			ActivationPolicy: "NEVER",
+			AvailabilityType: "REGIONAL",
+			BackupConfiguration: &cloudsqladmin.BackupConfiguration{
+				BackupRetentionSettings: &cloudsqladmin.BackupRetentionSettings{
+					RetainedBackups: 14,
+					RetentionUnit:   "COUNT",
+				},
+				Enabled:                     true,
+				Location:                    "us",
+				PointInTimeRecoveryEnabled:  true,
+				StartTime:                   "03:00",
+				TransactionLogRetentionDays: 3,
+			},
+			DatabaseFlags: []*cloudsqladmin.DatabaseFlags{
+				{Name: "max_connections", Value: "200"},
+			},
+			DataDiskSizeGb:            50,
+			DataDiskType:              "PD_HDD",
+			DeletionProtectionEnabled: true,
+			DenyMaintenancePeriods: []*cloudsqladmin.DenyMaintenancePeriod{
+				{StartDate: "11-15", EndDate: "01-15", Time: "00:00:00"},
+			},
+			InsightsConfig: &cloudsqladmin.InsightsConfig{
+				QueryInsightsEnabled: true,
+				QueryStringLength:    2048,
+				RecordClientAddress:  true,
+			},
+			IpConfiguration: &cloudsqladmin.IpConfiguration{
+				AuthorizedNetworks: []*cloudsqladmin.AclEntry{
+					{Name: "office", Value: "10.0.0.0/8"},
+				},
+				Ipv4Enabled:    true,
+				PrivateNetwork: "projects/project-0/global/networks/default",
+				RequireSsl:     true,
+			},
+			MaintenanceWindow: &cloudsqladmin.MaintenanceWindow{
+				Day:         3,
+				Hour:        5,
+				UpdateTrack: "canary",
+			},
+			StorageAutoResize:      pointers.NewBool(true),
+			StorageAutoResizeLimit: 100,
+			Tier:                   "db-custom-2-7680",
+		},
+	}
+}
+
+func TestMutatePostgresqlInstanceSpecFromDatabaseInstance(t *testing.T) {
+	// Unset fields are taken from the pre-existing CSQLP instance.
+	p := &v1alpha1.PostgresqlInstance{}
+	if err := mutatePostgresqlInstanceSpecFromDatabaseInstance(p, newDatabaseInstance()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	version := v1alpha1.PostgresqlInstanceSpecVersion11
+	day := v1alpha1.PostgresqlInstanceSpecMaintenanceDayWednesday
+	hour := v1alpha1.PostgresqlInstanceSpecMaintenanceHour("05:00")
+	expected := v1alpha1.PostgresqlInstanceSpec{
+		ActivationPolicy: &v1alpha1.PostgresqlInstanceSpecActivationPolicyNever,
+		Availability: &v1alpha1.PostgresqlInstanceSpecAvailability{
+			Type: &v1alpha1.PostgresqlInstanceSpecAvailabilityTypeRegional,
+		},
+		Backups: &v1alpha1.PostgresqlInstanceSpecBackups{
+			Daily: &v1alpha1.PostgresqlInstancSpecBackupsDaily{
+				Enabled:   pointers.NewBool(true),
+				StartTime: pointers.NewString("03:00"),
+			},
+			Location: pointers.NewString("us"),
+			PointInTimeRecovery: &v1alpha1.PostgresqlInstanceSpecBackupsPointInTimeRecovery{
+				Enabled:                     pointers.NewBool(true),
+				TransactionLogRetentionDays: pointers.NewInt32(3),
+			},
+			RetainedBackups: pointers.NewInt32(14),
+		},
+		DatabaseFlags: v1alpha1.PostgresqlInstanceSpecDatabaseFlags{
+			"max_connections": "200",
+		},
+		DeletionProtection: pointers.NewBool(true),
+		Insights: &v1alpha1.PostgresqlInstanceSpecInsights{
+			Enabled:               pointers.NewBool(true),
+			QueryStringLength:     pointers.NewInt32(2048),
+			RecordApplicationTags: pointers.NewBool(false),
+			RecordClientAddress:   pointers.NewBool(true),
+		},
+		Location: &v1alpha1.PostgresqlInstanceSpecLocation{
+			Region: pointers.NewString("us-central1"),
+		},
+		Maintenance: &v1alpha1.PostgresqlInstanceSpecMaintenance{
+			Day: &day,
+			DenyPeriods: v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList{
+				{StartDate: "11-15", EndDate: "01-15", Time: pointers.NewString("00:00:00")},
+			},
+			Hour:        &hour,
+			UpdateTrack: &v1alpha1.PostgresqlInstanceSpecMaintenanceUpdateTrackCanary,
+		},
+		Networking: &v1alpha1.PostgresqlInstanceSpecNetworking{
+			PrivateIP: &v1alpha1.PostgresqlInstanceSpecNetworkingPrivateIP{
+				Enabled: pointers.NewBool(true),
+				Network: pointers.NewString("projects/project-0/global/networks/default"),
+			},
+			PublicIP: &v1alpha1.PostgresqlInstanceSpecNetworkingPublicIP{
+				AuthorizedNetworks: v1alpha1.PostgresqlInstanceSpecNetworkingPublicIPAuthorizedNetworkList{
+					{Cidr: "10.0.0.0/8", Name: pointers.NewString("office")},
+				},
+				Enabled: pointers.NewBool(true),
+			},
+			RequireSsl: pointers.NewBool(true),
+		},
+		Resources: &v1alpha1.PostgresqlInstanceSpecResources{
+			Disk: &v1alpha1.PostgresqlInstanceSpecResourcesDisk{
+				SizeMaximumGb: pointers.NewInt32(100),
+				SizeMinimumGb: pointers.NewInt32(50),
+				Type:          &v1alpha1.PostgresqlInstanceSpecResourceDiskTypeHDD,
+			},
+			InstanceType: pointers.NewString("db-custom-2-7680"),
+		},
+		Version: &version,
+	}
+	if !reflect.DeepEqual(p.Spec, expected) {
+		t.Errorf("expected the unset fields to be taken from the instance:\nexpected: %+v\ngot:      %+v", expected, p.Spec)
+	}
+
+	// Fields that are already set are kept.
+	specifiedVersion := v1alpha1.PostgresqlInstanceSpecVersion12
+	p = &v1alpha1.PostgresqlInstance{
+		Spec: v1alpha1.PostgresqlInstanceSpec{
+			Resources: &v1alpha1.PostgresqlInstanceSpecResources{
+				Disk: &v1alpha1.PostgresqlInstanceSpecResourcesDisk{
+					SizeMinimumGb: pointers.NewInt32(20),
+				},
+			},
+			Version: &specifiedVersion,
+		},
+	}
+	if err := mutatePostgresqlInstanceSpecFromDatabaseInstance(p, newDatabaseInstance()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *p.Spec.Version != v1alpha1.PostgresqlInstanceSpecVersion12 {
+		t.Errorf("expected the version to be kept, got %q", *p.Spec.Version)
+	}
+	if *p.Spec.Resources.Disk.SizeMinimumGb != 20 || p.Spec.Resources.Disk.SizeMaximumGb != nil {
+		t.Errorf("expected the disk size to be kept and the maximum disk size to be left unset, got %d and %v", *p.Spec.Resources.Disk.SizeMinimumGb, p.Spec.Resources.Disk.SizeMaximumGb)
+	}
+
+	// Instances running an unsupported version cannot be adopted.
+	i := newDatabaseInstance()
+	i.DatabaseVersion = "POSTGRES_99"
+	if err := mutatePostgresqlInstanceSpecFromDatabaseInstance(&v1alpha1.PostgresqlInstance{}, i); err == nil {
+		t.Errorf("expected an error adopting an instance running an unsupported version")
+	}
+}
+
+func TestValidateAndMutatePostgresqlInstanceSpecBackups(t *testing.T) {
+	newPostgresqlInstance := func(dailyEnabled bool, location *string, retainedBackups *int32, pitr *v1alpha1.PostgresqlInstanceSpecBackupsPointInTimeRecovery) *v1alpha1.PostgresqlInstance {
+		return &v1alpha1.PostgresqlInstance{
+			Spec: v1alpha1.PostgresqlInstanceSpec{
+				Backups: &v1alpha1.PostgresqlInstanceSpecBackups{
+					Daily: &v1alpha1.PostgresqlInstancSpecBackupsDaily{
+						Enabled: pointers.NewBool(dailyEnabled),
+					},
+					Location:            location,
+					PointInTimeRecovery: pitr,
+					RetainedBackups:     retainedBackups,
+				},
+			},
+		}
+	}
+	tests := []struct {
+		description string
+		obj         *v1alpha1.PostgresqlInstance
+		expectError bool
+	}{
+		{
+			description: "defaults",
+			obj:         newPostgresqlInstance(true, nil, nil, nil),
+		},
+		{
+			description: "point-in-time recovery enabled",
+			obj: newPostgresqlInstance(true, pointers.NewString("europe-west1"), pointers.NewInt32(14), &v1alpha1.PostgresqlInstanceSpecBackupsPointInTimeRecovery{
+				Enabled:                     pointers.NewBool(true),
+				TransactionLogRetentionDays: pointers.NewInt32(7),
+			}),
+		},
+		{
+			description: "multi-region location",
+			obj:         newPostgresqlInstance(true, pointers.NewString("eu"), nil, nil),
+		},
+		{
+			description: "invalid location",
+			obj:         newPostgresqlInstance(true, pointers.NewString("Europe West 1"), nil, nil),
+			expectError: true,
+		},
+		{
+			description: "too few retained backups",
+			obj:         newPostgresqlInstance(true, nil, pointers.NewInt32(0), nil),
+			expectError: true,
+		},
+		{
+			description: "too many retained backups",
+			obj:         newPostgresqlInstance(true, nil, pointers.NewInt32(366), nil),
+			expectError: true,
+		},
+		{
+			description: "too many days of transaction logs",
+			obj: newPostgresqlInstance(true, nil, pointers.NewInt32(14), &v1alpha1.PostgresqlInstanceSpecBackupsPointInTimeRecovery{
+				TransactionLogRetentionDays: pointers.NewInt32(8),
+			}),
+			expectError: true,
+		},
+		{
+			description: "more days of transaction logs than retained backups",
+			obj: newPostgresqlInstance(true, nil, pointers.NewInt32(3), &v1alpha1.PostgresqlInstanceSpecBackupsPointInTimeRecovery{
+				TransactionLogRetentionDays: pointers.NewInt32(5),
+			}),
+			expectError: true,
+		},
+		{
+			description: "point-in-time recovery without daily backups",
+			obj: newPostgresqlInstance(false, nil, nil, &v1alpha1.PostgresqlInstanceSpecBackupsPointInTimeRecovery{
+				Enabled: pointers.NewBool(true),
+			}),
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		var err error
+		for _, fn := range []postgresqlInstanceWebhookOperation{
+			validateAndMutatePostgresqlInstanceSpecBackupsLocation,
+			validateAndMutatePostgresqlInstanceSpecBackupsRetainedBackups,
+			validateAndMutatePostgresqlInstanceSpecBackupsPointInTimeRecovery,
+		} {
+			if err = fn(test.obj, nil); err != nil {
+				break
+			}
+		}
+		if test.expectError && err == nil {
+			t.Errorf("%s: expected an error", test.description)
+		}
+		if !test.expectError && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.description, err)
+		}
+	}
+}
+
+func TestValidateAndMutatePostgresqlInstanceSpecInsights(t *testing.T) {
+	tests := []struct {
+		description               string
+		insights                  *v1alpha1.PostgresqlInstanceSpecInsights
+		expectError               bool
+		expectedQueryStringLength int32
+	}{
+		{
+			description:               "defaults",
+			expectedQueryStringLength: PostgresqlInstanceSpecInsightsQueryStringLengthDefault,
+		},
+		{
+			description:               "custom query string length",
+			insights:                  &v1alpha1.PostgresqlInstanceSpecInsights{Enabled: pointers.NewBool(true), QueryStringLength: pointers.NewInt32(4500)},
+			expectedQueryStringLength: 4500,
+		},
+		{
+			description: "query string length too short",
+			insights:    &v1alpha1.PostgresqlInstanceSpecInsights{QueryStringLength: pointers.NewInt32(255)},
+			expectError: true,
+		},
+		{
+			description: "query string length too long",
+			insights:    &v1alpha1.PostgresqlInstanceSpecInsights{QueryStringLength: pointers.NewInt32(4501)},
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		obj := &v1alpha1.PostgresqlInstance{
+			Spec: v1alpha1.PostgresqlInstanceSpec{
+				Insights: test.insights,
+			},
+		}
+		err := validateAndMutatePostgresqlInstanceSpecInsights(obj, nil)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.description)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.description, err)
+			continue
+		}
+		i := obj.Spec.Insights
+		if i.Enabled == nil || i.RecordApplicationTags == nil || i.RecordClientAddress == nil || *i.QueryStringLength != test.expectedQueryStringLength {
+			t.Errorf("%s: expected the query insights settings to be defaulted, got %+v", test.description, i)
+		}
+	}
+}
+
+func TestValidateAndMutatePostgresqlInstanceSpecMaintenanceDenyPeriods(t *testing.T) {
+	newDenyPeriod := func(startDate, endDate string) v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriod {
+		return v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriod{StartDate: startDate, EndDate: endDate}
+	}
+	tests := []struct {
+		description string
+		denyPeriods v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList
+		expectError bool
+	}{
+		{
+			description: "no deny periods",
+		},
+		{
+			description: "single deny period",
+			denyPeriods: v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList{newDenyPeriod("2019-11-15", "2020-01-15")},
+		},
+		{
+			description: "single deny period with a time",
+			denyPeriods: v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList{
+				{StartDate: "2019-11-15", EndDate: "2019-11-30", Time: pointers.NewString("12:30:00")},
+			},
+		},
+		{
+			description: "recurring deny period spanning the end of the year",
+			denyPeriods: v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList{newDenyPeriod("12-15", "01-15")},
+		},
+		{
+			description: "adjacent deny periods",
+			denyPeriods: v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList{newDenyPeriod("2019-11-01", "2019-11-15"), newDenyPeriod("2019-11-15", "2019-11-30")},
+		},
+		{
+			description: "invalid date",
+			denyPeriods: v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList{newDenyPeriod("2019-02-30", "2019-03-15")},
+			expectError: true,
+		},
+		{
+			description: "mixed date formats",
+			denyPeriods: v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList{newDenyPeriod("2019-11-15", "12-15")},
+			expectError: true,
+		},
+		{
+			description: "invalid time",
+			denyPeriods: v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList{
+				{StartDate: "2019-11-15", EndDate: "2019-11-30", Time: pointers.NewString("24:00:00")},
+			},
+			expectError: true,
+		},
+		{
+			description: "deny period ending before it starts",
+			denyPeriods: v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList{newDenyPeriod("2019-11-15", "2019-11-01")},
+			expectError: true,
+		},
+		{
+			description: "deny period longer than 90 days",
+			denyPeriods: v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList{newDenyPeriod("2019-01-01", "2019-04-02")},
+			expectError: true,
+		},
+		{
+			description: "overlapping deny periods",
+			denyPeriods: v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList{newDenyPeriod("2019-11-01", "2019-11-20"), newDenyPeriod("2019-11-15", "2019-11-30")},
+			expectError: true,
+		},
+		{
+			description: "deny period overlapping a recurring deny period",
+			denyPeriods: v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList{newDenyPeriod("12-15", "01-15"), newDenyPeriod("2020-01-10", "2020-01-20")},
+			expectError: true,
+		},
+		{
+			description: "recurring deny periods overlapping across the end of the year",
+			denyPeriods: v1alpha1.PostgresqlInstanceSpecMaintenanceDenyPeriodList{newDenyPeriod("12-15", "01-15"), newDenyPeriod("01-10", "02-01")},
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		obj := &v1alpha1.PostgresqlInstance{
+			Spec: v1alpha1.PostgresqlInstanceSpec{
+				Maintenance: &v1alpha1.PostgresqlInstanceSpecMaintenance{
+					DenyPeriods: test.denyPeriods,
+				},
+			},
+		}
+		err := validateAndMutatePostgresqlInstanceSpecMaintenance(obj, nil)
+		if test.expectError && err == nil {
+			t.Errorf("%s: expected an error", test.description)
+		}
+		if !test.expectError && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.description, err)
+		}
+		for _, dp := range obj.Spec.Maintenance.DenyPeriods {
+			if dp.Time == nil {
+				t.Errorf("%s: expected the time of deny period %q to %q to be defaulted", test.description, dp.StartDate, dp.EndDate)
+			}
+		}
+	}
+}
+
+func TestValidateAndMutatePostgresqlInstanceSpecSource(t *testing.T) {
+	newSource := func(name string, pitr bool) *v1alpha1.PostgresqlInstance {
+		return &v1alpha1.PostgresqlInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: v1alpha1.PostgresqlInstanceSpec{
+				Backups: &v1alpha1.PostgresqlInstanceSpecBackups{
+					PointInTimeRecovery: &v1alpha1.PostgresqlInstanceSpecBackupsPointInTimeRecovery{
+						Enabled: pointers.NewBool(pitr),
+					},
+				},
+			},
+		}
+	}
+	w := &Webhook{
+		selfClient: selfclientfake.NewSimpleClientset(
+			newSource("with-pitr", true),
+			newSource("without-pitr", false),
+		),
+	}
+	past := metav1.NewTime(time.Now().Add(-time.Hour))
+	future := metav1.NewTime(time.Now().Add(time.Hour))
+	tests := []struct {
+		description string
+		source      *v1alpha1.PostgresqlInstanceSpecSource
+		expectError bool
+	}{
+		{
+			description: "current state",
+			source:      &v1alpha1.PostgresqlInstanceSpecSource{Instance: "without-pitr"},
+		},
+		{
+			description: "point in time",
+			source:      &v1alpha1.PostgresqlInstanceSpecSource{Instance: "with-pitr", PointInTime: &past},
+		},
+		{
+			description: "point in time in the future",
+			source:      &v1alpha1.PostgresqlInstanceSpecSource{Instance: "with-pitr", PointInTime: &future},
+			expectError: true,
+		},
+		{
+			description: "point in time without point-in-time recovery",
+			source:      &v1alpha1.PostgresqlInstanceSpecSource{Instance: "without-pitr", PointInTime: &past},
+			expectError: true,
+		},
+		{
+			description: "non-existing instance",
+			source:      &v1alpha1.PostgresqlInstanceSpecSource{Instance: "missing"},
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		obj := &v1alpha1.PostgresqlInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "clone",
+			},
+			Spec: v1alpha1.PostgresqlInstanceSpec{
+				Source: test.source,
+			},
+		}
+		err := w.validateAndMutatePostgresqlInstanceSpecSource(obj, nil)
+		if test.expectError && err == nil {
+			t.Errorf("%s: expected an error", test.description)
+		}
+		if !test.expectError && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.description, err)
+		}
+	}
+}
+
+func TestIsGeneratedPostgresqlInstanceSpecName(t *testing.T) {
+	tests := []struct {
+		description string
+		name        string
+		namePrefix  string
+		expected    bool
+	}{
+		{description: "generated name", name: "cloudsql-psql-x7k2m9qa", namePrefix: "cloudsql-psql", expected: true},
+		{description: "generated name (prefix ending with a dash)", name: "cloudsql-psql-x7k2m9qa", namePrefix: "cloudsql-psql-", expected: true},
+		{description: "empty name", name: "", namePrefix: "cloudsql-psql", expected: false},
+		{description: "different prefix", name: "cloudsql-pg-x7k2m9qa", namePrefix: "cloudsql-psql", expected: false},
+		{description: "suffix too short", name: "cloudsql-psql-x7k2m9q", namePrefix: "cloudsql-psql", expected: false},
+		{description: "suffix too long", name: "cloudsql-psql-x7k2m9qab", namePrefix: "cloudsql-psql", expected: false},
+		{description: "suffix outside the alphabet", name: "cloudsql-psql-X7K2M9QA", namePrefix: "cloudsql-psql", expected: false},
+	}
+	for _, test := range tests {
+		if actual := isGeneratedPostgresqlInstanceSpecName(test.name, test.namePrefix); actual != test.expected {
+			t.Errorf("%s: expected %t, got %t", test.description, test.expected, actual)
+		}
+	}
+}
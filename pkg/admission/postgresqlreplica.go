@@ -0,0 +1,235 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+	googleutil "github.com/travelaudience/cloudsql-postgres-operator/pkg/util/google"
+)
+
+const (
+	// replicaPromotionFailedReason is the reason reported in the "Promoted" condition of PostgresqlReplica resources whose promotion has failed.
+	replicaPromotionFailedReason = "ReplicaPromotionFailed"
+)
+
+// postgresqlReplicaWebhookOperation represents a validation/mutation operation performed by the admission webhook on PostgresqlReplica resources.
+type postgresqlReplicaWebhookOperation func(mutatedObj, previousObj *v1alpha1.PostgresqlReplica) error
+
+// validateAndMutatePostgresqlReplica validates and mutates the provided PostgresqlReplica object.
+// If the current request is a CREATE request, only currentObj is populated.
+// If the current request is an UPDATE request, both currentObj and previousObj are populated.
+// If the current request is a DELETE request, only previousObj is populated.
+func (w *Webhook) validateAndMutatePostgresqlReplica(currentObj, previousObj *v1alpha1.PostgresqlReplica) (*v1alpha1.PostgresqlReplica, error) {
+	// Check whether the current request is a DELETE request and act accordingly.
+	// In this case, we allow the request if and only if the "cloudsql.travelaudience.com/allow-deletion" annotation is present on the resource and set to "true".
+	if currentObj == nil && previousObj != nil {
+		if v, exists := previousObj.Annotations[constants.AllowDeletionAnnotationKey]; !exists || v != v1alpha1.True {
+			return nil, fmt.Errorf("the resource cannot be deleted unless the %q annotation is set to %q", constants.AllowDeletionAnnotationKey, v1alpha1.True)
+		}
+		return nil, nil
+	}
+
+	// At this point we know the current request is either a CREATE or UPDATE request.
+
+	// Clone the current object so that we can safely mutate it if necessary.
+	mutatedObj := currentObj.DeepCopy()
+
+	// Perform the required validation/mutation steps.
+	for _, fn := range []postgresqlReplicaWebhookOperation{
+		mutatePostgresqlReplicaMetadataAnnotations,
+		validatePostgresqlReplicaMetadataAnnotations,
+		w.validatePostgresqlReplicaSpecPrimary,
+		w.validateAndMutatePostgresqlReplicaSpecFlags,
+		validateAndMutatePostgresqlReplicaSpecInstanceType,
+		w.validateAndMutatePostgresqlReplicaSpecLocation,
+		w.validatePostgresqlReplicaSpecName,
+	} {
+		if err := fn(mutatedObj, previousObj); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return the (possibly) mutated object so a patch can be created if necessary.
+	return mutatedObj, nil
+}
+
+// mutatePostgresqlReplicaMetadataAnnotations injects annotations on the specified PostgresqlReplica resource.
+func mutatePostgresqlReplicaMetadataAnnotations(mutatedObj, _ *v1alpha1.PostgresqlReplica) error {
+	// Make sure that the map of annotations is initialized on the cloned object.
+	if mutatedObj.Annotations == nil {
+		mutatedObj.Annotations = make(map[string]string, 1)
+	}
+	// Inject the "cloudsql.travelaudience.com/allow-deletion" annotation with a value of "false" if the annotation is not present or is empty.
+	if v, exists := mutatedObj.Annotations[constants.AllowDeletionAnnotationKey]; !exists || v == "" {
+		mutatedObj.Annotations[constants.AllowDeletionAnnotationKey] = v1alpha1.False
+	}
+	return nil
+}
+
+// validatePostgresqlReplicaMetadataAnnotations validates the annotations present on the specified PostgresqlReplica resource.
+func validatePostgresqlReplicaMetadataAnnotations(mutatedObj, previousObj *v1alpha1.PostgresqlReplica) error {
+	// Make sure that the "cloudsql.travelaudience.com/promote" annotation, if present, is either "true" or "false".
+	v, exists := mutatedObj.Annotations[constants.PromoteReplicaAnnotationKey]
+	if exists && v != v1alpha1.True && v != v1alpha1.False {
+		return fmt.Errorf("the value of the %q annotation must be either %q or %q (got %q)", constants.PromoteReplicaAnnotationKey, v1alpha1.True, v1alpha1.False, v)
+	}
+	// If the current request is an UPDATE request, make sure that the "cloudsql.travelaudience.com/promote" annotation is not being unset after having been set to "true", as promotion cannot be undone.
+	// The only exception is when the promotion has been refused, in which case the annotation may be toggled in order to request a new attempt.
+	if previousObj != nil && previousObj.Annotations[constants.PromoteReplicaAnnotationKey] == v1alpha1.True && v != v1alpha1.True && !isPostgresqlReplicaPromotionFailed(previousObj) {
+		return fmt.Errorf("the %q annotation cannot be changed after having been set to %q, as promotion cannot be undone", constants.PromoteReplicaAnnotationKey, v1alpha1.True)
+	}
+	return nil
+}
+
+// isPostgresqlReplicaPromotionFailed returns a value indicating whether the "Promoted" condition of the specified PostgresqlReplica resource reports a failed promotion.
+func isPostgresqlReplicaPromotionFailed(postgresqlReplica *v1alpha1.PostgresqlReplica) bool {
+	for _, cdn := range postgresqlReplica.Status.Conditions {
+		if cdn.Type == v1alpha1.PostgresqlReplicaStatusConditionTypePromoted {
+			return cdn.Status == corev1.ConditionFalse && cdn.Reason == replicaPromotionFailedReason
+		}
+	}
+	return false
+}
+
+// validatePostgresqlReplicaSpecPrimary validates the value of ".spec.primary".
+func (w *Webhook) validatePostgresqlReplicaSpecPrimary(mutatedObj, previousObj *v1alpha1.PostgresqlReplica) error {
+	// If the current request is an UPDATE request, make sure that ".spec.primary" is not being changed/removed.
+	if previousObj != nil && mutatedObj.Spec.Primary != previousObj.Spec.Primary {
+		return fmt.Errorf("the primary instance of the read replica cannot be changed (had %q, got %q)", previousObj.Spec.Primary, mutatedObj.Spec.Primary)
+	}
+	// Make sure that ".spec.primary" is not empty.
+	if mutatedObj.Spec.Primary == "" {
+		return fmt.Errorf("the primary instance of the read replica cannot be empty")
+	}
+	// If the current request is a CREATE request, make sure that ".spec.primary" references an existing PostgresqlInstance resource.
+	if previousObj == nil {
+		if _, err := w.getPostgresqlReplicaPrimary(mutatedObj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateAndMutatePostgresqlReplicaSpecFlags validates and mutates the values of ".spec.flags" and ".spec.databaseFlags".
+func (w *Webhook) validateAndMutatePostgresqlReplicaSpecFlags(mutatedObj, previousObj *v1alpha1.PostgresqlReplica) error {
+	// Make sure that ".spec.flags" is initialized.
+	if mutatedObj.Spec.Flags == nil {
+		mutatedObj.Spec.Flags = make([]string, 0)
+	}
+	// Validate the flags against the catalog of database flags only if the current request is a CREATE request or if the flags are being changed, so that existing resources are not affected by changes to the catalog.
+	if previousObj != nil && reflect.DeepEqual(mutatedObj.Spec.Flags, previousObj.Spec.Flags) && reflect.DeepEqual(mutatedObj.Spec.DatabaseFlags, previousObj.Spec.DatabaseFlags) {
+		return w.validateDatabaseFlags(mutatedObj.Spec.Flags, mutatedObj.Spec.DatabaseFlags, PostgresqlInstanceSpecVersionDefault, false)
+	}
+	// The read replica runs the same version as the primary CSQLP instance, so we validate the flags against said version.
+	p, err := w.getPostgresqlReplicaPrimary(mutatedObj)
+	if err != nil {
+		return err
+	}
+	version := PostgresqlInstanceSpecVersionDefault
+	if p.Spec.Version != nil {
+		version = *p.Spec.Version
+	}
+	return w.validateDatabaseFlags(mutatedObj.Spec.Flags, mutatedObj.Spec.DatabaseFlags, version, true)
+}
+
+// validateAndMutatePostgresqlReplicaSpecInstanceType validates and mutates the value of ".spec.instanceType".
+func validateAndMutatePostgresqlReplicaSpecInstanceType(mutatedObj, _ *v1alpha1.PostgresqlReplica) error {
+	// If no value for ".spec.instanceType" has been provided, use the default one.
+	if mutatedObj.Spec.InstanceType == nil {
+		mutatedObj.Spec.InstanceType = &PostgresqlInstanceSpecResourcesInstanceTypeDefault
+	}
+	return nil
+}
+
+// validateAndMutatePostgresqlReplicaSpecLocation validates and mutates the value of ".spec.location".
+func (w *Webhook) validateAndMutatePostgresqlReplicaSpecLocation(mutatedObj, previousObj *v1alpha1.PostgresqlReplica) error {
+	// Make sure that ".spec.location" is initialized.
+	if mutatedObj.Spec.Location == nil {
+		mutatedObj.Spec.Location = &v1alpha1.PostgresqlInstanceSpecLocation{}
+	}
+	// If no value for ".spec.location.region" has been provided, use the region of the primary instance.
+	if mutatedObj.Spec.Location.Region == nil {
+		p, err := w.getPostgresqlReplicaPrimary(mutatedObj)
+		if err != nil {
+			return err
+		}
+		r := *p.Spec.Location.Region
+		mutatedObj.Spec.Location.Region = &r
+	}
+	// If no value for ".spec.location.zone" has been provided, use the default one.
+	if mutatedObj.Spec.Location.Zone == nil {
+		mutatedObj.Spec.Location.Zone = &PostgresqlInstanceSpecLocationZoneDefault
+	}
+	// If the current request is an UPDATE request, make sure that ".spec.location.region" is not being changed/removed.
+	if previousObj != nil && previousObj.Spec.Location != nil && *mutatedObj.Spec.Location.Region != *previousObj.Spec.Location.Region {
+		return fmt.Errorf("the region where the read replica is located cannot be changed (had %q, got %q)", *previousObj.Spec.Location.Region, *mutatedObj.Spec.Location.Region)
+	}
+	return nil
+}
+
+// validatePostgresqlReplicaSpecName validates the value of ".spec.name".
+func (w *Webhook) validatePostgresqlReplicaSpecName(mutatedObj, previousObj *v1alpha1.PostgresqlReplica) error {
+	// If the current request is an UPDATE request, make sure that ".spec.name" is not being changed/removed.
+	if previousObj != nil && mutatedObj.Spec.Name != previousObj.Spec.Name {
+		return fmt.Errorf("the name of the read replica cannot be changed (had %q, got %q)", previousObj.Spec.Name, mutatedObj.Spec.Name)
+	}
+	// Make sure that ".spec.name" is not empty.
+	if mutatedObj.Spec.Name == "" {
+		return fmt.Errorf("the name of the read replica cannot be empty")
+	}
+	// Make sure that ".spec.name" matches the required format.
+	if !postgresqlInstanceSpecNameRegex.MatchString(mutatedObj.Spec.Name) {
+		return fmt.Errorf("the name of the read replica must match the %q regular expression (got %q)", postgresqlInstanceSpecNameRegex, mutatedObj.Spec.Name)
+	}
+	// Make sure that ".spec.name" does not exceed the maximum length.
+	if len(mutatedObj.Spec.Name)+len(w.projectID) > postgresqlInstanceSpecNameProjectIDMaxLength {
+		return fmt.Errorf("the name of the read replica must not exceed %d characters (got %q)", postgresqlInstanceSpecNameProjectIDMaxLength-len(w.projectID), mutatedObj.Spec.Name)
+	}
+	// If the current request is a CREATE request, make sure that ".spec.name" does not clash with the name of a pre-existing CSQLP instance.
+	if previousObj == nil {
+		_, err := w.cloudsqlClient.Instances.Get(w.projectID, mutatedObj.Spec.Name).Do()
+		if err == nil {
+			// No error has been returned, which means that ".spec.name" is already being used.
+			return fmt.Errorf("the name %q is already in use by an instance", mutatedObj.Spec.Name)
+		}
+		if !googleutil.IsNotFound(err) {
+			// An error has been returned, but it is not a "404 NOT FOUND" one.
+			return fmt.Errorf("failed to check whether %q can be used as a read replica name: %v", mutatedObj.Spec.Name, err)
+		}
+	}
+	return nil
+}
+
+// getPostgresqlReplicaPrimary returns the PostgresqlInstance resource referenced by the ".spec.primary" field of the specified PostgresqlReplica resource.
+func (w *Webhook) getPostgresqlReplicaPrimary(postgresqlReplica *v1alpha1.PostgresqlReplica) (*v1alpha1.PostgresqlInstance, error) {
+	p, err := w.selfClient.CloudsqlV1alpha1().PostgresqlInstances().Get(postgresqlReplica.Spec.Primary, metav1.GetOptions{})
+	if err != nil {
+		if kubeerrors.IsNotFound(err) {
+			return nil, fmt.Errorf("postgresqlinstance %q does not exist", postgresqlReplica.Spec.Primary)
+		}
+		return nil, fmt.Errorf("failed to get postgresqlinstance %q: %v", postgresqlReplica.Spec.Primary, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"fmt"
+
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+)
+
+// postgresqlRestoreWebhookOperation represents a validation/mutation operation performed by the admission webhook on PostgresqlRestore resources.
+type postgresqlRestoreWebhookOperation func(mutatedObj, previousObj *v1alpha1.PostgresqlRestore) error
+
+// validateAndMutatePostgresqlRestore validates and mutates the provided PostgresqlRestore object.
+// If the current request is a CREATE request, only currentObj is populated.
+// If the current request is an UPDATE request, both currentObj and previousObj are populated.
+// If the current request is a DELETE request, only previousObj is populated.
+func (w *Webhook) validateAndMutatePostgresqlRestore(currentObj, previousObj *v1alpha1.PostgresqlRestore) (*v1alpha1.PostgresqlRestore, error) {
+	// Check whether the current request is a DELETE request and act accordingly.
+	// Deleting a PostgresqlRestore resource has no effect on the target CSQLP instance, so we always allow the request.
+	if currentObj == nil && previousObj != nil {
+		return nil, nil
+	}
+
+	// At this point we know the current request is either a CREATE or UPDATE request.
+
+	// Clone the current object so that we can safely mutate it if necessary.
+	mutatedObj := currentObj.DeepCopy()
+
+	// Perform the required validation/mutation steps.
+	for _, fn := range []postgresqlRestoreWebhookOperation{
+		validatePostgresqlRestoreMetadataAnnotations,
+		w.validatePostgresqlRestoreSpecTarget,
+		w.validateAndMutatePostgresqlRestoreSpecBackupAndSource,
+	} {
+		if err := fn(mutatedObj, previousObj); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return the (possibly) mutated object so a patch can be created if necessary.
+	return mutatedObj, nil
+}
+
+// validatePostgresqlRestoreMetadataAnnotations validates the annotations present on the specified PostgresqlRestore resource.
+func validatePostgresqlRestoreMetadataAnnotations(mutatedObj, previousObj *v1alpha1.PostgresqlRestore) error {
+	// If the current request is a CREATE request, make sure that the "cloudsql.travelaudience.com/confirm-restore" annotation is present and set to "true".
+	// Restoring a backup run overwrites all the data in the target CSQLP instance, so we require explicit confirmation.
+	if previousObj == nil {
+		if v, exists := mutatedObj.Annotations[constants.ConfirmRestoreAnnotationKey]; !exists || v != v1alpha1.True {
+			return fmt.Errorf("the resource cannot be created unless the %q annotation is set to %q", constants.ConfirmRestoreAnnotationKey, v1alpha1.True)
+		}
+	}
+	return nil
+}
+
+// validatePostgresqlRestoreSpecTarget validates the value of ".spec.target".
+func (w *Webhook) validatePostgresqlRestoreSpecTarget(mutatedObj, previousObj *v1alpha1.PostgresqlRestore) error {
+	// If the current request is an UPDATE request, make sure that ".spec.target" is not being changed/removed.
+	if previousObj != nil && mutatedObj.Spec.Target != previousObj.Spec.Target {
+		return fmt.Errorf("the target instance of the restore cannot be changed (had %q, got %q)", previousObj.Spec.Target, mutatedObj.Spec.Target)
+	}
+	// Make sure that ".spec.target" is not empty.
+	if mutatedObj.Spec.Target == "" {
+		return fmt.Errorf("the target instance of the restore cannot be empty")
+	}
+	// If the current request is a CREATE request, make sure that ".spec.target" references an existing PostgresqlInstance resource.
+	if previousObj == nil {
+		if err := w.checkPostgresqlInstanceExists(mutatedObj.Spec.Target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateAndMutatePostgresqlRestoreSpecBackupAndSource validates and mutates the values of ".spec.backup", ".spec.backupRunID" and ".spec.source".
+func (w *Webhook) validateAndMutatePostgresqlRestoreSpecBackupAndSource(mutatedObj, previousObj *v1alpha1.PostgresqlRestore) error {
+	// If the current request is an UPDATE request, make sure that none of the fields is being changed/removed.
+	if previousObj != nil {
+		if mutatedObj.Spec.Backup != previousObj.Spec.Backup {
+			return fmt.Errorf("the backup to restore cannot be changed (had %q, got %q)", previousObj.Spec.Backup, mutatedObj.Spec.Backup)
+		}
+		if mutatedObj.Spec.BackupRunID != previousObj.Spec.BackupRunID {
+			return fmt.Errorf("the id of the backup run to restore cannot be changed (had %d, got %d)", previousObj.Spec.BackupRunID, mutatedObj.Spec.BackupRunID)
+		}
+		if mutatedObj.Spec.Source != previousObj.Spec.Source {
+			return fmt.Errorf("the source instance of the restore cannot be changed (had %q, got %q)", previousObj.Spec.Source, mutatedObj.Spec.Source)
+		}
+		return nil
+	}
+	// Make sure that exactly one of ".spec.backup" and ".spec.backupRunID" has been provided.
+	if (mutatedObj.Spec.Backup == "") == (mutatedObj.Spec.BackupRunID == 0) {
+		return fmt.Errorf("exactly one of the backup and the id of the backup run to restore must be specified")
+	}
+	// Make sure that ".spec.backupRunID" is not negative.
+	if mutatedObj.Spec.BackupRunID < 0 {
+		return fmt.Errorf("the id of the backup run to restore must be a positive number (got %d)", mutatedObj.Spec.BackupRunID)
+	}
+	// If ".spec.backup" has been provided, make sure that it references an existing PostgresqlBackup resource, and use the instance it references as the source.
+	if mutatedObj.Spec.Backup != "" {
+		b, err := w.selfClient.CloudsqlV1alpha1().PostgresqlBackups().Get(mutatedObj.Spec.Backup, metav1.GetOptions{})
+		if err != nil {
+			if kubeerrors.IsNotFound(err) {
+				return fmt.Errorf("postgresqlbackup %q does not exist", mutatedObj.Spec.Backup)
+			}
+			return fmt.Errorf("failed to get postgresqlbackup %q: %v", mutatedObj.Spec.Backup, err)
+		}
+		if mutatedObj.Spec.Source != "" && mutatedObj.Spec.Source != b.Spec.Instance {
+			return fmt.Errorf("the source instance of the restore must match the instance of postgresqlbackup %q (expected %q, got %q)", b.Name, b.Spec.Instance, mutatedObj.Spec.Source)
+		}
+		mutatedObj.Spec.Source = b.Spec.Instance
+	}
+	// If no value for ".spec.source" has been provided, use the target instance.
+	if mutatedObj.Spec.Source == "" {
+		mutatedObj.Spec.Source = mutatedObj.Spec.Target
+	}
+	// Make sure that ".spec.source" references an existing PostgresqlInstance resource.
+	return w.checkPostgresqlInstanceExists(mutatedObj.Spec.Source)
+}
+
+// checkPostgresqlInstanceExists checks whether a PostgresqlInstance resource with the provided name exists.
+func (w *Webhook) checkPostgresqlInstanceExists(name string) error {
+	if _, err := w.selfClient.CloudsqlV1alpha1().PostgresqlInstances().Get(name, metav1.GetOptions{}); err != nil {
+		if kubeerrors.IsNotFound(err) {
+			return fmt.Errorf("postgresqlinstance %q does not exist", name)
+		}
+		return fmt.Errorf("failed to get postgresqlinstance %q: %v", name, err)
+	}
+	return nil
+}
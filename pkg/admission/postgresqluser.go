@@ -0,0 +1,188 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+	googleutil "github.com/travelaudience/cloudsql-postgres-operator/pkg/util/google"
+)
+
+var (
+	// postgresqlUserSpecNameRegex is the regular expression used to validate the ".spec.name" field of a PostgresqlUser resource.
+	postgresqlUserSpecNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]{0,62}$`)
+	// postgresqlUserSpecNameReserved is the set of names which cannot be used as the value of the ".spec.name" field of a PostgresqlUser resource.
+	postgresqlUserSpecNameReserved = []string{
+		"cloudsqladmin",
+		"cloudsqlagent",
+		"cloudsqlreplica",
+		"cloudsqlsuperuser",
+		constants.PostgresqlInstanceUsernameValue,
+	}
+)
+
+// postgresqlUserWebhookOperation represents a validation/mutation operation performed by the admission webhook on PostgresqlUser resources.
+type postgresqlUserWebhookOperation func(mutatedObj, previousObj *v1alpha1.PostgresqlUser) error
+
+// validateAndMutatePostgresqlUser validates and mutates the provided PostgresqlUser object.
+// If the current request is a CREATE request, only currentObj is populated.
+// If the current request is an UPDATE request, both currentObj and previousObj are populated.
+// If the current request is a DELETE request, only previousObj is populated.
+func (w *Webhook) validateAndMutatePostgresqlUser(currentObj, previousObj *v1alpha1.PostgresqlUser) (*v1alpha1.PostgresqlUser, error) {
+	// Check whether the current request is a DELETE request and act accordingly.
+	// In this case, we allow the request if and only if the "cloudsql.travelaudience.com/allow-deletion" annotation is present on the resource and set to "true".
+	if currentObj == nil && previousObj != nil {
+		if v, exists := previousObj.Annotations[constants.AllowDeletionAnnotationKey]; !exists || v != v1alpha1.True {
+			return nil, fmt.Errorf("the resource cannot be deleted unless the %q annotation is set to %q", constants.AllowDeletionAnnotationKey, v1alpha1.True)
+		}
+		return nil, nil
+	}
+
+	// At this point we know the current request is either a CREATE or UPDATE request.
+
+	// Clone the current object so that we can safely mutate it if necessary.
+	mutatedObj := currentObj.DeepCopy()
+
+	// Perform the required validation/mutation steps.
+	for _, fn := range []postgresqlUserWebhookOperation{
+		mutatePostgresqlUserMetadataAnnotations,
+		w.validatePostgresqlUserSpecInstance,
+		w.validatePostgresqlUserSpecName,
+		validateAndMutatePostgresqlUserSpecSecretName,
+	} {
+		if err := fn(mutatedObj, previousObj); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return the (possibly) mutated object so a patch can be created if necessary.
+	return mutatedObj, nil
+}
+
+// mutatePostgresqlUserMetadataAnnotations injects annotations on the specified PostgresqlUser resource.
+func mutatePostgresqlUserMetadataAnnotations(mutatedObj, _ *v1alpha1.PostgresqlUser) error {
+	// Make sure that the map of annotations is initialized on the cloned object.
+	if mutatedObj.Annotations == nil {
+		mutatedObj.Annotations = make(map[string]string, 1)
+	}
+	// Inject the "cloudsql.travelaudience.com/allow-deletion" annotation with a value of "false" if the annotation is not present or is empty.
+	if v, exists := mutatedObj.Annotations[constants.AllowDeletionAnnotationKey]; !exists || v == "" {
+		mutatedObj.Annotations[constants.AllowDeletionAnnotationKey] = v1alpha1.False
+	}
+	return nil
+}
+
+// validatePostgresqlUserSpecInstance validates the value of ".spec.instance".
+func (w *Webhook) validatePostgresqlUserSpecInstance(mutatedObj, previousObj *v1alpha1.PostgresqlUser) error {
+	// If the current request is an UPDATE request, make sure that ".spec.instance" is not being changed/removed.
+	if previousObj != nil && mutatedObj.Spec.Instance != previousObj.Spec.Instance {
+		return fmt.Errorf("the instance of the user cannot be changed (had %q, got %q)", previousObj.Spec.Instance, mutatedObj.Spec.Instance)
+	}
+	// Make sure that ".spec.instance" is not empty.
+	if mutatedObj.Spec.Instance == "" {
+		return fmt.Errorf("the instance of the user cannot be empty")
+	}
+	// If the current request is a CREATE request, make sure that ".spec.instance" references an existing PostgresqlInstance resource.
+	if previousObj == nil {
+		_, err := w.selfClient.CloudsqlV1alpha1().PostgresqlInstances().Get(mutatedObj.Spec.Instance, metav1.GetOptions{})
+		if err != nil {
+			if kubeerrors.IsNotFound(err) {
+				return fmt.Errorf("postgresqlinstance %q does not exist", mutatedObj.Spec.Instance)
+			}
+			return fmt.Errorf("failed to get postgresqlinstance %q: %v", mutatedObj.Spec.Instance, err)
+		}
+	}
+	return nil
+}
+
+// validatePostgresqlUserSpecName validates the value of ".spec.name".
+func (w *Webhook) validatePostgresqlUserSpecName(mutatedObj, previousObj *v1alpha1.PostgresqlUser) error {
+	// If the current request is an UPDATE request, make sure that ".spec.name" is not being changed/removed.
+	if previousObj != nil && mutatedObj.Spec.Name != previousObj.Spec.Name {
+		return fmt.Errorf("the name of the user cannot be changed (had %q, got %q)", previousObj.Spec.Name, mutatedObj.Spec.Name)
+	}
+	// Make sure that ".spec.name" is not empty.
+	if mutatedObj.Spec.Name == "" {
+		return fmt.Errorf("the name of the user cannot be empty")
+	}
+	// Make sure that ".spec.name" matches the required format.
+	if !postgresqlUserSpecNameRegex.MatchString(mutatedObj.Spec.Name) {
+		return fmt.Errorf("the name of the user must match the %q regular expression (got %q)", postgresqlUserSpecNameRegex, mutatedObj.Spec.Name)
+	}
+	// Make sure that ".spec.name" is not a reserved name.
+	for _, name := range postgresqlUserSpecNameReserved {
+		if mutatedObj.Spec.Name == name {
+			return fmt.Errorf("the name %q is reserved and cannot be used as a user name", mutatedObj.Spec.Name)
+		}
+	}
+	// If the current request is a CREATE request, make sure that ".spec.name" is neither claimed by another PostgresqlUser resource (in any namespace) nor in use by a pre-existing user.
+	// Otherwise, the password of the user would be reset and written to a secret in the namespace of the PostgresqlUser resource, and the user would be dropped whenever the PostgresqlUser resource is deleted.
+	if previousObj == nil {
+		users, err := w.selfClient.CloudsqlV1alpha1().PostgresqlUsers(metav1.NamespaceAll).List(metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list postgresqlusers: %v", err)
+		}
+		for _, u := range users.Items {
+			if (u.Namespace != mutatedObj.Namespace || u.Name != mutatedObj.Name) && u.Spec.Instance == mutatedObj.Spec.Instance && u.Spec.Name == mutatedObj.Spec.Name {
+				return fmt.Errorf("the name %q is already claimed by postgresqluser \"%s/%s\"", mutatedObj.Spec.Name, u.Namespace, u.Name)
+			}
+		}
+		instance, err := w.selfClient.CloudsqlV1alpha1().PostgresqlInstances().Get(mutatedObj.Spec.Instance, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get postgresqlinstance %q: %v", mutatedObj.Spec.Instance, err)
+		}
+		existing, err := w.cloudsqlClient.Users.List(w.projectID, instance.Spec.Name).Do()
+		if err != nil {
+			if googleutil.IsNotFound(err) {
+				// The CSQLP instance has not been created yet, and hence there are no users in it.
+				return nil
+			}
+			return fmt.Errorf("failed to check whether %q can be used as a user name: %v", mutatedObj.Spec.Name, err)
+		}
+		for _, u := range existing.Items {
+			if u != nil && u.Name == mutatedObj.Spec.Name {
+				return fmt.Errorf("the name %q is already in use by a user in instance %q", mutatedObj.Spec.Name, instance.Spec.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateAndMutatePostgresqlUserSpecSecretName validates and mutates the value of ".spec.secretName".
+func validateAndMutatePostgresqlUserSpecSecretName(mutatedObj, previousObj *v1alpha1.PostgresqlUser) error {
+	// If no value for ".spec.secretName" has been provided, use the name of the PostgresqlUser resource.
+	if mutatedObj.Spec.SecretName == nil {
+		mutatedObj.Spec.SecretName = &mutatedObj.Name
+	}
+	// If the current request is an UPDATE request, make sure that ".spec.secretName" is not being changed.
+	if previousObj != nil && previousObj.Spec.SecretName != nil && *mutatedObj.Spec.SecretName != *previousObj.Spec.SecretName {
+		return fmt.Errorf("the name of the secret cannot be changed (had %q, got %q)", *previousObj.Spec.SecretName, *mutatedObj.Spec.SecretName)
+	}
+	// Make sure that ".spec.secretName" is a valid name for a secret.
+	if errs := validation.IsDNS1123Subdomain(*mutatedObj.Spec.SecretName); len(errs) > 0 {
+		return fmt.Errorf("the name of the secret is invalid (got %q): %s", *mutatedObj.Spec.SecretName, strings.Join(errs, ", "))
+	}
+	return nil
+}
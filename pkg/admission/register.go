@@ -45,15 +45,47 @@ const (
 	podPlural = "pods"
 	// podWebhookName is the name of the admission webhook that deals with Pod resources.
 	podWebhookName = "pod.cloudsql.travelaudience.com"
+	// postgresqlBackupWebhookName is the name of the admission webhook that deals with PostgresqlBackup resources.
+	postgresqlBackupWebhookName = "postgresqlbackup.cloudsql.travelaudience.com"
+	// postgresqlClientCertificateWebhookName is the name of the admission webhook that deals with PostgresqlClientCertificate resources.
+	postgresqlClientCertificateWebhookName = "postgresqlclientcertificate.cloudsql.travelaudience.com"
+	// postgresqlDatabaseWebhookName is the name of the admission webhook that deals with PostgresqlDatabase resources.
+	postgresqlDatabaseWebhookName = "postgresqldatabase.cloudsql.travelaudience.com"
+	// postgresqlExportWebhookName is the name of the admission webhook that deals with PostgresqlExport resources.
+	postgresqlExportWebhookName = "postgresqlexport.cloudsql.travelaudience.com"
+	// postgresqlImportWebhookName is the name of the admission webhook that deals with PostgresqlImport resources.
+	postgresqlImportWebhookName = "postgresqlimport.cloudsql.travelaudience.com"
 	// postgresqlInstanceWebhookName is the name of the admission webhook that deals with PostgresqlInstance resources.
 	postgresqlInstanceWebhookName = "postgresqlinstance.cloudsql.travelaudience.com"
+	// postgresqlReplicaWebhookName is the name of the admission webhook that deals with PostgresqlReplica resources.
+	postgresqlReplicaWebhookName = "postgresqlreplica.cloudsql.travelaudience.com"
+	// postgresqlRestoreWebhookName is the name of the admission webhook that deals with PostgresqlRestore resources.
+	postgresqlRestoreWebhookName = "postgresqlrestore.cloudsql.travelaudience.com"
+	// postgresqlUserWebhookName is the name of the admission webhook that deals with PostgresqlUser resources.
+	postgresqlUserWebhookName = "postgresqluser.cloudsql.travelaudience.com"
 )
 
 var (
 	// podFailurePolicy is the failure policy to use for the admission webhook that deals with Pod resources.
 	podFailurePolicy = admissionregistrationv1beta1.Ignore
+	// postgresqlBackupFailurePolicy is the failure policy to use for the admission webhook that deals with PostgresqlBackup resources.
+	postgresqlBackupFailurePolicy = admissionregistrationv1beta1.Fail
+	// postgresqlClientCertificateFailurePolicy is the failure policy to use for the admission webhook that deals with PostgresqlClientCertificate resources.
+	postgresqlClientCertificateFailurePolicy = admissionregistrationv1beta1.Fail
+	// postgresqlDatabaseFailurePolicy is the failure policy to use for the admission webhook that deals with PostgresqlDatabase resources.
+	postgresqlDatabaseFailurePolicy = admissionregistrationv1beta1.Fail
+	// postgresqlExportFailurePolicy is the failure policy to use for the admission webhook that deals with PostgresqlExport resources.
+	postgresqlExportFailurePolicy = admissionregistrationv1beta1.Fail
+	// postgresqlImportFailurePolicy is the failure policy to use for the admission webhook that deals with PostgresqlImport resources.
+	postgresqlImportFailurePolicy = admissionregistrationv1beta1.Fail
 	// postgresInstanceFailurePolicy is the failure policy to use for the admission webhook that deals with PostgresqlInstance resources.
 	postgresInstanceFailurePolicy = admissionregistrationv1beta1.Fail
+	// postgresqlReplicaFailurePolicy is the failure policy to use for the admission webhook that deals with PostgresqlReplica resources.
+	postgresqlReplicaFailurePolicy = admissionregistrationv1beta1.Fail
+	// postgresqlRestoreFailurePolicy is the failure policy to use for the admission webhook that deals with PostgresqlRestore resources.
+	postgresqlRestoreFailurePolicy = admissionregistrationv1beta1.Fail
+	// postgresqlUserFailurePolicy is the failure policy to use for the admission webhook that deals with PostgresqlUser resources.
+	postgresqlUserFailurePolicy = admissionregistrationv1beta1.Fail
 )
 
 // Register registers the admission webhook by making sure a MutatingWebhookConfiguration resource with the desired configuration exists.
@@ -184,6 +216,262 @@ func (w *Webhook) buildMutatingWebhookConfigurationObject() *admissionregistrati
 				},
 				FailurePolicy: &postgresInstanceFailurePolicy,
 			},
+			{
+				Name: postgresqlBackupWebhookName,
+				Rules: []admissionregistrationv1beta1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Create,
+							admissionregistrationv1beta1.Update,
+							admissionregistrationv1beta1.Delete,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups: []string{
+								v1alpha1.SchemeGroupVersion.Group,
+							},
+							APIVersions: []string{
+								v1alpha1.SchemeGroupVersion.Version,
+							},
+							Resources: []string{
+								crds.PostgresqlBackupPlural,
+							},
+						},
+					},
+				},
+				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+					Service: &admissionregistrationv1beta1.ServiceReference{
+						Name:      cloudsqlPostgresOperatorServiceName,
+						Namespace: w.namespace,
+						Path:      &admissionPath,
+					},
+					CABundle: caBundle,
+				},
+				FailurePolicy: &postgresqlBackupFailurePolicy,
+			},
+			{
+				Name: postgresqlClientCertificateWebhookName,
+				Rules: []admissionregistrationv1beta1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Create,
+							admissionregistrationv1beta1.Update,
+							admissionregistrationv1beta1.Delete,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups: []string{
+								v1alpha1.SchemeGroupVersion.Group,
+							},
+							APIVersions: []string{
+								v1alpha1.SchemeGroupVersion.Version,
+							},
+							Resources: []string{
+								crds.PostgresqlClientCertificatePlural,
+							},
+						},
+					},
+				},
+				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+					Service: &admissionregistrationv1beta1.ServiceReference{
+						Name:      cloudsqlPostgresOperatorServiceName,
+						Namespace: w.namespace,
+						Path:      &admissionPath,
+					},
+					CABundle: caBundle,
+				},
+				FailurePolicy: &postgresqlClientCertificateFailurePolicy,
+			},
+			{
+				Name: postgresqlDatabaseWebhookName,
+				Rules: []admissionregistrationv1beta1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Create,
+							admissionregistrationv1beta1.Update,
+							admissionregistrationv1beta1.Delete,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups: []string{
+								v1alpha1.SchemeGroupVersion.Group,
+							},
+							APIVersions: []string{
+								v1alpha1.SchemeGroupVersion.Version,
+							},
+							Resources: []string{
+								crds.PostgresqlDatabasePlural,
+							},
+						},
+					},
+				},
+				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+					Service: &admissionregistrationv1beta1.ServiceReference{
+						Name:      cloudsqlPostgresOperatorServiceName,
+						Namespace: w.namespace,
+						Path:      &admissionPath,
+					},
+					CABundle: caBundle,
+				},
+				FailurePolicy: &postgresqlDatabaseFailurePolicy,
+			},
+			{
+				Name: postgresqlExportWebhookName,
+				Rules: []admissionregistrationv1beta1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Create,
+							admissionregistrationv1beta1.Update,
+							admissionregistrationv1beta1.Delete,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups: []string{
+								v1alpha1.SchemeGroupVersion.Group,
+							},
+							APIVersions: []string{
+								v1alpha1.SchemeGroupVersion.Version,
+							},
+							Resources: []string{
+								crds.PostgresqlExportPlural,
+							},
+						},
+					},
+				},
+				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+					Service: &admissionregistrationv1beta1.ServiceReference{
+						Name:      cloudsqlPostgresOperatorServiceName,
+						Namespace: w.namespace,
+						Path:      &admissionPath,
+					},
+					CABundle: caBundle,
+				},
+				FailurePolicy: &postgresqlExportFailurePolicy,
+			},
+			{
+				Name: postgresqlImportWebhookName,
+				Rules: []admissionregistrationv1beta1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Create,
+							admissionregistrationv1beta1.Update,
+							admissionregistrationv1beta1.Delete,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups: []string{
+								v1alpha1.SchemeGroupVersion.Group,
+							},
+							APIVersions: []string{
+								v1alpha1.SchemeGroupVersion.Version,
+							},
+							Resources: []string{
+								crds.PostgresqlImportPlural,
+							},
+						},
+					},
+				},
+				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+					Service: &admissionregistrationv1beta1.ServiceReference{
+						Name:      cloudsqlPostgresOperatorServiceName,
+						Namespace: w.namespace,
+						Path:      &admissionPath,
+					},
+					CABundle: caBundle,
+				},
+				FailurePolicy: &postgresqlImportFailurePolicy,
+			},
+			{
+				Name: postgresqlReplicaWebhookName,
+				Rules: []admissionregistrationv1beta1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Create,
+							admissionregistrationv1beta1.Update,
+							admissionregistrationv1beta1.Delete,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups: []string{
+								v1alpha1.SchemeGroupVersion.Group,
+							},
+							APIVersions: []string{
+								v1alpha1.SchemeGroupVersion.Version,
+							},
+							Resources: []string{
+								crds.PostgresqlReplicaPlural,
+							},
+						},
+					},
+				},
+				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+					Service: &admissionregistrationv1beta1.ServiceReference{
+						Name:      cloudsqlPostgresOperatorServiceName,
+						Namespace: w.namespace,
+						Path:      &admissionPath,
+					},
+					CABundle: caBundle,
+				},
+				FailurePolicy: &postgresqlReplicaFailurePolicy,
+			},
+			{
+				Name: postgresqlRestoreWebhookName,
+				Rules: []admissionregistrationv1beta1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Create,
+							admissionregistrationv1beta1.Update,
+							admissionregistrationv1beta1.Delete,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups: []string{
+								v1alpha1.SchemeGroupVersion.Group,
+							},
+							APIVersions: []string{
+								v1alpha1.SchemeGroupVersion.Version,
+							},
+							Resources: []string{
+								crds.PostgresqlRestorePlural,
+							},
+						},
+					},
+				},
+				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+					Service: &admissionregistrationv1beta1.ServiceReference{
+						Name:      cloudsqlPostgresOperatorServiceName,
+						Namespace: w.namespace,
+						Path:      &admissionPath,
+					},
+					CABundle: caBundle,
+				},
+				FailurePolicy: &postgresqlRestoreFailurePolicy,
+			},
+			{
+				Name: postgresqlUserWebhookName,
+				Rules: []admissionregistrationv1beta1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Create,
+							admissionregistrationv1beta1.Update,
+							admissionregistrationv1beta1.Delete,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups: []string{
+								v1alpha1.SchemeGroupVersion.Group,
+							},
+							APIVersions: []string{
+								v1alpha1.SchemeGroupVersion.Version,
+							},
+							Resources: []string{
+								crds.PostgresqlUserPlural,
+							},
+						},
+					},
+				},
+				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+					Service: &admissionregistrationv1beta1.ServiceReference{
+						Name:      cloudsqlPostgresOperatorServiceName,
+						Namespace: w.namespace,
+						Path:      &admissionPath,
+					},
+					CABundle: caBundle,
+				},
+				FailurePolicy: &postgresqlUserFailurePolicy,
+			},
 		},
 	}
 }
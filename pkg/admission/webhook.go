@@ -36,11 +36,15 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 
 	"github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
 	selfClient "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/clientset/versioned"
+	v1alpha1informers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/informers/externalversions/cloudsql/v1alpha1"
+	v1alpha1listers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/listers/cloudsql/v1alpha1"
 	"github.com/travelaudience/cloudsql-postgres-operator/pkg/configuration"
 	"github.com/travelaudience/cloudsql-postgres-operator/pkg/crds"
+	googleutil "github.com/travelaudience/cloudsql-postgres-operator/pkg/util/google"
 )
 
 const (
@@ -63,6 +67,66 @@ var (
 		Version:  v1.SchemeGroupVersion.Version,
 		Resource: podPlural,
 	}
+	// postgresqlBackupGvk is the GroupVersionKind that corresponds to PostgresqlBackup resources.
+	postgresqlBackupGvk = &schema.GroupVersionKind{
+		Group:   v1alpha1.SchemeGroupVersion.Group,
+		Version: v1alpha1.SchemeGroupVersion.Version,
+		Kind:    crds.PostgresqlBackupKind,
+	}
+	// postgresqlBackupGvr is the GroupVersionResource that corresponds to PostgresqlBackup resources.
+	postgresqlBackupGvr = metav1.GroupVersionResource{
+		Group:    v1alpha1.SchemeGroupVersion.Group,
+		Version:  v1alpha1.SchemeGroupVersion.Version,
+		Resource: crds.PostgresqlBackupPlural,
+	}
+	// postgresqlClientCertificateGvk is the GroupVersionKind that corresponds to PostgresqlClientCertificate resources.
+	postgresqlClientCertificateGvk = &schema.GroupVersionKind{
+		Group:   v1alpha1.SchemeGroupVersion.Group,
+		Version: v1alpha1.SchemeGroupVersion.Version,
+		Kind:    crds.PostgresqlClientCertificateKind,
+	}
+	// postgresqlClientCertificateGvr is the GroupVersionResource that corresponds to PostgresqlClientCertificate resources.
+	postgresqlClientCertificateGvr = metav1.GroupVersionResource{
+		Group:    v1alpha1.SchemeGroupVersion.Group,
+		Version:  v1alpha1.SchemeGroupVersion.Version,
+		Resource: crds.PostgresqlClientCertificatePlural,
+	}
+	// postgresqlDatabaseGvk is the GroupVersionKind that corresponds to PostgresqlDatabase resources.
+	postgresqlDatabaseGvk = &schema.GroupVersionKind{
+		Group:   v1alpha1.SchemeGroupVersion.Group,
+		Version: v1alpha1.SchemeGroupVersion.Version,
+		Kind:    crds.PostgresqlDatabaseKind,
+	}
+	// postgresqlDatabaseGvr is the GroupVersionResource that corresponds to PostgresqlDatabase resources.
+	postgresqlDatabaseGvr = metav1.GroupVersionResource{
+		Group:    v1alpha1.SchemeGroupVersion.Group,
+		Version:  v1alpha1.SchemeGroupVersion.Version,
+		Resource: crds.PostgresqlDatabasePlural,
+	}
+	// postgresqlExportGvk is the GroupVersionKind that corresponds to PostgresqlExport resources.
+	postgresqlExportGvk = &schema.GroupVersionKind{
+		Group:   v1alpha1.SchemeGroupVersion.Group,
+		Version: v1alpha1.SchemeGroupVersion.Version,
+		Kind:    crds.PostgresqlExportKind,
+	}
+	// postgresqlExportGvr is the GroupVersionResource that corresponds to PostgresqlExport resources.
+	postgresqlExportGvr = metav1.GroupVersionResource{
+		Group:    v1alpha1.SchemeGroupVersion.Group,
+		Version:  v1alpha1.SchemeGroupVersion.Version,
+		Resource: crds.PostgresqlExportPlural,
+	}
+	// postgresqlImportGvk is the GroupVersionKind that corresponds to PostgresqlImport resources.
+	postgresqlImportGvk = &schema.GroupVersionKind{
+		Group:   v1alpha1.SchemeGroupVersion.Group,
+		Version: v1alpha1.SchemeGroupVersion.Version,
+		Kind:    crds.PostgresqlImportKind,
+	}
+	// postgresqlImportGvr is the GroupVersionResource that corresponds to PostgresqlImport resources.
+	postgresqlImportGvr = metav1.GroupVersionResource{
+		Group:    v1alpha1.SchemeGroupVersion.Group,
+		Version:  v1alpha1.SchemeGroupVersion.Version,
+		Resource: crds.PostgresqlImportPlural,
+	}
 	// postgresqlInstanceGvk is the GroupVersionKind that corresponds to PostgresqlInstance resources.
 	postgresqlInstanceGvk = &schema.GroupVersionKind{
 		Group:   v1alpha1.SchemeGroupVersion.Group,
@@ -75,6 +139,42 @@ var (
 		Version:  v1alpha1.SchemeGroupVersion.Version,
 		Resource: crds.PostgresqlInstancePlural,
 	}
+	// postgresqlReplicaGvk is the GroupVersionKind that corresponds to PostgresqlReplica resources.
+	postgresqlReplicaGvk = &schema.GroupVersionKind{
+		Group:   v1alpha1.SchemeGroupVersion.Group,
+		Version: v1alpha1.SchemeGroupVersion.Version,
+		Kind:    crds.PostgresqlReplicaKind,
+	}
+	// postgresqlReplicaGvr is the GroupVersionResource that corresponds to PostgresqlReplica resources.
+	postgresqlReplicaGvr = metav1.GroupVersionResource{
+		Group:    v1alpha1.SchemeGroupVersion.Group,
+		Version:  v1alpha1.SchemeGroupVersion.Version,
+		Resource: crds.PostgresqlReplicaPlural,
+	}
+	// postgresqlRestoreGvk is the GroupVersionKind that corresponds to PostgresqlRestore resources.
+	postgresqlRestoreGvk = &schema.GroupVersionKind{
+		Group:   v1alpha1.SchemeGroupVersion.Group,
+		Version: v1alpha1.SchemeGroupVersion.Version,
+		Kind:    crds.PostgresqlRestoreKind,
+	}
+	// postgresqlRestoreGvr is the GroupVersionResource that corresponds to PostgresqlRestore resources.
+	postgresqlRestoreGvr = metav1.GroupVersionResource{
+		Group:    v1alpha1.SchemeGroupVersion.Group,
+		Version:  v1alpha1.SchemeGroupVersion.Version,
+		Resource: crds.PostgresqlRestorePlural,
+	}
+	// postgresqlUserGvk is the GroupVersionKind that corresponds to PostgresqlUser resources.
+	postgresqlUserGvk = &schema.GroupVersionKind{
+		Group:   v1alpha1.SchemeGroupVersion.Group,
+		Version: v1alpha1.SchemeGroupVersion.Version,
+		Kind:    crds.PostgresqlUserKind,
+	}
+	// postgresqlUserGvr is the GroupVersionResource that corresponds to PostgresqlUser resources.
+	postgresqlUserGvr = metav1.GroupVersionResource{
+		Group:    v1alpha1.SchemeGroupVersion.Group,
+		Version:  v1alpha1.SchemeGroupVersion.Version,
+		Resource: crds.PostgresqlUserPlural,
+	}
 	// patchType is the type of patch sent in admission responses.
 	patchType = admissionv1beta1.PatchTypeJSONPatch
 )
@@ -91,10 +191,16 @@ type Webhook struct {
 	cloudsqlProxyImage string
 	// codecs is the codec factory to use to serialize/deserialize resources.
 	codecs serializer.CodecFactory
+	// databaseFlagsCatalog is the (cached) catalog of database flags supported by Cloud SQL.
+	databaseFlagsCatalog *googleutil.DatabaseFlagsCatalog
 	// kubeClient is the Kubernetes client to use.
 	kubeClient kubernetes.Interface
 	// namespace is the namespace where cloudsql-postgres-operator is deployed.
 	namespace string
+	// postgresqlInstanceLister is a lister for PostgresqlInstance resources.
+	postgresqlInstanceLister v1alpha1listers.PostgresqlInstanceLister
+	// postgresqlInstanceSynced is the function used to determine if the cache of PostgresqlInstance resources is synced.
+	postgresqlInstanceSynced cache.InformerSynced
 	// projectID is the ID of the Google Cloud Project in which cloudsql-postgres-operator is managing Cloud SQL instances.
 	projectID string
 	// selfClient is a client to the cloudsql-postgres-operator API.
@@ -104,31 +210,47 @@ type Webhook struct {
 }
 
 // NewWebhook creates a new instance of the admission webhook.
-func NewWebhook(kubeClient kubernetes.Interface, selfClient selfClient.Interface, cloudsqlClient *cloudsqladmin.Service, config configuration.Configuration) (*Webhook, error) {
+func NewWebhook(kubeClient kubernetes.Interface, selfClient selfClient.Interface, postgresqlInstanceInformer v1alpha1informers.PostgresqlInstanceInformer, cloudsqlClient *cloudsqladmin.Service, config configuration.Configuration) (*Webhook, error) {
 	// Read the credentials of the client IAM service account.
 	c, err := ioutil.ReadFile(config.GCP.ClientServiceAccountKeyPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read the credentials of the client iam service account: %v", err)
 	}
-	// Create a new scheme and register the PostgresqlInstance type so we can serialize/deserialize it.
+	// Create a new scheme and register our API types so we can serialize/deserialize them.
 	scheme := runtime.NewScheme()
+	scheme.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.PostgresqlBackup{})
+	scheme.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.PostgresqlClientCertificate{})
+	scheme.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.PostgresqlDatabase{})
+	scheme.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.PostgresqlExport{})
+	scheme.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.PostgresqlImport{})
 	scheme.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.PostgresqlInstance{})
+	scheme.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.PostgresqlReplica{})
+	scheme.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.PostgresqlRestore{})
+	scheme.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.PostgresqlUser{})
 	scheme.AddKnownTypes(v1.SchemeGroupVersion, &v1.Pod{})
 	return &Webhook{
-		bindAddress:             config.Admission.BindAddress,
-		clientServiceAccountKey: string(c),
-		cloudsqlProxyImage:      config.Admission.CloudSQLProxyImage,
-		cloudsqlClient:          cloudsqlClient,
-		selfClient:              selfClient,
-		codecs:                  serializer.NewCodecFactory(scheme),
-		kubeClient:              kubeClient,
-		namespace:               config.Cluster.Namespace,
-		projectID:               config.GCP.ProjectID,
+		bindAddress:              config.Admission.BindAddress,
+		clientServiceAccountKey:  string(c),
+		cloudsqlProxyImage:       config.Admission.CloudSQLProxyImage,
+		cloudsqlClient:           cloudsqlClient,
+		selfClient:               selfClient,
+		codecs:                   serializer.NewCodecFactory(scheme),
+		databaseFlagsCatalog:     googleutil.NewDatabaseFlagsCatalog(cloudsqlClient),
+		kubeClient:               kubeClient,
+		namespace:                config.Cluster.Namespace,
+		postgresqlInstanceLister: postgresqlInstanceInformer.Lister(),
+		postgresqlInstanceSynced: postgresqlInstanceInformer.Informer().HasSynced,
+		projectID:                config.GCP.ProjectID,
 	}, nil
 }
 
 // Run starts the HTTP server that backs the admission webhook.
 func (w *Webhook) Run(stopCh chan struct{}) error {
+	// Wait for the cache of PostgresqlInstance resources to be synced before serving requests, as admission of pods relies on it.
+	if ok := cache.WaitForCacheSync(stopCh, w.postgresqlInstanceSynced); !ok {
+		return fmt.Errorf("failed to wait for informer caches to be synced")
+	}
+
 	// Create an HTTP server and register handler functions to back the admission webhook.
 	mux := http.NewServeMux()
 	mux.HandleFunc(admissionPath, w.handleAdmission)
@@ -212,8 +334,24 @@ func (w *Webhook) handleAdmission(res http.ResponseWriter, req *http.Request) {
 // readObject reads the object pointed by the provided coordinates from the Kubernetes API.
 func (w *Webhook) readObject(kind *schema.GroupVersionKind, namespace, name string) (runtime.Object, error) {
 	switch kind {
+	case postgresqlBackupGvk:
+		return w.selfClient.CloudsqlV1alpha1().PostgresqlBackups().Get(name, metav1.GetOptions{})
+	case postgresqlClientCertificateGvk:
+		return w.selfClient.CloudsqlV1alpha1().PostgresqlClientCertificates(namespace).Get(name, metav1.GetOptions{})
+	case postgresqlDatabaseGvk:
+		return w.selfClient.CloudsqlV1alpha1().PostgresqlDatabases().Get(name, metav1.GetOptions{})
+	case postgresqlExportGvk:
+		return w.selfClient.CloudsqlV1alpha1().PostgresqlExports().Get(name, metav1.GetOptions{})
+	case postgresqlImportGvk:
+		return w.selfClient.CloudsqlV1alpha1().PostgresqlImports().Get(name, metav1.GetOptions{})
 	case postgresqlInstanceGvk:
 		return w.selfClient.CloudsqlV1alpha1().PostgresqlInstances().Get(name, metav1.GetOptions{})
+	case postgresqlReplicaGvk:
+		return w.selfClient.CloudsqlV1alpha1().PostgresqlReplicas().Get(name, metav1.GetOptions{})
+	case postgresqlRestoreGvk:
+		return w.selfClient.CloudsqlV1alpha1().PostgresqlRestores().Get(name, metav1.GetOptions{})
+	case postgresqlUserGvk:
+		return w.selfClient.CloudsqlV1alpha1().PostgresqlUsers(namespace).Get(name, metav1.GetOptions{})
 	default:
 		return nil, fmt.Errorf("unsupported gvk: %s", kind.String())
 	}
@@ -226,7 +364,7 @@ func (w *Webhook) validateAndMutateResource(rev admissionv1beta1.AdmissionReview
 		// It MUST NOT be modified, as it is used as the basis for the patch to apply as a result of the current request.
 		currentObj runtime.Object
 		// currentGVK will contain the GVK (Group/Version/Kind) of the current resource.
-		// It is used to identify the kind of resource (PostgresqlInstance/...) we are dealing with in the current request.
+		// It is used to identify the kind of resource (PostgresqlBackup/PostgresqlClientCertificate/PostgresqlDatabase/PostgresqlExport/PostgresqlImport/PostgresqlInstance/PostgresqlReplica/PostgresqlRestore/PostgresqlUser/...) we are dealing with in the current request.
 		currentGVK *schema.GroupVersionKind
 		// mutatedObj will contain a clone of currentObj.
 		// It will be modified as required in order to explicitly set the values of all annotations.
@@ -243,9 +381,33 @@ func (w *Webhook) validateAndMutateResource(rev admissionv1beta1.AdmissionReview
 	case podGvr:
 		// We're dealing with a Pod resource.
 		currentGVK = podGvk
+	case postgresqlBackupGvr:
+		// We're dealing with a PostgresqlBackup resource.
+		currentGVK = postgresqlBackupGvk
+	case postgresqlClientCertificateGvr:
+		// We're dealing with a PostgresqlClientCertificate resource.
+		currentGVK = postgresqlClientCertificateGvk
+	case postgresqlDatabaseGvr:
+		// We're dealing with a PostgresqlDatabase resource.
+		currentGVK = postgresqlDatabaseGvk
+	case postgresqlExportGvr:
+		// We're dealing with a PostgresqlExport resource.
+		currentGVK = postgresqlExportGvk
+	case postgresqlImportGvr:
+		// We're dealing with a PostgresqlImport resource.
+		currentGVK = postgresqlImportGvk
 	case postgresqlInstanceGvr:
 		// We're dealing with a PostgresqlInstance resource.
 		currentGVK = postgresqlInstanceGvk
+	case postgresqlReplicaGvr:
+		// We're dealing with a PostgresqlReplica resource.
+		currentGVK = postgresqlReplicaGvk
+	case postgresqlRestoreGvr:
+		// We're dealing with a PostgresqlRestore resource.
+		currentGVK = postgresqlRestoreGvk
+	case postgresqlUserGvr:
+		// We're dealing with a PostgresqlUser resource.
+		currentGVK = postgresqlUserGvk
 	default:
 		// We're dealing with an unsupported resource, so we must fail.
 		return admissionResponseFromError(fmt.Errorf("failed to validate resource with unsupported gvr %s", rev.Request.Resource.String()))
@@ -296,6 +458,71 @@ func (w *Webhook) validateAndMutateResource(rev admissionv1beta1.AdmissionReview
 			return admissionResponseFromError(fmt.Errorf(""))
 		}
 		mutatedObj, err = w.mutatePod(rev.Request.Namespace, currentObj.(*v1.Pod))
+	case postgresqlBackupGvk:
+		var (
+			currentPostgresqlBackup, previousPostgresqlBackup *v1alpha1.PostgresqlBackup
+		)
+		// If currentObj is not nil, cast it to PostgresqlBackup.
+		if currentObj != nil {
+			currentPostgresqlBackup = currentObj.(*v1alpha1.PostgresqlBackup)
+		}
+		// If previousObj is not nil, cast it to PostgresqlBackup.
+		if previousObj != nil {
+			previousPostgresqlBackup = previousObj.(*v1alpha1.PostgresqlBackup)
+		}
+		mutatedObj, err = w.validateAndMutatePostgresqlBackup(currentPostgresqlBackup, previousPostgresqlBackup)
+	case postgresqlClientCertificateGvk:
+		var (
+			currentPostgresqlClientCertificate, previousPostgresqlClientCertificate *v1alpha1.PostgresqlClientCertificate
+		)
+		// If currentObj is not nil, cast it to PostgresqlClientCertificate.
+		if currentObj != nil {
+			currentPostgresqlClientCertificate = currentObj.(*v1alpha1.PostgresqlClientCertificate)
+		}
+		// If previousObj is not nil, cast it to PostgresqlClientCertificate.
+		if previousObj != nil {
+			previousPostgresqlClientCertificate = previousObj.(*v1alpha1.PostgresqlClientCertificate)
+		}
+		mutatedObj, err = w.validateAndMutatePostgresqlClientCertificate(currentPostgresqlClientCertificate, previousPostgresqlClientCertificate)
+	case postgresqlDatabaseGvk:
+		var (
+			currentPostgresqlDatabase, previousPostgresqlDatabase *v1alpha1.PostgresqlDatabase
+		)
+		// If currentObj is not nil, cast it to PostgresqlDatabase.
+		if currentObj != nil {
+			currentPostgresqlDatabase = currentObj.(*v1alpha1.PostgresqlDatabase)
+		}
+		// If previousObj is not nil, cast it to PostgresqlDatabase.
+		if previousObj != nil {
+			previousPostgresqlDatabase = previousObj.(*v1alpha1.PostgresqlDatabase)
+		}
+		mutatedObj, err = w.validateAndMutatePostgresqlDatabase(currentPostgresqlDatabase, previousPostgresqlDatabase)
+	case postgresqlExportGvk:
+		var (
+			currentPostgresqlExport, previousPostgresqlExport *v1alpha1.PostgresqlExport
+		)
+		// If currentObj is not nil, cast it to PostgresqlExport.
+		if currentObj != nil {
+			currentPostgresqlExport = currentObj.(*v1alpha1.PostgresqlExport)
+		}
+		// If previousObj is not nil, cast it to PostgresqlExport.
+		if previousObj != nil {
+			previousPostgresqlExport = previousObj.(*v1alpha1.PostgresqlExport)
+		}
+		mutatedObj, err = w.validateAndMutatePostgresqlExport(currentPostgresqlExport, previousPostgresqlExport)
+	case postgresqlImportGvk:
+		var (
+			currentPostgresqlImport, previousPostgresqlImport *v1alpha1.PostgresqlImport
+		)
+		// If currentObj is not nil, cast it to PostgresqlImport.
+		if currentObj != nil {
+			currentPostgresqlImport = currentObj.(*v1alpha1.PostgresqlImport)
+		}
+		// If previousObj is not nil, cast it to PostgresqlImport.
+		if previousObj != nil {
+			previousPostgresqlImport = previousObj.(*v1alpha1.PostgresqlImport)
+		}
+		mutatedObj, err = w.validateAndMutatePostgresqlImport(currentPostgresqlImport, previousPostgresqlImport)
 	case postgresqlInstanceGvk:
 		var (
 			currentPostgresqlInstance, previousPostgresqlInstance *v1alpha1.PostgresqlInstance
@@ -309,6 +536,45 @@ func (w *Webhook) validateAndMutateResource(rev admissionv1beta1.AdmissionReview
 			previousPostgresqlInstance = previousObj.(*v1alpha1.PostgresqlInstance)
 		}
 		mutatedObj, err = w.validateAndMutatePostgresqlInstance(currentPostgresqlInstance, previousPostgresqlInstance)
+	case postgresqlReplicaGvk:
+		var (
+			currentPostgresqlReplica, previousPostgresqlReplica *v1alpha1.PostgresqlReplica
+		)
+		// If currentObj is not nil, cast it to PostgresqlReplica.
+		if currentObj != nil {
+			currentPostgresqlReplica = currentObj.(*v1alpha1.PostgresqlReplica)
+		}
+		// If previousObj is not nil, cast it to PostgresqlReplica.
+		if previousObj != nil {
+			previousPostgresqlReplica = previousObj.(*v1alpha1.PostgresqlReplica)
+		}
+		mutatedObj, err = w.validateAndMutatePostgresqlReplica(currentPostgresqlReplica, previousPostgresqlReplica)
+	case postgresqlRestoreGvk:
+		var (
+			currentPostgresqlRestore, previousPostgresqlRestore *v1alpha1.PostgresqlRestore
+		)
+		// If currentObj is not nil, cast it to PostgresqlRestore.
+		if currentObj != nil {
+			currentPostgresqlRestore = currentObj.(*v1alpha1.PostgresqlRestore)
+		}
+		// If previousObj is not nil, cast it to PostgresqlRestore.
+		if previousObj != nil {
+			previousPostgresqlRestore = previousObj.(*v1alpha1.PostgresqlRestore)
+		}
+		mutatedObj, err = w.validateAndMutatePostgresqlRestore(currentPostgresqlRestore, previousPostgresqlRestore)
+	case postgresqlUserGvk:
+		var (
+			currentPostgresqlUser, previousPostgresqlUser *v1alpha1.PostgresqlUser
+		)
+		// If currentObj is not nil, cast it to PostgresqlUser.
+		if currentObj != nil {
+			currentPostgresqlUser = currentObj.(*v1alpha1.PostgresqlUser)
+		}
+		// If previousObj is not nil, cast it to PostgresqlUser.
+		if previousObj != nil {
+			previousPostgresqlUser = previousObj.(*v1alpha1.PostgresqlUser)
+		}
+		mutatedObj, err = w.validateAndMutatePostgresqlUser(currentPostgresqlUser, previousPostgresqlUser)
 	default:
 		return admissionResponseFromError(fmt.Errorf("failed to validate resource of unsupported type %v", reflect.TypeOf(currentObj)))
 	}
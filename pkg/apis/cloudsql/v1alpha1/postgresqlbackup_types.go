@@ -0,0 +1,119 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PostgresqlBackupStatusConditionTypeCompleted indicates whether the backup run represented by a given PostgresqlBackup resource has completed successfully.
+	PostgresqlBackupStatusConditionTypeCompleted = PostgresqlBackupStatusConditionType("Completed")
+	// PostgresqlBackupStatusConditionTypeCreated indicates that the backup run represented by a given PostgresqlBackup resource has been created.
+	PostgresqlBackupStatusConditionTypeCreated = PostgresqlBackupStatusConditionType("Created")
+)
+
+const (
+	// PostgresqlBackupStatusStateFailed indicates that the backup run has failed.
+	PostgresqlBackupStatusStateFailed = PostgresqlBackupStatusState("Failed")
+	// PostgresqlBackupStatusStatePending indicates that the backup run has been requested but has not started yet.
+	PostgresqlBackupStatusStatePending = PostgresqlBackupStatusState("Pending")
+	// PostgresqlBackupStatusStateRunning indicates that the backup run is in progress.
+	PostgresqlBackupStatusStateRunning = PostgresqlBackupStatusState("Running")
+	// PostgresqlBackupStatusStateSuccessful indicates that the backup run has completed successfully.
+	PostgresqlBackupStatusStateSuccessful = PostgresqlBackupStatusState("Successful")
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlBackup represents an on-demand backup run of a CSQLP instance.
+type PostgresqlBackup struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard object metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec represents the specification of the backup run.
+	Spec PostgresqlBackupSpec `json:"spec"`
+	// Status represents the status of the backup run.
+	Status PostgresqlBackupStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlBackupList is a list of PostgresqlBackup resources.
+type PostgresqlBackupList struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	metav1.ListMeta `json:"metadata"`
+	// Items is the set of PostgresqlBackup resources in the list.
+	Items []PostgresqlBackup `json:"items"`
+}
+
+// PostgresqlBackupSpec represents the specification of an on-demand backup run of a CSQLP instance.
+type PostgresqlBackupSpec struct {
+	// Instance is the name of the PostgresqlInstance resource (i.e. its ".metadata.name") that represents the CSQLP instance to backup.
+	Instance string `json:"instance"`
+}
+
+// PostgresqlBackupStatus represents the status of an on-demand backup run of a CSQLP instance.
+type PostgresqlBackupStatus struct {
+	// Conditions is the set of conditions associated with the current PostgresqlBackup resource.
+	// +optional
+	Conditions []PostgresqlBackupStatusCondition `json:"conditions,omitempty"`
+	// EndTime is the time at which the backup run finished.
+	// +optional
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+	// ID is the ID of the backup run.
+	// +optional
+	ID int64 `json:"id,omitempty"`
+	// OperationID is the ID of the Cloud SQL Admin API operation that created the backup run.
+	// +optional
+	OperationID string `json:"operationID,omitempty"`
+	// StartTime is the time at which the backup run started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// State is the state of the backup run.
+	// +optional
+	State PostgresqlBackupStatusState `json:"state,omitempty"`
+}
+
+// PostgresqlBackupStatusCondition represents a condition associated with a PostgresqlBackup resource.
+type PostgresqlBackupStatusCondition struct {
+	// LastTransitionTime is the timestamp corresponding to the last status change of this condition.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Message is a human readable description of the details of the condition's last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Reason is a brief machine readable explanation for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Status is the status of the condition (one of "True", "False" or "Unknown").
+	Status corev1.ConditionStatus `json:"status"`
+	// Type is the type of the condition.
+	Type PostgresqlBackupStatusConditionType `json:"type"`
+}
+
+// PostgresqlBackupStatusConditionType represents the type of a condition associated with a PostgresqlBackup resource.
+type PostgresqlBackupStatusConditionType string
+
+// PostgresqlBackupStatusState represents the state of an on-demand backup run of a CSQLP instance.
+type PostgresqlBackupStatusState string
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PostgresqlClientCertificateStatusConditionTypeCreated indicates that the client certificate represented by a given PostgresqlClientCertificate resource has been issued.
+	PostgresqlClientCertificateStatusConditionTypeCreated = PostgresqlClientCertificateStatusConditionType("Created")
+	// PostgresqlClientCertificateStatusConditionTypeReady indicates that the client certificate represented by a given PostgresqlClientCertificate resource is in a ready state.
+	PostgresqlClientCertificateStatusConditionTypeReady = PostgresqlClientCertificateStatusConditionType("Ready")
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlClientCertificate represents a client certificate used to establish SSL connections to a CSQLP instance.
+type PostgresqlClientCertificate struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard object metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec represents the specification of the client certificate.
+	Spec PostgresqlClientCertificateSpec `json:"spec"`
+	// Status represents the status of the client certificate.
+	Status PostgresqlClientCertificateStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlClientCertificateList is a list of PostgresqlClientCertificate resources.
+type PostgresqlClientCertificateList struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	metav1.ListMeta `json:"metadata"`
+	// Items is the set of PostgresqlClientCertificate resources in the list.
+	Items []PostgresqlClientCertificate `json:"items"`
+}
+
+// PostgresqlClientCertificateSpec represents the specification of a client certificate used to establish SSL connections to a CSQLP instance.
+type PostgresqlClientCertificateSpec struct {
+	// Instance is the name of the PostgresqlInstance resource (i.e. its ".metadata.name") that represents the CSQLP instance for which to issue the client certificate.
+	Instance string `json:"instance"`
+	// SecretName is the name of the secret in which to store the client certificate, its private key and the server CA bundle.
+	// The secret is created in the same namespace as the PostgresqlClientCertificate resource.
+	// +optional
+	SecretName *string `json:"secretName"`
+}
+
+// PostgresqlClientCertificateStatus represents the status of a client certificate used to establish SSL connections to a CSQLP instance.
+type PostgresqlClientCertificateStatus struct {
+	// CommonName is the common name of the client certificate.
+	// +optional
+	CommonName string `json:"commonName,omitempty"`
+	// Conditions is the set of conditions associated with the current PostgresqlClientCertificate resource.
+	// +optional
+	Conditions []PostgresqlClientCertificateStatusCondition `json:"conditions,omitempty"`
+	// ExpirationTime is the time at which the client certificate expires.
+	// +optional
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+	// SHA1Fingerprint is the SHA-1 fingerprint of the client certificate, which is used to identify it in the Cloud SQL Admin API.
+	// +optional
+	SHA1Fingerprint string `json:"sha1Fingerprint,omitempty"`
+}
+
+// PostgresqlClientCertificateStatusCondition represents a condition associated with a PostgresqlClientCertificate resource.
+type PostgresqlClientCertificateStatusCondition struct {
+	// LastTransitionTime is the timestamp corresponding to the last status change of this condition.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Message is a human readable description of the details of the condition's last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Reason is a brief machine readable explanation for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Status is the status of the condition (one of "True", "False" or "Unknown").
+	Status corev1.ConditionStatus `json:"status"`
+	// Type is the type of the condition.
+	Type PostgresqlClientCertificateStatusConditionType `json:"type"`
+}
+
+// PostgresqlClientCertificateStatusConditionType represents the type of a condition associated with a PostgresqlClientCertificate resource.
+type PostgresqlClientCertificateStatusConditionType string
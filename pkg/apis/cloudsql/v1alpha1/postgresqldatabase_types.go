@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PostgresqlDatabaseStatusConditionTypeCreated indicates that the database represented by a given PostgresqlDatabase resource has been created.
+	PostgresqlDatabaseStatusConditionTypeCreated = PostgresqlDatabaseStatusConditionType("Created")
+	// PostgresqlDatabaseStatusConditionTypeReady indicates that the database represented by a given PostgresqlDatabase resource is in a ready state.
+	PostgresqlDatabaseStatusConditionTypeReady = PostgresqlDatabaseStatusConditionType("Ready")
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlDatabase represents a database inside a CSQLP instance.
+type PostgresqlDatabase struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard object metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec represents the specification of the database.
+	Spec PostgresqlDatabaseSpec `json:"spec"`
+	// Status represents the status of the database.
+	Status PostgresqlDatabaseStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlDatabaseList is a list of PostgresqlDatabase resources.
+type PostgresqlDatabaseList struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	metav1.ListMeta `json:"metadata"`
+	// Items is the set of PostgresqlDatabase resources in the list.
+	Items []PostgresqlDatabase `json:"items"`
+}
+
+// PostgresqlDatabaseSpec represents the specification of a database inside a CSQLP instance.
+type PostgresqlDatabaseSpec struct {
+	// Charset is the character set of the database.
+	// +optional
+	Charset *string `json:"charset"`
+	// Collation is the collation of the database.
+	// +optional
+	Collation *string `json:"collation"`
+	// Instance is the name of the PostgresqlInstance resource (i.e. its ".metadata.name") that represents the CSQLP instance in which to create the database.
+	Instance string `json:"instance"`
+	// Name is the name of the database.
+	Name string `json:"name"`
+}
+
+// PostgresqlDatabaseStatus represents the status of a database inside a CSQLP instance.
+type PostgresqlDatabaseStatus struct {
+	// Conditions is the set of conditions associated with the current PostgresqlDatabase resource.
+	// +optional
+	Conditions []PostgresqlDatabaseStatusCondition `json:"conditions,omitempty"`
+}
+
+// PostgresqlDatabaseStatusCondition represents a condition associated with a PostgresqlDatabase resource.
+type PostgresqlDatabaseStatusCondition struct {
+	// LastTransitionTime is the timestamp corresponding to the last status change of this condition.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Message is a human readable description of the details of the condition's last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Reason is a brief machine readable explanation for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Status is the status of the condition (one of "True", "False" or "Unknown").
+	Status corev1.ConditionStatus `json:"status"`
+	// Type is the type of the condition.
+	Type PostgresqlDatabaseStatusConditionType `json:"type"`
+}
+
+// PostgresqlDatabaseStatusConditionType represents the type of a condition associated with a PostgresqlDatabase resource.
+type PostgresqlDatabaseStatusConditionType string
@@ -0,0 +1,159 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PostgresqlExportStatusConditionTypeCompleted indicates whether the last export run represented by a given PostgresqlExport resource has completed successfully.
+	PostgresqlExportStatusConditionTypeCompleted = PostgresqlExportStatusConditionType("Completed")
+)
+
+const (
+	// PostgresqlExportSpecFileTypeCSV represents exports in CSV format.
+	PostgresqlExportSpecFileTypeCSV = PostgresqlExportSpecFileType("CSV")
+	// PostgresqlExportSpecFileTypeSQL represents exports in the format of a SQL dump.
+	PostgresqlExportSpecFileTypeSQL = PostgresqlExportSpecFileType("SQL")
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlExport represents one-off or scheduled exports of databases inside a CSQLP instance to Google Cloud Storage.
+type PostgresqlExport struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard object metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec represents the specification of the exports.
+	Spec PostgresqlExportSpec `json:"spec"`
+	// Status represents the status of the exports.
+	Status PostgresqlExportStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlExportList is a list of PostgresqlExport resources.
+type PostgresqlExportList struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	metav1.ListMeta `json:"metadata"`
+	// Items is the set of PostgresqlExport resources in the list.
+	Items []PostgresqlExport `json:"items"`
+}
+
+// PostgresqlExportSpec represents the specification of one-off or scheduled exports of databases inside a CSQLP instance.
+type PostgresqlExportSpec struct {
+	// CSV allows for customizing exports in CSV format.
+	// +optional
+	CSV *PostgresqlExportSpecCSV `json:"csv,omitempty"`
+	// Databases is the list of databases to export.
+	Databases []string `json:"databases"`
+	// Destination is the Google Cloud Storage URI (in the "gs://<bucket>/<path>" format) under which export files are written.
+	Destination string `json:"destination"`
+	// FileType is the format of the export files.
+	// +optional
+	FileType *PostgresqlExportSpecFileType `json:"fileType"`
+	// Instance is the name of the PostgresqlInstance resource (i.e. its ".metadata.name") that represents the CSQLP instance whose databases are exported.
+	Instance string `json:"instance"`
+	// Retention is the number of successful export runs whose files are kept when exports are scheduled.
+	// Zero means that the files of all export runs are kept.
+	// +optional
+	Retention *int32 `json:"retention"`
+	// Schedule is a cron expression (interpreted in UTC) specifying when export runs are performed.
+	// If empty, a single export run is performed.
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// PostgresqlExportSpecCSV allows for customizing exports in CSV format.
+type PostgresqlExportSpecCSV struct {
+	// SelectQuery is the query used to select the data to export from each database.
+	SelectQuery string `json:"selectQuery"`
+}
+
+// PostgresqlExportSpecFileType represents the format of export files.
+type PostgresqlExportSpecFileType string
+
+// APIValue returns the Cloud SQL Admin API value that represents the current file type.
+func (v *PostgresqlExportSpecFileType) APIValue() string {
+	return strings.ToUpper(string(*v))
+}
+
+// Extension returns the extension used for export files of the current file type.
+// Export files are always compressed.
+func (v *PostgresqlExportSpecFileType) Extension() string {
+	return strings.ToLower(string(*v)) + ".gz"
+}
+
+// PostgresqlExportStatus represents the status of one-off or scheduled exports of databases inside a CSQLP instance.
+type PostgresqlExportStatus struct {
+	// Conditions is the set of conditions associated with the current PostgresqlExport resource.
+	// +optional
+	Conditions []PostgresqlExportStatusCondition `json:"conditions,omitempty"`
+	// CurrentRun is the export run currently in progress, if any.
+	// +optional
+	CurrentRun *PostgresqlExportStatusRun `json:"currentRun,omitempty"`
+	// LastExportFile is the Google Cloud Storage URI of the last export file successfully written.
+	// +optional
+	LastExportFile string `json:"lastExportFile,omitempty"`
+	// NextRunTime is the time at which the next scheduled export run will be performed.
+	// +optional
+	NextRunTime *metav1.Time `json:"nextRunTime,omitempty"`
+	// Runs is the list of successful export runs whose files are being kept, sorted from oldest to newest.
+	// +optional
+	Runs []PostgresqlExportStatusRun `json:"runs,omitempty"`
+}
+
+// PostgresqlExportStatusRun represents a single export run.
+type PostgresqlExportStatusRun struct {
+	// Files is the list of Google Cloud Storage URIs of the export files written by the export run.
+	// +optional
+	Files []string `json:"files,omitempty"`
+	// OperationID is the ID of the Cloud SQL Admin API operation exporting the database currently being exported.
+	// +optional
+	OperationID string `json:"operationID,omitempty"`
+	// StartTime is the time at which the export run started.
+	StartTime metav1.Time `json:"startTime"`
+}
+
+// PostgresqlExportStatusCondition represents a condition associated with a PostgresqlExport resource.
+type PostgresqlExportStatusCondition struct {
+	// LastTransitionTime is the timestamp corresponding to the last status change of this condition.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Message is a human readable description of the details of the condition's last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Reason is a brief machine readable explanation for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Status is the status of the condition (one of "True", "False" or "Unknown").
+	Status corev1.ConditionStatus `json:"status"`
+	// Type is the type of the condition.
+	Type PostgresqlExportStatusConditionType `json:"type"`
+}
+
+// PostgresqlExportStatusConditionType represents the type of a condition associated with a PostgresqlExport resource.
+type PostgresqlExportStatusConditionType string
@@ -0,0 +1,135 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PostgresqlImportStatusConditionTypeCompleted indicates whether the import operation represented by a given PostgresqlImport resource has completed successfully.
+	PostgresqlImportStatusConditionTypeCompleted = PostgresqlImportStatusConditionType("Completed")
+	// PostgresqlImportStatusConditionTypeStarted indicates that the import operation represented by a given PostgresqlImport resource has been started.
+	PostgresqlImportStatusConditionTypeStarted = PostgresqlImportStatusConditionType("Started")
+)
+
+const (
+	// PostgresqlImportSpecFileTypeCSV represents imports of files in CSV format.
+	PostgresqlImportSpecFileTypeCSV = PostgresqlImportSpecFileType("CSV")
+	// PostgresqlImportSpecFileTypeSQL represents imports of SQL dumps.
+	PostgresqlImportSpecFileTypeSQL = PostgresqlImportSpecFileType("SQL")
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlImport represents the import of a file stored in Google Cloud Storage into a database inside a CSQLP instance.
+type PostgresqlImport struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard object metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec represents the specification of the import operation.
+	Spec PostgresqlImportSpec `json:"spec"`
+	// Status represents the status of the import operation.
+	Status PostgresqlImportStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlImportList is a list of PostgresqlImport resources.
+type PostgresqlImportList struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	metav1.ListMeta `json:"metadata"`
+	// Items is the set of PostgresqlImport resources in the list.
+	Items []PostgresqlImport `json:"items"`
+}
+
+// PostgresqlImportSpec represents the specification of the import of a file stored in Google Cloud Storage into a database inside a CSQLP instance.
+type PostgresqlImportSpec struct {
+	// CSV allows for customizing imports of files in CSV format.
+	// +optional
+	CSV *PostgresqlImportSpecCSV `json:"csv,omitempty"`
+	// Database is the name of the database into which to import the file.
+	Database string `json:"database"`
+	// FileType is the format of the file to import.
+	// +optional
+	FileType *PostgresqlImportSpecFileType `json:"fileType"`
+	// Instance is the name of the PostgresqlInstance resource (i.e. its ".metadata.name") that represents the CSQLP instance into which to import the file.
+	Instance string `json:"instance"`
+	// Source is the Google Cloud Storage URI (in the "gs://<bucket>/<object>" format) of the file to import.
+	// Compressed files (with the ".gz" extension) are supported.
+	Source string `json:"source"`
+	// User is the name of the PostgreSQL user that performs the import.
+	// If empty, the import is performed by the "postgres" user.
+	// +optional
+	User string `json:"user,omitempty"`
+}
+
+// PostgresqlImportSpecCSV allows for customizing imports of files in CSV format.
+type PostgresqlImportSpecCSV struct {
+	// Columns is the list of columns of the table into which to import the file.
+	// If empty, all columns are imported.
+	// +optional
+	Columns []string `json:"columns,omitempty"`
+	// Table is the name of the table into which to import the file.
+	Table string `json:"table"`
+}
+
+// PostgresqlImportSpecFileType represents the format of a file to import.
+type PostgresqlImportSpecFileType string
+
+// APIValue returns the Cloud SQL Admin API value that represents the current file type.
+func (v *PostgresqlImportSpecFileType) APIValue() string {
+	return strings.ToUpper(string(*v))
+}
+
+// PostgresqlImportStatus represents the status of the import of a file stored in Google Cloud Storage into a database inside a CSQLP instance.
+type PostgresqlImportStatus struct {
+	// Conditions is the set of conditions associated with the current PostgresqlImport resource.
+	// +optional
+	Conditions []PostgresqlImportStatusCondition `json:"conditions,omitempty"`
+	// OperationID is the ID of the Cloud SQL Admin API operation that performs the import.
+	// +optional
+	OperationID string `json:"operationID,omitempty"`
+}
+
+// PostgresqlImportStatusCondition represents a condition associated with a PostgresqlImport resource.
+type PostgresqlImportStatusCondition struct {
+	// LastTransitionTime is the timestamp corresponding to the last status change of this condition.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Message is a human readable description of the details of the condition's last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Reason is a brief machine readable explanation for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Status is the status of the condition (one of "True", "False" or "Unknown").
+	Status corev1.ConditionStatus `json:"status"`
+	// Type is the type of the condition.
+	Type PostgresqlImportStatusConditionType `json:"type"`
+}
+
+// PostgresqlImportStatusConditionType represents the type of a condition associated with a PostgresqlImport resource.
+type PostgresqlImportStatusConditionType string
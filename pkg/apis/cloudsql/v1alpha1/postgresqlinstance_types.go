@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"sort"
 	"strconv"
 	"strings"
 
@@ -37,6 +38,13 @@ const (
 	True = "true"
 )
 
+const (
+	// PostgresqlInstanceSpecActivationPolicyAlways represents the "ALWAYS" activation policy for CSQLP instances (i.e. the CSQLP instance is running).
+	PostgresqlInstanceSpecActivationPolicyAlways = PostgresqlInstanceSpecActivationPolicy("Always")
+	// PostgresqlInstanceSpecActivationPolicyNever represents the "NEVER" activation policy for CSQLP instances (i.e. the CSQLP instance is stopped).
+	PostgresqlInstanceSpecActivationPolicyNever = PostgresqlInstanceSpecActivationPolicy("Never")
+)
+
 const (
 	// PostgresqlInstanceSpecAvailabilityTypeRegional represents the "REGIONAL" availability type for CSQLP instances.
 	PostgresqlInstanceSpecAvailabilityTypeRegional = PostgresqlInstanceSpecAvailabilityType("Regional")
@@ -44,6 +52,15 @@ const (
 	PostgresqlInstanceSpecAvailabilityTypeZonal = PostgresqlInstanceSpecAvailabilityType("Zonal")
 )
 
+const (
+	// PostgresqlInstanceSpecDeletionPolicyBackupThenDelete represents the choice of exporting the databases of a CSQLP instance to Google Cloud Storage before deleting it.
+	PostgresqlInstanceSpecDeletionPolicyBackupThenDelete = PostgresqlInstanceSpecDeletionPolicy("BackupThenDelete")
+	// PostgresqlInstanceSpecDeletionPolicyDelete represents the choice of deleting a CSQLP instance right away.
+	PostgresqlInstanceSpecDeletionPolicyDelete = PostgresqlInstanceSpecDeletionPolicy("Delete")
+	// PostgresqlInstanceSpecDeletionPolicyRetain represents the choice of leaving a CSQLP instance untouched.
+	PostgresqlInstanceSpecDeletionPolicyRetain = PostgresqlInstanceSpecDeletionPolicy("Retain")
+)
+
 const (
 	// PostgresqlInstanceSpecLocationZoneAny represents an arbitrary choice of a zone for a CSQLP instance.
 	PostgresqlInstanceSpecLocationZoneAny = PostgresqlInstanceSpecLocationZone(Any)
@@ -68,11 +85,27 @@ const (
 	PostgresqlInstanceSpecMaintenanceDaySunday = PostgresqlInstanceSpecMaintenanceDay("Sunday")
 )
 
+const (
+	// PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyImmediate represents the choice of applying changes that require restarting a CSQLP instance as soon as possible.
+	PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyImmediate = PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicy("Immediate")
+	// PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow represents the choice of applying changes that require restarting a CSQLP instance during its maintenance window.
+	PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow = PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicy("MaintenanceWindow")
+	// PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyOnApproval represents the choice of applying changes that require restarting a CSQLP instance only after they have been explicitly approved.
+	PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyOnApproval = PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicy("OnApproval")
+)
+
 const (
 	// PostgresqlInstanceSpecMaintenanceHourAny represents an arbitrary choice of an hour of the day for periodic maintenance of a CSQLP instance.
 	PostgresqlInstanceSpecMaintenanceHourAny = Any
 )
 
+const (
+	// PostgresqlInstanceSpecMaintenanceUpdateTrackCanary represents the choice of receiving maintenance updates earlier than most CSQLP instances.
+	PostgresqlInstanceSpecMaintenanceUpdateTrackCanary = PostgresqlInstanceSpecMaintenanceUpdateTrack("Canary")
+	// PostgresqlInstanceSpecMaintenanceUpdateTrackStable represents the choice of receiving maintenance updates later than canary CSQLP instances.
+	PostgresqlInstanceSpecMaintenanceUpdateTrackStable = PostgresqlInstanceSpecMaintenanceUpdateTrack("Stable")
+)
+
 const (
 	// PostgresqlInstanceSpecResourceDiskTypeHDD represents the "HDD" disk type for CSQLP instances.
 	PostgresqlInstanceSpecResourceDiskTypeHDD = PostgresqlInstanceSpecResourcesDiskType("HDD")
@@ -83,13 +116,37 @@ const (
 const (
 	// PostgresqlInstanceSpecVersion96 represents the "POSTGRES_9_6" version of CSQLP instances.
 	PostgresqlInstanceSpecVersion96 = PostgresqlInstanceSpecVersion("9.6")
+	// PostgresqlInstanceSpecVersion10 represents the "POSTGRES_10" version of CSQLP instances.
+	PostgresqlInstanceSpecVersion10 = PostgresqlInstanceSpecVersion("10")
+	// PostgresqlInstanceSpecVersion11 represents the "POSTGRES_11" version of CSQLP instances.
+	PostgresqlInstanceSpecVersion11 = PostgresqlInstanceSpecVersion("11")
+	// PostgresqlInstanceSpecVersion12 represents the "POSTGRES_12" version of CSQLP instances.
+	PostgresqlInstanceSpecVersion12 = PostgresqlInstanceSpecVersion("12")
+	// PostgresqlInstanceSpecVersion13 represents the "POSTGRES_13" version of CSQLP instances.
+	PostgresqlInstanceSpecVersion13 = PostgresqlInstanceSpecVersion("13")
+	// PostgresqlInstanceSpecVersion14 represents the "POSTGRES_14" version of CSQLP instances.
+	PostgresqlInstanceSpecVersion14 = PostgresqlInstanceSpecVersion("14")
+	// PostgresqlInstanceSpecVersion15 represents the "POSTGRES_15" version of CSQLP instances.
+	PostgresqlInstanceSpecVersion15 = PostgresqlInstanceSpecVersion("15")
+	// PostgresqlInstanceSpecVersion16 represents the "POSTGRES_16" version of CSQLP instances.
+	PostgresqlInstanceSpecVersion16 = PostgresqlInstanceSpecVersion("16")
+	// PostgresqlInstanceSpecVersion17 represents the "POSTGRES_17" version of CSQLP instances.
+	PostgresqlInstanceSpecVersion17 = PostgresqlInstanceSpecVersion("17")
 )
 
 const (
+	// PostgresqlInstanceStatusConditionTypeAdopted indicates that the pre-existing CSQLP instance represented by a given PostgresqlInstance resource has been adopted.
+	PostgresqlInstanceStatusConditionTypeAdopted = PostgresqlInstanceStatusConditionType("Adopted")
 	// PostgresqlInstanceStatusConditionTypeCreated indicates that the CSQLP instance represented by a given PostgresqlInstance resource has been created.
 	PostgresqlInstanceStatusConditionTypeCreated = PostgresqlInstanceStatusConditionType("Created")
+	// PostgresqlInstanceStatusConditionTypePendingRestart indicates that changes to the CSQLP instance represented by a given PostgresqlInstance resource which require restarting it are pending.
+	PostgresqlInstanceStatusConditionTypePendingRestart = PostgresqlInstanceStatusConditionType("PendingRestart")
 	// PostgresqlInstanceStatusConditionTypeReady indicates that the CSQLP instance represented by a given PostgresqlInstance resource is in a ready state.
 	PostgresqlInstanceStatusConditionTypeReady = PostgresqlInstanceStatusConditionType("Ready")
+	// PostgresqlInstanceStatusConditionTypeServerCAExpiring indicates that the server CA certificate of the CSQLP instance represented by a given PostgresqlInstance resource is about to expire.
+	PostgresqlInstanceStatusConditionTypeServerCAExpiring = PostgresqlInstanceStatusConditionType("ServerCAExpiring")
+	// PostgresqlInstanceStatusConditionTypeUpgrading indicates that the CSQLP instance represented by a given PostgresqlInstance resource is being upgraded to a newer major version.
+	PostgresqlInstanceStatusConditionTypeUpgrading = PostgresqlInstanceStatusConditionType("Upgrading")
 	// PostgresqlInstanceStatusConditionTypeUpToDate indicates that the settings for the CSQLP instance represented by a given PostgresqlInstance resource are up-to-date.
 	PostgresqlInstanceStatusConditionTypeUpToDate = PostgresqlInstanceStatusConditionType("UpToDate")
 )
@@ -124,15 +181,38 @@ type PostgresqlInstanceList struct {
 
 // PostgresqlInstanceSpec represents the specification of a CSQLP instance.
 type PostgresqlInstanceSpec struct {
+	// ActivationPolicy indicates whether the CSQLP instance should be running or stopped.
+	// +optional
+	ActivationPolicy *PostgresqlInstanceSpecActivationPolicy `json:"activationPolicy"`
+	// Adopt indicates whether a pre-existing CSQLP instance having ".spec.name" as its name should be adopted instead of a new one being created.
+	// +optional
+	Adopt bool `json:"adopt,omitempty"`
 	// Availability allows for customizing the availability of the CSQLP instance.
 	// +optional
 	Availability *PostgresqlInstanceSpecAvailability `json:"availability"`
 	// Backups allows for customizing the backup strategy for the CSQLP instance.
 	// +optional
 	Backups *PostgresqlInstanceSpecBackups `json:"backups"`
-	// Flags is a list of flags passed to the CSQLP instance.
+	// DatabaseFlags is a map of flags passed to the CSQLP instance, keyed by flag name.
+	// +optional
+	DatabaseFlags PostgresqlInstanceSpecDatabaseFlags `json:"databaseFlags,omitempty"`
+	// DeletionPolicy indicates what happens to the CSQLP instance when the PostgresqlInstance resource is deleted.
+	// +optional
+	DeletionPolicy *PostgresqlInstanceSpecDeletionPolicy `json:"deletionPolicy,omitempty"`
+	// DeletionProtection indicates whether deletion protection is enabled for the CSQLP instance.
+	// Deletion protection is lifted whenever the "cloudsql.travelaudience.com/allow-deletion" annotation is set to "true".
+	// +optional
+	DeletionProtection *bool `json:"deletionProtection,omitempty"`
+	// FinalBackup allows for customizing the final backup taken before the CSQLP instance is deleted.
+	// +optional
+	FinalBackup *PostgresqlInstanceSpecFinalBackup `json:"finalBackup,omitempty"`
+	// Flags is a list of flags passed to the CSQLP instance, in the "<name>=<value>" format.
+	// Deprecated: use DatabaseFlags instead.
 	// +optional
 	Flags PostgresqlInstanceSpecFlags `json:"flags"`
+	// Insights allows for customizing Query Insights for the CSQLP instance.
+	// +optional
+	Insights *PostgresqlInstanceSpecInsights `json:"insights,omitempty"`
 	// Labels is a map of user-defined labels to be set on the CSQLP instance.
 	// +optional
 	Labels map[string]string `json:"labels"`
@@ -143,7 +223,12 @@ type PostgresqlInstanceSpec struct {
 	// +optional
 	Maintenance *PostgresqlInstanceSpecMaintenance `json:"maintenance"`
 	// Name is the name of the CSQLP instance.
+	// If not specified, a unique name is generated based on NamePrefix.
+	// +optional
 	Name string `json:"name"`
+	// NamePrefix is the prefix of the unique name generated for the CSQLP instance when Name is not specified.
+	// +optional
+	NamePrefix string `json:"namePrefix,omitempty"`
 	// Networking allows for customizing the networking aspects of the CSQLP instance.
 	// +optional
 	Networking *PostgresqlInstanceSpecNetworking `json:"networking"`
@@ -154,11 +239,25 @@ type PostgresqlInstanceSpec struct {
 	// Resources allows for customizing the resource requests for the CSQLP instance.
 	// +optional
 	Resources *PostgresqlInstanceSpecResources `json:"resources"`
+	// Schedule allows for automatically starting and stopping the CSQLP instance at specific times.
+	// +optional
+	Schedule *PostgresqlInstanceSpecSchedule `json:"schedule,omitempty"`
+	// Source allows for creating the CSQLP instance as a clone of an existing one.
+	// +optional
+	Source *PostgresqlInstanceSpecSource `json:"source,omitempty"`
 	// Version is the version of the CSQLP instance.
 	// +optional
 	Version *PostgresqlInstanceSpecVersion `json:"version"`
 }
 
+// PostgresqlInstanceSpecActivationPolicy represents activation policies for CSQLP instances.
+type PostgresqlInstanceSpecActivationPolicy string
+
+// APIValue returns the Cloud SQL Admin API value that represents the current activation policy.
+func (v *PostgresqlInstanceSpecActivationPolicy) APIValue() string {
+	return strings.ToUpper(string(*v))
+}
+
 // PostgresqlInstanceSpecAvailability allows for customizing the availability of a CSQLP instance.
 type PostgresqlInstanceSpecAvailability struct {
 	// Type is the availability type of the CSQLP instance.
@@ -178,6 +277,16 @@ type PostgresqlInstanceSpecBackups struct {
 	// Daily allows for customizing the daily backup strategy for the CSQLP instance.
 	// +optional
 	Daily *PostgresqlInstancSpecBackupsDaily `json:"daily"`
+	// Location is the region or multi-region (e.g. "europe-west1" or "eu") where backups of the CSQLP instance are stored.
+	// If not specified, backups are stored in the multi-region closest to the CSQLP instance.
+	// +optional
+	Location *string `json:"location,omitempty"`
+	// PointInTimeRecovery allows for customizing point-in-time recovery for the CSQLP instance.
+	// +optional
+	PointInTimeRecovery *PostgresqlInstanceSpecBackupsPointInTimeRecovery `json:"pointInTimeRecovery,omitempty"`
+	// RetainedBackups is the number of automated backups of the CSQLP instance to retain.
+	// +optional
+	RetainedBackups *int32 `json:"retainedBackups,omitempty"`
 }
 
 // PostgresqlInstancSpecBackupsDaily allows for customizing the daily backup strategy for a CSQLP instance.
@@ -190,7 +299,50 @@ type PostgresqlInstancSpecBackupsDaily struct {
 	StartTime *string `json:"startTime"`
 }
 
-// PostgresqlInstanceSpecFlags allows for customizing the database flags for a CSQLP instance.
+// PostgresqlInstanceSpecBackupsPointInTimeRecovery allows for customizing point-in-time recovery for a CSQLP instance.
+type PostgresqlInstanceSpecBackupsPointInTimeRecovery struct {
+	// Enabled specifies whether point-in-time recovery (i.e. archiving of write-ahead logs) is enabled for the CSQLP instance.
+	// +optional
+	Enabled *bool `json:"enabled"`
+	// TransactionLogRetentionDays is the number of days of write-ahead logs to retain for point-in-time recovery.
+	// +optional
+	TransactionLogRetentionDays *int32 `json:"transactionLogRetentionDays"`
+}
+
+// PostgresqlInstanceSpecDatabaseFlags allows for customizing the database flags for a CSQLP instance.
+type PostgresqlInstanceSpecDatabaseFlags map[string]string
+
+// APIValue returns the Cloud SQL Admin API value that represents the current set of database flags.
+// Flags are sorted by name so that the returned value is stable.
+func (v *PostgresqlInstanceSpecDatabaseFlags) APIValue() []*cloudsqladmin.DatabaseFlags {
+	if len(*v) == 0 {
+		return nil
+	}
+	n := make([]string, 0, len(*v))
+	for name := range *v {
+		n = append(n, name)
+	}
+	sort.Strings(n)
+	f := make([]*cloudsqladmin.DatabaseFlags, 0, len(n))
+	for _, name := range n {
+		f = append(f, &cloudsqladmin.DatabaseFlags{
+			Name:  name,
+			Value: (*v)[name],
+		})
+	}
+	return f
+}
+
+// PostgresqlInstanceSpecDeletionPolicy represents what happens to a CSQLP instance when the PostgresqlInstance resource that represents it is deleted.
+type PostgresqlInstanceSpecDeletionPolicy string
+
+// PostgresqlInstanceSpecFinalBackup allows for customizing the final backup taken before a CSQLP instance is deleted.
+type PostgresqlInstanceSpecFinalBackup struct {
+	// Destination is the Google Cloud Storage URI (in the "gs://<bucket>/<path>" format) under which the export files that make up the final backup are written.
+	Destination string `json:"destination"`
+}
+
+// PostgresqlInstanceSpecFlags allows for customizing the database flags for a CSQLP instance using the legacy "<name>=<value>" format.
 type PostgresqlInstanceSpecFlags []string
 
 // APIValue returns the Cloud SQL Admin API value that represents the current set of database flags.
@@ -200,7 +352,7 @@ func (v *PostgresqlInstanceSpecFlags) APIValue() []*cloudsqladmin.DatabaseFlags
 	}
 	f := make([]*cloudsqladmin.DatabaseFlags, 0, len(*v))
 	for _, flag := range *v {
-		parts := strings.Split(flag, "=")
+		parts := strings.SplitN(flag, "=", 2)
 		if len(parts) != 2 {
 			// If the current flag specifier is malformed, we skip it.
 			// This should never happen in practice, as the admission webhook rejects any PostgresqlInstance resources for which this does not hold.
@@ -214,6 +366,28 @@ func (v *PostgresqlInstanceSpecFlags) APIValue() []*cloudsqladmin.DatabaseFlags
 	return f
 }
 
+// DatabaseFlagsAPIValue returns the Cloud SQL Admin API value that represents the combination of the provided sets of database flags.
+// Flags specified in the legacy format come first, in the order in which they were specified, followed by the remaining flags sorted by name.
+func DatabaseFlagsAPIValue(flags PostgresqlInstanceSpecFlags, databaseFlags PostgresqlInstanceSpecDatabaseFlags) []*cloudsqladmin.DatabaseFlags {
+	return append(flags.APIValue(), databaseFlags.APIValue()...)
+}
+
+// PostgresqlInstanceSpecInsights allows for customizing Query Insights for a CSQLP instance.
+type PostgresqlInstanceSpecInsights struct {
+	// Enabled specifies whether Query Insights is enabled for the CSQLP instance.
+	// +optional
+	Enabled *bool `json:"enabled"`
+	// QueryStringLength is the maximum length (in bytes) of the query strings recorded by Query Insights.
+	// +optional
+	QueryStringLength *int32 `json:"queryStringLength"`
+	// RecordApplicationTags specifies whether Query Insights records application tags from queries.
+	// +optional
+	RecordApplicationTags *bool `json:"recordApplicationTags"`
+	// RecordClientAddress specifies whether Query Insights records the address of the clients issuing queries.
+	// +optional
+	RecordClientAddress *bool `json:"recordClientAddress"`
+}
+
 // PostgresqlInstanceSpecLocation allows for customizing the geographical location of a CSQLP instance.
 type PostgresqlInstanceSpecLocation struct {
 	// Region is the region where the CSQLP instance is located.
@@ -242,9 +416,18 @@ type PostgresqlInstanceSpecMaintenance struct {
 	// Day is the preferred day of the week for periodic maintenance of the CSQLP instance.
 	// +optional
 	Day *PostgresqlInstanceSpecMaintenanceDay `json:"day"`
+	// DenyPeriods is the list of periods during which periodic maintenance of the CSQLP instance is not performed.
+	// +optional
+	DenyPeriods PostgresqlInstanceSpecMaintenanceDenyPeriodList `json:"denyPeriods,omitempty"`
+	// DisruptiveUpdatePolicy indicates when changes that require restarting the CSQLP instance are applied.
+	// +optional
+	DisruptiveUpdatePolicy *PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicy `json:"disruptiveUpdatePolicy,omitempty"`
 	// Hour is the preferred hour of the day (in UTC) for periodic maintenance of the CSQLP instance, in 24-hour format.
 	// +optional
 	Hour *PostgresqlInstanceSpecMaintenanceHour `json:"hour"`
+	// UpdateTrack is the maintenance timing setting of the CSQLP instance.
+	// +optional
+	UpdateTrack *PostgresqlInstanceSpecMaintenanceUpdateTrack `json:"updateTrack,omitempty"`
 }
 
 // PostgresqlInstanceSpecMaintenanceDay represents a day of the week for periodic maintenance of a CSQLP instance.
@@ -272,6 +455,42 @@ func (v *PostgresqlInstanceSpecMaintenanceDay) APIValue() int64 {
 	}
 }
 
+// PostgresqlInstanceSpecMaintenanceDenyPeriod represents a period during which periodic maintenance of a CSQLP instance is not performed.
+type PostgresqlInstanceSpecMaintenanceDenyPeriod struct {
+	// EndDate is the date on which the deny period ends, in the "YYYY-MM-DD" format (or in the "MM-DD" format for deny periods that recur every year).
+	EndDate string `json:"endDate"`
+	// StartDate is the date on which the deny period starts, in the "YYYY-MM-DD" format (or in the "MM-DD" format for deny periods that recur every year).
+	StartDate string `json:"startDate"`
+	// Time is the time of the day (in UTC) at which the deny period starts on its start date and ends on its end date, in the "hh:mm:ss" format.
+	// +optional
+	Time *string `json:"time"`
+}
+
+// PostgresqlInstanceSpecMaintenanceDenyPeriodList represents a list of periods during which periodic maintenance of a CSQLP instance is not performed.
+type PostgresqlInstanceSpecMaintenanceDenyPeriodList []PostgresqlInstanceSpecMaintenanceDenyPeriod
+
+// APIValue returns the Cloud SQL Admin API value that represents the current list of deny periods.
+func (v *PostgresqlInstanceSpecMaintenanceDenyPeriodList) APIValue() []*cloudsqladmin.DenyMaintenancePeriod {
+	if len(*v) == 0 {
+		return nil
+	}
+	r := make([]*cloudsqladmin.DenyMaintenancePeriod, 0, len(*v))
+	for _, dp := range *v {
+		p := &cloudsqladmin.DenyMaintenancePeriod{
+			EndDate:   dp.EndDate,
+			StartDate: dp.StartDate,
+		}
+		if dp.Time != nil {
+			p.Time = *dp.Time
+		}
+		r = append(r, p)
+	}
+	return r
+}
+
+// PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicy represents a policy for applying changes that require restarting a CSQLP instance.
+type PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicy string
+
 // PostgresqlInstanceSpecMaintenanceHour represents a hour of the day for periodic maintenance of a CSQLP instance.
 type PostgresqlInstanceSpecMaintenanceHour string
 
@@ -284,6 +503,14 @@ func (v *PostgresqlInstanceSpecMaintenanceHour) APIValue() int64 {
 	return i
 }
 
+// PostgresqlInstanceSpecMaintenanceUpdateTrack represents a maintenance timing setting of a CSQLP instance.
+type PostgresqlInstanceSpecMaintenanceUpdateTrack string
+
+// APIValue returns the Cloud SQL Admin API value that represents the current maintenance timing setting.
+func (v *PostgresqlInstanceSpecMaintenanceUpdateTrack) APIValue() string {
+	return strings.ToLower(string(*v))
+}
+
 // PostgresqlInstanceSpecNetworking allows for customizing the networking aspects of a CSQLP instance.
 type PostgresqlInstanceSpecNetworking struct {
 	// PrivateIP allows for customizing access to the CSQLP instance via a private IP address.
@@ -292,6 +519,12 @@ type PostgresqlInstanceSpecNetworking struct {
 	// PublicIP allows for customizing access to the CSQLP instance via a public IP address.
 	// +optional
 	PublicIP *PostgresqlInstanceSpecNetworkingPublicIP `json:"publicIp"`
+	// RequireSsl specifies whether SSL connections over IP are enforced for the CSQLP instance.
+	// +optional
+	RequireSsl *bool `json:"requireSsl"`
+	// ServerCA allows for customizing the handling of the server CA certificate of the CSQLP instance.
+	// +optional
+	ServerCA *PostgresqlInstanceSpecNetworkingServerCA `json:"serverCa"`
 }
 
 // PostgresqlInstanceSpecNetworkingPrivateIP allows for customizing access to a CSQLP instance via a private IP.
@@ -342,6 +575,13 @@ func (v *PostgresqlInstanceSpecNetworkingPublicIPAuthorizedNetworkList) APIValue
 	return r
 }
 
+// PostgresqlInstanceSpecNetworkingServerCA allows for customizing the handling of the server CA certificate of a CSQLP instance.
+type PostgresqlInstanceSpecNetworkingServerCA struct {
+	// AutoRotate specifies whether the server CA certificate of the CSQLP instance is automatically rotated ahead of its expiry.
+	// +optional
+	AutoRotate *bool `json:"autoRotate"`
+}
+
 // PostgresqlInstanceSpecResources allows for customizing the resource requests for a CSQLP instance.
 type PostgresqlInstanceSpecResources struct {
 	// Disk allows for customizing the storage of the CSQLP instance.
@@ -378,12 +618,49 @@ func (v *PostgresqlInstanceSpecResourcesDiskType) APIValue() string {
 	}
 }
 
+// PostgresqlInstanceSpecSchedule allows for automatically starting and stopping a CSQLP instance at specific times.
+type PostgresqlInstanceSpecSchedule struct {
+	// Start is the cron expression that specifies when the CSQLP instance is to be started.
+	Start string `json:"start"`
+	// Stop is the cron expression that specifies when the CSQLP instance is to be stopped.
+	Stop string `json:"stop"`
+	// TimeZone is the name of the time zone (as found in the IANA Time Zone database) in which the cron expressions are interpreted.
+	// +optional
+	TimeZone *string `json:"timeZone"`
+}
+
+// PostgresqlInstanceSpecSource allows for creating a CSQLP instance as a clone of an existing one.
+type PostgresqlInstanceSpecSource struct {
+	// Instance is the name of the PostgresqlInstance resource (i.e. its ".metadata.name") that represents the CSQLP instance to clone.
+	Instance string `json:"instance"`
+	// PointInTime is the point in time at which to clone the CSQLP instance.
+	// If not specified, the CSQLP instance is cloned at its current state.
+	// +optional
+	PointInTime *metav1.Time `json:"pointInTime,omitempty"`
+}
+
 // PostgresqlInstanceSpecVersion represents a supported Cloud SQL for PostgreSQL version.
 type PostgresqlInstanceSpecVersion string
 
 // APIValue returns the Cloud SQL Admin API value that represents the current Cloud SQL for PostgreSQL version.
 func (v *PostgresqlInstanceSpecVersion) APIValue() string {
 	switch *v {
+	case PostgresqlInstanceSpecVersion10:
+		return "POSTGRES_10"
+	case PostgresqlInstanceSpecVersion11:
+		return "POSTGRES_11"
+	case PostgresqlInstanceSpecVersion12:
+		return "POSTGRES_12"
+	case PostgresqlInstanceSpecVersion13:
+		return "POSTGRES_13"
+	case PostgresqlInstanceSpecVersion14:
+		return "POSTGRES_14"
+	case PostgresqlInstanceSpecVersion15:
+		return "POSTGRES_15"
+	case PostgresqlInstanceSpecVersion16:
+		return "POSTGRES_16"
+	case PostgresqlInstanceSpecVersion17:
+		return "POSTGRES_17"
 	case PostgresqlInstanceSpecVersion96:
 		fallthrough
 	default:
@@ -391,16 +668,67 @@ func (v *PostgresqlInstanceSpecVersion) APIValue() string {
 	}
 }
 
+// PostgresqlInstanceSpecVersions is the list of supported Cloud SQL for PostgreSQL versions, sorted in ascending order.
+// The position of a given version in this list is used to determine whether a change of version represents an upgrade or a downgrade.
+var PostgresqlInstanceSpecVersions = []PostgresqlInstanceSpecVersion{
+	PostgresqlInstanceSpecVersion96,
+	PostgresqlInstanceSpecVersion10,
+	PostgresqlInstanceSpecVersion11,
+	PostgresqlInstanceSpecVersion12,
+	PostgresqlInstanceSpecVersion13,
+	PostgresqlInstanceSpecVersion14,
+	PostgresqlInstanceSpecVersion15,
+	PostgresqlInstanceSpecVersion16,
+	PostgresqlInstanceSpecVersion17,
+}
+
 // PostgresqlInstanceStatus represents the status of a CSQLP instance.
 type PostgresqlInstanceStatus struct {
+	// ActivationPolicy is the activation policy that the CSQLP instance is meant to have, as determined by ".spec.activationPolicy" and ".spec.schedule".
+	// +optional
+	ActivationPolicy PostgresqlInstanceSpecActivationPolicy `json:"activationPolicy,omitempty"`
+	// CloneOperationID is the ID of the Cloud SQL Admin API operation that creates the CSQLP instance as a clone of an existing one.
+	// +optional
+	CloneOperationID string `json:"cloneOperationID,omitempty"`
 	// Conditions is the set of conditions associated with the current PostgresqlInstance resource.
 	// +optional
 	Conditions []PostgresqlInstanceStatusCondition `json:"conditions,omitempty"`
 	// ConnectionName is the connection name to use when connecting to the CSQLP instance.
 	ConnectionName string `json:"connectionName,omitempty"`
+	// FailedUpgrade describes the last major version upgrade of the CSQLP instance that the Cloud SQL Admin API has refused to perform, if any.
+	// +optional
+	FailedUpgrade *PostgresqlInstanceStatusFailedUpgrade `json:"failedUpgrade,omitempty"`
+	// FinalBackup describes the final backup taken before the CSQLP instance is deleted, if any.
+	// +optional
+	FinalBackup *PostgresqlInstanceStatusFinalBackup `json:"finalBackup,omitempty"`
 	// IPs is the set of IPs associated with the current PostgresqlInstance resource.
 	// +optional
 	IPs PostgresqlInstanceStatusIPAddresses `json:"ips,omitempty"`
+	// InstanceName is the name of the CSQLP instance (i.e. the value of ".spec.name", which may have been generated based on ".spec.namePrefix").
+	// +optional
+	InstanceName string `json:"instanceName,omitempty"`
+	// LastDisruptiveUpdateApprovedAt is the value of the "cloudsql.travelaudience.com/disruptive-update-approved-at" annotation for which pending changes that require restarting the CSQLP instance have last been applied.
+	// +optional
+	LastDisruptiveUpdateApprovedAt string `json:"lastDisruptiveUpdateApprovedAt,omitempty"`
+	// LastFailoverRequestedAt is the value of the "cloudsql.travelaudience.com/failover-requested-at" annotation for which a failover has last been performed.
+	// +optional
+	LastFailoverRequestedAt string `json:"lastFailoverRequestedAt,omitempty"`
+	// LastRestartRequestedAt is the value of the "cloudsql.travelaudience.com/restart-requested-at" annotation for which a restart has last been performed.
+	// +optional
+	LastRestartRequestedAt string `json:"lastRestartRequestedAt,omitempty"`
+	// LastScheduledTransition is the last transition of the CSQLP instance's activation policy that happened according to ".spec.schedule".
+	// +optional
+	LastScheduledTransition *PostgresqlInstanceStatusLastScheduledTransition `json:"lastScheduledTransition,omitempty"`
+	// NextScheduledTransition is the next transition of the CSQLP instance's activation policy scheduled according to ".spec.schedule".
+	// +optional
+	NextScheduledTransition *PostgresqlInstanceStatusScheduledTransition `json:"nextScheduledTransition,omitempty"`
+	// PromotedReplica is the name of the PostgresqlReplica resource that represents the most recently promoted read replica of the CSQLP instance, if any.
+	// In this case, ".status.connectionName" is the connection name of said read replica.
+	// +optional
+	PromotedReplica string `json:"promotedReplica,omitempty"`
+	// ServerCA holds information about the server CA certificate of the CSQLP instance.
+	// +optional
+	ServerCA *PostgresqlInstanceStatusServerCA `json:"serverCa,omitempty"`
 }
 
 // PostgresqlInstanceStatusCondition represents a condition associated with a PostgresqlInstance resource.
@@ -423,6 +751,29 @@ type PostgresqlInstanceStatusCondition struct {
 // PostgresqlInstanceStatusConditionType represents the type of a condition associated with a PostgresqlInstance resource.
 type PostgresqlInstanceStatusConditionType string
 
+// PostgresqlInstanceStatusFailedUpgrade describes a major version upgrade of a CSQLP instance that the Cloud SQL Admin API has refused to perform.
+type PostgresqlInstanceStatusFailedUpgrade struct {
+	// ObservedGeneration is the generation of the PostgresqlInstance resource for which the upgrade has been refused.
+	ObservedGeneration int64 `json:"observedGeneration"`
+	// Version is the version of PostgreSQL to which the CSQLP instance could not be upgraded.
+	Version PostgresqlInstanceSpecVersion `json:"version"`
+}
+
+// PostgresqlInstanceStatusFinalBackup describes the final backup taken before a CSQLP instance is deleted.
+type PostgresqlInstanceStatusFinalBackup struct {
+	// Databases is the list of databases being exported as part of the final backup.
+	// +optional
+	Databases []string `json:"databases,omitempty"`
+	// Files is the list of export files that have been written so far.
+	// +optional
+	Files []string `json:"files,omitempty"`
+	// OperationID is the ID of the Cloud SQL Admin API operation that is currently exporting a database, if any.
+	// +optional
+	OperationID string `json:"operationID,omitempty"`
+	// StartTime is the time at which the final backup has been started.
+	StartTime metav1.Time `json:"startTime"`
+}
+
 // PostgresqlInstanceStatusIPAddresses holds the reported IP addresses of a CSQLP instance.
 type PostgresqlInstanceStatusIPAddresses struct {
 	// PrivateIP is the private IP associated with the CSQLP instance (if any).
@@ -430,3 +781,33 @@ type PostgresqlInstanceStatusIPAddresses struct {
 	// PublicIP is the public IP associated with the CSQLP instance (if any).
 	PublicIP string `json:"publicIp,omitempty"`
 }
+
+// PostgresqlInstanceStatusLastScheduledTransition represents a transition of the activation policy of a CSQLP instance that happened according to its schedule.
+type PostgresqlInstanceStatusLastScheduledTransition struct {
+	// ActivationPolicy is the activation policy that the CSQLP instance has transitioned to.
+	ActivationPolicy PostgresqlInstanceSpecActivationPolicy `json:"activationPolicy"`
+	// SpecActivationPolicy is the value of ".spec.activationPolicy" at the time of the transition.
+	// Changing ".spec.activationPolicy" afterwards overrides the transition until the next one happens.
+	SpecActivationPolicy PostgresqlInstanceSpecActivationPolicy `json:"specActivationPolicy"`
+	// Time is the time at which the transition happened.
+	Time metav1.Time `json:"time"`
+}
+
+// PostgresqlInstanceStatusScheduledTransition represents a scheduled transition of the activation policy of a CSQLP instance.
+type PostgresqlInstanceStatusScheduledTransition struct {
+	// ActivationPolicy is the activation policy that the CSQLP instance will transition to.
+	ActivationPolicy PostgresqlInstanceSpecActivationPolicy `json:"activationPolicy"`
+	// Time is the time at which the transition will happen.
+	Time metav1.Time `json:"time"`
+}
+
+// PostgresqlInstanceStatusServerCA holds information about the server CA certificate of a CSQLP instance.
+type PostgresqlInstanceStatusServerCA struct {
+	// BundleUpdateTime is the time at which the server CA bundles distributed to namespace-local secrets have last been updated ahead of a rotation of the server CA certificate.
+	// +optional
+	BundleUpdateTime *metav1.Time `json:"bundleUpdateTime,omitempty"`
+	// ExpirationTime is the time at which the active server CA certificate expires.
+	ExpirationTime metav1.Time `json:"expirationTime"`
+	// Sha1Fingerprint is the SHA-1 fingerprint of the active server CA certificate.
+	Sha1Fingerprint string `json:"sha1Fingerprint"`
+}
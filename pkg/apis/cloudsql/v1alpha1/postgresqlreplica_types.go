@@ -0,0 +1,131 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PostgresqlReplicaStatusConditionTypeCreated indicates that the read replica represented by a given PostgresqlReplica resource has been created.
+	PostgresqlReplicaStatusConditionTypeCreated = PostgresqlReplicaStatusConditionType("Created")
+	// PostgresqlReplicaStatusConditionTypePromoted indicates whether the read replica represented by a given PostgresqlReplica resource has been promoted to a standalone CSQLP instance.
+	PostgresqlReplicaStatusConditionTypePromoted = PostgresqlReplicaStatusConditionType("Promoted")
+	// PostgresqlReplicaStatusConditionTypeReady indicates that the read replica represented by a given PostgresqlReplica resource is in a ready state.
+	PostgresqlReplicaStatusConditionTypeReady = PostgresqlReplicaStatusConditionType("Ready")
+	// PostgresqlReplicaStatusConditionTypeUpToDate indicates that the settings for the read replica represented by a given PostgresqlReplica resource are up-to-date.
+	PostgresqlReplicaStatusConditionTypeUpToDate = PostgresqlReplicaStatusConditionType("UpToDate")
+)
+
+const (
+	// PostgresqlReplicaStatusReplicationStateRunning indicates that replication from the primary CSQLP instance to the read replica is running.
+	PostgresqlReplicaStatusReplicationStateRunning = PostgresqlReplicaStatusReplicationState("Running")
+	// PostgresqlReplicaStatusReplicationStateStopped indicates that replication from the primary CSQLP instance to the read replica is stopped.
+	PostgresqlReplicaStatusReplicationStateStopped = PostgresqlReplicaStatusReplicationState("Stopped")
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlReplica represents a read replica of a CSQLP instance.
+type PostgresqlReplica struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard object metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec represents the specification of the read replica.
+	Spec PostgresqlReplicaSpec `json:"spec"`
+	// Status represents the status of the read replica.
+	Status PostgresqlReplicaStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlReplicaList is a list of PostgresqlReplica resources.
+type PostgresqlReplicaList struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	metav1.ListMeta `json:"metadata"`
+	// Items is the set of PostgresqlReplica resources in the list.
+	Items []PostgresqlReplica `json:"items"`
+}
+
+// PostgresqlReplicaSpec represents the specification of a read replica of a CSQLP instance.
+type PostgresqlReplicaSpec struct {
+	// DatabaseFlags is a map of flags passed to the read replica, keyed by flag name.
+	// +optional
+	DatabaseFlags PostgresqlInstanceSpecDatabaseFlags `json:"databaseFlags,omitempty"`
+	// Flags is a list of flags passed to the read replica, in the "<name>=<value>" format.
+	// Deprecated: use DatabaseFlags instead.
+	// +optional
+	Flags PostgresqlInstanceSpecFlags `json:"flags"`
+	// InstanceType is the instance type to use for the read replica.
+	// +optional
+	InstanceType *string `json:"instanceType"`
+	// Location allows for customizing the geographical location of the read replica.
+	// +optional
+	Location *PostgresqlInstanceSpecLocation `json:"location"`
+	// Name is the name of the read replica.
+	Name string `json:"name"`
+	// Paused indicates whether reconciliation of the read replica is paused.
+	// Meant only to facilitate end-to-end testing.
+	Paused bool `json:"paused,omitempty"`
+	// Primary is the name of the PostgresqlInstance resource (i.e. its ".metadata.name") that represents the CSQLP instance to replicate.
+	Primary string `json:"primary"`
+}
+
+// PostgresqlReplicaStatus represents the status of a read replica of a CSQLP instance.
+type PostgresqlReplicaStatus struct {
+	// Conditions is the set of conditions associated with the current PostgresqlReplica resource.
+	// +optional
+	Conditions []PostgresqlReplicaStatusCondition `json:"conditions,omitempty"`
+	// ConnectionName is the connection name of the read replica.
+	// +optional
+	ConnectionName string `json:"connectionName,omitempty"`
+	// IPs is the set of IPs associated with the read replica.
+	// +optional
+	IPs PostgresqlInstanceStatusIPAddresses `json:"ips,omitempty"`
+	// ReplicationState is the state of replication from the primary CSQLP instance to the read replica.
+	// +optional
+	ReplicationState PostgresqlReplicaStatusReplicationState `json:"replicationState,omitempty"`
+}
+
+// PostgresqlReplicaStatusCondition represents a condition associated with a PostgresqlReplica resource.
+type PostgresqlReplicaStatusCondition struct {
+	// LastTransitionTime is the timestamp corresponding to the last status change of this condition.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Message is a human readable description of the details of the condition's last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Reason is a brief machine readable explanation for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Status is the status of the condition (one of "True", "False" or "Unknown").
+	Status corev1.ConditionStatus `json:"status"`
+	// Type is the type of the condition.
+	Type PostgresqlReplicaStatusConditionType `json:"type"`
+}
+
+// PostgresqlReplicaStatusConditionType represents the type of a condition associated with a PostgresqlReplica resource.
+type PostgresqlReplicaStatusConditionType string
+
+// PostgresqlReplicaStatusReplicationState represents the state of replication from the primary CSQLP instance to a read replica.
+type PostgresqlReplicaStatusReplicationState string
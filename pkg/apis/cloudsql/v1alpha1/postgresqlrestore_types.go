@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PostgresqlRestoreStatusConditionTypeCompleted indicates whether the restore operation represented by a given PostgresqlRestore resource has completed successfully.
+	PostgresqlRestoreStatusConditionTypeCompleted = PostgresqlRestoreStatusConditionType("Completed")
+	// PostgresqlRestoreStatusConditionTypeStarted indicates that the restore operation represented by a given PostgresqlRestore resource has been started.
+	PostgresqlRestoreStatusConditionTypeStarted = PostgresqlRestoreStatusConditionType("Started")
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlRestore represents the restore of a backup run into a CSQLP instance.
+type PostgresqlRestore struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard object metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec represents the specification of the restore operation.
+	Spec PostgresqlRestoreSpec `json:"spec"`
+	// Status represents the status of the restore operation.
+	Status PostgresqlRestoreStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlRestoreList is a list of PostgresqlRestore resources.
+type PostgresqlRestoreList struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	metav1.ListMeta `json:"metadata"`
+	// Items is the set of PostgresqlRestore resources in the list.
+	Items []PostgresqlRestore `json:"items"`
+}
+
+// PostgresqlRestoreSpec represents the specification of the restore of a backup run into a CSQLP instance.
+type PostgresqlRestoreSpec struct {
+	// Backup is the name of the PostgresqlBackup resource (i.e. its ".metadata.name") that represents the backup run to restore.
+	// Exactly one of Backup and BackupRunID must be specified.
+	// +optional
+	Backup string `json:"backup,omitempty"`
+	// BackupRunID is the ID of the backup run to restore.
+	// Exactly one of Backup and BackupRunID must be specified.
+	// +optional
+	BackupRunID int64 `json:"backupRunID,omitempty"`
+	// Source is the name of the PostgresqlInstance resource (i.e. its ".metadata.name") that represents the CSQLP instance to which the backup run belongs.
+	// +optional
+	Source string `json:"source"`
+	// Target is the name of the PostgresqlInstance resource (i.e. its ".metadata.name") that represents the CSQLP instance into which to restore the backup run.
+	Target string `json:"target"`
+}
+
+// PostgresqlRestoreStatus represents the status of the restore of a backup run into a CSQLP instance.
+type PostgresqlRestoreStatus struct {
+	// Conditions is the set of conditions associated with the current PostgresqlRestore resource.
+	// +optional
+	Conditions []PostgresqlRestoreStatusCondition `json:"conditions,omitempty"`
+	// OperationID is the ID of the Cloud SQL Admin API operation that performs the restore.
+	// +optional
+	OperationID string `json:"operationID,omitempty"`
+}
+
+// PostgresqlRestoreStatusCondition represents a condition associated with a PostgresqlRestore resource.
+type PostgresqlRestoreStatusCondition struct {
+	// LastTransitionTime is the timestamp corresponding to the last status change of this condition.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Message is a human readable description of the details of the condition's last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Reason is a brief machine readable explanation for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Status is the status of the condition (one of "True", "False" or "Unknown").
+	Status corev1.ConditionStatus `json:"status"`
+	// Type is the type of the condition.
+	Type PostgresqlRestoreStatusConditionType `json:"type"`
+}
+
+// PostgresqlRestoreStatusConditionType represents the type of a condition associated with a PostgresqlRestore resource.
+type PostgresqlRestoreStatusConditionType string
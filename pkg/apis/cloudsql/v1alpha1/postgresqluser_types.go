@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PostgresqlUserStatusConditionTypeCreated indicates that the user represented by a given PostgresqlUser resource has been created.
+	PostgresqlUserStatusConditionTypeCreated = PostgresqlUserStatusConditionType("Created")
+	// PostgresqlUserStatusConditionTypeReady indicates that the user represented by a given PostgresqlUser resource is in a ready state.
+	PostgresqlUserStatusConditionTypeReady = PostgresqlUserStatusConditionType("Ready")
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlUser represents a PostgreSQL user of a CSQLP instance.
+type PostgresqlUser struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard object metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec represents the specification of the user.
+	Spec PostgresqlUserSpec `json:"spec"`
+	// Status represents the status of the user.
+	Status PostgresqlUserStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresqlUserList is a list of PostgresqlUser resources.
+type PostgresqlUserList struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	metav1.ListMeta `json:"metadata"`
+	// Items is the set of PostgresqlUser resources in the list.
+	Items []PostgresqlUser `json:"items"`
+}
+
+// PostgresqlUserSpec represents the specification of a PostgreSQL user of a CSQLP instance.
+type PostgresqlUserSpec struct {
+	// Instance is the name of the PostgresqlInstance resource (i.e. its ".metadata.name") that represents the CSQLP instance in which to create the user.
+	Instance string `json:"instance"`
+	// Name is the name of the user.
+	Name string `json:"name"`
+	// SecretName is the name of the secret in which to store the user's credentials.
+	// The secret is created in the same namespace as the PostgresqlUser resource.
+	// +optional
+	SecretName *string `json:"secretName"`
+}
+
+// PostgresqlUserStatus represents the status of a PostgreSQL user of a CSQLP instance.
+type PostgresqlUserStatus struct {
+	// Conditions is the set of conditions associated with the current PostgresqlUser resource.
+	// +optional
+	Conditions []PostgresqlUserStatusCondition `json:"conditions,omitempty"`
+}
+
+// PostgresqlUserStatusCondition represents a condition associated with a PostgresqlUser resource.
+type PostgresqlUserStatusCondition struct {
+	// LastTransitionTime is the timestamp corresponding to the last status change of this condition.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Message is a human readable description of the details of the condition's last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Reason is a brief machine readable explanation for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Status is the status of the condition (one of "True", "False" or "Unknown").
+	Status corev1.ConditionStatus `json:"status"`
+	// Type is the type of the condition.
+	Type PostgresqlUserStatusConditionType `json:"type"`
+}
+
+// PostgresqlUserStatusConditionType represents the type of a condition associated with a PostgresqlUser resource.
+type PostgresqlUserStatusConditionType string
@@ -42,7 +42,15 @@ func Resource(resource string) schema.GroupResource {
 }
 
 func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(SchemeGroupVersion, &PostgresqlBackup{}, &PostgresqlBackupList{})
+	scheme.AddKnownTypes(SchemeGroupVersion, &PostgresqlClientCertificate{}, &PostgresqlClientCertificateList{})
+	scheme.AddKnownTypes(SchemeGroupVersion, &PostgresqlDatabase{}, &PostgresqlDatabaseList{})
+	scheme.AddKnownTypes(SchemeGroupVersion, &PostgresqlExport{}, &PostgresqlExportList{})
+	scheme.AddKnownTypes(SchemeGroupVersion, &PostgresqlImport{}, &PostgresqlImportList{})
 	scheme.AddKnownTypes(SchemeGroupVersion, &PostgresqlInstance{}, &PostgresqlInstanceList{})
+	scheme.AddKnownTypes(SchemeGroupVersion, &PostgresqlReplica{}, &PostgresqlReplicaList{})
+	scheme.AddKnownTypes(SchemeGroupVersion, &PostgresqlRestore{}, &PostgresqlRestoreList{})
+	scheme.AddKnownTypes(SchemeGroupVersion, &PostgresqlUser{}, &PostgresqlUserList{})
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
 }
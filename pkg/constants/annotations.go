@@ -24,8 +24,30 @@ const (
 const (
 	// AllowDeletionAnnotationKey is the key of the annotation that specifies whether deletion of a given resource is allowed.
 	AllowDeletionAnnotationKey = annotationKeyPrefix + "allow-deletion"
+	// AllowMajorVersionUpgradeAnnotationKey is the key of the annotation that specifies whether a major version upgrade of a given CSQLP instance is allowed.
+	AllowMajorVersionUpgradeAnnotationKey = annotationKeyPrefix + "allow-major-version-upgrade"
+	// AllowPasswordResetAnnotationKey is the key of the annotation that specifies whether the password of the "postgres" user of a given adopted CSQLP instance may be reset.
+	AllowPasswordResetAnnotationKey = annotationKeyPrefix + "allow-password-reset"
+	// ConfirmAdoptionAnnotationKey is the key of the annotation that confirms that the adoption of a given pre-existing CSQLP instance is intended.
+	ConfirmAdoptionAnnotationKey = annotationKeyPrefix + "confirm-adoption"
+	// ConfirmRestoreAnnotationKey is the key of the annotation that confirms that a given restore operation, which overwrites the data in the target CSQLP instance, is intended.
+	ConfirmRestoreAnnotationKey = annotationKeyPrefix + "confirm-restore"
+	// DisruptiveUpdateApprovedAtAnnotationKey is the key of the annotation that approves the pending changes to a given CSQLP instance which require restarting it, and whose value is the time at which the approval has been given.
+	DisruptiveUpdateApprovedAtAnnotationKey = annotationKeyPrefix + "disruptive-update-approved-at"
+	// FailoverRequestedAtAnnotationKey is the key of the annotation that requests a failover of a given (regional) CSQLP instance, and whose value is the time at which the failover has been requested.
+	FailoverRequestedAtAnnotationKey = annotationKeyPrefix + "failover-requested-at"
+	// PostgresqlClientCertificateNameAnnotationKey is the key of the annotation that specifies which PostgresqlClientCertificate a given pod wants to use in order to connect directly to a CSQLP instance.
+	PostgresqlClientCertificateNameAnnotationKey = annotationKeyPrefix + "postgresqlclientcertificate-name"
 	// PostgresqlInstanceNameAnnotationKey is the key of the annotation that specifies which PostgresqlInstance a given pod wants to connect to.
 	PostgresqlInstanceNameAnnotationKey = annotationKeyPrefix + "postgresqlinstance-name"
+	// PostgresqlReplicaNameAnnotationKey is the key of the annotation that specifies which read replica of the requested PostgresqlInstance a given pod wants to connect to.
+	PostgresqlReplicaNameAnnotationKey = annotationKeyPrefix + "postgresqlreplica-name"
+	// PromoteReplicaAnnotationKey is the key of the annotation that specifies whether a given read replica should be promoted to a standalone CSQLP instance.
+	PromoteReplicaAnnotationKey = annotationKeyPrefix + "promote"
 	// ProxyInjectedAnnotationKey is the key of the annotation set on Pod resources which have been injected with the Cloud SQL proxy sidecar.
 	ProxyInjectedAnnotationKey = annotationKeyPrefix + "proxy-injected"
+	// RestartRequestedAtAnnotationKey is the key of the annotation that requests a restart of a given CSQLP instance, and whose value is the time at which the restart has been requested.
+	RestartRequestedAtAnnotationKey = annotationKeyPrefix + "restart-requested-at"
+	// SkipFinalBackupAnnotationKey is the key of the annotation that specifies whether a given CSQLP instance whose ".spec.deletionPolicy" is "BackupThenDelete" may be deleted without taking the final backup.
+	SkipFinalBackupAnnotationKey = annotationKeyPrefix + "skip-final-backup"
 )
@@ -17,14 +17,40 @@ limitations under the License.
 package constants
 
 const (
+	// BackupRetentionSettingsRetentionUnitCount is the retention unit used to retain a given number of automated backups of a CSQLP instance.
+	BackupRetentionSettingsRetentionUnitCount = "COUNT"
+	// BackupRunStatusEnqueued is the status of a backup run that has not started yet.
+	BackupRunStatusEnqueued = "ENQUEUED"
+	// BackupRunStatusFailed is the status of a backup run that has failed.
+	BackupRunStatusFailed = "FAILED"
+	// BackupRunStatusRunning is the status of a backup run that is in progress.
+	BackupRunStatusRunning = "RUNNING"
+	// BackupRunStatusSuccessful is the status of a backup run that has completed successfully.
+	BackupRunStatusSuccessful = "SUCCESSFUL"
 	// DatabaseInstanceActivationPolicyAlways is the activation policy of a running, healthy CSQLP instance.
 	DatabaseInstanceActivationPolicyAlways = "ALWAYS"
+	// DatabaseInstanceActivationPolicyNever is the activation policy of a stopped CSQLP instance.
+	DatabaseInstanceActivationPolicyNever = "NEVER"
+	// DatabaseInstanceDatabaseVersionPostgresPrefix is the prefix of the database version of every CSQLP instance.
+	DatabaseInstanceDatabaseVersionPostgresPrefix = "POSTGRES_"
 	// DatabaseInstanceIPAddressTypePublic is the type associated with a CSQLP instance's public IP.
 	DatabaseInstanceIPAddressTypePublic = "PRIMARY"
 	// DatabaseInstanceIPAddressTypePrivate is the type associated with a CSQLP instance's private IP.
 	DatabaseInstanceIPAddressTypePrivate = "PRIVATE"
 	// DatabaseInstanceStateRunnable is the state of a running, healthy CSQLP instance.
 	DatabaseInstanceStateRunnable = "RUNNABLE"
+	// DatabaseInstanceTypeCloudSQLInstance is the type of a primary CSQLP instance (i.e. one which is not a read replica).
+	DatabaseInstanceTypeCloudSQLInstance = "CLOUD_SQL_INSTANCE"
 	// OperationStatusDone is the status of an operation that has terminated.
 	OperationStatusDone = "DONE"
+	// OperationTypeFailover is the type of an operation that fails over a CSQLP instance to its standby.
+	OperationTypeFailover = "FAILOVER"
+	// OperationTypeMajorVersionUpgrade is the type of an operation that upgrades a CSQLP instance to a newer major version.
+	OperationTypeMajorVersionUpgrade = "MAJOR_VERSION_UPGRADE"
+	// OperationTypePromoteReplica is the type of an operation that promotes a read replica to a standalone CSQLP instance.
+	OperationTypePromoteReplica = "PROMOTE_REPLICA"
+	// OperationTypeRestart is the type of an operation that restarts a CSQLP instance.
+	OperationTypeRestart = "RESTART"
+	// OperationTypeRestoreVolume is the type of an operation that restores a CSQLP instance from a backup run.
+	OperationTypeRestoreVolume = "RESTORE_VOLUME"
 )
@@ -17,10 +17,20 @@ limitations under the License.
 package constants
 
 const (
+	// ClientCertificateKey is the secret key that holds the client certificate used to establish SSL connections to a given CSQLP instance.
+	ClientCertificateKey = "client-cert.pem"
+	// ClientPrivateKeyKey is the secret key that holds the private key of the client certificate used to establish SSL connections to a given CSQLP instance.
+	ClientPrivateKeyKey = "client-key.pem"
 	// PostgresqlInstancePasswordKey is the secret key that holds a given CSQLP instance's password.
 	PostgresqlInstancePasswordKey = "PGPASS"
 	// PostgresqlInstanceUsernameKey is the secret key that holds a given CSQLP instance's username.
 	PostgresqlInstanceUsernameKey = "PGUSER"
 	// PostgresqlInstanceUsernameValue is the fixed value of the secret key that holds a given CSQLP instance's username.
 	PostgresqlInstanceUsernameValue = "postgres"
+	// PostgresqlUserPasswordKey is the secret key that holds a given PostgreSQL user's password.
+	PostgresqlUserPasswordKey = "PGPASS"
+	// PostgresqlUserUsernameKey is the secret key that holds a given PostgreSQL user's username.
+	PostgresqlUserUsernameKey = "PGUSER"
+	// ServerCACertificateKey is the secret key that holds the bundle of server CA certificates used to verify the server certificate of a given CSQLP instance.
+	ServerCACertificateKey = "server-ca.pem"
 )
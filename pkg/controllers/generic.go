@@ -162,3 +162,16 @@ func (c *genericController) enqueue(obj interface{}) {
 	}
 	c.workqueue.Add(key)
 }
+
+// enqueueAfter takes a resource, computes its resource key and puts it as a work item onto the work queue once the specified duration has elapsed.
+func (c *genericController) enqueueAfter(obj interface{}, duration time.Duration) {
+	var (
+		err error
+		key string
+	)
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	c.workqueue.AddAfter(key, duration)
+}
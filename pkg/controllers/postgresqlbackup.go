@@ -0,0 +1,340 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/util/slice"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	v1alpha1client "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/clientset/versioned"
+	v1alpha1informers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/informers/externalversions/cloudsql/v1alpha1"
+	v1alpha1listers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/listers/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/configuration"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/google"
+)
+
+const (
+	// postgresqlBackupControllerName is the name of the controller for PostgresqlBackup resources.
+	postgresqlBackupControllerName = "postgresqlbackup-controller"
+	// postgresqlBackupControllerThreadiness is the number of workers controller for PostgresqlBackup resource will use to process items from its work queue.
+	postgresqlBackupControllerThreadiness = 1
+)
+
+// PostgresqlBackupController is the controller for PostgresqlBackup resources.
+type PostgresqlBackupController struct {
+	// PostgresqlBackupController is based-off of a generic controller.
+	*genericController
+	// cloudsqlClient is a client for the Cloud SQL Admin API.
+	cloudsqlClient *cloudsqladmin.Service
+	// er is an EventRecorder through which we can emit events associated with PostgresqlBackup resources.
+	er record.EventRecorder
+	// postgresqlBackupLister is a lister for PostgresqlBackup resources.
+	postgresqlBackupLister v1alpha1listers.PostgresqlBackupLister
+	// postgresqlInstanceLister is a lister for PostgresqlInstance resources.
+	postgresqlInstanceLister v1alpha1listers.PostgresqlInstanceLister
+	// projectID is the ID of the GCP project where cloudsql-postgres-operator is managing CSQLP instances.
+	projectID string
+	// selfClient is a client to the "cloudsql.travelaudience.com" API.
+	selfClient v1alpha1client.Interface
+}
+
+// NewPostgresqlBackupController creates a new instance of the controller for PostgresqlBackup resources.
+func NewPostgresqlBackupController(config configuration.Configuration, selfClient v1alpha1client.Interface, er record.EventRecorder, postgresqlBackupInformer v1alpha1informers.PostgresqlBackupInformer, postgresqlInstanceInformer v1alpha1informers.PostgresqlInstanceInformer, cloudsqlClient *cloudsqladmin.Service) *PostgresqlBackupController {
+	// Create a new instance of the controller for PostgresqlBackup resources using the specified name and threadiness.
+	c := &PostgresqlBackupController{
+		cloudsqlClient:           cloudsqlClient,
+		genericController:        newGenericController(postgresqlBackupControllerName, postgresqlBackupControllerThreadiness),
+		er:                       er,
+		postgresqlBackupLister:   postgresqlBackupInformer.Lister(),
+		postgresqlInstanceLister: postgresqlInstanceInformer.Lister(),
+		projectID:                config.GCP.ProjectID,
+		selfClient:               selfClient,
+	}
+	// Make the controller wait for the caches to sync.
+	c.hasSyncedFuncs = []cache.InformerSynced{
+		postgresqlBackupInformer.Informer().HasSynced,
+		postgresqlInstanceInformer.Informer().HasSynced,
+	}
+	// Make "processQueueItem" the handler for items popped out of the work queue.
+	c.syncHandler = c.processQueueItem
+
+	// Setup an event handler to inform us when PostgresqlBackup resources change.
+	postgresqlBackupInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			c.enqueue(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+	})
+
+	// Return the instance of the controller for PostgresqlBackup resources created above.
+	return c
+}
+
+// processQueueItem attempts to reconcile the state of the PostgresqlBackup resource pointed at by the specified key.
+func (c *PostgresqlBackupController) processQueueItem(key string) (err error) {
+	// Grab the name of the PostgresqlBackup resource from the specified key.
+	// NOTE: PostgresqlBackup is cluster-scoped, and hence there is no associated namespace.
+	_, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("invalid resource key %q", key))
+		return nil
+	}
+
+	// Get the PostgresqlBackup resource with the specified name.
+	b, err := c.postgresqlBackupLister.Get(name)
+	if err != nil {
+		// The PostgresqlBackup may no longer exist, in which case we stop processing.
+		if kubeerrors.IsNotFound(err) {
+			c.logger.WithField(logFieldName, name).Debug("postgresqlbackup resource in work queue no longer exists")
+			return nil
+		}
+		return err
+	}
+	// Create a deep copy of the PostgresqlBackup resource so we don't possibly mutate the cache.
+	p := b.DeepCopy()
+
+	// Check whether the PostgresqlBackup resource is being deleted (indicated by a non-zero deletion timestamp).
+	if p.DeletionTimestamp.IsZero() {
+		// The PostgresqlBackup resource is not being deleted, so we must add the finalizer in case it is not already present.
+		if !slice.ContainsString(p.Finalizers, constants.CleanupFinalizer, nil) {
+			p.Finalizers = append(p.Finalizers, constants.CleanupFinalizer)
+			if p, err = c.patchPostgresqlBackup(b, p); err != nil {
+				return err
+			}
+		}
+	} else {
+		// The PostgresqlBackup resource is being deleted, so we must delete the backup run and remove the finalizer.
+		if slice.ContainsString(p.Finalizers, constants.CleanupFinalizer, nil) {
+			if err := c.deleteBackupRun(p); err != nil {
+				return err
+			}
+			p.Finalizers = slice.RemoveString(p.Finalizers, constants.CleanupFinalizer, nil)
+			if _, err = c.patchPostgresqlBackup(b, p); err != nil {
+				return err
+			}
+		}
+		// The finalizer has finished, so there is nothing else to do.
+		return nil
+	}
+
+	// Make sure that the PostgresqlBackup resource's ".status" field is always updated as the last processing step.
+	// If an error occurs during the update, it is aggregated with the error we would be returning (if any).
+	defer func() {
+		if _, patchErr := c.patchPostgresqlBackupStatus(b, p); patchErr != nil {
+			err = utilerrors.NewAggregate([]error{patchErr, err})
+		}
+	}()
+
+	// If the backup run has already finished (either successfully or not), there is nothing else to do.
+	if cdn := getPostgresqlBackupCondition(p, v1alpha1api.PostgresqlBackupStatusConditionTypeCompleted); cdn != nil && (cdn.Reason == ReasonBackupCompleted || cdn.Reason == ReasonBackupFailed) {
+		c.logger.WithField(logFieldName, name).Debug("the backup run has already finished")
+		return nil
+	}
+
+	// Grab the PostgresqlInstance resource that represents the CSQLP instance to backup.
+	i, err := c.postgresqlInstanceLister.Get(p.Spec.Instance)
+	if err != nil {
+		// If we've got an error other than "404 NOT FOUND", we stop processing and propagate it.
+		if !kubeerrors.IsNotFound(err) {
+			return err
+		}
+		// At this point we know that the PostgresqlInstance resource does not exist, so we report it and skip further processing (but don't error).
+		message := fmt.Sprintf("postgresqlinstance %q does not exist", p.Spec.Instance)
+		setPostgresqlBackupCondition(p, v1alpha1api.PostgresqlBackupStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+		c.logger.WithField(logFieldName, name).Infof("skipping sync because %s", message)
+		return nil
+	}
+
+	// Check whether the backup run has already been requested, and request it if necessary.
+	if p.Status.OperationID == "" {
+		// Backup runs can only be requested once the CSQLP instance is ready, so we skip further processing (but don't error) otherwise.
+		if cdn := getPostgresqlInstanceCondition(i, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady); cdn == nil || cdn.Status != corev1.ConditionTrue {
+			message := fmt.Sprintf("postgresqlinstance %q is not ready", p.Spec.Instance)
+			setPostgresqlBackupCondition(p, v1alpha1api.PostgresqlBackupStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+			c.er.Event(p, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+			c.logger.WithField(logFieldName, name).Infof("skipping sync because %s", message)
+			return nil
+		}
+		if created, err := c.createBackupRun(p, i); err != nil {
+			// Creation of the backup run failed with a transient error.
+			return err
+		} else if !created {
+			// Creation of the backup run failed with a permanent error.
+			return nil
+		}
+	}
+
+	// Grab the operation that created the backup run in order to check whether it has finished.
+	c.logger.WithField(logFieldName, name).Debugf("checking the status of operation %q", p.Status.OperationID)
+	op, err := c.cloudsqlClient.Operations.Get(c.projectID, p.Status.OperationID).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get operation %q: %v", p.Status.OperationID, err)
+	}
+
+	// Grab the most up-to-date representation of the backup run and update the PostgresqlBackup resource's status accordingly.
+	backupRun, err := c.getBackupRun(p, i)
+	if err != nil {
+		return err
+	}
+	if backupRun != nil {
+		setPostgresqlBackupStatusFields(p, backupRun)
+	}
+
+	// Check whether the operation is still in progress or has failed.
+	// If it is still in progress, we skip further processing (but don't error), and the status of the operation will be checked again after the controller's resync period elapses.
+	operationInProgressOrFailed, operationID, _, operationStatus, operationErrorMessage := isOperationInProgressOrFailedFromOperation(op)
+	switch {
+	case operationInProgressOrFailed && operationErrorMessage == "":
+		message := fmt.Sprintf("the backup run is in progress (operation: %q, status: %q)", operationID, operationStatus)
+		setPostgresqlBackupCondition(p, v1alpha1api.PostgresqlBackupStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonOperationInProgress, message)
+		c.logger.WithField(logFieldName, name).Debug(message)
+		return nil
+	case operationInProgressOrFailed && operationErrorMessage != "":
+		message := fmt.Sprintf("the backup run has failed (operation: %q, errors: %q)", operationID, operationErrorMessage)
+		p.Status.State = v1alpha1api.PostgresqlBackupStatusStateFailed
+		setPostgresqlBackupCondition(p, v1alpha1api.PostgresqlBackupStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonBackupFailed, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonBackupFailed, message)
+		c.logger.WithField(logFieldName, name).Error(message)
+		return nil
+	}
+
+	// At this point we know the operation has finished successfully, so we must have found the backup run.
+	if backupRun == nil {
+		return fmt.Errorf("failed to find the backup run created by operation %q", op.Name)
+	}
+
+	// Update the PostgresqlBackup resource's conditions to indicate completion.
+	message := fmt.Sprintf("the backup run has completed (id: %d)", p.Status.ID)
+	setPostgresqlBackupCondition(p, v1alpha1api.PostgresqlBackupStatusConditionTypeCompleted, corev1.ConditionTrue, ReasonBackupCompleted, message)
+	c.er.Event(p, corev1.EventTypeNormal, ReasonBackupCompleted, message)
+	c.logger.WithField(logFieldName, name).Info(message)
+	return nil
+}
+
+// createBackupRun attempts to create an on-demand backup run based on the specified PostgresqlBackup resource.
+// It returns a boolean value indicating whether the backup run has been created.
+func (c *PostgresqlBackupController) createBackupRun(postgresqlBackup *v1alpha1api.PostgresqlBackup, postgresqlInstance *v1alpha1api.PostgresqlInstance) (bool, error) {
+	c.logger.WithField(logFieldName, postgresqlBackup.Name).Info("creating backup run")
+	// Build the BackupRun object based on the specified PostgresqlBackup resource.
+	backupRun := buildBackupRun(postgresqlBackup, postgresqlInstance)
+	// Attempt to create the BackupRun object.
+	op, err := c.cloudsqlClient.BackupRuns.Insert(c.projectID, backupRun.Instance, backupRun).Do()
+	if err != nil {
+		if google.IsBadRequest(err) {
+			// We've been told that the backup run cannot be created.
+			// This most probably means that the CSQLP instance is not in a state that allows for backups to be taken (e.g. because it is stopped).
+			// Hence, we log but do not propagate the error, since subsequent attempts to create the backup run are likely to fail as well.
+			message := fmt.Sprintf("the backup run cannot be created: %v", err)
+			setPostgresqlBackupCondition(postgresqlBackup, v1alpha1api.PostgresqlBackupStatusConditionTypeCreated, corev1.ConditionFalse, ReasonInvalidSpec, message)
+			c.er.Event(postgresqlBackup, corev1.EventTypeWarning, ReasonInvalidSpec, message)
+			c.logger.WithField(logFieldName, postgresqlBackup.Name).Error(message)
+			return false, nil
+		}
+		// The Cloud SQL Admin API returned a different error, which we propagate so that creation may be retried.
+		setPostgresqlBackupCondition(postgresqlBackup, v1alpha1api.PostgresqlBackupStatusConditionTypeCreated, corev1.ConditionFalse, ReasonUnexpectedError, err.Error())
+		c.er.Event(postgresqlBackup, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+		return false, err
+	}
+	// Record the ID of the operation so that its status can be tracked.
+	postgresqlBackup.Status.OperationID = op.Name
+	postgresqlBackup.Status.State = v1alpha1api.PostgresqlBackupStatusStatePending
+	// Update the PostgresqlBackup resource's conditions.
+	message := fmt.Sprintf("the backup run has been created (operation: %q)", op.Name)
+	setPostgresqlBackupCondition(postgresqlBackup, v1alpha1api.PostgresqlBackupStatusConditionTypeCreated, corev1.ConditionTrue, ReasonBackupCreated, message)
+	c.er.Event(postgresqlBackup, corev1.EventTypeNormal, ReasonBackupCreated, message)
+	return true, nil
+}
+
+// deleteBackupRun attempts to delete the backup run associated with the specified PostgresqlBackup resource.
+func (c *PostgresqlBackupController) deleteBackupRun(postgresqlBackup *v1alpha1api.PostgresqlBackup) error {
+	c.logger.WithField(logFieldName, postgresqlBackup.Name).Debug("checking whether the backup run needs to be deleted")
+	// If the backup run has never been requested, there is nothing to delete.
+	if postgresqlBackup.Status.OperationID == "" {
+		c.logger.WithField(logFieldName, postgresqlBackup.Name).Debug("the backup run has never been created")
+		return nil
+	}
+	// Grab the PostgresqlInstance resource that represents the CSQLP instance that was backed up.
+	// If it no longer exists, the backup run has been deleted together with the CSQLP instance.
+	i, err := c.postgresqlInstanceLister.Get(postgresqlBackup.Spec.Instance)
+	if err != nil {
+		if kubeerrors.IsNotFound(err) {
+			c.logger.WithField(logFieldName, postgresqlBackup.Name).Debug("the instance has already been deleted")
+			return nil
+		}
+		return err
+	}
+	// Before issuing a delete request, make sure the backup run is still listed.
+	backupRun, err := c.getBackupRun(postgresqlBackup, i)
+	if err != nil {
+		return err
+	}
+	if backupRun == nil {
+		c.logger.WithField(logFieldName, postgresqlBackup.Name).Debug("the backup run has already been deleted")
+		return nil
+	}
+	c.logger.WithField(logFieldName, postgresqlBackup.Name).Infof("deleting backup run %d", backupRun.Id)
+	// At this point we know the backup run exists, so we issue the delete request.
+	if _, err := c.cloudsqlClient.BackupRuns.Delete(c.projectID, i.Spec.Name, backupRun.Id).Do(); err != nil {
+		return err
+	}
+	c.logger.WithField(logFieldName, postgresqlBackup.Name).Debugf("backup run %d has been deleted", backupRun.Id)
+	return nil
+}
+
+// getBackupRun returns the backup run associated with the specified PostgresqlBackup resource, or nil if no such backup run exists.
+func (c *PostgresqlBackupController) getBackupRun(postgresqlBackup *v1alpha1api.PostgresqlBackup, postgresqlInstance *v1alpha1api.PostgresqlInstance) (*cloudsqladmin.BackupRun, error) {
+	// If the ID of the backup run is already known, grab the backup run directly.
+	if postgresqlBackup.Status.ID != 0 {
+		backupRun, err := c.cloudsqlClient.BackupRuns.Get(c.projectID, postgresqlInstance.Spec.Name, postgresqlBackup.Status.ID).Do()
+		if err != nil {
+			if google.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get backup run %d: %v", postgresqlBackup.Status.ID, err)
+		}
+		return backupRun, nil
+	}
+	// Otherwise, look for the backup run using the description it was created with.
+	// Backup runs are sorted in reverse chronological order, so the backup run we are looking for is expected to be on the first page.
+	backupRuns, err := c.cloudsqlClient.BackupRuns.List(c.projectID, postgresqlInstance.Spec.Name).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backup runs: %v", err)
+	}
+	description := buildBackupRunDescription(postgresqlBackup)
+	for _, backupRun := range backupRuns.Items {
+		if backupRun != nil && backupRun.Description == description {
+			return backupRun, nil
+		}
+	}
+	return nil, nil
+}
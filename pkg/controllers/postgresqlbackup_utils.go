@@ -0,0 +1,149 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+)
+
+// buildBackupRun builds the BackupRun object that corresponds to the specified PostgresqlBackup resource.
+func buildBackupRun(postgresqlBackup *v1alpha1api.PostgresqlBackup, postgresqlInstance *v1alpha1api.PostgresqlInstance) *cloudsqladmin.BackupRun {
+	return &cloudsqladmin.BackupRun{
+		Description: buildBackupRunDescription(postgresqlBackup),
+		Instance:    postgresqlInstance.Spec.Name,
+	}
+}
+
+// buildBackupRunDescription builds the description of the backup run that corresponds to the specified PostgresqlBackup resource.
+// The description includes the UID of the PostgresqlBackup resource, and is used to find the backup run after it has been created.
+func buildBackupRunDescription(postgresqlBackup *v1alpha1api.PostgresqlBackup) string {
+	return fmt.Sprintf("%s: postgresqlbackup %s (%s)", constants.ApplicationName, postgresqlBackup.Name, postgresqlBackup.UID)
+}
+
+// getPostgresqlBackupCondition returns the condition of the provided type associated with the provided PostgresqlBackup resource, or nil if no such condition exists.
+func getPostgresqlBackupCondition(postgresqlBackup *v1alpha1api.PostgresqlBackup, conditionType v1alpha1api.PostgresqlBackupStatusConditionType) *v1alpha1api.PostgresqlBackupStatusCondition {
+	for idx := range postgresqlBackup.Status.Conditions {
+		if postgresqlBackup.Status.Conditions[idx].Type == conditionType {
+			return &postgresqlBackup.Status.Conditions[idx]
+		}
+	}
+	return nil
+}
+
+// parseBackupRunTime parses the provided RFC 3339 timestamp as returned by the Cloud SQL Admin API.
+// It returns nil if the timestamp is empty or invalid.
+func parseBackupRunTime(v string) *v1.Time {
+	if v == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil
+	}
+	r := v1.NewTime(t)
+	return &r
+}
+
+// patchPostgresqlBackup updates the provided PostgresqlBackup using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlBackupController) patchPostgresqlBackup(oldObj, newObj *v1alpha1api.PostgresqlBackup, subresources ...string) (*v1alpha1api.PostgresqlBackup, error) {
+	// Return if there are no changes to be made.
+	if reflect.DeepEqual(oldObj, newObj) {
+		return newObj, nil
+	}
+	// Prepare the patch to apply based on the provided objects.
+	oldBytes, err := json.Marshal(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newBytes, err := json.Marshal(newObj)
+	if err != nil {
+		return nil, err
+	}
+	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldBytes, newBytes, &v1alpha1api.PostgresqlBackup{})
+	if err != nil {
+		return nil, err
+	}
+	// Apply the patch.
+	return c.selfClient.CloudsqlV1alpha1().PostgresqlBackups().Patch(oldObj.Name, types.MergePatchType, patchBytes, subresources...)
+}
+
+// patchPostgresqlBackupStatus updates the status of the provided PostgresqlBackup using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlBackupController) patchPostgresqlBackupStatus(oldObj, newObj *v1alpha1api.PostgresqlBackup) (*v1alpha1api.PostgresqlBackup, error) {
+	return c.patchPostgresqlBackup(oldObj, newObj, "status")
+}
+
+// setPostgresqlBackupCondition sets a condition on the provided PostgresqlBackup resource according to the following rules:
+// 1. If no condition of the provided type exists, the condition is inserted with its last transition time set to the current time.
+// 2. If a condition of the provided type and state exists, the condition is updated but its last transition time is not modified.
+// 3. If a condition of the provided type but different state exists, the condition is updated and its last transition time is set to the current time.
+func setPostgresqlBackupCondition(postgresqlBackup *v1alpha1api.PostgresqlBackup, conditionType v1alpha1api.PostgresqlBackupStatusConditionType, conditionStatus corev1.ConditionStatus, conditionReason string, conditionMessage string) {
+	// Create the new condition.
+	newCondition := v1alpha1api.PostgresqlBackupStatusCondition{
+		LastTransitionTime: v1.NewTime(time.Now()),
+		Message:            conditionMessage,
+		Reason:             conditionReason,
+		Status:             conditionStatus,
+		Type:               conditionType,
+	}
+	// Search through existing conditions in order to understand if we need to insert the new condition or not.
+	for idx, cdn := range postgresqlBackup.Status.Conditions {
+		// If the current condition's type is different from the one we will be inserting, skip it.
+		if cdn.Type != newCondition.Type {
+			continue
+		}
+		// If the status is the same, we should not update the condition's last transition time.
+		if cdn.Status == newCondition.Status {
+			newCondition.LastTransitionTime = cdn.LastTransitionTime
+		}
+		// Overwrite the existing condition and return.
+		postgresqlBackup.Status.Conditions[idx] = newCondition
+		return
+	}
+	// At this point we know that there is no existing condition with this type, so we just append it to the set of conditions.
+	postgresqlBackup.Status.Conditions = append(postgresqlBackup.Status.Conditions, newCondition)
+}
+
+// setPostgresqlBackupStatusFields sets the ID, the start and end times and the state of the provided backup run.
+func setPostgresqlBackupStatusFields(postgresqlBackup *v1alpha1api.PostgresqlBackup, backupRun *cloudsqladmin.BackupRun) {
+	postgresqlBackup.Status.ID = backupRun.Id
+	postgresqlBackup.Status.StartTime = parseBackupRunTime(backupRun.StartTime)
+	postgresqlBackup.Status.EndTime = parseBackupRunTime(backupRun.EndTime)
+	switch backupRun.Status {
+	case constants.BackupRunStatusEnqueued:
+		postgresqlBackup.Status.State = v1alpha1api.PostgresqlBackupStatusStatePending
+	case constants.BackupRunStatusRunning:
+		postgresqlBackup.Status.State = v1alpha1api.PostgresqlBackupStatusStateRunning
+	case constants.BackupRunStatusSuccessful:
+		postgresqlBackup.Status.State = v1alpha1api.PostgresqlBackupStatusStateSuccessful
+	case constants.BackupRunStatusFailed:
+		postgresqlBackup.Status.State = v1alpha1api.PostgresqlBackupStatusStateFailed
+	}
+}
@@ -0,0 +1,338 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/util/slice"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	v1alpha1client "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/clientset/versioned"
+	v1alpha1informers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/informers/externalversions/cloudsql/v1alpha1"
+	v1alpha1listers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/listers/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/configuration"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/crds"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/google"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/pointers"
+)
+
+const (
+	// clientCertificateCommonNameFormatString is the format string used to build the common name of a client certificate based on the namespace and name of the PostgresqlClientCertificate resource that represents it.
+	clientCertificateCommonNameFormatString = "%s-%s-%s"
+	// postgresqlClientCertificateControllerName is the name of the controller for PostgresqlClientCertificate resources.
+	postgresqlClientCertificateControllerName = "postgresqlclientcertificate-controller"
+	// postgresqlClientCertificateControllerThreadiness is the number of workers controller for PostgresqlClientCertificate resource will use to process items from its work queue.
+	postgresqlClientCertificateControllerThreadiness = 1
+)
+
+// PostgresqlClientCertificateController is the controller for PostgresqlClientCertificate resources.
+type PostgresqlClientCertificateController struct {
+	// PostgresqlClientCertificateController is based-off of a generic controller.
+	*genericController
+	// cloudsqlClient is a client for the Cloud SQL Admin API.
+	cloudsqlClient *cloudsqladmin.Service
+	// er is an EventRecorder through which we can emit events associated with PostgresqlClientCertificate resources.
+	er record.EventRecorder
+	// kubeClient is a client to the Kubernetes API.
+	kubeClient kubernetes.Interface
+	// postgresqlClientCertificateLister is a lister for PostgresqlClientCertificate resources.
+	postgresqlClientCertificateLister v1alpha1listers.PostgresqlClientCertificateLister
+	// postgresqlInstanceLister is a lister for PostgresqlInstance resources.
+	postgresqlInstanceLister v1alpha1listers.PostgresqlInstanceLister
+	// projectID is the ID of the GCP project where cloudsql-postgres-operator is managing CSQLP instances.
+	projectID string
+	// selfClient is a client to the "cloudsql.travelaudience.com" API.
+	selfClient v1alpha1client.Interface
+}
+
+// NewPostgresqlClientCertificateController creates a new instance of the controller for PostgresqlClientCertificate resources.
+func NewPostgresqlClientCertificateController(config configuration.Configuration, kubeClient kubernetes.Interface, selfClient v1alpha1client.Interface, er record.EventRecorder, postgresqlClientCertificateInformer v1alpha1informers.PostgresqlClientCertificateInformer, postgresqlInstanceInformer v1alpha1informers.PostgresqlInstanceInformer, cloudsqlClient *cloudsqladmin.Service) *PostgresqlClientCertificateController {
+	// Create a new instance of the controller for PostgresqlClientCertificate resources using the specified name and threadiness.
+	c := &PostgresqlClientCertificateController{
+		cloudsqlClient:                    cloudsqlClient,
+		genericController:                 newGenericController(postgresqlClientCertificateControllerName, postgresqlClientCertificateControllerThreadiness),
+		er:                                er,
+		kubeClient:                        kubeClient,
+		postgresqlClientCertificateLister: postgresqlClientCertificateInformer.Lister(),
+		postgresqlInstanceLister:          postgresqlInstanceInformer.Lister(),
+		projectID:                         config.GCP.ProjectID,
+		selfClient:                        selfClient,
+	}
+	// Make the controller wait for the caches to sync.
+	c.hasSyncedFuncs = []cache.InformerSynced{
+		postgresqlClientCertificateInformer.Informer().HasSynced,
+		postgresqlInstanceInformer.Informer().HasSynced,
+	}
+	// Make "processQueueItem" the handler for items popped out of the work queue.
+	c.syncHandler = c.processQueueItem
+
+	// Setup an event handler to inform us when PostgresqlClientCertificate resources change.
+	postgresqlClientCertificateInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			c.enqueue(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+	})
+
+	// Return the instance of the controller for PostgresqlClientCertificate resources created above.
+	return c
+}
+
+// processQueueItem attempts to reconcile the state of the PostgresqlClientCertificate resource pointed at by the specified key.
+func (c *PostgresqlClientCertificateController) processQueueItem(key string) (err error) {
+	// Grab the namespace and name of the PostgresqlClientCertificate resource from the specified key.
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("invalid resource key %q", key))
+		return nil
+	}
+
+	// Get the PostgresqlClientCertificate resource with the specified namespace and name.
+	u, err := c.postgresqlClientCertificateLister.PostgresqlClientCertificates(namespace).Get(name)
+	if err != nil {
+		// The PostgresqlClientCertificate may no longer exist, in which case we stop processing.
+		if kubeerrors.IsNotFound(err) {
+			c.logger.WithField(logFieldName, key).Debug("postgresqlclientcertificate resource in work queue no longer exists")
+			return nil
+		}
+		return err
+	}
+	// Create a deep copy of the PostgresqlClientCertificate resource so we don't possibly mutate the cache.
+	p := u.DeepCopy()
+
+	// Check whether the PostgresqlClientCertificate resource is being deleted (indicated by a non-zero deletion timestamp).
+	if p.DeletionTimestamp.IsZero() {
+		// The PostgresqlClientCertificate resource is not being deleted, so we must add the finalizer in case it is not already present.
+		if !slice.ContainsString(p.Finalizers, constants.CleanupFinalizer, nil) {
+			p.Finalizers = append(p.Finalizers, constants.CleanupFinalizer)
+			if p, err = c.patchPostgresqlClientCertificate(u, p); err != nil {
+				return err
+			}
+		}
+	} else {
+		// The PostgresqlClientCertificate resource is being deleted, so we must revoke the client certificate and remove the finalizer.
+		// The secret containing the client certificate is owned by the PostgresqlClientCertificate resource, and hence is garbage-collected afterwards.
+		if slice.ContainsString(p.Finalizers, constants.CleanupFinalizer, nil) {
+			if err := c.deleteClientCertificate(p); err != nil {
+				return err
+			}
+			p.Finalizers = slice.RemoveString(p.Finalizers, constants.CleanupFinalizer, nil)
+			if _, err = c.patchPostgresqlClientCertificate(u, p); err != nil {
+				return err
+			}
+		}
+		// The finalizer has finished, so there is nothing else to do.
+		return nil
+	}
+
+	// Make sure that the PostgresqlClientCertificate resource's ".status" field is always updated as the last processing step.
+	// If an error occurs during the update, it is aggregated with the error we would be returning (if any).
+	defer func() {
+		if _, patchErr := c.patchPostgresqlClientCertificateStatus(u, p); patchErr != nil {
+			err = utilerrors.NewAggregate([]error{patchErr, err})
+		}
+	}()
+
+	// Grab the PostgresqlInstance resource that represents the CSQLP instance for which the client certificate is to be issued.
+	i, err := c.postgresqlInstanceLister.Get(p.Spec.Instance)
+	if err != nil {
+		// If we've got an error other than "404 NOT FOUND", we stop processing and propagate it.
+		if !kubeerrors.IsNotFound(err) {
+			return err
+		}
+		// At this point we know that the PostgresqlInstance resource does not exist, so we report it and skip further processing (but don't error).
+		message := fmt.Sprintf("postgresqlinstance %q does not exist", p.Spec.Instance)
+		setPostgresqlClientCertificateCondition(p, v1alpha1api.PostgresqlClientCertificateStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+		c.logger.WithField(logFieldName, key).Infof("skipping sync because %s", message)
+		return nil
+	}
+
+	// Check whether the CSQLP instance is ready, in which case we skip further processing (but don't error).
+	if cdn := getPostgresqlInstanceCondition(i, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady); cdn == nil || cdn.Status != corev1.ConditionTrue {
+		message := fmt.Sprintf("postgresqlinstance %q is not ready", p.Spec.Instance)
+		setPostgresqlClientCertificateCondition(p, v1alpha1api.PostgresqlClientCertificateStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+		c.logger.WithField(logFieldName, key).Infof("skipping sync because %s", message)
+		return nil
+	}
+
+	// Create the secret associated with the current PostgresqlClientCertificate resource, if necessary.
+	s, err := c.kubeClient.CoreV1().Secrets(p.Namespace).Get(*p.Spec.SecretName, metav1.GetOptions{})
+	if err != nil {
+		// If we've got an error other than "404 NOT FOUND", we stop processing and propagate it.
+		if !kubeerrors.IsNotFound(err) {
+			c.logger.WithField(logFieldName, key).Debugf("failed to check if the secret associated with the resource already exists: %v", err)
+			return err
+		}
+		// At this point we know that the secret associated with the current PostgresqlClientCertificate resource must be created.
+		if s, err = c.createClientCertificateSecret(p); err != nil {
+			c.logger.WithField(logFieldName, key).Debugf("failed to create the secret associated with the resource: %v", err)
+			return err
+		}
+	}
+	// Make sure that the secret is owned by the current PostgresqlClientCertificate resource, as writing the client certificate to a pre-existing secret would overwrite its contents.
+	if !metav1.IsControlledBy(s, p) {
+		message := fmt.Sprintf("secret %q already exists and is not owned by the resource", s.Name)
+		setPostgresqlClientCertificateCondition(p, v1alpha1api.PostgresqlClientCertificateStatusConditionTypeReady, corev1.ConditionFalse, ReasonSecretUnavailable, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonSecretUnavailable, message)
+		c.logger.WithField(logFieldName, key).Error(message)
+		return nil
+	}
+
+	// Issue a client certificate if the secret does not contain one yet.
+	// The private key of a client certificate is only returned upon creation, so a client certificate must be issued again if the secret has lost its contents.
+	if len(s.Data[constants.ClientCertificateKey]) == 0 || len(s.Data[constants.ClientPrivateKeyKey]) == 0 {
+		if err := c.issueClientCertificate(p, i, s); err != nil {
+			setPostgresqlClientCertificateCondition(p, v1alpha1api.PostgresqlClientCertificateStatusConditionTypeCreated, corev1.ConditionFalse, ReasonUnexpectedError, err.Error())
+			c.er.Event(p, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+			c.logger.WithField(logFieldName, key).Debugf("failed to issue the client certificate: %v", err)
+			return err
+		}
+		message := fmt.Sprintf("client certificate %q has been issued", p.Status.CommonName)
+		setPostgresqlClientCertificateCondition(p, v1alpha1api.PostgresqlClientCertificateStatusConditionTypeCreated, corev1.ConditionTrue, ReasonClientCertificateCreated, message)
+		c.er.Event(p, corev1.EventTypeNormal, ReasonClientCertificateCreated, message)
+	}
+
+	// Update the PostgresqlClientCertificate resource's conditions to indicate readiness.
+	message := "the client certificate is ready"
+	setPostgresqlClientCertificateCondition(p, v1alpha1api.PostgresqlClientCertificateStatusConditionTypeReady, corev1.ConditionTrue, ReasonClientCertificateReady, message)
+	c.er.Event(p, corev1.EventTypeNormal, ReasonClientCertificateReady, message)
+	return nil
+}
+
+// createClientCertificateSecret creates the (initially empty) secret associated with the specified PostgresqlClientCertificate resource.
+func (c *PostgresqlClientCertificateController) createClientCertificateSecret(postgresqlClientCertificate *v1alpha1api.PostgresqlClientCertificate) (*corev1.Secret, error) {
+	return c.kubeClient.CoreV1().Secrets(postgresqlClientCertificate.Namespace).Create(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				constants.LabelAppKey: constants.ApplicationName,
+			},
+			Name:      *postgresqlClientCertificate.Spec.SecretName,
+			Namespace: postgresqlClientCertificate.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         v1alpha1api.SchemeGroupVersion.String(),
+					Kind:               crds.PostgresqlClientCertificateKind,
+					Name:               postgresqlClientCertificate.Name,
+					UID:                postgresqlClientCertificate.UID,
+					Controller:         pointers.NewBool(true),
+					BlockOwnerDeletion: pointers.NewBool(true),
+				},
+			},
+		},
+	})
+}
+
+// deleteClientCertificate attempts to revoke the client certificate associated with the specified PostgresqlClientCertificate resource.
+func (c *PostgresqlClientCertificateController) deleteClientCertificate(postgresqlClientCertificate *v1alpha1api.PostgresqlClientCertificate) error {
+	c.logger.WithField(logFieldName, postgresqlClientCertificate.Name).Debug("checking whether the client certificate needs to be deleted")
+	// If no client certificate has been issued, there is nothing to do.
+	if postgresqlClientCertificate.Status.SHA1Fingerprint == "" {
+		c.logger.WithField(logFieldName, postgresqlClientCertificate.Name).Debug("no client certificate has been issued for the resource")
+		return nil
+	}
+	// Grab the PostgresqlInstance resource that represents the CSQLP instance for which the client certificate was issued.
+	// If it no longer exists, the client certificate has been deleted together with the CSQLP instance.
+	i, err := c.postgresqlInstanceLister.Get(postgresqlClientCertificate.Spec.Instance)
+	if err != nil {
+		if kubeerrors.IsNotFound(err) {
+			c.logger.WithField(logFieldName, postgresqlClientCertificate.Name).Debug("the instance has already been deleted")
+			return nil
+		}
+		return err
+	}
+	return c.revokeClientCertificate(postgresqlClientCertificate, i, postgresqlClientCertificate.Status.SHA1Fingerprint)
+}
+
+// issueClientCertificate issues a new client certificate for the CSQLP instance represented by the specified PostgresqlInstance resource and writes it (together with its private key and with the server CA bundle) to the specified secret.
+// Any client certificate previously issued for the specified PostgresqlClientCertificate resource is revoked, as its private key has been lost.
+func (c *PostgresqlClientCertificateController) issueClientCertificate(postgresqlClientCertificate *v1alpha1api.PostgresqlClientCertificate, postgresqlInstance *v1alpha1api.PostgresqlInstance, secret *corev1.Secret) error {
+	if fingerprint := postgresqlClientCertificate.Status.SHA1Fingerprint; fingerprint != "" {
+		if err := c.revokeClientCertificate(postgresqlClientCertificate, postgresqlInstance, fingerprint); err != nil {
+			return err
+		}
+		postgresqlClientCertificate.Status.CommonName = ""
+		postgresqlClientCertificate.Status.ExpirationTime = nil
+		postgresqlClientCertificate.Status.SHA1Fingerprint = ""
+	}
+	// A random suffix is added to the common name of the client certificate, as common names must be unique within a CSQLP instance.
+	commonName := fmt.Sprintf(clientCertificateCommonNameFormatString, postgresqlClientCertificate.Namespace, postgresqlClientCertificate.Name, rand.String(5))
+	c.logger.WithField(logFieldName, postgresqlClientCertificate.Name).Infof("issuing client certificate %q", commonName)
+	r, err := c.cloudsqlClient.SslCerts.Insert(c.projectID, postgresqlInstance.Spec.Name, &cloudsqladmin.SslCertsInsertRequest{
+		CommonName: commonName,
+	}).Do()
+	if err != nil {
+		return fmt.Errorf("failed to issue a client certificate: %v", err)
+	}
+	if r.ClientCert == nil || r.ClientCert.CertInfo == nil {
+		return fmt.Errorf("failed to issue a client certificate: incomplete response from the cloud sql admin api")
+	}
+	// Record the client certificate right away so that it is revoked even if writing it to the secret fails.
+	postgresqlClientCertificate.Status.CommonName = commonName
+	postgresqlClientCertificate.Status.SHA1Fingerprint = r.ClientCert.CertInfo.Sha1Fingerprint
+	if t, err := time.Parse(time.RFC3339, r.ClientCert.CertInfo.ExpirationTime); err == nil {
+		postgresqlClientCertificate.Status.ExpirationTime = &metav1.Time{Time: t}
+	}
+	// Include every server CA certificate (and not only the active one) so that the server certificate can still be verified after the server CA certificate is rotated.
+	cas, err := c.cloudsqlClient.Instances.ListServerCas(c.projectID, postgresqlInstance.Spec.Name).Do()
+	if err != nil {
+		return fmt.Errorf("failed to list the server ca certificates: %v", err)
+	}
+	// Update the secret with the client certificate, its private key and the server CA bundle.
+	if secret.StringData == nil {
+		secret.StringData = make(map[string]string, 3)
+	}
+	secret.StringData[constants.ClientCertificateKey] = r.ClientCert.CertInfo.Cert
+	secret.StringData[constants.ClientPrivateKeyKey] = r.ClientCert.CertPrivateKey
+	secret.StringData[constants.ServerCACertificateKey] = google.ServerCABundle(cas.Certs)
+	_, err = c.kubeClient.CoreV1().Secrets(secret.Namespace).Update(secret)
+	return err
+}
+
+// revokeClientCertificate deletes the client certificate with the specified SHA-1 fingerprint from the CSQLP instance represented by the specified PostgresqlInstance resource.
+func (c *PostgresqlClientCertificateController) revokeClientCertificate(postgresqlClientCertificate *v1alpha1api.PostgresqlClientCertificate, postgresqlInstance *v1alpha1api.PostgresqlInstance, fingerprint string) error {
+	c.logger.WithField(logFieldName, postgresqlClientCertificate.Name).Infof("deleting client certificate %q", fingerprint)
+	if _, err := c.cloudsqlClient.SslCerts.Delete(c.projectID, postgresqlInstance.Spec.Name, fingerprint).Do(); err != nil {
+		// If the client certificate (or the CSQLP instance) no longer exists, there is nothing to do.
+		if google.IsNotFound(err) {
+			c.logger.WithField(logFieldName, postgresqlClientCertificate.Name).Debugf("client certificate %q has already been deleted", fingerprint)
+			return nil
+		}
+		return fmt.Errorf("failed to delete client certificate %q: %v", fingerprint, err)
+	}
+	c.logger.WithField(logFieldName, postgresqlClientCertificate.Name).Debugf("client certificate %q has been deleted", fingerprint)
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+)
+
+// getPostgresqlClientCertificateCondition returns the condition of the provided type associated with the provided PostgresqlClientCertificate resource, or nil if no such condition exists.
+func getPostgresqlClientCertificateCondition(postgresqlClientCertificate *v1alpha1api.PostgresqlClientCertificate, conditionType v1alpha1api.PostgresqlClientCertificateStatusConditionType) *v1alpha1api.PostgresqlClientCertificateStatusCondition {
+	for idx := range postgresqlClientCertificate.Status.Conditions {
+		if postgresqlClientCertificate.Status.Conditions[idx].Type == conditionType {
+			return &postgresqlClientCertificate.Status.Conditions[idx]
+		}
+	}
+	return nil
+}
+
+// patchPostgresqlClientCertificate updates the provided PostgresqlClientCertificate using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlClientCertificateController) patchPostgresqlClientCertificate(oldObj, newObj *v1alpha1api.PostgresqlClientCertificate, subresources ...string) (*v1alpha1api.PostgresqlClientCertificate, error) {
+	// Return if there are no changes to be made.
+	if reflect.DeepEqual(oldObj, newObj) {
+		return newObj, nil
+	}
+	// Prepare the patch to apply based on the provided objects.
+	oldBytes, err := json.Marshal(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newBytes, err := json.Marshal(newObj)
+	if err != nil {
+		return nil, err
+	}
+	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldBytes, newBytes, &v1alpha1api.PostgresqlClientCertificate{})
+	if err != nil {
+		return nil, err
+	}
+	// Apply the patch.
+	return c.selfClient.CloudsqlV1alpha1().PostgresqlClientCertificates(oldObj.Namespace).Patch(oldObj.Name, types.MergePatchType, patchBytes, subresources...)
+}
+
+// patchPostgresqlClientCertificateStatus updates the status of the provided PostgresqlClientCertificate using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlClientCertificateController) patchPostgresqlClientCertificateStatus(oldObj, newObj *v1alpha1api.PostgresqlClientCertificate) (*v1alpha1api.PostgresqlClientCertificate, error) {
+	return c.patchPostgresqlClientCertificate(oldObj, newObj, "status")
+}
+
+// setPostgresqlClientCertificateCondition sets a condition on the provided PostgresqlClientCertificate resource according to the following rules:
+// 1. If no condition of the provided type exists, the condition is inserted with its last transition time set to the current time.
+// 2. If a condition of the provided type and state exists, the condition is updated but its last transition time is not modified.
+// 3. If a condition of the provided type but different state exists, the condition is updated and its last transition time is set to the current time.
+func setPostgresqlClientCertificateCondition(postgresqlClientCertificate *v1alpha1api.PostgresqlClientCertificate, conditionType v1alpha1api.PostgresqlClientCertificateStatusConditionType, conditionStatus corev1.ConditionStatus, conditionReason string, conditionMessage string) {
+	// Create the new condition.
+	newCondition := v1alpha1api.PostgresqlClientCertificateStatusCondition{
+		LastTransitionTime: v1.NewTime(time.Now()),
+		Message:            conditionMessage,
+		Reason:             conditionReason,
+		Status:             conditionStatus,
+		Type:               conditionType,
+	}
+	// Search through existing conditions in order to understand if we need to insert the new condition or not.
+	for idx, cdn := range postgresqlClientCertificate.Status.Conditions {
+		// If the current condition's type is different from the one we will be inserting, skip it.
+		if cdn.Type != newCondition.Type {
+			continue
+		}
+		// If the status is the same, we should not update the condition's last transition time.
+		if cdn.Status == newCondition.Status {
+			newCondition.LastTransitionTime = cdn.LastTransitionTime
+		}
+		// Overwrite the existing condition and return.
+		postgresqlClientCertificate.Status.Conditions[idx] = newCondition
+		return
+	}
+	// At this point we know that there is no existing condition with this type, so we just append it to the set of conditions.
+	postgresqlClientCertificate.Status.Conditions = append(postgresqlClientCertificate.Status.Conditions, newCondition)
+}
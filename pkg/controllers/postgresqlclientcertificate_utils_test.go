@@ -0,0 +1,57 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+)
+
+func TestSetPostgresqlClientCertificateCondition(t *testing.T) {
+	past := v1.NewTime(time.Now().Add(-time.Hour))
+	p := &v1alpha1api.PostgresqlClientCertificate{}
+
+	// Inserting a condition sets its last transition time to the current time.
+	setPostgresqlClientCertificateCondition(p, v1alpha1api.PostgresqlClientCertificateStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceNotReady, "issuing")
+	cdn := getPostgresqlClientCertificateCondition(p, v1alpha1api.PostgresqlClientCertificateStatusConditionTypeReady)
+	if cdn == nil || cdn.Status != corev1.ConditionFalse || cdn.Reason != ReasonInstanceNotReady || cdn.Message != "issuing" {
+		t.Fatalf("expected the condition to be inserted, got %v", cdn)
+	}
+	if !cdn.LastTransitionTime.After(past.Time) {
+		t.Errorf("expected the last transition time to be set to the current time, got %s", cdn.LastTransitionTime)
+	}
+
+	// Updating a condition without changing its status keeps its last transition time.
+	cdn.LastTransitionTime = past
+	setPostgresqlClientCertificateCondition(p, v1alpha1api.PostgresqlClientCertificateStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceNotReady, "still issuing")
+	cdn = getPostgresqlClientCertificateCondition(p, v1alpha1api.PostgresqlClientCertificateStatusConditionTypeReady)
+	if len(p.Status.Conditions) != 1 || cdn.Message != "still issuing" || !cdn.LastTransitionTime.Equal(&past) {
+		t.Errorf("expected the condition to be updated without changing its last transition time, got %v", p.Status.Conditions)
+	}
+
+	// Changing the status of a condition updates its last transition time.
+	setPostgresqlClientCertificateCondition(p, v1alpha1api.PostgresqlClientCertificateStatusConditionTypeReady, corev1.ConditionTrue, ReasonClientCertificateReady, "issued")
+	cdn = getPostgresqlClientCertificateCondition(p, v1alpha1api.PostgresqlClientCertificateStatusConditionTypeReady)
+	if len(p.Status.Conditions) != 1 || cdn.Status != corev1.ConditionTrue || !cdn.LastTransitionTime.After(past.Time) {
+		t.Errorf("expected the condition to be updated together with its last transition time, got %v", p.Status.Conditions)
+	}
+}
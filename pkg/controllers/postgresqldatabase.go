@@ -0,0 +1,291 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/util/slice"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	v1alpha1client "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/clientset/versioned"
+	v1alpha1informers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/informers/externalversions/cloudsql/v1alpha1"
+	v1alpha1listers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/listers/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/configuration"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/google"
+)
+
+const (
+	// postgresqlDatabaseControllerName is the name of the controller for PostgresqlDatabase resources.
+	postgresqlDatabaseControllerName = "postgresqldatabase-controller"
+	// postgresqlDatabaseControllerThreadiness is the number of workers controller for PostgresqlDatabase resource will use to process items from its work queue.
+	postgresqlDatabaseControllerThreadiness = 1
+)
+
+// PostgresqlDatabaseController is the controller for PostgresqlDatabase resources.
+type PostgresqlDatabaseController struct {
+	// PostgresqlDatabaseController is based-off of a generic controller.
+	*genericController
+	// cloudsqlClient is a client for the Cloud SQL Admin API.
+	cloudsqlClient *cloudsqladmin.Service
+	// er is an EventRecorder through which we can emit events associated with PostgresqlDatabase resources.
+	er record.EventRecorder
+	// postgresqlDatabaseLister is a lister for PostgresqlDatabase resources.
+	postgresqlDatabaseLister v1alpha1listers.PostgresqlDatabaseLister
+	// postgresqlInstanceLister is a lister for PostgresqlInstance resources.
+	postgresqlInstanceLister v1alpha1listers.PostgresqlInstanceLister
+	// projectID is the ID of the GCP project where cloudsql-postgres-operator is managing CSQLP instances.
+	projectID string
+	// selfClient is a client to the "cloudsql.travelaudience.com" API.
+	selfClient v1alpha1client.Interface
+}
+
+// NewPostgresqlDatabaseController creates a new instance of the controller for PostgresqlDatabase resources.
+func NewPostgresqlDatabaseController(config configuration.Configuration, selfClient v1alpha1client.Interface, er record.EventRecorder, postgresqlDatabaseInformer v1alpha1informers.PostgresqlDatabaseInformer, postgresqlInstanceInformer v1alpha1informers.PostgresqlInstanceInformer, cloudsqlClient *cloudsqladmin.Service) *PostgresqlDatabaseController {
+	// Create a new instance of the controller for PostgresqlDatabase resources using the specified name and threadiness.
+	c := &PostgresqlDatabaseController{
+		cloudsqlClient:           cloudsqlClient,
+		genericController:        newGenericController(postgresqlDatabaseControllerName, postgresqlDatabaseControllerThreadiness),
+		er:                       er,
+		postgresqlDatabaseLister: postgresqlDatabaseInformer.Lister(),
+		postgresqlInstanceLister: postgresqlInstanceInformer.Lister(),
+		projectID:                config.GCP.ProjectID,
+		selfClient:               selfClient,
+	}
+	// Make the controller wait for the caches to sync.
+	c.hasSyncedFuncs = []cache.InformerSynced{
+		postgresqlDatabaseInformer.Informer().HasSynced,
+		postgresqlInstanceInformer.Informer().HasSynced,
+	}
+	// Make "processQueueItem" the handler for items popped out of the work queue.
+	c.syncHandler = c.processQueueItem
+
+	// Setup an event handler to inform us when PostgresqlDatabase resources change.
+	postgresqlDatabaseInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			c.enqueue(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+	})
+
+	// Return the instance of the controller for PostgresqlDatabase resources created above.
+	return c
+}
+
+// processQueueItem attempts to reconcile the state of the PostgresqlDatabase resource pointed at by the specified key.
+func (c *PostgresqlDatabaseController) processQueueItem(key string) (err error) {
+	// Grab the name of the PostgresqlDatabase resource from the specified key.
+	// NOTE: PostgresqlDatabase is cluster-scoped, and hence there is no associated namespace.
+	_, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("invalid resource key %q", key))
+		return nil
+	}
+
+	// Get the PostgresqlDatabase resource with the specified name.
+	d, err := c.postgresqlDatabaseLister.Get(name)
+	if err != nil {
+		// The PostgresqlDatabase may no longer exist, in which case we stop processing.
+		if kubeerrors.IsNotFound(err) {
+			c.logger.WithField(logFieldName, name).Debug("postgresqldatabase resource in work queue no longer exists")
+			return nil
+		}
+		return err
+	}
+	// Create a deep copy of the PostgresqlDatabase resource so we don't possibly mutate the cache.
+	p := d.DeepCopy()
+
+	// Check whether the PostgresqlDatabase resource is being deleted (indicated by a non-zero deletion timestamp).
+	if p.DeletionTimestamp.IsZero() {
+		// The PostgresqlDatabase resource is not being deleted, so we must add the finalizer in case it is not already present.
+		if !slice.ContainsString(p.Finalizers, constants.CleanupFinalizer, nil) {
+			p.Finalizers = append(p.Finalizers, constants.CleanupFinalizer)
+			if p, err = c.patchPostgresqlDatabase(d, p); err != nil {
+				return err
+			}
+		}
+	} else {
+		// The PostgresqlDatabase resource is being deleted, so we must delete the database and remove the finalizer.
+		if slice.ContainsString(p.Finalizers, constants.CleanupFinalizer, nil) {
+			if err := c.deleteDatabase(p); err != nil {
+				return err
+			}
+			p.Finalizers = slice.RemoveString(p.Finalizers, constants.CleanupFinalizer, nil)
+			if _, err = c.patchPostgresqlDatabase(d, p); err != nil {
+				return err
+			}
+		}
+		// The finalizer has finished, so there is nothing else to do.
+		return nil
+	}
+
+	// Make sure that the PostgresqlDatabase resource's ".status" field is always updated as the last processing step.
+	// If an error occurs during the update, it is aggregated with the error we would be returning (if any).
+	defer func() {
+		if _, patchErr := c.patchPostgresqlDatabaseStatus(d, p); patchErr != nil {
+			err = utilerrors.NewAggregate([]error{patchErr, err})
+		}
+	}()
+
+	// Grab the PostgresqlInstance resource that represents the CSQLP instance in which the database is to be created.
+	i, err := c.postgresqlInstanceLister.Get(p.Spec.Instance)
+	if err != nil {
+		// If we've got an error other than "404 NOT FOUND", we stop processing and propagate it.
+		if !kubeerrors.IsNotFound(err) {
+			return err
+		}
+		// At this point we know that the PostgresqlInstance resource does not exist, so we report it and skip further processing (but don't error).
+		message := fmt.Sprintf("postgresqlinstance %q does not exist", p.Spec.Instance)
+		setPostgresqlDatabaseCondition(p, v1alpha1api.PostgresqlDatabaseStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+		c.logger.WithField(logFieldName, name).Infof("skipping sync because %s", message)
+		return nil
+	}
+
+	// Check whether the CSQLP instance is ready, in which case we skip further processing (but don't error).
+	// This may happen, for instance, if the CSQLP instance is still being created, or if it is down for maintenance.
+	if cdn := getPostgresqlInstanceCondition(i, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady); cdn == nil || cdn.Status != corev1.ConditionTrue {
+		message := fmt.Sprintf("postgresqlinstance %q is not ready", p.Spec.Instance)
+		setPostgresqlDatabaseCondition(p, v1alpha1api.PostgresqlDatabaseStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+		c.logger.WithField(logFieldName, name).Infof("skipping sync because %s", message)
+		return nil
+	}
+
+	// Check whether a database with the specified ".spec.name" already exists in the CSQLP instance, and create it if necessary.
+	c.logger.WithField(logFieldName, name).Debugf("checking whether a database with name %q already exists", p.Spec.Name)
+	database, err := c.cloudsqlClient.Databases.Get(c.projectID, i.Spec.Name, p.Spec.Name).Do()
+	if err != nil {
+		// If we've got an error other than "404 NOT FOUND", we stop processing and propagate it.
+		if !google.IsNotFound(err) {
+			c.logger.WithField(logFieldName, name).Debugf("failed to check if a database with name %q exists: %v", p.Spec.Name, err)
+			return fmt.Errorf("failed to check if a database with name %q exists: %v", p.Spec.Name, err)
+		}
+		// At this point we know that no database having ".spec.name" as its name exists, so we proceed to creating it.
+		if database, err = c.createDatabase(p, i); err != nil {
+			// Creation of the database failed with a transient error.
+			return err
+		} else if database == nil {
+			// Creation of the database failed with a permanent error.
+			return nil
+		}
+	} else if !isPostgresqlDatabaseCreated(p) {
+		// A database with the specified ".spec.name" exists, but it has not been created by us (e.g. it has been created out-of-band after the PostgresqlDatabase resource has been admitted).
+		// Hence, we report it and skip further processing (but don't error), as taking ownership of the database would cause it to be dropped when the PostgresqlDatabase resource is deleted.
+		message := fmt.Sprintf("the name %q is already in use by a database which has not been created by %s", p.Spec.Name, constants.ApplicationName)
+		setPostgresqlDatabaseCondition(p, v1alpha1api.PostgresqlDatabaseStatusConditionTypeCreated, corev1.ConditionFalse, ReasonNameUnavailable, message)
+		setPostgresqlDatabaseCondition(p, v1alpha1api.PostgresqlDatabaseStatusConditionTypeReady, corev1.ConditionFalse, ReasonNameUnavailable, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonNameUnavailable, message)
+		c.logger.WithField(logFieldName, name).Error(message)
+		return nil
+	}
+
+	// Check whether the charset and collation of the database match the ones specified in the PostgresqlDatabase resource.
+	// Since these cannot be changed after the database has been created, we can only report the mismatch.
+	if database.Charset != *p.Spec.Charset || database.Collation != *p.Spec.Collation {
+		message := fmt.Sprintf("the database has charset %q and collation %q (expected %q and %q)", database.Charset, database.Collation, *p.Spec.Charset, *p.Spec.Collation)
+		setPostgresqlDatabaseCondition(p, v1alpha1api.PostgresqlDatabaseStatusConditionTypeReady, corev1.ConditionFalse, ReasonInvalidSpec, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonInvalidSpec, message)
+		c.logger.WithField(logFieldName, name).Error(message)
+		return nil
+	}
+
+	// Update the PostgresqlDatabase resource's conditions to indicate readiness.
+	message := "the database is ready"
+	setPostgresqlDatabaseCondition(p, v1alpha1api.PostgresqlDatabaseStatusConditionTypeReady, corev1.ConditionTrue, ReasonDatabaseReady, message)
+	c.er.Event(p, corev1.EventTypeNormal, ReasonDatabaseReady, message)
+	return nil
+}
+
+// createDatabase attempts to create a database based on the specified PostgresqlDatabase resource.
+func (c *PostgresqlDatabaseController) createDatabase(postgresqlDatabase *v1alpha1api.PostgresqlDatabase, postgresqlInstance *v1alpha1api.PostgresqlInstance) (*cloudsqladmin.Database, error) {
+	c.logger.WithField(logFieldName, postgresqlDatabase.Name).Info("creating database")
+	// Build the Database object based on the specified PostgresqlDatabase resource.
+	database := buildDatabase(postgresqlDatabase, postgresqlInstance)
+	// Attempt to create the Database object.
+	_, err := c.cloudsqlClient.Databases.Insert(c.projectID, database.Instance, database).Do()
+	if err != nil {
+		if google.IsBadRequest(err) {
+			// We've been told that the database's specification is invalid.
+			// This most probably means that the user has specified an invalid value for some field under ".spec" (such as an unsupported charset or collation).
+			// Hence, we log but do not propagate the error, since subsequent attempts to create the database are likely to fail as well until ".spec" is fixed.
+			message := fmt.Sprintf("the database's specification is invalid: %v", err)
+			setPostgresqlDatabaseCondition(postgresqlDatabase, v1alpha1api.PostgresqlDatabaseStatusConditionTypeCreated, corev1.ConditionFalse, ReasonInvalidSpec, message)
+			c.er.Event(postgresqlDatabase, corev1.EventTypeWarning, ReasonInvalidSpec, message)
+			c.logger.WithField(logFieldName, postgresqlDatabase.Name).Error(message)
+			return nil, nil
+		}
+		// The Cloud SQL Admin API returned a different error, which we propagate so that creation may be retried.
+		setPostgresqlDatabaseCondition(postgresqlDatabase, v1alpha1api.PostgresqlDatabaseStatusConditionTypeCreated, corev1.ConditionFalse, ReasonUnexpectedError, err.Error())
+		c.er.Event(postgresqlDatabase, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+		return nil, err
+	}
+	// Update the PostgresqlDatabase resource's conditions.
+	message := "the database has been created"
+	setPostgresqlDatabaseCondition(postgresqlDatabase, v1alpha1api.PostgresqlDatabaseStatusConditionTypeCreated, corev1.ConditionTrue, ReasonDatabaseCreated, message)
+	c.er.Event(postgresqlDatabase, corev1.EventTypeNormal, ReasonDatabaseCreated, message)
+	// Grab and return the most up-to-date representation of the database.
+	return c.cloudsqlClient.Databases.Get(c.projectID, database.Instance, database.Name).Do()
+}
+
+// deleteDatabase attempts to delete the database associated with the specified PostgresqlDatabase resource.
+func (c *PostgresqlDatabaseController) deleteDatabase(postgresqlDatabase *v1alpha1api.PostgresqlDatabase) error {
+	c.logger.WithField(logFieldName, postgresqlDatabase.Name).Debug("checking whether the database needs to be deleted")
+	// If the database has not been created by us, it must not be dropped (e.g. because it belongs to another PostgresqlDatabase resource or has been created out-of-band).
+	if !isPostgresqlDatabaseCreated(postgresqlDatabase) {
+		c.logger.WithField(logFieldName, postgresqlDatabase.Name).Debug("the database has not been created by the current resource")
+		return nil
+	}
+	// Grab the PostgresqlInstance resource that represents the CSQLP instance in which the database was created.
+	// If it no longer exists, the database has been deleted together with the CSQLP instance.
+	i, err := c.postgresqlInstanceLister.Get(postgresqlDatabase.Spec.Instance)
+	if err != nil {
+		if kubeerrors.IsNotFound(err) {
+			c.logger.WithField(logFieldName, postgresqlDatabase.Name).Debug("the instance has already been deleted")
+			return nil
+		}
+		return err
+	}
+	// Before issuing a delete request, make sure the database is still listed.
+	if _, err := c.cloudsqlClient.Databases.Get(c.projectID, i.Spec.Name, postgresqlDatabase.Spec.Name).Do(); err != nil {
+		if google.IsNotFound(err) {
+			c.logger.WithField(logFieldName, postgresqlDatabase.Name).Debug("the database has already been deleted")
+			return nil
+		}
+		return err
+	}
+	c.logger.WithField(logFieldName, postgresqlDatabase.Name).Infof("deleting database %q", postgresqlDatabase.Spec.Name)
+	// At this point we know the database already exists, so we issue the delete request.
+	if _, err := c.cloudsqlClient.Databases.Delete(c.projectID, i.Spec.Name, postgresqlDatabase.Spec.Name).Do(); err != nil {
+		return err
+	}
+	c.logger.WithField(logFieldName, postgresqlDatabase.Name).Debugf("database %q has been deleted", postgresqlDatabase.Spec.Name)
+	return nil
+}
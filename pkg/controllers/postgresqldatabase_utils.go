@@ -0,0 +1,118 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+)
+
+// buildDatabase builds the Database object that corresponds to the specified PostgresqlDatabase resource.
+func buildDatabase(postgresqlDatabase *v1alpha1api.PostgresqlDatabase, postgresqlInstance *v1alpha1api.PostgresqlInstance) *cloudsqladmin.Database {
+	return &cloudsqladmin.Database{
+		Charset:   *postgresqlDatabase.Spec.Charset,
+		Collation: *postgresqlDatabase.Spec.Collation,
+		Instance:  postgresqlInstance.Spec.Name,
+		Name:      postgresqlDatabase.Spec.Name,
+	}
+}
+
+// getPostgresqlDatabaseCondition returns the condition of the provided type associated with the provided PostgresqlDatabase resource, or nil if no such condition exists.
+func getPostgresqlDatabaseCondition(postgresqlDatabase *v1alpha1api.PostgresqlDatabase, conditionType v1alpha1api.PostgresqlDatabaseStatusConditionType) *v1alpha1api.PostgresqlDatabaseStatusCondition {
+	for idx := range postgresqlDatabase.Status.Conditions {
+		if postgresqlDatabase.Status.Conditions[idx].Type == conditionType {
+			return &postgresqlDatabase.Status.Conditions[idx]
+		}
+	}
+	return nil
+}
+
+// isPostgresqlDatabaseCreated returns whether the database represented by the provided PostgresqlDatabase resource has been created by cloudsql-postgres-operator.
+func isPostgresqlDatabaseCreated(postgresqlDatabase *v1alpha1api.PostgresqlDatabase) bool {
+	cdn := getPostgresqlDatabaseCondition(postgresqlDatabase, v1alpha1api.PostgresqlDatabaseStatusConditionTypeCreated)
+	return cdn != nil && cdn.Status == corev1.ConditionTrue
+}
+
+// patchPostgresqlDatabase updates the provided PostgresqlDatabase using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlDatabaseController) patchPostgresqlDatabase(oldObj, newObj *v1alpha1api.PostgresqlDatabase, subresources ...string) (*v1alpha1api.PostgresqlDatabase, error) {
+	// Return if there are no changes to be made.
+	if reflect.DeepEqual(oldObj, newObj) {
+		return newObj, nil
+	}
+	// Prepare the patch to apply based on the provided objects.
+	oldBytes, err := json.Marshal(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newBytes, err := json.Marshal(newObj)
+	if err != nil {
+		return nil, err
+	}
+	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldBytes, newBytes, &v1alpha1api.PostgresqlDatabase{})
+	if err != nil {
+		return nil, err
+	}
+	// Apply the patch.
+	return c.selfClient.CloudsqlV1alpha1().PostgresqlDatabases().Patch(oldObj.Name, types.MergePatchType, patchBytes, subresources...)
+}
+
+// patchPostgresqlDatabaseStatus updates the status of the provided PostgresqlDatabase using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlDatabaseController) patchPostgresqlDatabaseStatus(oldObj, newObj *v1alpha1api.PostgresqlDatabase) (*v1alpha1api.PostgresqlDatabase, error) {
+	return c.patchPostgresqlDatabase(oldObj, newObj, "status")
+}
+
+// setPostgresqlDatabaseCondition sets a condition on the provided PostgresqlDatabase resource according to the following rules:
+// 1. If no condition of the provided type exists, the condition is inserted with its last transition time set to the current time.
+// 2. If a condition of the provided type and state exists, the condition is updated but its last transition time is not modified.
+// 3. If a condition of the provided type but different state exists, the condition is updated and its last transition time is set to the current time.
+func setPostgresqlDatabaseCondition(postgresqlDatabase *v1alpha1api.PostgresqlDatabase, conditionType v1alpha1api.PostgresqlDatabaseStatusConditionType, conditionStatus corev1.ConditionStatus, conditionReason string, conditionMessage string) {
+	// Create the new condition.
+	newCondition := v1alpha1api.PostgresqlDatabaseStatusCondition{
+		LastTransitionTime: v1.NewTime(time.Now()),
+		Message:            conditionMessage,
+		Reason:             conditionReason,
+		Status:             conditionStatus,
+		Type:               conditionType,
+	}
+	// Search through existing conditions in order to understand if we need to insert the new condition or not.
+	for idx, cdn := range postgresqlDatabase.Status.Conditions {
+		// If the current condition's type is different from the one we will be inserting, skip it.
+		if cdn.Type != newCondition.Type {
+			continue
+		}
+		// If the status is the same, we should not update the condition's last transition time.
+		if cdn.Status == newCondition.Status {
+			newCondition.LastTransitionTime = cdn.LastTransitionTime
+		}
+		// Overwrite the existing condition and return.
+		postgresqlDatabase.Status.Conditions[idx] = newCondition
+		return
+	}
+	// At this point we know that there is no existing condition with this type, so we just append it to the set of conditions.
+	postgresqlDatabase.Status.Conditions = append(postgresqlDatabase.Status.Conditions, newCondition)
+}
@@ -0,0 +1,362 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+	storage "google.golang.org/api/storage/v1"
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	v1alpha1client "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/clientset/versioned"
+	v1alpha1informers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/informers/externalversions/cloudsql/v1alpha1"
+	v1alpha1listers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/listers/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/configuration"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/cron"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/google"
+)
+
+const (
+	// postgresqlExportControllerName is the name of the controller for PostgresqlExport resources.
+	postgresqlExportControllerName = "postgresqlexport-controller"
+	// postgresqlExportControllerThreadiness is the number of workers controller for PostgresqlExport resource will use to process items from its work queue.
+	postgresqlExportControllerThreadiness = 1
+)
+
+// PostgresqlExportController is the controller for PostgresqlExport resources.
+type PostgresqlExportController struct {
+	// PostgresqlExportController is based-off of a generic controller.
+	*genericController
+	// cloudsqlClient is a client for the Cloud SQL Admin API.
+	cloudsqlClient *cloudsqladmin.Service
+	// er is an EventRecorder through which we can emit events associated with PostgresqlExport resources.
+	er record.EventRecorder
+	// postgresqlExportLister is a lister for PostgresqlExport resources.
+	postgresqlExportLister v1alpha1listers.PostgresqlExportLister
+	// postgresqlInstanceLister is a lister for PostgresqlInstance resources.
+	postgresqlInstanceLister v1alpha1listers.PostgresqlInstanceLister
+	// projectID is the ID of the GCP project where cloudsql-postgres-operator is managing CSQLP instances.
+	projectID string
+	// selfClient is a client to the "cloudsql.travelaudience.com" API.
+	selfClient v1alpha1client.Interface
+	// storageClient is a client for the Google Cloud Storage API.
+	storageClient *storage.Service
+}
+
+// NewPostgresqlExportController creates a new instance of the controller for PostgresqlExport resources.
+func NewPostgresqlExportController(config configuration.Configuration, selfClient v1alpha1client.Interface, er record.EventRecorder, postgresqlExportInformer v1alpha1informers.PostgresqlExportInformer, postgresqlInstanceInformer v1alpha1informers.PostgresqlInstanceInformer, cloudsqlClient *cloudsqladmin.Service, storageClient *storage.Service) *PostgresqlExportController {
+	// Create a new instance of the controller for PostgresqlExport resources using the specified name and threadiness.
+	c := &PostgresqlExportController{
+		cloudsqlClient:           cloudsqlClient,
+		genericController:        newGenericController(postgresqlExportControllerName, postgresqlExportControllerThreadiness),
+		er:                       er,
+		postgresqlExportLister:   postgresqlExportInformer.Lister(),
+		postgresqlInstanceLister: postgresqlInstanceInformer.Lister(),
+		projectID:                config.GCP.ProjectID,
+		selfClient:               selfClient,
+		storageClient:            storageClient,
+	}
+	// Make the controller wait for the caches to sync.
+	c.hasSyncedFuncs = []cache.InformerSynced{
+		postgresqlExportInformer.Informer().HasSynced,
+		postgresqlInstanceInformer.Informer().HasSynced,
+	}
+	// Make "processQueueItem" the handler for items popped out of the work queue.
+	c.syncHandler = c.processQueueItem
+
+	// Setup an event handler to inform us when PostgresqlExport resources change.
+	postgresqlExportInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			c.enqueue(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+	})
+
+	// Return the instance of the controller for PostgresqlExport resources created above.
+	return c
+}
+
+// processQueueItem attempts to reconcile the state of the PostgresqlExport resource pointed at by the specified key.
+// Since export runs are only checked for whenever the PostgresqlExport resource is processed, scheduled export runs may start up to one resync period later than specified.
+func (c *PostgresqlExportController) processQueueItem(key string) (err error) {
+	// Grab the name of the PostgresqlExport resource from the specified key.
+	// NOTE: PostgresqlExport is cluster-scoped, and hence there is no associated namespace.
+	_, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("invalid resource key %q", key))
+		return nil
+	}
+
+	// Get the PostgresqlExport resource with the specified name.
+	r, err := c.postgresqlExportLister.Get(name)
+	if err != nil {
+		// The PostgresqlExport may no longer exist, in which case we stop processing.
+		if kubeerrors.IsNotFound(err) {
+			c.logger.WithField(logFieldName, name).Debug("postgresqlexport resource in work queue no longer exists")
+			return nil
+		}
+		return err
+	}
+	// If the PostgresqlExport resource is being deleted there is nothing to do, as deleting it has no effect on existing export files.
+	if !r.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	// Create a deep copy of the PostgresqlExport resource so we don't possibly mutate the cache.
+	p := r.DeepCopy()
+
+	// Make sure that the PostgresqlExport resource's ".status" field is always updated as the last processing step.
+	// If an error occurs during the update, it is aggregated with the error we would be returning (if any).
+	defer func() {
+		if _, patchErr := c.patchPostgresqlExportStatus(r, p); patchErr != nil {
+			err = utilerrors.NewAggregate([]error{patchErr, err})
+		}
+	}()
+
+	// If there is no export run in progress, check whether one is due and start it if necessary.
+	if p.Status.CurrentRun == nil {
+		if due := c.isExportRunDue(p, time.Now()); !due {
+			return nil
+		}
+		// Export runs can only be started for CSQLP instances that are ready, so we skip further processing (but don't error) otherwise.
+		if i, err := c.getPostgresqlInstance(p); i == nil || err != nil {
+			return err
+		}
+		// Start a new export run, and compute the time at which the next one will be performed if necessary.
+		now := time.Now()
+		p.Status.CurrentRun = &v1alpha1api.PostgresqlExportStatusRun{
+			StartTime: metav1.NewTime(now),
+		}
+		if p.Spec.Schedule != "" {
+			s, _ := cron.Parse(p.Spec.Schedule)
+			next := metav1.NewTime(s.Next(now))
+			p.Status.NextRunTime = &next
+		}
+		message := fmt.Sprintf("the export run has been started (databases: %q)", p.Spec.Databases)
+		setPostgresqlExportCondition(p, v1alpha1api.PostgresqlExportStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonExportStarted, message)
+		c.er.Event(p, corev1.EventTypeNormal, ReasonExportStarted, message)
+		c.logger.WithField(logFieldName, name).Info(message)
+	}
+
+	// Start exporting the next database if there is no export operation in progress.
+	// If the list of databases has been shortened in the meantime and there is nothing left to export, the export run is completed right away.
+	if p.Status.CurrentRun.OperationID == "" && len(p.Status.CurrentRun.Files) >= len(p.Spec.Databases) {
+		return c.completeExportRun(p)
+	}
+	if p.Status.CurrentRun.OperationID == "" {
+		// Grab the PostgresqlInstance resource that represents the CSQLP instance whose databases are being exported.
+		i, err := c.getPostgresqlInstance(p)
+		if i == nil || err != nil {
+			return err
+		}
+		// Start the export operation.
+		// Any permanent error has already been reported, so we stop processing in that case.
+		if started, err := c.startExport(p, i); !started || err != nil {
+			return err
+		}
+	}
+
+	// Grab the operation that performs the export in order to check whether it has finished.
+	c.logger.WithField(logFieldName, name).Debugf("checking the status of operation %q", p.Status.CurrentRun.OperationID)
+	op, err := c.cloudsqlClient.Operations.Get(c.projectID, p.Status.CurrentRun.OperationID).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get operation %q: %v", p.Status.CurrentRun.OperationID, err)
+	}
+
+	// Check whether the operation is still in progress or has failed.
+	// If it is still in progress, we skip further processing (but don't error), and the status of the operation will be checked again after the controller's resync period elapses.
+	operationInProgressOrFailed, operationID, _, operationStatus, operationErrorMessage := isOperationInProgressOrFailedFromOperation(op)
+	switch {
+	case operationInProgressOrFailed && operationErrorMessage == "":
+		message := fmt.Sprintf("the export run is in progress (operation: %q, status: %q)", operationID, operationStatus)
+		setPostgresqlExportCondition(p, v1alpha1api.PostgresqlExportStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonExportInProgress, message)
+		c.logger.WithField(logFieldName, name).Debug(message)
+		return nil
+	case operationInProgressOrFailed && operationErrorMessage != "":
+		message := fmt.Sprintf("the export run has failed (operation: %q, errors: %q)", operationID, operationErrorMessage)
+		c.failExportRun(p, message)
+		return nil
+	}
+
+	// At this point we know that the current database has been exported successfully, so we record the resulting export file.
+	file := postgresqlExportFileURI(p, p.Status.CurrentRun)
+	p.Status.CurrentRun.Files = append(p.Status.CurrentRun.Files, file)
+	p.Status.CurrentRun.OperationID = ""
+	p.Status.LastExportFile = file
+	c.logger.WithField(logFieldName, name).Infof("exported database to %q", file)
+
+	// If there are databases left to export, start exporting the next one right away.
+	if len(p.Status.CurrentRun.Files) < len(p.Spec.Databases) {
+		i, err := c.getPostgresqlInstance(p)
+		if i == nil || err != nil {
+			return err
+		}
+		_, err = c.startExport(p, i)
+		return err
+	}
+
+	// At this point we know that all databases have been exported.
+	return c.completeExportRun(p)
+}
+
+// completeExportRun marks the export run currently in progress for the specified PostgresqlExport resource as completed and enforces the retention policy.
+func (c *PostgresqlExportController) completeExportRun(postgresqlExport *v1alpha1api.PostgresqlExport) error {
+	postgresqlExport.Status.Runs = append(postgresqlExport.Status.Runs, *postgresqlExport.Status.CurrentRun)
+	postgresqlExport.Status.CurrentRun = nil
+	message := fmt.Sprintf("the export run has completed (last export file: %q)", postgresqlExport.Status.LastExportFile)
+	setPostgresqlExportCondition(postgresqlExport, v1alpha1api.PostgresqlExportStatusConditionTypeCompleted, corev1.ConditionTrue, ReasonExportCompleted, message)
+	c.er.Event(postgresqlExport, corev1.EventTypeNormal, ReasonExportCompleted, message)
+	c.logger.WithField(logFieldName, postgresqlExport.Name).Info(message)
+	return c.enforceRetention(postgresqlExport)
+}
+
+// enforceRetention deletes the export files of the oldest export runs recorded in the status of the specified PostgresqlExport resource so that no more than the configured number of export runs is kept.
+func (c *PostgresqlExportController) enforceRetention(postgresqlExport *v1alpha1api.PostgresqlExport) error {
+	// A retention of zero means that the files of all export runs are kept.
+	if postgresqlExport.Spec.Retention == nil || *postgresqlExport.Spec.Retention == 0 {
+		return nil
+	}
+	for len(postgresqlExport.Status.Runs) > int(*postgresqlExport.Spec.Retention) {
+		for _, file := range postgresqlExport.Status.Runs[0].Files {
+			bucket, object, err := parseGCSURI(file)
+			if err != nil {
+				return err
+			}
+			c.logger.WithField(logFieldName, postgresqlExport.Name).Debugf("deleting export file %q", file)
+			// Attempt to delete the export file, ignoring the error in case it has already been deleted.
+			if err := c.storageClient.Objects.Delete(bucket, object).Do(); err != nil && !google.IsNotFound(err) {
+				return fmt.Errorf("failed to delete export file %q: %v", file, err)
+			}
+		}
+		// Remove the export run from the list of export runs being kept only after all its files have been deleted, so that deletion is retried in case of error.
+		postgresqlExport.Status.Runs = postgresqlExport.Status.Runs[1:]
+	}
+	return nil
+}
+
+// failExportRun marks the export run currently in progress for the specified PostgresqlExport resource as failed.
+func (c *PostgresqlExportController) failExportRun(postgresqlExport *v1alpha1api.PostgresqlExport, message string) {
+	postgresqlExport.Status.CurrentRun = nil
+	setPostgresqlExportCondition(postgresqlExport, v1alpha1api.PostgresqlExportStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonExportFailed, message)
+	c.er.Event(postgresqlExport, corev1.EventTypeWarning, ReasonExportFailed, message)
+	c.logger.WithField(logFieldName, postgresqlExport.Name).Error(message)
+}
+
+// getPostgresqlInstance returns the PostgresqlInstance resource referenced by the specified PostgresqlExport resource.
+// In case the PostgresqlInstance resource does not exist or is not ready, the condition is reported on the specified PostgresqlExport resource and nil is returned.
+func (c *PostgresqlExportController) getPostgresqlInstance(postgresqlExport *v1alpha1api.PostgresqlExport) (*v1alpha1api.PostgresqlInstance, error) {
+	i, err := c.postgresqlInstanceLister.Get(postgresqlExport.Spec.Instance)
+	if err != nil {
+		// If we've got an error other than "404 NOT FOUND", we stop processing and propagate it.
+		if !kubeerrors.IsNotFound(err) {
+			return nil, err
+		}
+		// At this point we know that the PostgresqlInstance resource does not exist, so we report it and skip further processing (but don't error).
+		message := fmt.Sprintf("postgresqlinstance %q does not exist", postgresqlExport.Spec.Instance)
+		setPostgresqlExportCondition(postgresqlExport, v1alpha1api.PostgresqlExportStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+		c.er.Event(postgresqlExport, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+		c.logger.WithField(logFieldName, postgresqlExport.Name).Infof("skipping sync because %s", message)
+		return nil, nil
+	}
+	if cdn := getPostgresqlInstanceCondition(i, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady); cdn == nil || cdn.Status != corev1.ConditionTrue {
+		message := fmt.Sprintf("postgresqlinstance %q is not ready", postgresqlExport.Spec.Instance)
+		setPostgresqlExportCondition(postgresqlExport, v1alpha1api.PostgresqlExportStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+		c.er.Event(postgresqlExport, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+		c.logger.WithField(logFieldName, postgresqlExport.Name).Infof("skipping sync because %s", message)
+		return nil, nil
+	}
+	return i, nil
+}
+
+// isExportRunDue indicates whether a new export run should be started for the specified PostgresqlExport resource at the specified time.
+func (c *PostgresqlExportController) isExportRunDue(postgresqlExport *v1alpha1api.PostgresqlExport, now time.Time) bool {
+	// If no schedule has been specified, a single export run is performed.
+	if postgresqlExport.Spec.Schedule == "" {
+		cdn := getPostgresqlExportCondition(postgresqlExport, v1alpha1api.PostgresqlExportStatusConditionTypeCompleted)
+		return cdn == nil || (cdn.Reason != ReasonExportCompleted && cdn.Reason != ReasonExportFailed)
+	}
+	// Parse the schedule.
+	// This should never fail as the schedule has been validated by the admission webhook, but we report it just in case.
+	s, err := cron.Parse(postgresqlExport.Spec.Schedule)
+	if err != nil {
+		message := fmt.Sprintf("the schedule is invalid: %v", err)
+		setPostgresqlExportCondition(postgresqlExport, v1alpha1api.PostgresqlExportStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonInvalidSpec, message)
+		c.er.Event(postgresqlExport, corev1.EventTypeWarning, ReasonInvalidSpec, message)
+		c.logger.WithField(logFieldName, postgresqlExport.Name).Error(message)
+		return false
+	}
+	// Compute the time at which the next export run should be performed.
+	// This is also done when the schedule has been changed so that the new schedule causes an earlier export run.
+	if next := s.Next(now); postgresqlExport.Status.NextRunTime == nil || next.Before(postgresqlExport.Status.NextRunTime.Time) {
+		nextRunTime := metav1.NewTime(next)
+		postgresqlExport.Status.NextRunTime = &nextRunTime
+		return false
+	}
+	return !now.Before(postgresqlExport.Status.NextRunTime.Time)
+}
+
+// startExport attempts to start exporting the next database in the export run currently in progress for the specified PostgresqlExport resource.
+// It returns a boolean value indicating whether the export operation has been started.
+func (c *PostgresqlExportController) startExport(postgresqlExport *v1alpha1api.PostgresqlExport, postgresqlInstance *v1alpha1api.PostgresqlInstance) (bool, error) {
+	database := postgresqlExport.Spec.Databases[len(postgresqlExport.Status.CurrentRun.Files)]
+	file := postgresqlExportFileURI(postgresqlExport, postgresqlExport.Status.CurrentRun)
+	c.logger.WithField(logFieldName, postgresqlExport.Name).Infof("exporting database %q of %q to %q", database, postgresqlInstance.Spec.Name, file)
+	// Build the export context based on the specification of the PostgresqlExport resource.
+	ctx := &cloudsqladmin.ExportContext{
+		Databases: []string{database},
+		FileType:  postgresqlExport.Spec.FileType.APIValue(),
+		Uri:       file,
+	}
+	if postgresqlExport.Spec.CSV != nil {
+		ctx.CsvExportOptions = &cloudsqladmin.ExportContextCsvExportOptions{
+			SelectQuery: postgresqlExport.Spec.CSV.SelectQuery,
+		}
+	}
+	// Attempt to start the export operation.
+	op, err := c.cloudsqlClient.Instances.Export(c.projectID, postgresqlInstance.Spec.Name, &cloudsqladmin.InstancesExportRequest{
+		ExportContext: ctx,
+	}).Do()
+	if err != nil {
+		if google.IsBadRequest(err) {
+			// We've been told that the export operation cannot be started.
+			// This most probably means that the database does not exist or that the destination is not writable.
+			// Hence, we log but do not propagate the error, and mark the export run as failed since subsequent attempts are likely to fail as well.
+			c.failExportRun(postgresqlExport, fmt.Sprintf("the export operation cannot be started: %v", err))
+			return false, nil
+		}
+		// The Cloud SQL Admin API returned a different error, which we propagate so that the export operation may be retried.
+		setPostgresqlExportCondition(postgresqlExport, v1alpha1api.PostgresqlExportStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonUnexpectedError, err.Error())
+		c.er.Event(postgresqlExport, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+		return false, err
+	}
+	// Record the ID of the operation so that its status can be tracked.
+	postgresqlExport.Status.CurrentRun.OperationID = op.Name
+	return true, nil
+}
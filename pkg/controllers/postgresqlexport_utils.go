@@ -0,0 +1,123 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+)
+
+// getPostgresqlExportCondition returns the condition of the provided type associated with the provided PostgresqlExport resource, or nil if no such condition exists.
+func getPostgresqlExportCondition(postgresqlExport *v1alpha1api.PostgresqlExport, conditionType v1alpha1api.PostgresqlExportStatusConditionType) *v1alpha1api.PostgresqlExportStatusCondition {
+	for idx := range postgresqlExport.Status.Conditions {
+		if postgresqlExport.Status.Conditions[idx].Type == conditionType {
+			return &postgresqlExport.Status.Conditions[idx]
+		}
+	}
+	return nil
+}
+
+// patchPostgresqlExport updates the provided PostgresqlExport using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlExportController) patchPostgresqlExport(oldObj, newObj *v1alpha1api.PostgresqlExport, subresources ...string) (*v1alpha1api.PostgresqlExport, error) {
+	// Return if there are no changes to be made.
+	if reflect.DeepEqual(oldObj, newObj) {
+		return newObj, nil
+	}
+	// Prepare the patch to apply based on the provided objects.
+	oldBytes, err := json.Marshal(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newBytes, err := json.Marshal(newObj)
+	if err != nil {
+		return nil, err
+	}
+	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldBytes, newBytes, &v1alpha1api.PostgresqlExport{})
+	if err != nil {
+		return nil, err
+	}
+	// Apply the patch.
+	return c.selfClient.CloudsqlV1alpha1().PostgresqlExports().Patch(oldObj.Name, types.MergePatchType, patchBytes, subresources...)
+}
+
+// patchPostgresqlExportStatus updates the status of the provided PostgresqlExport using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlExportController) patchPostgresqlExportStatus(oldObj, newObj *v1alpha1api.PostgresqlExport) (*v1alpha1api.PostgresqlExport, error) {
+	return c.patchPostgresqlExport(oldObj, newObj, "status")
+}
+
+// setPostgresqlExportCondition sets a condition on the provided PostgresqlExport resource according to the following rules:
+// 1. If no condition of the provided type exists, the condition is inserted with its last transition time set to the current time.
+// 2. If a condition of the provided type and state exists, the condition is updated but its last transition time is not modified.
+// 3. If a condition of the provided type but different state exists, the condition is updated and its last transition time is set to the current time.
+func setPostgresqlExportCondition(postgresqlExport *v1alpha1api.PostgresqlExport, conditionType v1alpha1api.PostgresqlExportStatusConditionType, conditionStatus corev1.ConditionStatus, conditionReason string, conditionMessage string) {
+	// Create the new condition.
+	newCondition := v1alpha1api.PostgresqlExportStatusCondition{
+		LastTransitionTime: v1.NewTime(time.Now()),
+		Message:            conditionMessage,
+		Reason:             conditionReason,
+		Status:             conditionStatus,
+		Type:               conditionType,
+	}
+	// Search through existing conditions in order to understand if we need to insert the new condition or not.
+	for idx, cdn := range postgresqlExport.Status.Conditions {
+		// If the current condition's type is different from the one we will be inserting, skip it.
+		if cdn.Type != newCondition.Type {
+			continue
+		}
+		// If the status is the same, we should not update the condition's last transition time.
+		if cdn.Status == newCondition.Status {
+			newCondition.LastTransitionTime = cdn.LastTransitionTime
+		}
+		// Overwrite the existing condition and return.
+		postgresqlExport.Status.Conditions[idx] = newCondition
+		return
+	}
+	// At this point we know that there is no existing condition with this type, so we just append it to the set of conditions.
+	postgresqlExport.Status.Conditions = append(postgresqlExport.Status.Conditions, newCondition)
+}
+
+// parseGCSURI splits the specified Google Cloud Storage URI (in the "gs://<bucket>/<object>" format) into the name of the bucket and the name of the object.
+func parseGCSURI(uri string) (string, string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(uri, "gs://"), "/", 2)
+	if !strings.HasPrefix(uri, "gs://") || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid google cloud storage uri %q", uri)
+	}
+	return parts[0], parts[1], nil
+}
+
+// postgresqlExportFileURI returns the Google Cloud Storage URI of the export file for the next database to be exported in the specified export run of the provided PostgresqlExport resource.
+// Export files are written to "<destination>/<name>/<start time>/<database>.<extension>".
+func postgresqlExportFileURI(postgresqlExport *v1alpha1api.PostgresqlExport, run *v1alpha1api.PostgresqlExportStatusRun) string {
+	return fmt.Sprintf("%s/%s/%s/%s.%s",
+		strings.TrimSuffix(postgresqlExport.Spec.Destination, "/"),
+		postgresqlExport.Name,
+		run.StartTime.UTC().Format("20060102T150405Z"),
+		postgresqlExport.Spec.Databases[len(run.Files)],
+		postgresqlExport.Spec.FileType.Extension())
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	v1alpha1client "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/clientset/versioned"
+	v1alpha1informers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/informers/externalversions/cloudsql/v1alpha1"
+	v1alpha1listers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/listers/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/configuration"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/google"
+)
+
+const (
+	// postgresqlImportControllerName is the name of the controller for PostgresqlImport resources.
+	postgresqlImportControllerName = "postgresqlimport-controller"
+	// postgresqlImportControllerThreadiness is the number of workers controller for PostgresqlImport resource will use to process items from its work queue.
+	postgresqlImportControllerThreadiness = 1
+)
+
+// PostgresqlImportController is the controller for PostgresqlImport resources.
+type PostgresqlImportController struct {
+	// PostgresqlImportController is based-off of a generic controller.
+	*genericController
+	// cloudsqlClient is a client for the Cloud SQL Admin API.
+	cloudsqlClient *cloudsqladmin.Service
+	// er is an EventRecorder through which we can emit events associated with PostgresqlImport resources.
+	er record.EventRecorder
+	// postgresqlImportLister is a lister for PostgresqlImport resources.
+	postgresqlImportLister v1alpha1listers.PostgresqlImportLister
+	// postgresqlInstanceLister is a lister for PostgresqlInstance resources.
+	postgresqlInstanceLister v1alpha1listers.PostgresqlInstanceLister
+	// projectID is the ID of the GCP project where cloudsql-postgres-operator is managing CSQLP instances.
+	projectID string
+	// selfClient is a client to the "cloudsql.travelaudience.com" API.
+	selfClient v1alpha1client.Interface
+}
+
+// NewPostgresqlImportController creates a new instance of the controller for PostgresqlImport resources.
+func NewPostgresqlImportController(config configuration.Configuration, selfClient v1alpha1client.Interface, er record.EventRecorder, postgresqlImportInformer v1alpha1informers.PostgresqlImportInformer, postgresqlInstanceInformer v1alpha1informers.PostgresqlInstanceInformer, cloudsqlClient *cloudsqladmin.Service) *PostgresqlImportController {
+	// Create a new instance of the controller for PostgresqlImport resources using the specified name and threadiness.
+	c := &PostgresqlImportController{
+		cloudsqlClient:           cloudsqlClient,
+		genericController:        newGenericController(postgresqlImportControllerName, postgresqlImportControllerThreadiness),
+		er:                       er,
+		postgresqlImportLister:   postgresqlImportInformer.Lister(),
+		postgresqlInstanceLister: postgresqlInstanceInformer.Lister(),
+		projectID:                config.GCP.ProjectID,
+		selfClient:               selfClient,
+	}
+	// Make the controller wait for the caches to sync.
+	c.hasSyncedFuncs = []cache.InformerSynced{
+		postgresqlImportInformer.Informer().HasSynced,
+		postgresqlInstanceInformer.Informer().HasSynced,
+	}
+	// Make "processQueueItem" the handler for items popped out of the work queue.
+	c.syncHandler = c.processQueueItem
+
+	// Setup an event handler to inform us when PostgresqlImport resources change.
+	postgresqlImportInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			c.enqueue(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+	})
+
+	// Return the instance of the controller for PostgresqlImport resources created above.
+	return c
+}
+
+// processQueueItem attempts to reconcile the state of the PostgresqlImport resource pointed at by the specified key.
+func (c *PostgresqlImportController) processQueueItem(key string) (err error) {
+	// Grab the name of the PostgresqlImport resource from the specified key.
+	// NOTE: PostgresqlImport is cluster-scoped, and hence there is no associated namespace.
+	_, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("invalid resource key %q", key))
+		return nil
+	}
+
+	// Get the PostgresqlImport resource with the specified name.
+	r, err := c.postgresqlImportLister.Get(name)
+	if err != nil {
+		// The PostgresqlImport may no longer exist, in which case we stop processing.
+		if kubeerrors.IsNotFound(err) {
+			c.logger.WithField(logFieldName, name).Debug("postgresqlimport resource in work queue no longer exists")
+			return nil
+		}
+		return err
+	}
+	// If the PostgresqlImport resource is being deleted there is nothing to do, as deleting it has no effect on the target CSQLP instance.
+	if !r.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	// Create a deep copy of the PostgresqlImport resource so we don't possibly mutate the cache.
+	p := r.DeepCopy()
+
+	// Make sure that the PostgresqlImport resource's ".status" field is always updated as the last processing step.
+	// If an error occurs during the update, it is aggregated with the error we would be returning (if any).
+	defer func() {
+		if _, patchErr := c.patchPostgresqlImportStatus(r, p); patchErr != nil {
+			err = utilerrors.NewAggregate([]error{patchErr, err})
+		}
+	}()
+
+	// If the import operation has already finished (either successfully or not), there is nothing else to do.
+	if cdn := getPostgresqlImportCondition(p, v1alpha1api.PostgresqlImportStatusConditionTypeCompleted); cdn != nil && (cdn.Reason == ReasonImportCompleted || cdn.Reason == ReasonImportFailed) {
+		c.logger.WithField(logFieldName, name).Debug("the import operation has already finished")
+		return nil
+	}
+
+	// Check whether the import operation has already been started, and start it if necessary.
+	if p.Status.OperationID == "" {
+		// Grab the PostgresqlInstance resource that represents the target CSQLP instance.
+		i, err := c.postgresqlInstanceLister.Get(p.Spec.Instance)
+		if err != nil {
+			// If we've got an error other than "404 NOT FOUND", we stop processing and propagate it.
+			if !kubeerrors.IsNotFound(err) {
+				return err
+			}
+			// At this point we know that the PostgresqlInstance resource does not exist, so we report it and skip further processing (but don't error).
+			message := fmt.Sprintf("postgresqlinstance %q does not exist", p.Spec.Instance)
+			setPostgresqlImportCondition(p, v1alpha1api.PostgresqlImportStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+			c.er.Event(p, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+			c.logger.WithField(logFieldName, name).Infof("skipping sync because %s", message)
+			return nil
+		}
+		// Files can only be imported into a CSQLP instance that is ready, so we skip further processing (but don't error) otherwise.
+		if cdn := getPostgresqlInstanceCondition(i, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady); cdn == nil || cdn.Status != corev1.ConditionTrue {
+			message := fmt.Sprintf("postgresqlinstance %q is not ready", p.Spec.Instance)
+			setPostgresqlImportCondition(p, v1alpha1api.PostgresqlImportStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+			c.er.Event(p, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+			c.logger.WithField(logFieldName, name).Infof("skipping sync because %s", message)
+			return nil
+		}
+		// Start the import operation.
+		// Any permanent error has already been reported, so we stop processing in that case.
+		if started, err := c.startImport(p, i); !started || err != nil {
+			return err
+		}
+	}
+
+	// Grab the operation that performs the import in order to check whether it has finished.
+	c.logger.WithField(logFieldName, name).Debugf("checking the status of operation %q", p.Status.OperationID)
+	op, err := c.cloudsqlClient.Operations.Get(c.projectID, p.Status.OperationID).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get operation %q: %v", p.Status.OperationID, err)
+	}
+
+	// Check whether the operation is still in progress or has failed.
+	// If it is still in progress, we skip further processing (but don't error), and the status of the operation will be checked again after the controller's resync period elapses.
+	operationInProgressOrFailed, operationID, _, operationStatus, operationErrorMessage := isOperationInProgressOrFailedFromOperation(op)
+	switch {
+	case operationInProgressOrFailed && operationErrorMessage == "":
+		message := fmt.Sprintf("the import operation is in progress (operation: %q, status: %q)", operationID, operationStatus)
+		setPostgresqlImportCondition(p, v1alpha1api.PostgresqlImportStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonImportInProgress, message)
+		c.logger.WithField(logFieldName, name).Debug(message)
+		return nil
+	case operationInProgressOrFailed && operationErrorMessage != "":
+		message := fmt.Sprintf("the import operation has failed (operation: %q, errors: %q)", operationID, operationErrorMessage)
+		setPostgresqlImportCondition(p, v1alpha1api.PostgresqlImportStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonImportFailed, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonImportFailed, message)
+		c.logger.WithField(logFieldName, name).Error(message)
+		return nil
+	}
+
+	// Update the PostgresqlImport resource's conditions to indicate completion.
+	message := fmt.Sprintf("the import operation has completed (operation: %q)", op.Name)
+	setPostgresqlImportCondition(p, v1alpha1api.PostgresqlImportStatusConditionTypeCompleted, corev1.ConditionTrue, ReasonImportCompleted, message)
+	c.er.Event(p, corev1.EventTypeNormal, ReasonImportCompleted, message)
+	c.logger.WithField(logFieldName, name).Info(message)
+	return nil
+}
+
+// startImport attempts to import the file referenced by the specified PostgresqlImport resource into the specified CSQLP instance.
+// It returns a boolean value indicating whether the import operation has been started.
+func (c *PostgresqlImportController) startImport(postgresqlImport *v1alpha1api.PostgresqlImport, postgresqlInstance *v1alpha1api.PostgresqlInstance) (bool, error) {
+	c.logger.WithField(logFieldName, postgresqlImport.Name).Infof("importing %q into database %q of %q", postgresqlImport.Spec.Source, postgresqlImport.Spec.Database, postgresqlInstance.Spec.Name)
+	// Build the import context based on the specification of the PostgresqlImport resource.
+	ctx := &cloudsqladmin.ImportContext{
+		Database:   postgresqlImport.Spec.Database,
+		FileType:   postgresqlImport.Spec.FileType.APIValue(),
+		ImportUser: postgresqlImport.Spec.User,
+		Uri:        postgresqlImport.Spec.Source,
+	}
+	if postgresqlImport.Spec.CSV != nil {
+		ctx.CsvImportOptions = &cloudsqladmin.ImportContextCsvImportOptions{
+			Columns: postgresqlImport.Spec.CSV.Columns,
+			Table:   postgresqlImport.Spec.CSV.Table,
+		}
+	}
+	// Attempt to start the import operation.
+	op, err := c.cloudsqlClient.Instances.Import(c.projectID, postgresqlInstance.Spec.Name, &cloudsqladmin.InstancesImportRequest{
+		ImportContext: ctx,
+	}).Do()
+	if err != nil {
+		if google.IsBadRequest(err) {
+			// We've been told that the import operation cannot be started.
+			// This most probably means that the database or the file does not exist, or that the file cannot be read by the CSQLP instance.
+			// Hence, we log but do not propagate the error, and mark the import operation as failed since subsequent attempts are likely to fail as well.
+			message := fmt.Sprintf("the import operation cannot be started: %v", err)
+			setPostgresqlImportCondition(postgresqlImport, v1alpha1api.PostgresqlImportStatusConditionTypeStarted, corev1.ConditionFalse, ReasonInvalidSpec, message)
+			setPostgresqlImportCondition(postgresqlImport, v1alpha1api.PostgresqlImportStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonImportFailed, message)
+			c.er.Event(postgresqlImport, corev1.EventTypeWarning, ReasonInvalidSpec, message)
+			c.logger.WithField(logFieldName, postgresqlImport.Name).Error(message)
+			return false, nil
+		}
+		// The Cloud SQL Admin API returned a different error, which we propagate so that the import operation may be retried.
+		setPostgresqlImportCondition(postgresqlImport, v1alpha1api.PostgresqlImportStatusConditionTypeStarted, corev1.ConditionFalse, ReasonUnexpectedError, err.Error())
+		c.er.Event(postgresqlImport, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+		return false, err
+	}
+	// Record the ID of the operation so that its status can be tracked.
+	postgresqlImport.Status.OperationID = op.Name
+	// Update the PostgresqlImport resource's conditions.
+	message := fmt.Sprintf("the import operation has been started (operation: %q)", op.Name)
+	setPostgresqlImportCondition(postgresqlImport, v1alpha1api.PostgresqlImportStatusConditionTypeStarted, corev1.ConditionTrue, ReasonImportStarted, message)
+	c.er.Event(postgresqlImport, corev1.EventTypeNormal, ReasonImportStarted, message)
+	return true, nil
+}
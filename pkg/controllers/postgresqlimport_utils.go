@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+)
+
+// getPostgresqlImportCondition returns the condition of the provided type associated with the provided PostgresqlImport resource, or nil if no such condition exists.
+func getPostgresqlImportCondition(postgresqlImport *v1alpha1api.PostgresqlImport, conditionType v1alpha1api.PostgresqlImportStatusConditionType) *v1alpha1api.PostgresqlImportStatusCondition {
+	for idx := range postgresqlImport.Status.Conditions {
+		if postgresqlImport.Status.Conditions[idx].Type == conditionType {
+			return &postgresqlImport.Status.Conditions[idx]
+		}
+	}
+	return nil
+}
+
+// patchPostgresqlImport updates the provided PostgresqlImport using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlImportController) patchPostgresqlImport(oldObj, newObj *v1alpha1api.PostgresqlImport, subresources ...string) (*v1alpha1api.PostgresqlImport, error) {
+	// Return if there are no changes to be made.
+	if reflect.DeepEqual(oldObj, newObj) {
+		return newObj, nil
+	}
+	// Prepare the patch to apply based on the provided objects.
+	oldBytes, err := json.Marshal(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newBytes, err := json.Marshal(newObj)
+	if err != nil {
+		return nil, err
+	}
+	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldBytes, newBytes, &v1alpha1api.PostgresqlImport{})
+	if err != nil {
+		return nil, err
+	}
+	// Apply the patch.
+	return c.selfClient.CloudsqlV1alpha1().PostgresqlImports().Patch(oldObj.Name, types.MergePatchType, patchBytes, subresources...)
+}
+
+// patchPostgresqlImportStatus updates the status of the provided PostgresqlImport using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlImportController) patchPostgresqlImportStatus(oldObj, newObj *v1alpha1api.PostgresqlImport) (*v1alpha1api.PostgresqlImport, error) {
+	return c.patchPostgresqlImport(oldObj, newObj, "status")
+}
+
+// setPostgresqlImportCondition sets a condition on the provided PostgresqlImport resource according to the following rules:
+// 1. If no condition of the provided type exists, the condition is inserted with its last transition time set to the current time.
+// 2. If a condition of the provided type and state exists, the condition is updated but its last transition time is not modified.
+// 3. If a condition of the provided type but different state exists, the condition is updated and its last transition time is set to the current time.
+func setPostgresqlImportCondition(postgresqlImport *v1alpha1api.PostgresqlImport, conditionType v1alpha1api.PostgresqlImportStatusConditionType, conditionStatus corev1.ConditionStatus, conditionReason string, conditionMessage string) {
+	// Create the new condition.
+	newCondition := v1alpha1api.PostgresqlImportStatusCondition{
+		LastTransitionTime: v1.NewTime(time.Now()),
+		Message:            conditionMessage,
+		Reason:             conditionReason,
+		Status:             conditionStatus,
+		Type:               conditionType,
+	}
+	// Search through existing conditions in order to understand if we need to insert the new condition or not.
+	for idx, cdn := range postgresqlImport.Status.Conditions {
+		// If the current condition's type is different from the one we will be inserting, skip it.
+		if cdn.Type != newCondition.Type {
+			continue
+		}
+		// If the status is the same, we should not update the condition's last transition time.
+		if cdn.Status == newCondition.Status {
+			newCondition.LastTransitionTime = cdn.LastTransitionTime
+		}
+		// Overwrite the existing condition and return.
+		postgresqlImport.Status.Conditions[idx] = newCondition
+		return
+	}
+	// At this point we know that there is no existing condition with this type, so we just append it to the set of conditions.
+	postgresqlImport.Status.Conditions = append(postgresqlImport.Status.Conditions, newCondition)
+}
@@ -17,12 +17,16 @@ limitations under the License.
 package controllers
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 
 	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
 	corev1 "k8s.io/api/core/v1"
 	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes"
@@ -45,6 +49,10 @@ import (
 const (
 	// logFieldName is the name of the "name" log field.
 	logFieldName = "name"
+	// deletionProtectionCheckInterval is the amount of time after which a PostgresqlInstance resource whose CSQLP instance is having deletion protection lifted is processed again.
+	deletionProtectionCheckInterval = 30 * time.Second
+	// finalBackupCheckInterval is the amount of time after which a PostgresqlInstance resource whose final backup has not completed yet is processed again.
+	finalBackupCheckInterval = 30 * time.Second
 	// postgresqlInstanceControllerName is the name of the controller for PostgresqlInstance resources.
 	postgresqlInstanceControllerName = "postgresqlinstance-controller"
 	// postgresqlInstanceControllerThreadiness is the number of workers controller for PostgresqlInstance resource will use to process items from its work queue.
@@ -53,6 +61,10 @@ const (
 	passwordLength = 36
 	// passwordAlphabet is the alphabet used to generate the random password for CSQLP instances.
 	passwordAlphabet = `abcdefghijklmnopqrstuvwxyz0123456789~!@#$%^&*()_-+={[}]|\:;"'<,>.?/`
+	// serverCAExpiryWarningThreshold is the amount of time before the expiry of a server CA certificate from which it is reported as about to expire (and rotated, if requested).
+	serverCAExpiryWarningThreshold = 30 * 24 * time.Hour
+	// serverCARotationGracePeriod is the amount of time that consumers are given to pick up an updated server CA bundle before the server CA certificate is rotated.
+	serverCARotationGracePeriod = 7 * 24 * time.Hour
 )
 
 // PostgresqlInstanceController is the controller for PostgresqlInstance resources.
@@ -61,14 +73,20 @@ type PostgresqlInstanceController struct {
 	*genericController
 	// cloudsqlClient is a client for the Cloud SQL Admin API.
 	cloudsqlClient *cloudsqladmin.Service
+	// databaseFlagsCatalog is the (cached) catalog of database flags supported by Cloud SQL.
+	databaseFlagsCatalog *google.DatabaseFlagsCatalog
 	// er is an EventRecorder through which we can emit events associated with PostgresqlInstance resources.
 	er record.EventRecorder
 	// kubeClient is a client to the Kubernetes API.
 	kubeClient kubernetes.Interface
 	// namespace is the namespace where cloudsql-postgres-operator is deployed.
 	namespace string
+	// postgresqlClientCertificateLister is a lister for PostgresqlClientCertificate resources.
+	postgresqlClientCertificateLister v1alpha1listers.PostgresqlClientCertificateLister
 	// postgresqlInstanceLister is a lister for PostgresqlInstance resources.
 	postgresqlInstanceLister v1alpha1listers.PostgresqlInstanceLister
+	// postgresqlReplicaLister is a lister for PostgresqlReplica resources.
+	postgresqlReplicaLister v1alpha1listers.PostgresqlReplicaLister
 	// projectID is the ID of the GCP project where cloudsql-postgres-operator is managing CSQLP instances.
 	projectID string
 	// selfClient is a client to the "cloudsql.travelaudience.com" API.
@@ -76,21 +94,26 @@ type PostgresqlInstanceController struct {
 }
 
 // NewPostgresqlInstance Controller creates a new instance of the controller for PostgresqlInstance resources.
-func NewPostgresqlInstanceController(config configuration.Configuration, kubeClient kubernetes.Interface, selfClient v1alpha1client.Interface, er record.EventRecorder, postgresqlInstanceInformer v1alpha1informers.PostgresqlInstanceInformer, cloudsqlClient *cloudsqladmin.Service) *PostgresqlInstanceController {
+func NewPostgresqlInstanceController(config configuration.Configuration, kubeClient kubernetes.Interface, selfClient v1alpha1client.Interface, er record.EventRecorder, postgresqlInstanceInformer v1alpha1informers.PostgresqlInstanceInformer, postgresqlReplicaInformer v1alpha1informers.PostgresqlReplicaInformer, postgresqlClientCertificateInformer v1alpha1informers.PostgresqlClientCertificateInformer, cloudsqlClient *cloudsqladmin.Service) *PostgresqlInstanceController {
 	// Create a new instance of the controller for PostgresqlInstance resources using the specified name and threadiness.
 	c := &PostgresqlInstanceController{
-		cloudsqlClient:           cloudsqlClient,
-		genericController:        newGenericController(postgresqlInstanceControllerName, postgresqlInstanceControllerThreadiness),
-		er:                       er,
-		kubeClient:               kubeClient,
-		namespace:                config.Cluster.Namespace,
-		postgresqlInstanceLister: postgresqlInstanceInformer.Lister(),
-		projectID:                config.GCP.ProjectID,
-		selfClient:               selfClient,
+		cloudsqlClient:                    cloudsqlClient,
+		databaseFlagsCatalog:              google.NewDatabaseFlagsCatalog(cloudsqlClient),
+		genericController:                 newGenericController(postgresqlInstanceControllerName, postgresqlInstanceControllerThreadiness),
+		er:                                er,
+		kubeClient:                        kubeClient,
+		namespace:                         config.Cluster.Namespace,
+		postgresqlClientCertificateLister: postgresqlClientCertificateInformer.Lister(),
+		postgresqlInstanceLister:          postgresqlInstanceInformer.Lister(),
+		postgresqlReplicaLister:           postgresqlReplicaInformer.Lister(),
+		projectID:                         config.GCP.ProjectID,
+		selfClient:                        selfClient,
 	}
 	// Make the controller wait for the caches to sync.
 	c.hasSyncedFuncs = []cache.InformerSynced{
 		postgresqlInstanceInformer.Informer().HasSynced,
+		postgresqlReplicaInformer.Informer().HasSynced,
+		postgresqlClientCertificateInformer.Informer().HasSynced,
 	}
 	// Make "processQueueItem" the handler for items popped out of the work queue.
 	c.syncHandler = c.processQueueItem
@@ -108,6 +131,15 @@ func NewPostgresqlInstanceController(config configuration.Configuration, kubeCli
 		},
 	})
 
+	// Setup an event handler to inform us when a read replica is promoted, so that the connection name of its primary can be updated right away.
+	postgresqlReplicaInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, obj interface{}) {
+			if r, ok := obj.(*v1alpha1api.PostgresqlReplica); ok && isPostgresqlReplicaPromoted(r) {
+				c.workqueue.Add(r.Spec.Primary)
+			}
+		},
+	})
+
 	// Return the instance of the controller for PostgresqlInstance resources created above.
 	return c
 }
@@ -145,9 +177,9 @@ func (c *PostgresqlInstanceController) processQueueItem(key string) (err error)
 			}
 		}
 	} else {
-		// The PostgresqlInstance resource is being deleted, so we must delete the CSQLP instance and remove the finalizer.
+		// The PostgresqlInstance resource is being deleted, so we must handle the CSQLP instance according to ".spec.deletionPolicy" and remove the finalizer.
 		if slice.ContainsString(p.Finalizers, constants.CleanupFinalizer, nil) {
-			if err := c.deleteInstance(p); err != nil {
+			if done, err := c.finalizeInstance(i, p); err != nil || !done {
 				return err
 			}
 			p.Finalizers = slice.RemoveString(p.Finalizers, constants.CleanupFinalizer, nil)
@@ -173,6 +205,9 @@ func (c *PostgresqlInstanceController) processQueueItem(key string) (err error)
 		}
 	}()
 
+	// Compute the activation policy that the CSQLP instance is meant to have according to ".spec.activationPolicy" and ".spec.schedule".
+	c.applySchedule(p)
+
 	// Check whether a CSQLP instance with the specified ".spec.name" already exists, and create it if necessary.
 	c.logger.WithField(logFieldName, name).Debugf("checking whether an instance with name %q already exists", p.Spec.Name)
 	instance, err := c.cloudsqlClient.Instances.Get(c.projectID, p.Spec.Name).Do()
@@ -195,13 +230,19 @@ func (c *PostgresqlInstanceController) processQueueItem(key string) (err error)
 	// Check whether the CSQLP instance has any pending or failed operations, in which case we skip further processing (but don't error).
 	// This may happen, for instance, if the CSQLP instance is still being created, if it is currently being updated, or if the last (asynchronous) operation failed.
 	// In the latter case, manual intervention by the user is most probably required, so we skip further sync of the instance until it is fixed.
-	operationInProgressOrFailed, lastOperationID, lastOperationType, lastOperationStatus, lastOperationErrorMessage, err := c.isOperationInProgressOrFailed(p)
+	operationInProgressOrFailed, lastOperationID, lastOperationType, lastOperationStatus, lastOperationErrorMessage, err := isOperationInProgressOrFailed(c.cloudsqlClient, c.projectID, p.Spec.Name)
 	switch {
 	case err != nil:
 		message := fmt.Sprintf("failed to understand if the instance has any pending operations")
 		setPostgresqlInstanceCondition(p, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionUnknown, ReasonUnexpectedError, message)
 		c.er.Event(p, corev1.EventTypeWarning, ReasonUnexpectedError, message)
 		return err
+	case operationInProgressOrFailed && lastOperationErrorMessage == "" && lastOperationType == constants.OperationTypeRestoreVolume:
+		message := fmt.Sprintf("the instance is being restored from a backup (id: %q, status: %q)", lastOperationID, lastOperationStatus)
+		setPostgresqlInstanceCondition(p, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceRestoring, message)
+		c.er.Event(p, corev1.EventTypeNormal, ReasonInstanceRestoring, message)
+		c.logger.WithField(logFieldName, name).Infof("skipping sync because %s", message)
+		return nil
 	case operationInProgressOrFailed && lastOperationErrorMessage == "":
 		message := fmt.Sprintf("the instance has an ongoing operation (id: %q, type: %q, status: %q)", lastOperationID, lastOperationType, lastOperationStatus)
 		setPostgresqlInstanceCondition(p, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonOperationInProgress, message)
@@ -211,6 +252,10 @@ func (c *PostgresqlInstanceController) processQueueItem(key string) (err error)
 	case operationInProgressOrFailed && lastOperationErrorMessage != "":
 		message := fmt.Sprintf("the last operation on the instance has failed (id: %q, type: %q, status: %q, errors: %q)", lastOperationID, lastOperationType, lastOperationStatus, lastOperationErrorMessage)
 		setPostgresqlInstanceCondition(p, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonUnexpectedError, message)
+		// If the failed operation was a major version upgrade, report the failure in the "Upgrading" condition as well.
+		if lastOperationType == constants.OperationTypeMajorVersionUpgrade {
+			setPostgresqlInstanceCondition(p, v1alpha1api.PostgresqlInstanceStatusConditionTypeUpgrading, corev1.ConditionFalse, ReasonUpgradeFailed, message)
+		}
 		c.er.Event(p, corev1.EventTypeWarning, ReasonUnexpectedError, message)
 		c.logger.WithField(logFieldName, name).Infof("skipping sync because %s", message)
 		return nil
@@ -226,16 +271,46 @@ func (c *PostgresqlInstanceController) processQueueItem(key string) (err error)
 		return nil
 	}
 
-	// Check whether the CSQLP instance is currently running.
-	// If it is not running, we also skip further processing (but don't error).
-	if instance.Settings.ActivationPolicy != constants.DatabaseInstanceActivationPolicyAlways {
-		message := "the instance is currently shut down"
-		setPostgresqlInstanceCondition(p, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceNotReady, message)
-		c.er.Event(p, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
-		c.logger.WithField(logFieldName, name).Info("skipping sync because the instance is currently shut down")
+	// If a pre-existing CSQLP instance is being adopted, make sure that it matches the specification before taking ownership of it.
+	// Until it does, we skip further processing (but don't error) so that the CSQLP instance is not modified.
+	if pending, err := c.maybeAdoptInstance(p); err != nil || pending {
+		return err
+	}
+
+	// Check whether the CSQLP instance must be started or stopped according to ".spec.activationPolicy", and start or stop it if necessary.
+	// In this case, we skip further processing (but don't error) until the resulting operation finishes.
+	desiredActivationPolicy := buildDatabaseInstanceSettings(p).ActivationPolicy
+	if instance.Settings.ActivationPolicy != desiredActivationPolicy {
+		// Any permanent error has already been reported, so we stop processing in that case.
+		if err := c.setInstanceActivationPolicy(p, instance, desiredActivationPolicy); err != nil {
+			return err
+		}
+		c.logger.WithField(logFieldName, name).Info("skipping sync because the instance is being started or stopped")
 		return nil
 	}
 
+	// Check whether the CSQLP instance is meant to be stopped.
+	// This is a desired state rather than an error, but there is nothing else to do until the CSQLP instance is started again, so we skip further processing (but don't error).
+	if desiredActivationPolicy == constants.DatabaseInstanceActivationPolicyNever {
+		message := "the instance is stopped as requested"
+		setPostgresqlInstanceCondition(p, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceStopped, message)
+		c.er.Event(p, corev1.EventTypeNormal, ReasonInstanceStopped, message)
+		c.logger.WithField(logFieldName, name).Infof("skipping sync because %s", message)
+		return nil
+	}
+
+	// Restart or fail over the CSQLP instance if requested.
+	// If a restart or failover has just been started, we skip further processing (but don't error) until it finishes.
+	if acting, err := c.maybePerformRequestedAction(p, instance); err != nil || acting {
+		return err
+	}
+
+	// Upgrade the CSQLP instance to a newer major version if necessary.
+	// If an upgrade has just been started, we skip further processing (but don't error) until it finishes.
+	if upgrading, err := c.maybeUpgradeInstance(p, instance); err != nil || upgrading {
+		return err
+	}
+
 	// Update the PostgresqlInstance resource's conditions to indicate readiness.
 	message := "the instance is running and ready"
 	setPostgresqlInstanceCondition(p, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionTrue, ReasonInstanceReady, message)
@@ -257,25 +332,52 @@ func (c *PostgresqlInstanceController) processQueueItem(key string) (err error)
 	}
 	// Check whether we need to generate and set a password for the CSQLP instance.
 	if password, exists := s.Data[constants.PostgresqlInstancePasswordKey]; !exists || len(password) == 0 {
-		if err := c.setInstancePassword(p, s); err != nil {
+		// The password of an adopted CSQLP instance is most likely in use by other clients, so we only reset it if explicitly allowed to.
+		if p.Spec.Adopt && p.Annotations[constants.AllowPasswordResetAnnotationKey] != v1alpha1api.True {
+			message := fmt.Sprintf("the password of the %q user has not been reset as the instance has been adopted (write it to the %q key of the %q secret or set the %q annotation to %q)", constants.PostgresqlInstanceUsernameValue, constants.PostgresqlInstancePasswordKey, s.Name, constants.AllowPasswordResetAnnotationKey, v1alpha1api.True)
+			c.logger.WithField(logFieldName, name).Warn(message)
+			c.er.Event(p, corev1.EventTypeWarning, ReasonPasswordNotReset, message)
+		} else if err := c.setInstancePassword(p, s); err != nil {
 			c.logger.WithField(logFieldName, name).Debugf("failed to set instance password: %v", err)
 			return err
 		}
 	}
 
+	// Keep track of the expiry of the server CA certificate, and rotate it if requested and necessary.
+	// If an operation on the CSQLP instance has just been started, we skip further processing (but don't error) until it finishes.
+	if rotating, err := c.maybeRotateServerCA(p, instance); err != nil || rotating {
+		return err
+	}
+
 	// Update the CSQLP instance's settings if necessary.
 	instance, err = c.maybeUpdateInstance(p, instance)
 	if err != nil {
 		return err
 	}
 
-	// Update the connection name and the set of IP addresses associated with the CSQLP instance and return.
+	// Update the connection name and the set of IP addresses associated with the CSQLP instance.
 	setPostgresqlInstanceConnectionNameAndIPs(p, instance)
+
+	// If one of the CSQLP instance's read replicas has been promoted (e.g. as part of a disaster recovery procedure), report its connection name instead so that pods created from now on connect to it.
+	r, err := c.getPromotedPostgresqlReplica(p)
+	if err != nil {
+		return err
+	}
+	if r != nil {
+		p.Status.ConnectionName = r.Status.ConnectionName
+		p.Status.PromotedReplica = r.Name
+	} else {
+		p.Status.PromotedReplica = ""
+	}
 	return nil
 }
 
 // createInstance attempts to create a CSQLP instance based on the specified PostgresqlInstance resource.
 func (c *PostgresqlInstanceController) createInstance(postgresqlInstance *v1alpha1api.PostgresqlInstance) (*cloudsqladmin.DatabaseInstance, error) {
+	// If the CSQLP instance is meant to be a clone of an existing one, clone it instead of creating it from scratch.
+	if postgresqlInstance.Spec.Source != nil {
+		return c.cloneInstance(postgresqlInstance)
+	}
 	c.logger.WithField(logFieldName, postgresqlInstance.Name).Info("creating instance")
 	// Build the DatabaseInstance object based on the specified PostgresqlInstance resource.
 	instance := buildDatabaseInstance(postgresqlInstance)
@@ -315,7 +417,99 @@ func (c *PostgresqlInstanceController) createInstance(postgresqlInstance *v1alph
 	return c.cloudsqlClient.Instances.Get(c.projectID, postgresqlInstance.Spec.Name).Do()
 }
 
-// createInstanceSecret creates the (initially empty) secret associated with the specified PostgresqlInstance resource.
+// cloneInstance attempts to create a CSQLP instance as a clone of an existing one based on the specified PostgresqlInstance resource.
+// Cloning happens asynchronously, and the resulting CSQLP instance only becomes visible after a while, so the clone operation is tracked until then.
+func (c *PostgresqlInstanceController) cloneInstance(postgresqlInstance *v1alpha1api.PostgresqlInstance) (*cloudsqladmin.DatabaseInstance, error) {
+	// Check whether the clone operation has already been started, in which case we check its status instead of starting it again.
+	if postgresqlInstance.Status.CloneOperationID != "" {
+		op, err := c.cloudsqlClient.Operations.Get(c.projectID, postgresqlInstance.Status.CloneOperationID).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get operation %q: %v", postgresqlInstance.Status.CloneOperationID, err)
+		}
+		operationInProgressOrFailed, operationID, _, operationStatus, operationErrorMessage := isOperationInProgressOrFailedFromOperation(op)
+		switch {
+		case operationInProgressOrFailed && operationErrorMessage == "":
+			message := fmt.Sprintf("the instance is being cloned from %q (id: %q, status: %q)", postgresqlInstance.Spec.Source.Instance, operationID, operationStatus)
+			setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceCloning, message)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Infof("skipping sync because %s", message)
+			return nil, nil
+		case operationInProgressOrFailed && operationErrorMessage != "":
+			// The clone operation has failed, and manual intervention by the user is required (e.g. re-creating the PostgresqlInstance resource with a valid point in time).
+			message := fmt.Sprintf("failed to clone the instance from %q (id: %q, errors: %q)", postgresqlInstance.Spec.Source.Instance, operationID, operationErrorMessage)
+			setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeCreated, corev1.ConditionFalse, ReasonCloneFailed, message)
+			setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonCloneFailed, message)
+			c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonCloneFailed, message)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Error(message)
+			return nil, nil
+		}
+		// The clone operation has finished successfully, so we grab and return the most up-to-date representation of the CSQLP instance.
+		return c.cloudsqlClient.Instances.Get(c.projectID, postgresqlInstance.Spec.Name).Do()
+	}
+
+	// Grab the PostgresqlInstance resource that represents the CSQLP instance to clone.
+	source, err := c.postgresqlInstanceLister.Get(postgresqlInstance.Spec.Source.Instance)
+	if err != nil {
+		// If we've got an error other than "404 NOT FOUND", we stop processing and propagate it.
+		if !kubeerrors.IsNotFound(err) {
+			return nil, err
+		}
+		// At this point we know that the PostgresqlInstance resource does not exist, so we report it and skip further processing (but don't error).
+		message := fmt.Sprintf("postgresqlinstance %q does not exist", postgresqlInstance.Spec.Source.Instance)
+		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeCreated, corev1.ConditionFalse, ReasonInvalidSpec, message)
+		c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonInvalidSpec, message)
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Error(message)
+		return nil, nil
+	}
+
+	c.logger.WithField(logFieldName, postgresqlInstance.Name).Infof("cloning instance %q", source.Spec.Name)
+	// Attempt to clone the CSQLP instance, optionally at the specified point in time.
+	cloneContext := &cloudsqladmin.CloneContext{
+		DestinationInstanceName: postgresqlInstance.Spec.Name,
+	}
+	if postgresqlInstance.Spec.Source.PointInTime != nil {
+		cloneContext.PointInTime = postgresqlInstance.Spec.Source.PointInTime.UTC().Format(time.RFC3339)
+	}
+	op, err := c.cloudsqlClient.Instances.Clone(c.projectID, source.Spec.Name, &cloudsqladmin.InstancesCloneRequest{
+		CloneContext: cloneContext,
+	}).Do()
+	if err != nil {
+		if google.IsConflict(err) {
+			// We've been told that the instance needs to be created, but the Cloud SQL Admin API is reporting a conflict
+			// This most probably means that a CSQLP instance with ".spec.name" as its name had previously existed but has been recently deleted.
+			// Hence, we log but do not propagate the error, since subsequent attempts to clone the instance are likely to fail as well until ".spec.name" becomes available again.
+			message := fmt.Sprintf("the name %q seems to be unavailable - has an instance with such a name been deleted recently?", postgresqlInstance.Spec.Name)
+			setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeCreated, corev1.ConditionFalse, ReasonNameUnavailable, message)
+			c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonNameUnavailable, message)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Error(message)
+			return nil, nil
+		}
+		if google.IsBadRequest(err) {
+			// We've been told that the clone request is invalid.
+			// This most probably means that point-in-time recovery is not enabled on the source instance, or that the point in time is outside of the recovery window.
+			// Hence, we log but do not propagate the error, since subsequent attempts to clone the instance are likely to fail as well.
+			message := fmt.Sprintf("the instance cannot be cloned from %q: %v", postgresqlInstance.Spec.Source.Instance, err)
+			setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeCreated, corev1.ConditionFalse, ReasonInvalidSpec, message)
+			c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonInvalidSpec, message)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Error(message)
+			return nil, nil
+		}
+		// The Cloud SQL Admin API returned a different error, which we propagate so that cloning may be retried.
+		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeCreated, corev1.ConditionFalse, ReasonUnexpectedError, err.Error())
+		c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+		return nil, err
+	}
+	// Record the ID of the operation so that its status can be tracked.
+	postgresqlInstance.Status.CloneOperationID = op.Name
+	// Update the PostgresqlInstance resource's conditions.
+	message := fmt.Sprintf("the instance is being cloned from %q (id: %q, status: %q)", postgresqlInstance.Spec.Source.Instance, op.Name, op.Status)
+	setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeCreated, corev1.ConditionTrue, ReasonInstanceCreated, message)
+	setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceCloning, message)
+	c.er.Event(postgresqlInstance, corev1.EventTypeNormal, ReasonInstanceCloning, message)
+	// The CSQLP instance only becomes visible after a while, so we skip further processing (but don't error) until the controller's resync period elapses.
+	return nil, nil
+}
+
+// createInstanceSecret creates the secret associated with the specified PostgresqlInstance resource, initially containing only the "postgres" user's username.
 func (c *PostgresqlInstanceController) createInstanceSecret(postgresqlInstance *v1alpha1api.PostgresqlInstance) (*corev1.Secret, error) {
 	return c.kubeClient.CoreV1().Secrets(c.namespace).Create(&corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -335,36 +529,439 @@ func (c *PostgresqlInstanceController) createInstanceSecret(postgresqlInstance *
 				},
 			},
 		},
+		StringData: map[string]string{
+			constants.PostgresqlInstanceUsernameKey: constants.PostgresqlInstanceUsernameValue,
+		},
 	})
 }
 
 // deleteInstance attempts to delete the CSQLP instance associated with the specified PostgresqlInstance resource.
-func (c *PostgresqlInstanceController) deleteInstance(postgresqlInstance *v1alpha1api.PostgresqlInstance) error {
+// If deletion protection is enabled on the CSQLP instance, it is lifted first, provided that the "cloudsql.travelaudience.com/allow-deletion" annotation is set to "true".
+// It returns a boolean value indicating whether the CSQLP instance has been deleted.
+func (c *PostgresqlInstanceController) deleteInstance(postgresqlInstance *v1alpha1api.PostgresqlInstance) (bool, error) {
 	c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug("checking whether the instance needs to be deleted")
 	// Before issuing a delete request (which can result in a "409 CONFLICT" response in case the CSQLP instance has already and recently been deleted), make sure the CSQLP instance is still listed.
-	if _, err := c.cloudsqlClient.Instances.Get(c.projectID, postgresqlInstance.Spec.Name).Do(); err != nil {
+	databaseInstance, err := c.cloudsqlClient.Instances.Get(c.projectID, postgresqlInstance.Spec.Name).Do()
+	if err != nil {
 		if google.IsNotFound(err) {
 			c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug("the instance has already been deleted")
-			return nil
+			return true, nil
 		}
-		return err
+		return false, err
+	}
+	// The Cloud SQL Admin API rejects requests to delete CSQLP instances which have deletion protection enabled.
+	// Hence, we lift deletion protection first, and process the PostgresqlInstance resource again shortly in order to delete the CSQLP instance once the update has completed.
+	if databaseInstance.Settings != nil && databaseInstance.Settings.DeletionProtectionEnabled {
+		// Deletion protection is only lifted if deletion has been explicitly allowed, which is normally enforced by the admission webhook.
+		// Otherwise, we skip further processing (but don't error), since setting the annotation causes the PostgresqlInstance resource to be processed again.
+		if postgresqlInstance.Annotations[constants.AllowDeletionAnnotationKey] != v1alpha1api.True {
+			message := fmt.Sprintf("the instance %q cannot be deleted since deletion protection is enabled and the %q annotation is not set to %q", postgresqlInstance.Spec.Name, constants.AllowDeletionAnnotationKey, v1alpha1api.True)
+			c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonDeletionProtectionEnabled, message)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Warn(message)
+			return false, nil
+		}
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Infof("lifting deletion protection from instance %q", postgresqlInstance.Spec.Name)
+		// Only deletion protection is sent in the request, so that any changes being held back according to ".spec.maintenance.disruptiveUpdatePolicy" are not applied as a side effect.
+		_, err := c.cloudsqlClient.Instances.Patch(c.projectID, postgresqlInstance.Spec.Name, &cloudsqladmin.DatabaseInstance{
+			Settings: &cloudsqladmin.Settings{
+				DeletionProtectionEnabled: false,
+				ForceSendFields:           []string{"DeletionProtectionEnabled"},
+				SettingsVersion:           databaseInstance.Settings.SettingsVersion,
+			},
+		}).Do()
+		if err != nil && !google.IsConflict(err) {
+			return false, err
+		}
+		// If a conflict has been reported, another update is most probably in progress, in which case we must wait as well.
+		if err == nil {
+			message := fmt.Sprintf("deletion protection is being lifted from the instance %q", postgresqlInstance.Spec.Name)
+			c.er.Event(postgresqlInstance, corev1.EventTypeNormal, ReasonDeletionProtectionLifted, message)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Info(message)
+		}
+		c.enqueueAfter(postgresqlInstance, deletionProtectionCheckInterval)
+		return false, nil
 	}
 	c.logger.WithField(logFieldName, postgresqlInstance.Name).Infof("deleting instance %q", postgresqlInstance.Spec.Name)
 	// At this point we know the CSQLP instance already exists, so we issue the delete request.
 	if _, err := c.cloudsqlClient.Instances.Delete(c.projectID, postgresqlInstance.Spec.Name).Do(); err != nil {
-		return err
+		// If a conflict has been reported, an update (such as the one lifting deletion protection) is most probably still in progress, in which case we must wait.
+		if google.IsConflict(err) {
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Debugf("conflict reported while trying to delete instance %q: %v", postgresqlInstance.Spec.Name, err)
+			c.enqueueAfter(postgresqlInstance, deletionProtectionCheckInterval)
+			return false, nil
+		}
+		return false, err
 	}
 	c.logger.WithField(logFieldName, postgresqlInstance.Name).Debugf("instance %q has been deleted", postgresqlInstance.Spec.Name)
-	return nil
+	return true, nil
+}
+
+// finalizeInstance handles the CSQLP instance associated with the specified PostgresqlInstance resource according to ".spec.deletionPolicy", as the latter is being deleted.
+// It returns a boolean value indicating whether the finalizer may be removed from the PostgresqlInstance resource.
+func (c *PostgresqlInstanceController) finalizeInstance(oldObj, newObj *v1alpha1api.PostgresqlInstance) (bool, error) {
+	// PostgresqlInstance resources created before ".spec.deletionPolicy" was introduced may not have it set, in which case the CSQLP instance is deleted.
+	policy := v1alpha1api.PostgresqlInstanceSpecDeletionPolicyDelete
+	if newObj.Spec.DeletionPolicy != nil {
+		policy = *newObj.Spec.DeletionPolicy
+	}
+	switch policy {
+	case v1alpha1api.PostgresqlInstanceSpecDeletionPolicyRetain:
+		// Leave the CSQLP instance untouched.
+		message := fmt.Sprintf("the instance %q has been retained", newObj.Spec.Name)
+		c.er.Event(newObj, corev1.EventTypeNormal, ReasonInstanceRetained, message)
+		c.logger.WithField(logFieldName, newObj.Name).Info(message)
+		return true, nil
+	case v1alpha1api.PostgresqlInstanceSpecDeletionPolicyBackupThenDelete:
+		// If the "cloudsql.travelaudience.com/skip-final-backup" annotation is set to "true", the CSQLP instance is deleted right away.
+		// This allows for deleting CSQLP instances whose final backup cannot be taken (e.g. because the destination is not writable) without having to change ".spec.deletionPolicy".
+		if newObj.Annotations[constants.SkipFinalBackupAnnotationKey] == v1alpha1api.True {
+			message := fmt.Sprintf("the final backup of the instance %q has been skipped", newObj.Spec.Name)
+			c.er.Event(newObj, corev1.EventTypeWarning, ReasonFinalBackupSkipped, message)
+			c.logger.WithField(logFieldName, newObj.Name).Warn(message)
+			break
+		}
+		// Take the final backup of the CSQLP instance, making sure that its progress is persisted.
+		// Until the final backup is complete, we skip further processing (but don't error), and process the PostgresqlInstance resource again shortly rather than only when the controller's resync period elapses.
+		done, err := c.takeFinalBackup(newObj)
+		if _, patchErr := c.patchPostgresqlInstanceStatus(oldObj, newObj); patchErr != nil {
+			return false, utilerrors.NewAggregate([]error{patchErr, err})
+		}
+		if err != nil {
+			return false, err
+		}
+		if !done {
+			c.enqueueAfter(newObj, finalBackupCheckInterval)
+			return false, nil
+		}
+	case v1alpha1api.PostgresqlInstanceSpecDeletionPolicyDelete:
+		// The CSQLP instance is deleted right away.
+	}
+	return c.deleteInstance(newObj)
+}
+
+// maybeAdoptInstance checks whether the pre-existing CSQLP instance being adopted by the specified PostgresqlInstance resource matches the latter's specification, and completes the adoption if it does.
+// It returns a boolean value indicating whether the adoption is still pending, in which case the CSQLP instance must not be modified.
+func (c *PostgresqlInstanceController) maybeAdoptInstance(postgresqlInstance *v1alpha1api.PostgresqlInstance) (bool, error) {
+	// If the CSQLP instance is not being adopted, or if it has already been adopted, there's nothing to do.
+	if !postgresqlInstance.Spec.Adopt {
+		return false, nil
+	}
+	if cdn := getPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeAdopted); cdn != nil && cdn.Status == corev1.ConditionTrue {
+		return false, nil
+	}
+	c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug("checking whether the instance can be adopted")
+	// Grab a separate representation of the CSQLP instance, as computing the differences modifies it.
+	databaseInstance, err := c.cloudsqlClient.Instances.Get(c.projectID, postgresqlInstance.Spec.Name).Do()
+	if err != nil {
+		return false, err
+	}
+	// If there are differences between the CSQLP instance and the specification, report them and leave the CSQLP instance untouched until they are resolved.
+	if differences := c.computeAdoptionDifferences(postgresqlInstance, databaseInstance); len(differences) > 0 {
+		message := fmt.Sprintf("the instance will not be modified until the following fields match the specification: %s", joinAdoptionDifferences(differences))
+		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeAdopted, corev1.ConditionFalse, ReasonAdoptionPending, message)
+		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonAdoptionPending, message)
+		c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonAdoptionPending, message)
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Info(message)
+		return true, nil
+	}
+	// At this point we know that the CSQLP instance matches the specification, so we take ownership of it.
+	message := "the instance has been adopted"
+	setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeAdopted, corev1.ConditionTrue, ReasonInstanceAdopted, message)
+	c.er.Event(postgresqlInstance, corev1.EventTypeNormal, ReasonInstanceAdopted, message)
+	c.logger.WithField(logFieldName, postgresqlInstance.Name).Info(message)
+	return false, nil
+}
+
+// applySchedule computes the activation policy that the CSQLP instance is meant to have according to ".spec.activationPolicy" and ".spec.schedule", and reports it in ".status.activationPolicy".
+// ".spec.activationPolicy" is never modified, so that it keeps reflecting what has been declared.
+// Instead, the last transition that happened according to ".spec.schedule" is recorded in ".status.lastScheduledTransition", and prevails until ".spec.activationPolicy" is changed.
+// It also updates ".status.nextScheduledTransition", and makes sure that the PostgresqlInstance resource is processed again as soon as the next transition is due.
+func (c *PostgresqlInstanceController) applySchedule(postgresqlInstance *v1alpha1api.PostgresqlInstance) {
+	// PostgresqlInstance resources created before ".spec.activationPolicy" was introduced may not have it set, in which case the CSQLP instance is meant to be running.
+	spec := v1alpha1api.PostgresqlInstanceSpecActivationPolicyAlways
+	if postgresqlInstance.Spec.ActivationPolicy != nil {
+		spec = *postgresqlInstance.Spec.ActivationPolicy
+	}
+	// If no schedule has been specified, the CSQLP instance is meant to have the activation policy specified in ".spec.activationPolicy".
+	if postgresqlInstance.Spec.Schedule == nil {
+		postgresqlInstance.Status.ActivationPolicy = spec
+		postgresqlInstance.Status.LastScheduledTransition = nil
+		postgresqlInstance.Status.NextScheduledTransition = nil
+		return
+	}
+	start, stop, err := parsePostgresqlInstanceSchedule(postgresqlInstance.Spec.Schedule)
+	if err != nil {
+		// This should never happen in practice, as the admission webhook rejects any PostgresqlInstance resources with an invalid schedule.
+		// Hence, we log but do not propagate the error, since subsequent attempts to parse the schedule are likely to fail as well until ".spec.schedule" is fixed.
+		message := fmt.Sprintf("the instance's schedule is invalid: %v", err)
+		c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonInvalidSpec, message)
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Error(message)
+		postgresqlInstance.Status.ActivationPolicy = spec
+		postgresqlInstance.Status.NextScheduledTransition = nil
+		return
+	}
+	now := time.Now()
+
+	// Check whether the previously computed transition is due.
+	// Several transitions may have happened since then (e.g. if cloudsql-postgres-operator was not running), in which case the most recent one prevails.
+	if t := postgresqlInstance.Status.NextScheduledTransition; t != nil && !now.Before(t.Time.Time) {
+		lastStart, lastStop := lastScheduledActivation(start, t.Time.Time, now), lastScheduledActivation(stop, t.Time.Time, now)
+		// Record the transition unless the schedule has changed in the meantime.
+		if !lastStart.IsZero() || !lastStop.IsZero() {
+			transition := &v1alpha1api.PostgresqlInstanceStatusLastScheduledTransition{
+				ActivationPolicy:     v1alpha1api.PostgresqlInstanceSpecActivationPolicyAlways,
+				SpecActivationPolicy: spec,
+				Time:                 metav1.NewTime(lastStart),
+			}
+			reason, message := ReasonInstanceScheduledStart, "the instance is being started according to its schedule"
+			if lastStop.After(lastStart) {
+				transition.ActivationPolicy, transition.Time = v1alpha1api.PostgresqlInstanceSpecActivationPolicyNever, metav1.NewTime(lastStop)
+				reason, message = ReasonInstanceScheduledStop, "the instance is being stopped according to its schedule"
+			}
+			postgresqlInstance.Status.LastScheduledTransition = transition
+			// Only report the transition if it actually changes the activation policy of the CSQLP instance.
+			if postgresqlInstance.Status.ActivationPolicy != transition.ActivationPolicy {
+				c.er.Event(postgresqlInstance, corev1.EventTypeNormal, reason, message)
+				c.logger.WithField(logFieldName, postgresqlInstance.Name).Info(message)
+			}
+		}
+	}
+
+	// The last scheduled transition prevails unless ".spec.activationPolicy" has been changed since it happened (e.g. in order to start a stopped CSQLP instance outside of the usual hours).
+	postgresqlInstance.Status.ActivationPolicy = spec
+	if t := postgresqlInstance.Status.LastScheduledTransition; t != nil && t.SpecActivationPolicy == spec {
+		postgresqlInstance.Status.ActivationPolicy = t.ActivationPolicy
+	}
+
+	// Compute the next transition.
+	// If the CSQLP instance is scheduled to be started and stopped at the same time, starting it prevails.
+	nextStart, nextStop := start.Next(now), stop.Next(now)
+	switch {
+	case nextStart.IsZero() && nextStop.IsZero():
+		postgresqlInstance.Status.NextScheduledTransition = nil
+	case nextStop.IsZero() || (!nextStart.IsZero() && !nextStart.After(nextStop)):
+		postgresqlInstance.Status.NextScheduledTransition = &v1alpha1api.PostgresqlInstanceStatusScheduledTransition{
+			ActivationPolicy: v1alpha1api.PostgresqlInstanceSpecActivationPolicyAlways,
+			Time:             metav1.NewTime(nextStart),
+		}
+	default:
+		postgresqlInstance.Status.NextScheduledTransition = &v1alpha1api.PostgresqlInstanceStatusScheduledTransition{
+			ActivationPolicy: v1alpha1api.PostgresqlInstanceSpecActivationPolicyNever,
+			Time:             metav1.NewTime(nextStop),
+		}
+	}
+	// Process the PostgresqlInstance resource again as soon as the next transition is due, rather than only when the controller's resync period next elapses.
+	if t := postgresqlInstance.Status.NextScheduledTransition; t != nil {
+		c.enqueueAfter(postgresqlInstance, time.Until(t.Time.Time))
+	}
+}
+
+// maybePerformRequestedAction checks whether a restart or failover of the CSQLP instance has been requested (and not yet performed), and starts it if necessary.
+// It returns a boolean value indicating whether a restart or failover has been started.
+func (c *PostgresqlInstanceController) maybePerformRequestedAction(postgresqlInstance *v1alpha1api.PostgresqlInstance, databaseInstance *cloudsqladmin.DatabaseInstance) (bool, error) {
+	var (
+		// action is a human-readable name for the requested action.
+		action string
+		// do performs the requested action.
+		do func() error
+		// failedReason is the reason used in case the requested action cannot be performed.
+		failedReason string
+		// handled is the field in ".status" where the value of the annotation requesting the action is recorded after the action has been performed.
+		handled *string
+		// operationType is the type of the operation started by the Cloud SQL Admin API in order to perform the requested action.
+		operationType string
+		// reason is the reason used in case the requested action has been started.
+		reason string
+		// requestedAt is the value of the annotation requesting the action.
+		requestedAt string
+	)
+	// Check which action (if any) has been requested.
+	// Restarts are performed before failovers in case both are requested at the same time.
+	if v := postgresqlInstance.Annotations[constants.RestartRequestedAtAnnotationKey]; v != "" && v != postgresqlInstance.Status.LastRestartRequestedAt {
+		action, failedReason, handled, operationType, reason, requestedAt = "restart", ReasonInstanceRestartFailed, &postgresqlInstance.Status.LastRestartRequestedAt, constants.OperationTypeRestart, ReasonInstanceRestarting, v
+		do = func() error {
+			_, err := c.cloudsqlClient.Instances.Restart(c.projectID, databaseInstance.Name).Do()
+			return err
+		}
+	} else if v := postgresqlInstance.Annotations[constants.FailoverRequestedAtAnnotationKey]; v != "" && v != postgresqlInstance.Status.LastFailoverRequestedAt {
+		action, failedReason, handled, operationType, reason, requestedAt = "failover", ReasonInstanceFailoverFailed, &postgresqlInstance.Status.LastFailoverRequestedAt, constants.OperationTypeFailover, ReasonInstanceFailingOver, v
+		do = func() error {
+			_, err := c.cloudsqlClient.Instances.Failover(c.projectID, databaseInstance.Name, &cloudsqladmin.InstancesFailoverRequest{
+				FailoverContext: &cloudsqladmin.FailoverContext{
+					SettingsVersion: databaseInstance.Settings.SettingsVersion,
+				},
+			}).Do()
+			return err
+		}
+	} else {
+		// No action has been requested, so there is nothing to do.
+		return false, nil
+	}
+	// The action may have already been performed in a previous iteration whose update to ".status" has not been persisted (e.g. because of a conflict).
+	// Hence, and in order not to restart or fail over the CSQLP instance twice, we check whether an operation of the same type has been started since the action was requested.
+	performed, err := hasOperationSince(c.cloudsqlClient, c.projectID, databaseInstance.Name, operationType, requestedAt)
+	if err != nil {
+		c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+		return false, err
+	}
+	if performed {
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Infof("the %s requested at %q has already been performed", action, requestedAt)
+		*handled = requestedAt
+		return false, nil
+	}
+	// At this point we know we have to perform the requested action.
+	c.logger.WithField(logFieldName, postgresqlInstance.Name).Infof("performing %s requested at %q", action, requestedAt)
+	if err := do(); err != nil {
+		if google.IsBadRequest(err) {
+			// We've been told that the action cannot be performed (e.g. because the CSQLP instance is not regional).
+			// Hence, we log but do not propagate the error, and record the request as handled so that it is not attempted again.
+			message := fmt.Sprintf("the %s requested at %q cannot be performed: %v", action, requestedAt, err)
+			c.er.Event(postgresqlInstance, corev1.EventTypeWarning, failedReason, message)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Error(message)
+			*handled = requestedAt
+			return false, nil
+		}
+		// The Cloud SQL Admin API returned a different error, which we propagate so that the action may be retried.
+		c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+		return false, err
+	}
+	// Record the request as handled so that the action is not performed again, and update the PostgresqlInstance resource's conditions.
+	*handled = requestedAt
+	message := fmt.Sprintf("the instance is performing the %s requested at %q", action, requestedAt)
+	setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, reason, message)
+	c.er.Event(postgresqlInstance, corev1.EventTypeNormal, reason, message)
+	return true, nil
+}
+
+// maybeRotateServerCA records the expiry of the CSQLP instance's server CA certificate, and reports whether it is about to expire.
+// If automatic rotation has been requested and the server CA certificate is about to expire, it adds an upcoming server CA certificate, distributes it to the namespace-local secrets containing client certificates and, after a grace period, rotates the server CA certificate.
+// It returns a boolean value indicating whether an operation on the CSQLP instance has been started.
+func (c *PostgresqlInstanceController) maybeRotateServerCA(postgresqlInstance *v1alpha1api.PostgresqlInstance, databaseInstance *cloudsqladmin.DatabaseInstance) (bool, error) {
+	// If the server CA certificate has not been reported, there is nothing to do.
+	if databaseInstance.ServerCaCert == nil || databaseInstance.ServerCaCert.ExpirationTime == "" {
+		return false, nil
+	}
+	expirationTime, err := time.Parse(time.RFC3339, databaseInstance.ServerCaCert.ExpirationTime)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse the expiration time of the server ca certificate (%q): %v", databaseInstance.ServerCaCert.ExpirationTime, err)
+	}
+	// Record the fingerprint and the expiration time of the server CA certificate.
+	// The time at which the server CA bundles have last been updated is only kept while the server CA certificate remains the same.
+	if postgresqlInstance.Status.ServerCA == nil || postgresqlInstance.Status.ServerCA.Sha1Fingerprint != databaseInstance.ServerCaCert.Sha1Fingerprint {
+		postgresqlInstance.Status.ServerCA = &v1alpha1api.PostgresqlInstanceStatusServerCA{}
+	}
+	postgresqlInstance.Status.ServerCA.ExpirationTime = metav1.NewTime(expirationTime)
+	postgresqlInstance.Status.ServerCA.Sha1Fingerprint = databaseInstance.ServerCaCert.Sha1Fingerprint
+
+	// Report whether the server CA certificate is about to expire.
+	if time.Until(expirationTime) > serverCAExpiryWarningThreshold {
+		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeServerCAExpiring, corev1.ConditionFalse, ReasonServerCAValid, "the server ca certificate is not about to expire")
+		return false, nil
+	}
+	message := fmt.Sprintf("the server ca certificate expires at %s", expirationTime.Format(time.RFC3339))
+	setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeServerCAExpiring, corev1.ConditionTrue, ReasonServerCAExpiring, message)
+	c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonServerCAExpiring, message)
+
+	// If automatic rotation of the server CA certificate has not been requested, there is nothing else to do.
+	if sc := postgresqlInstance.Spec.Networking.ServerCA; sc == nil || sc.AutoRotate == nil || !*sc.AutoRotate {
+		return false, nil
+	}
+
+	// Check whether an upcoming server CA certificate has already been added, and add it otherwise.
+	cas, err := c.cloudsqlClient.Instances.ListServerCas(c.projectID, databaseInstance.Name).Do()
+	if err != nil {
+		return false, fmt.Errorf("failed to list the server ca certificates of the instance: %v", err)
+	}
+	var upcoming *cloudsqladmin.SslCert
+	for _, cert := range cas.Certs {
+		if cert == nil || cert.Sha1Fingerprint == cas.ActiveVersion {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, cert.ExpirationTime); err == nil && t.After(expirationTime) {
+			upcoming = cert
+		}
+	}
+	if upcoming == nil {
+		if _, err := c.cloudsqlClient.Instances.AddServerCa(c.projectID, databaseInstance.Name).Do(); err != nil {
+			c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+			return false, err
+		}
+		message := "an upcoming server ca certificate is being added to the instance"
+		c.er.Event(postgresqlInstance, corev1.EventTypeNormal, ReasonServerCAAdding, message)
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Info(message)
+		return true, nil
+	}
+
+	// Make sure that the server CA bundles distributed to namespace-local secrets include the upcoming server CA certificate.
+	updated, err := c.updateServerCABundles(postgresqlInstance, google.ServerCABundle(cas.Certs))
+	if err != nil {
+		return false, err
+	}
+	if updated || postgresqlInstance.Status.ServerCA.BundleUpdateTime == nil {
+		now := metav1.Now()
+		postgresqlInstance.Status.ServerCA.BundleUpdateTime = &now
+		message := "the server ca bundles have been updated with the upcoming server ca certificate"
+		c.er.Event(postgresqlInstance, corev1.EventTypeNormal, ReasonServerCABundleUpdated, message)
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Info(message)
+		return false, nil
+	}
+	// Give consumers some time to pick up the updated server CA bundles before actually rotating the server CA certificate.
+	if time.Since(postgresqlInstance.Status.ServerCA.BundleUpdateTime.Time) < serverCARotationGracePeriod {
+		return false, nil
+	}
+	c.logger.WithField(logFieldName, postgresqlInstance.Name).Infof("rotating the server ca certificate to %q", upcoming.Sha1Fingerprint)
+	if _, err := c.cloudsqlClient.Instances.RotateServerCa(c.projectID, databaseInstance.Name, &cloudsqladmin.InstancesRotateServerCaRequest{
+		RotateServerCaContext: &cloudsqladmin.RotateServerCaContext{
+			NextVersion: upcoming.Sha1Fingerprint,
+		},
+	}).Do(); err != nil {
+		c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+		return false, err
+	}
+	message = fmt.Sprintf("the server ca certificate is being rotated to %q", upcoming.Sha1Fingerprint)
+	setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonServerCARotating, message)
+	c.er.Event(postgresqlInstance, corev1.EventTypeNormal, ReasonServerCARotating, message)
+	return true, nil
 }
 
 // maybeUpdateInstance checks whether the settings for the CSQLP instance must be updated, and updates it if necessary.
 func (c *PostgresqlInstanceController) maybeUpdateInstance(postgresqlInstance *v1alpha1api.PostgresqlInstance, databaseInstance *cloudsqladmin.DatabaseInstance) (*cloudsqladmin.DatabaseInstance, error) {
 	c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug("checking whether the instance's settings must be updated")
-	// Update the CSQLP instance's settings according to the PostgresqlInstance resource.
-	mustUpdate := c.updateDatabaseInstanceSettings(postgresqlInstance, databaseInstance)
-	if !mustUpdate {
-		// No differences have been detected, so there is nothing to do.
+	// Compute the desired settings based on the PostgresqlInstance resource, and check which of the pending changes require restarting the CSQLP instance.
+	desiredSettings := buildDatabaseInstanceSettings(postgresqlInstance)
+	disruptiveChanges, disruptiveFlags, err := c.computeDisruptiveChanges(databaseInstance.Settings, desiredSettings, databaseInstance.DatabaseVersion)
+	if err != nil {
+		return nil, err
+	}
+	applyDisruptiveChanges := len(disruptiveChanges) > 0 && mayApplyDisruptiveChanges(postgresqlInstance, time.Now())
+	holdBack := len(disruptiveChanges) > 0 && !applyDisruptiveChanges
+	switch {
+	case len(disruptiveChanges) == 0:
+		// Any approval given while no changes requiring a restart are pending is considered to have been handled, so that it does not apply to future changes.
+		postgresqlInstance.Status.LastDisruptiveUpdateApprovedAt = postgresqlInstance.Annotations[constants.DisruptiveUpdateApprovedAtAnnotationKey]
+		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypePendingRestart, corev1.ConditionFalse, ReasonNoDisruptiveUpdatePending, "no pending changes require restarting the instance")
+	case holdBack:
+		// Hold back the changes that require restarting the CSQLP instance, applying only the remaining ones.
+		holdBackDisruptiveChanges(databaseInstance.Settings, desiredSettings, disruptiveFlags)
+		message := fmt.Sprintf("the following changes require restarting the instance and will be applied %s: %s", describeDisruptiveUpdatePolicy(postgresqlInstance), joinDisruptiveChanges(disruptiveChanges))
+		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypePendingRestart, corev1.ConditionTrue, ReasonDisruptiveUpdatePending, message)
+		c.er.Event(postgresqlInstance, corev1.EventTypeNormal, ReasonDisruptiveUpdatePending, message)
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(message)
+		// If the changes are held back until the next maintenance window, make sure the PostgresqlInstance resource is processed again as soon as it starts rather than only when the controller's resync period elapses.
+		if t := nextMaintenanceWindow(postgresqlInstance, time.Now()); !t.IsZero() {
+			c.enqueueAfter(postgresqlInstance, time.Until(t))
+		}
+	}
+	// While changes that require restarting the CSQLP instance are held back, its settings are not reported as being up-to-date.
+	heldBackMessage := fmt.Sprintf("changes requiring a restart of the instance are held back until they can be applied %s", describeDisruptiveUpdatePolicy(postgresqlInstance))
+	// Update the CSQLP instance's settings according to the desired settings.
+	if changes := c.updateDatabaseInstanceSettings(postgresqlInstance, databaseInstance, desiredSettings); len(changes) == 0 {
+		// No differences besides the ones being held back have been detected, so there is nothing to do.
+		if holdBack {
+			setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeUpToDate, corev1.ConditionFalse, ReasonDisruptiveUpdateHeldBack, heldBackMessage)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(heldBackMessage)
+			return databaseInstance, nil
+		}
 		message := "the instance's settings are up-to-date"
 		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeUpToDate, corev1.ConditionTrue, ReasonInstanceUpToDate, message)
 		c.er.Event(postgresqlInstance, corev1.EventTypeNormal, ReasonInstanceUpToDate, message)
@@ -373,7 +970,7 @@ func (c *PostgresqlInstanceController) maybeUpdateInstance(postgresqlInstance *v
 	}
 	// At this point we know we have to update the CSQLP instance's settings.
 	c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug("the instance's settings must be updated")
-	_, err := c.cloudsqlClient.Instances.Update(c.projectID, databaseInstance.Name, databaseInstance).Do()
+	_, err = c.cloudsqlClient.Instances.Update(c.projectID, databaseInstance.Name, databaseInstance).Do()
 	if err != nil {
 		if google.IsConflict(err) {
 			// The Cloud SQL Admin API is reporting a conflict.
@@ -402,12 +999,171 @@ func (c *PostgresqlInstanceController) maybeUpdateInstance(postgresqlInstance *v
 	}
 	// Update the PostgresqlInstance resource's conditions.
 	message := "the instance has been updated"
-	setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeUpToDate, corev1.ConditionTrue, ReasonInstanceUpdated, message)
+	if holdBack {
+		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeUpToDate, corev1.ConditionFalse, ReasonDisruptiveUpdateHeldBack, heldBackMessage)
+	} else {
+		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeUpToDate, corev1.ConditionTrue, ReasonInstanceUpdated, message)
+	}
 	c.er.Event(postgresqlInstance, corev1.EventTypeNormal, ReasonInstanceUpdated, message)
+	if applyDisruptiveChanges {
+		// Record the approval (if any) as handled, and report that the changes requiring a restart have been applied.
+		postgresqlInstance.Status.LastDisruptiveUpdateApprovedAt = postgresqlInstance.Annotations[constants.DisruptiveUpdateApprovedAtAnnotationKey]
+		message := fmt.Sprintf("the following changes requiring a restart of the instance have been applied: %s", joinDisruptiveChanges(disruptiveChanges))
+		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypePendingRestart, corev1.ConditionFalse, ReasonDisruptiveUpdateApplied, message)
+		c.er.Event(postgresqlInstance, corev1.EventTypeNormal, ReasonDisruptiveUpdateApplied, message)
+	}
 	// Grab and return the most up-to-date representation of the CSQLP instance.
 	return c.cloudsqlClient.Instances.Get(c.projectID, postgresqlInstance.Spec.Name).Do()
 }
 
+// maybeUpgradeInstance checks whether the CSQLP instance must be upgraded to a newer major version, and starts the upgrade if necessary.
+// It returns a boolean value indicating whether an upgrade has been started.
+func (c *PostgresqlInstanceController) maybeUpgradeInstance(postgresqlInstance *v1alpha1api.PostgresqlInstance, databaseInstance *cloudsqladmin.DatabaseInstance) (bool, error) {
+	c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug("checking whether the instance must be upgraded")
+	// Compute the desired version based on the provided PostgresqlInstance resource.
+	desiredVersion := postgresqlInstance.Spec.Version.APIValue()
+	if databaseInstance.DatabaseVersion == desiredVersion {
+		// The CSQLP instance is already running the desired version.
+		// If it was previously being upgraded, we report that the upgrade has finished.
+		if cdn := getPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeUpgrading); cdn != nil && cdn.Status == corev1.ConditionTrue {
+			message := fmt.Sprintf("the instance has been upgraded to %q", desiredVersion)
+			setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeUpgrading, corev1.ConditionFalse, ReasonInstanceUpgraded, message)
+			c.er.Event(postgresqlInstance, corev1.EventTypeNormal, ReasonInstanceUpgraded, message)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Info(message)
+		}
+		postgresqlInstance.Status.FailedUpgrade = nil
+		return false, nil
+	}
+	// If the Cloud SQL Admin API has already refused to perform the same upgrade, don't attempt it again until ".spec" changes.
+	// In the meantime, the CSQLP instance keeps running its current version and its remaining settings keep being reconciled.
+	if f := postgresqlInstance.Status.FailedUpgrade; f != nil && f.Version == *postgresqlInstance.Spec.Version && f.ObservedGeneration == postgresqlInstance.Generation {
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debugf("skipping upgrade to %q as it has previously been refused", desiredVersion)
+		return false, nil
+	}
+	// At this point we know we have to upgrade the CSQLP instance.
+	// Only the version is sent in the request, as the remaining settings are reconciled separately once the upgrade finishes.
+	c.logger.WithField(logFieldName, postgresqlInstance.Name).Infof("upgrading instance from %q to %q", databaseInstance.DatabaseVersion, desiredVersion)
+	_, err := c.cloudsqlClient.Instances.Patch(c.projectID, databaseInstance.Name, &cloudsqladmin.DatabaseInstance{
+		DatabaseVersion: desiredVersion,
+	}).Do()
+	if err != nil {
+		if google.IsBadRequest(err) {
+			// We've been told that the upgrade cannot be performed.
+			// This most probably means that the instance uses extensions or flags which are not supported by the target version.
+			// Hence, we log but do not propagate the error, and record the failed upgrade so that it is not attempted again until ".spec" is changed.
+			message := fmt.Sprintf("the instance cannot be upgraded from %q to %q: %v", databaseInstance.DatabaseVersion, desiredVersion, err)
+			setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeUpgrading, corev1.ConditionFalse, ReasonUpgradeFailed, message)
+			c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonUpgradeFailed, message)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Error(message)
+			postgresqlInstance.Status.FailedUpgrade = &v1alpha1api.PostgresqlInstanceStatusFailedUpgrade{
+				ObservedGeneration: postgresqlInstance.Generation,
+				Version:            *postgresqlInstance.Spec.Version,
+			}
+			return false, nil
+		}
+		// The Cloud SQL Admin API returned a different error, which we propagate so that the upgrade may be retried.
+		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeUpgrading, corev1.ConditionFalse, ReasonUnexpectedError, err.Error())
+		c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+		return false, err
+	}
+	// Update the PostgresqlInstance resource's conditions.
+	message := fmt.Sprintf("the instance is being upgraded from %q to %q", databaseInstance.DatabaseVersion, desiredVersion)
+	setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeUpgrading, corev1.ConditionTrue, ReasonInstanceUpgrading, message)
+	setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceUpgrading, message)
+	c.er.Event(postgresqlInstance, corev1.EventTypeNormal, ReasonInstanceUpgrading, message)
+	return true, nil
+}
+
+// setInstanceActivationPolicy starts or stops the CSQLP instance by updating its activation policy to the specified value.
+// Only the activation policy is sent in the request, so that any changes being held back according to ".spec.maintenance.disruptiveUpdatePolicy" are not applied as a side effect.
+// Permanent errors are reported in the PostgresqlInstance resource's conditions rather than returned.
+func (c *PostgresqlInstanceController) setInstanceActivationPolicy(postgresqlInstance *v1alpha1api.PostgresqlInstance, databaseInstance *cloudsqladmin.DatabaseInstance, activationPolicy string) error {
+	reason, message := ReasonInstanceStarting, "the instance is being started"
+	if activationPolicy == constants.DatabaseInstanceActivationPolicyNever {
+		reason, message = ReasonInstanceStopping, "the instance is being stopped"
+	}
+	c.logger.WithField(logFieldName, postgresqlInstance.Name).Debugf("setting the instance's activation policy to %q", activationPolicy)
+	_, err := c.cloudsqlClient.Instances.Patch(c.projectID, databaseInstance.Name, &cloudsqladmin.DatabaseInstance{
+		Settings: &cloudsqladmin.Settings{
+			ActivationPolicy: activationPolicy,
+			SettingsVersion:  databaseInstance.Settings.SettingsVersion,
+		},
+	}).Do()
+	if err != nil {
+		if google.IsConflict(err) {
+			// The Cloud SQL Admin API is reporting a conflict.
+			// This most probably means that an update is already in progress, in which case we must wait.
+			// Hence, we log but do not propagate the error, waiting until the next iteration of the controller to actually start or stop the instance.
+			message := fmt.Sprintf("conflict reported while trying to update the instance's activation policy - maybe another update is currently in progress? %v", err)
+			setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeUpToDate, corev1.ConditionFalse, ReasonConflict, message)
+			c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonConflict, message)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Error(message)
+			return nil
+		}
+		if google.IsBadRequest(err) {
+			// We've been told that the activation policy cannot be updated.
+			// Hence, we log but do not propagate the error, since subsequent attempts are likely to fail as well until ".spec" is fixed.
+			message := fmt.Sprintf("the instance's activation policy cannot be updated: %v", err)
+			setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeUpToDate, corev1.ConditionFalse, ReasonInvalidSpec, message)
+			c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonInvalidSpec, message)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Error(message)
+			return nil
+		}
+		// The Cloud SQL Admin API returned a different error, which we propagate so that the update may be retried.
+		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeUpToDate, corev1.ConditionFalse, ReasonUnexpectedError, err.Error())
+		c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+		return err
+	}
+	// Update the PostgresqlInstance resource's conditions.
+	setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, reason, message)
+	c.er.Event(postgresqlInstance, corev1.EventTypeNormal, reason, message)
+	return nil
+}
+
+// updateServerCABundles updates the server CA bundle contained in the secret of every PostgresqlClientCertificate resource referencing the specified PostgresqlInstance resource.
+// Only secrets controlled by the PostgresqlClientCertificate resource and already containing a client certificate (and hence a server CA bundle) are updated.
+// It returns a boolean value indicating whether any namespace-local secret has been updated.
+func (c *PostgresqlInstanceController) updateServerCABundles(postgresqlInstance *v1alpha1api.PostgresqlInstance, bundle string) (bool, error) {
+	postgresqlClientCertificates, err := c.postgresqlClientCertificateLister.List(labels.Everything())
+	if err != nil {
+		return false, fmt.Errorf("failed to list postgresqlclientcertificates: %v", err)
+	}
+	updated := false
+	for _, p := range postgresqlClientCertificates {
+		// Skip PostgresqlClientCertificate resources that do not reference the current PostgresqlInstance resource.
+		if p.Spec.Instance != postgresqlInstance.Name || p.Spec.SecretName == nil {
+			continue
+		}
+		s, err := c.kubeClient.CoreV1().Secrets(p.Namespace).Get(*p.Spec.SecretName, metav1.GetOptions{})
+		if err != nil {
+			if kubeerrors.IsNotFound(err) {
+				// The secret will be created (with an up-to-date server CA bundle) by the controller for PostgresqlClientCertificate resources.
+				continue
+			}
+			return false, fmt.Errorf("failed to get secret \"%s/%s\": %v", p.Namespace, *p.Spec.SecretName, err)
+		}
+		// Skip secrets that are not owned by the PostgresqlClientCertificate resource or that do not contain a server CA bundle.
+		ca, exists := s.Data[constants.ServerCACertificateKey]
+		if !exists || !metav1.IsControlledBy(s, p) || string(ca) == bundle {
+			continue
+		}
+		patch, err := json.Marshal(map[string]interface{}{
+			"data": map[string][]byte{
+				constants.ServerCACertificateKey: []byte(bundle),
+			},
+		})
+		if err != nil {
+			return false, err
+		}
+		if _, err := c.kubeClient.CoreV1().Secrets(s.Namespace).Patch(s.Name, types.MergePatchType, patch); err != nil {
+			return false, fmt.Errorf("failed to update the server ca bundle in secret \"%s/%s\": %v", s.Namespace, s.Name, err)
+		}
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debugf("updated the server ca bundle in secret \"%s/%s\"", s.Namespace, s.Name)
+		updated = true
+	}
+	return updated, nil
+}
+
 // setInstancePassword generates a random password for the CSQLP instance's "postgres" user, sets it on the CSQLP instance and writes it to the specified secret.
 func (c *PostgresqlInstanceController) setInstancePassword(postgresqlInstance *v1alpha1api.PostgresqlInstance, secret *corev1.Secret) error {
 	c.logger.WithField(logFieldName, postgresqlInstance.Name).Debugf("setting the %q user's password", constants.PostgresqlInstanceUsernameValue)
@@ -417,7 +1173,7 @@ func (c *PostgresqlInstanceController) setInstancePassword(postgresqlInstance *v
 		Password: strings.RandomStringWithLength(passwordLength, passwordAlphabet),
 	}
 	// Update the "postgres" user with the generated password.
-	_, err := c.cloudsqlClient.Users.Update(c.projectID, postgresqlInstance.Spec.Name, u.Name, u).Do()
+	_, err := c.cloudsqlClient.Users.Update(c.projectID, postgresqlInstance.Spec.Name, u).Name(u.Name).Do()
 	if err != nil {
 		return err
 	}
@@ -430,3 +1186,135 @@ func (c *PostgresqlInstanceController) setInstancePassword(postgresqlInstance *v
 	_, err = c.kubeClient.CoreV1().Secrets(secret.Namespace).Update(secret)
 	return err
 }
+
+// takeFinalBackup exports every database inside the CSQLP instance associated with the specified PostgresqlInstance resource to Google Cloud Storage, one at a time, before the CSQLP instance is deleted.
+// Progress is tracked in ".status.finalBackup", and the function is meant to be called repeatedly until it returns true, indicating that the final backup is complete (or that there is nothing to back up).
+func (c *PostgresqlInstanceController) takeFinalBackup(postgresqlInstance *v1alpha1api.PostgresqlInstance) (bool, error) {
+	c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug("checking the status of the final backup")
+	// Start the final backup if it hasn't been started yet.
+	if postgresqlInstance.Status.FinalBackup == nil {
+		// If the CSQLP instance has already been deleted, there's nothing to back up.
+		instance, err := c.cloudsqlClient.Instances.Get(c.projectID, postgresqlInstance.Spec.Name).Do()
+		if err != nil {
+			if google.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		}
+		// Databases can only be exported while the CSQLP instance is running, so we report it and skip further processing (but don't error) otherwise.
+		if instance.State != constants.DatabaseInstanceStateRunnable {
+			message := fmt.Sprintf("the final backup cannot be taken as the instance is not running (state: %q) - set the %q annotation to %q to delete it without a final backup", instance.State, constants.SkipFinalBackupAnnotationKey, v1alpha1api.True)
+			setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonFinalBackupFailed, message)
+			c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonFinalBackupFailed, message)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Info(message)
+			return false, nil
+		}
+		// If the CSQLP instance has been stopped (e.g. according to ".spec.schedule"), start it so that its databases can be exported.
+		// The CSQLP instance is not stopped again afterwards, as it is deleted as soon as the final backup is complete.
+		if instance.Settings.ActivationPolicy == constants.DatabaseInstanceActivationPolicyNever {
+			if err := c.setInstanceActivationPolicy(postgresqlInstance, instance, constants.DatabaseInstanceActivationPolicyAlways); err != nil {
+				return false, err
+			}
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Info("starting the instance so that the final backup can be taken")
+			return false, nil
+		}
+		// Grab the list of databases to export.
+		databases, err := c.cloudsqlClient.Databases.List(c.projectID, postgresqlInstance.Spec.Name).Do()
+		if err != nil {
+			return false, err
+		}
+		postgresqlInstance.Status.FinalBackup = &v1alpha1api.PostgresqlInstanceStatusFinalBackup{
+			StartTime: metav1.NewTime(time.Now()),
+		}
+		for _, database := range databases.Items {
+			postgresqlInstance.Status.FinalBackup.Databases = append(postgresqlInstance.Status.FinalBackup.Databases, database.Name)
+		}
+		message := fmt.Sprintf("the final backup has been started (databases: %q)", postgresqlInstance.Status.FinalBackup.Databases)
+		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonFinalBackupStarted, message)
+		c.er.Event(postgresqlInstance, corev1.EventTypeNormal, ReasonFinalBackupStarted, message)
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Info(message)
+	}
+	b := postgresqlInstance.Status.FinalBackup
+
+	// Check whether the export operation currently in progress (if any) has finished.
+	if b.OperationID != "" {
+		op, err := c.cloudsqlClient.Operations.Get(c.projectID, b.OperationID).Do()
+		if err != nil {
+			return false, fmt.Errorf("failed to get operation %q: %v", b.OperationID, err)
+		}
+		operationInProgressOrFailed, operationID, _, operationStatus, operationErrorMessage := isOperationInProgressOrFailedFromOperation(op)
+		switch {
+		case operationInProgressOrFailed && operationErrorMessage == "":
+			message := fmt.Sprintf("the final backup is in progress (operation: %q, status: %q)", operationID, operationStatus)
+			setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonFinalBackupInProgress, message)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(message)
+			return false, nil
+		case operationInProgressOrFailed && operationErrorMessage != "":
+			// The export of the current database has failed, so we report it and attempt to export the database again the next time the PostgresqlInstance resource is processed.
+			// The CSQLP instance is not deleted in the meantime, so that no data is lost.
+			b.OperationID = ""
+			message := fmt.Sprintf("the final backup has failed and will be retried (operation: %q, errors: %q) - set the %q annotation to %q to delete the instance without a final backup", operationID, operationErrorMessage, constants.SkipFinalBackupAnnotationKey, v1alpha1api.True)
+			setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonFinalBackupFailed, message)
+			c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonFinalBackupFailed, message)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Error(message)
+			return false, nil
+		}
+		// At this point we know that the current database has been exported successfully, so we record the resulting export file.
+		file := postgresqlInstanceFinalBackupFileURI(postgresqlInstance)
+		b.Files = append(b.Files, file)
+		b.OperationID = ""
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Infof("exported database to %q", file)
+	}
+
+	// If all databases have been exported, the final backup is complete.
+	if len(b.Files) >= len(b.Databases) {
+		message := fmt.Sprintf("the final backup has completed (files: %q)", b.Files)
+		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonFinalBackupCompleted, message)
+		c.er.Event(postgresqlInstance, corev1.EventTypeNormal, ReasonFinalBackupCompleted, message)
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Info(message)
+		return true, nil
+	}
+
+	// Start exporting the next database.
+	file := postgresqlInstanceFinalBackupFileURI(postgresqlInstance)
+	c.logger.WithField(logFieldName, postgresqlInstance.Name).Infof("exporting database %q of %q to %q", b.Databases[len(b.Files)], postgresqlInstance.Spec.Name, file)
+	fileType := v1alpha1api.PostgresqlExportSpecFileTypeSQL
+	op, err := c.cloudsqlClient.Instances.Export(c.projectID, postgresqlInstance.Spec.Name, &cloudsqladmin.InstancesExportRequest{
+		ExportContext: &cloudsqladmin.ExportContext{
+			Databases: []string{b.Databases[len(b.Files)]},
+			FileType:  fileType.APIValue(),
+			Uri:       file,
+		},
+	}).Do()
+	if err != nil {
+		if google.IsNotFound(err) {
+			// The CSQLP instance has been deleted in the meantime, so there's nothing else to back up.
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Warn("the instance has been deleted before the final backup could complete")
+			return true, nil
+		}
+		if google.IsConflict(err) {
+			// The Cloud SQL Admin API is reporting a conflict.
+			// This most probably means that another operation (such as starting the CSQLP instance) is still in progress, in which case we must wait.
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Debugf("conflict reported while trying to start the final backup - maybe another operation is currently in progress? %v", err)
+			return false, nil
+		}
+		if google.IsBadRequest(err) {
+			// We've been told that the export operation cannot be started.
+			// This most probably means that the destination is not writable, so we report it and wait for the problem to be fixed (or for the final backup to be skipped).
+			message := fmt.Sprintf("the final backup cannot be started: %v - set the %q annotation to %q to delete the instance without a final backup", err, constants.SkipFinalBackupAnnotationKey, v1alpha1api.True)
+			setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonFinalBackupFailed, message)
+			c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonFinalBackupFailed, message)
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Error(message)
+			return false, nil
+		}
+		// The Cloud SQL Admin API returned a different error, which we propagate so that the export operation may be retried.
+		setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonUnexpectedError, err.Error())
+		c.er.Event(postgresqlInstance, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+		return false, err
+	}
+	// Record the ID of the operation so that its status can be tracked.
+	b.OperationID = op.Name
+	message := fmt.Sprintf("the final backup is in progress (operation: %q)", op.Name)
+	setPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonFinalBackupInProgress, message)
+	return false, nil
+}
@@ -20,17 +20,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/kubernetes/pkg/util/slice"
 
 	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
 	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
 
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/cron"
 	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/pointers"
 )
 
@@ -57,9 +62,10 @@ func buildDatabaseInstanceSettings(postgresqlInstance *v1alpha1api.PostgresqlIns
 			Enabled:   *postgresqlInstance.Spec.Backups.Daily.Enabled,
 			StartTime: *postgresqlInstance.Spec.Backups.Daily.StartTime,
 		},
-		DatabaseFlags:  postgresqlInstance.Spec.Flags.APIValue(),
-		DataDiskSizeGb: int64(*postgresqlInstance.Spec.Resources.Disk.SizeMinimumGb),
-		DataDiskType:   postgresqlInstance.Spec.Resources.Disk.Type.APIValue(),
+		DatabaseFlags:          v1alpha1api.DatabaseFlagsAPIValue(postgresqlInstance.Spec.Flags, postgresqlInstance.Spec.DatabaseFlags),
+		DataDiskSizeGb:         int64(*postgresqlInstance.Spec.Resources.Disk.SizeMinimumGb),
+		DataDiskType:           postgresqlInstance.Spec.Resources.Disk.Type.APIValue(),
+		DenyMaintenancePeriods: postgresqlInstance.Spec.Maintenance.DenyPeriods.APIValue(),
 		IpConfiguration: &cloudsqladmin.IpConfiguration{
 			AuthorizedNetworks: postgresqlInstance.Spec.Networking.PublicIP.AuthorizedNetworks.APIValue(),
 			Ipv4Enabled:        *postgresqlInstance.Spec.Networking.PublicIP.Enabled,
@@ -70,6 +76,16 @@ func buildDatabaseInstanceSettings(postgresqlInstance *v1alpha1api.PostgresqlIns
 		Tier:       *postgresqlInstance.Spec.Resources.InstanceType,
 		UserLabels: postgresqlInstance.Spec.Labels,
 	}
+	// The activation policy is the one computed according to ".spec.activationPolicy" and ".spec.schedule".
+	// If it has not been computed yet, or if ".spec.activationPolicy" is not set (as in PostgresqlInstance resources created before it was introduced), the CSQLP instance is meant to be running.
+	switch {
+	case postgresqlInstance.Status.ActivationPolicy != "":
+		r.ActivationPolicy = postgresqlInstance.Status.ActivationPolicy.APIValue()
+	case postgresqlInstance.Spec.ActivationPolicy != nil:
+		r.ActivationPolicy = postgresqlInstance.Spec.ActivationPolicy.APIValue()
+	default:
+		r.ActivationPolicy = constants.DatabaseInstanceActivationPolicyAlways
+	}
 	if *postgresqlInstance.Spec.Maintenance.Day == v1alpha1api.PostgresqlInstanceSpecMaintenanceDayAny {
 		r.MaintenanceWindow = &cloudsqladmin.MaintenanceWindow{}
 	} else {
@@ -78,9 +94,46 @@ func buildDatabaseInstanceSettings(postgresqlInstance *v1alpha1api.PostgresqlIns
 			Hour: postgresqlInstance.Spec.Maintenance.Hour.APIValue(),
 		}
 	}
+	// PostgresqlInstance resources created before ".spec.backups.location", ".spec.backups.pointInTimeRecovery" and ".spec.backups.retainedBackups" were introduced may not have them set, in which case the current settings are kept.
+	// The same goes for ".spec.backups.location" when backups are meant to be stored in the multi-region closest to the CSQLP instance.
+	if postgresqlInstance.Spec.Backups.Location != nil {
+		r.BackupConfiguration.Location = *postgresqlInstance.Spec.Backups.Location
+	}
+	if postgresqlInstance.Spec.Backups.PointInTimeRecovery != nil {
+		r.BackupConfiguration.PointInTimeRecoveryEnabled = *postgresqlInstance.Spec.Backups.PointInTimeRecovery.Enabled
+		r.BackupConfiguration.TransactionLogRetentionDays = int64(*postgresqlInstance.Spec.Backups.PointInTimeRecovery.TransactionLogRetentionDays)
+	}
+	if postgresqlInstance.Spec.Backups.RetainedBackups != nil {
+		r.BackupConfiguration.BackupRetentionSettings = &cloudsqladmin.BackupRetentionSettings{
+			RetainedBackups: int64(*postgresqlInstance.Spec.Backups.RetainedBackups),
+			RetentionUnit:   constants.BackupRetentionSettingsRetentionUnitCount,
+		}
+	}
+	// PostgresqlInstance resources created before ".spec.deletionProtection" was introduced may not have it set, in which case the current setting is kept.
+	// Deletion protection is lifted whenever the "cloudsql.travelaudience.com/allow-deletion" annotation is set to "true", as it would otherwise prevent the CSQLP instance from being deleted.
+	if postgresqlInstance.Spec.DeletionProtection != nil {
+		r.DeletionProtectionEnabled = *postgresqlInstance.Spec.DeletionProtection && postgresqlInstance.Annotations[constants.AllowDeletionAnnotationKey] != v1alpha1api.True
+	}
+	// PostgresqlInstance resources created before ".spec.insights" was introduced may not have it set, in which case the current Query Insights settings are kept.
+	if postgresqlInstance.Spec.Insights != nil {
+		r.InsightsConfig = &cloudsqladmin.InsightsConfig{
+			QueryInsightsEnabled:  *postgresqlInstance.Spec.Insights.Enabled,
+			QueryStringLength:     int64(*postgresqlInstance.Spec.Insights.QueryStringLength),
+			RecordApplicationTags: *postgresqlInstance.Spec.Insights.RecordApplicationTags,
+			RecordClientAddress:   *postgresqlInstance.Spec.Insights.RecordClientAddress,
+		}
+	}
+	// PostgresqlInstance resources created before ".spec.maintenance.updateTrack" was introduced may not have it set, in which case the current update track is kept.
+	if postgresqlInstance.Spec.Maintenance.UpdateTrack != nil {
+		r.MaintenanceWindow.UpdateTrack = postgresqlInstance.Spec.Maintenance.UpdateTrack.APIValue()
+	}
 	if *postgresqlInstance.Spec.Networking.PrivateIP.Enabled {
 		r.IpConfiguration.PrivateNetwork = *postgresqlInstance.Spec.Networking.PrivateIP.Network
 	}
+	// PostgresqlInstance resources created before ".spec.networking.requireSsl" was introduced may not have it set, in which case SSL connections are not enforced.
+	if postgresqlInstance.Spec.Networking.RequireSsl != nil {
+		r.IpConfiguration.RequireSsl = *postgresqlInstance.Spec.Networking.RequireSsl
+	}
 	if *postgresqlInstance.Spec.Resources.Disk.SizeMaximumGb == *postgresqlInstance.Spec.Resources.Disk.SizeMinimumGb {
 		r.StorageAutoResize = pointers.NewBool(false)
 		r.StorageAutoResizeLimit = 0
@@ -91,10 +144,209 @@ func buildDatabaseInstanceSettings(postgresqlInstance *v1alpha1api.PostgresqlIns
 	return r
 }
 
-// isOperationInProgressOrFailed indicates whether the last operation performed on the CSQLP instance associated with the provided PostgresqlInstance resource is still in progress, or has failed.
-func (c *PostgresqlInstanceController) isOperationInProgressOrFailed(postgresqlInstance *v1alpha1api.PostgresqlInstance) (bool, string, string, string, string, error) {
+// computeAdoptionDifferences compares a pre-existing CSQLP instance with the one described by the specified PostgresqlInstance resource, and returns the paths of the fields that differ.
+// Differences in user labels are not reported, since the "owner" label is always set on PostgresqlInstance resources and changing labels has no effect on the CSQLP instance itself.
+// The provided DatabaseInstance object is updated according to the PostgresqlInstance resource, and hence should not be used for anything else.
+func (c *PostgresqlInstanceController) computeAdoptionDifferences(postgresqlInstance *v1alpha1api.PostgresqlInstance, databaseInstance *cloudsqladmin.DatabaseInstance) []string {
+	var r []string
+	if databaseInstance.DatabaseVersion != postgresqlInstance.Spec.Version.APIValue() {
+		r = append(r, ".databaseVersion")
+	}
+	if databaseInstance.Region != *postgresqlInstance.Spec.Location.Region {
+		r = append(r, ".region")
+	}
+	for _, change := range c.updateDatabaseInstanceSettings(postgresqlInstance, databaseInstance, buildDatabaseInstanceSettings(postgresqlInstance)) {
+		if change != ".settings.userLabels" {
+			r = append(r, change)
+		}
+	}
+	return r
+}
+
+// computeDisruptiveChanges compares the current settings of a CSQLP instance with the desired ones, and returns a description of each pending change that requires restarting the CSQLP instance.
+// It additionally returns the names of the database flags whose pending changes require restarting the CSQLP instance.
+// Database flags are looked up in the catalog of database flags supported by the specified database version (i.e. the one the CSQLP instance is running).
+func (c *PostgresqlInstanceController) computeDisruptiveChanges(currentSettings, desiredSettings *cloudsqladmin.Settings, databaseVersion string) (changes, flags []string, err error) {
+	if currentSettings.AvailabilityType != desiredSettings.AvailabilityType {
+		changes = append(changes, fmt.Sprintf("availability type (%q to %q)", currentSettings.AvailabilityType, desiredSettings.AvailabilityType))
+	}
+	if currentSettings.Tier != desiredSettings.Tier {
+		changes = append(changes, fmt.Sprintf("instance type (%q to %q)", currentSettings.Tier, desiredSettings.Tier))
+	}
+	// Connecting the CSQLP instance to a (different) VPC network in order to enable private IP, as well as moving it to a different zone, restart the CSQLP instance.
+	if currentSettings.IpConfiguration != nil && currentSettings.IpConfiguration.PrivateNetwork != desiredSettings.IpConfiguration.PrivateNetwork {
+		changes = append(changes, fmt.Sprintf("private network (%q to %q)", currentSettings.IpConfiguration.PrivateNetwork, desiredSettings.IpConfiguration.PrivateNetwork))
+	}
+	if currentSettings.LocationPreference != nil && desiredSettings.LocationPreference.Zone != "" && currentSettings.LocationPreference.Zone != desiredSettings.LocationPreference.Zone {
+		changes = append(changes, fmt.Sprintf("zone (%q to %q)", currentSettings.LocationPreference.Zone, desiredSettings.LocationPreference.Zone))
+	}
+	// If the database flags are not being changed, there's nothing else to check.
+	if reflect.DeepEqual(currentSettings.DatabaseFlags, desiredSettings.DatabaseFlags) {
+		return changes, nil, nil
+	}
+	// Check whether each added, removed or changed database flag requires a restart, according to the catalog of database flags.
+	current := databaseFlagsMap(currentSettings.DatabaseFlags)
+	desired := databaseFlagsMap(desiredSettings.DatabaseFlags)
+	names := make([]string, 0, len(current)+len(desired))
+	for name := range current {
+		names = append(names, name)
+	}
+	for name := range desired {
+		if _, exists := current[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var catalog map[string]*cloudsqladmin.Flag
+	for _, name := range names {
+		currentValue, currentExists := current[name]
+		desiredValue, desiredExists := desired[name]
+		if currentExists == desiredExists && currentValue == desiredValue {
+			continue
+		}
+		if catalog == nil {
+			if catalog, err = c.databaseFlagsCatalog.Get(databaseVersion); err != nil {
+				return nil, nil, err
+			}
+		}
+		// Database flags which are not present in the catalog for the current database version are conservatively assumed to require a restart.
+		if flag, exists := catalog[name]; exists && !flag.RequiresRestart {
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("flag %q", name))
+		flags = append(flags, name)
+	}
+	return changes, flags, nil
+}
+
+// databaseFlagsMap returns a map containing the value of each of the provided database flags, keyed by flag name.
+func databaseFlagsMap(flags []*cloudsqladmin.DatabaseFlags) map[string]string {
+	r := make(map[string]string, len(flags))
+	for _, flag := range flags {
+		r[flag.Name] = flag.Value
+	}
+	return r
+}
+
+// describeDisruptiveUpdatePolicy returns a human-readable description of when changes that require restarting the specified CSQLP instance are applied.
+func describeDisruptiveUpdatePolicy(postgresqlInstance *v1alpha1api.PostgresqlInstance) string {
+	m := postgresqlInstance.Spec.Maintenance
+	switch {
+	case m == nil || m.DisruptiveUpdatePolicy == nil:
+		return "immediately"
+	case *m.DisruptiveUpdatePolicy == v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow && m.Day != nil && m.Hour != nil:
+		return fmt.Sprintf("during the next maintenance window (%s at %s UTC)", *m.Day, *m.Hour)
+	case *m.DisruptiveUpdatePolicy == v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyOnApproval:
+		return fmt.Sprintf("once approved using the %q annotation", constants.DisruptiveUpdateApprovedAtAnnotationKey)
+	default:
+		return "immediately"
+	}
+}
+
+// getPostgresqlInstanceCondition returns the condition of the provided type associated with the provided PostgresqlInstance resource, or nil if no such condition exists.
+func getPostgresqlInstanceCondition(postgresqlInstance *v1alpha1api.PostgresqlInstance, conditionType v1alpha1api.PostgresqlInstanceStatusConditionType) *v1alpha1api.PostgresqlInstanceStatusCondition {
+	for idx := range postgresqlInstance.Status.Conditions {
+		if postgresqlInstance.Status.Conditions[idx].Type == conditionType {
+			return &postgresqlInstance.Status.Conditions[idx]
+		}
+	}
+	return nil
+}
+
+// getPromotedPostgresqlReplica returns the most recently promoted read replica of the CSQLP instance represented by the provided PostgresqlInstance resource, or nil if no such read replica exists.
+func (c *PostgresqlInstanceController) getPromotedPostgresqlReplica(postgresqlInstance *v1alpha1api.PostgresqlInstance) (*v1alpha1api.PostgresqlReplica, error) {
+	replicas, err := c.postgresqlReplicaLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var (
+		r *v1alpha1api.PostgresqlReplica
+		t v1.Time
+	)
+	for _, replica := range replicas {
+		if replica.Spec.Primary != postgresqlInstance.Name || replica.Status.ConnectionName == "" || !isPostgresqlReplicaPromoted(replica) {
+			continue
+		}
+		cdn := getPostgresqlReplicaCondition(replica, v1alpha1api.PostgresqlReplicaStatusConditionTypePromoted)
+		if r == nil || t.Before(&cdn.LastTransitionTime) {
+			r, t = replica, cdn.LastTransitionTime
+		}
+	}
+	return r, nil
+}
+
+// holdBackDisruptiveChanges updates the provided desired settings of a CSQLP instance so that pending changes which require restarting the CSQLP instance are not applied.
+// disruptiveFlags contains the names of the database flags whose pending changes require restarting the CSQLP instance.
+func holdBackDisruptiveChanges(currentSettings, desiredSettings *cloudsqladmin.Settings, disruptiveFlags []string) {
+	desiredSettings.AvailabilityType = currentSettings.AvailabilityType
+	desiredSettings.Tier = currentSettings.Tier
+	if currentSettings.IpConfiguration != nil {
+		desiredSettings.IpConfiguration.PrivateNetwork = currentSettings.IpConfiguration.PrivateNetwork
+	}
+	if currentSettings.LocationPreference != nil && desiredSettings.LocationPreference.Zone != "" {
+		desiredSettings.LocationPreference.Zone = currentSettings.LocationPreference.Zone
+	}
+	if len(disruptiveFlags) == 0 {
+		return
+	}
+	// Keep the current value of every database flag whose pending change requires a restart, and apply the remaining changes.
+	current := databaseFlagsMap(currentSettings.DatabaseFlags)
+	desired := databaseFlagsMap(desiredSettings.DatabaseFlags)
+	flags := make([]*cloudsqladmin.DatabaseFlags, 0, len(desiredSettings.DatabaseFlags))
+	for _, flag := range desiredSettings.DatabaseFlags {
+		if !slice.ContainsString(disruptiveFlags, flag.Name, nil) {
+			flags = append(flags, flag)
+			continue
+		}
+		if value, exists := current[flag.Name]; exists {
+			flags = append(flags, &cloudsqladmin.DatabaseFlags{
+				Name:  flag.Name,
+				Value: value,
+			})
+		}
+	}
+	for _, flag := range currentSettings.DatabaseFlags {
+		if _, exists := desired[flag.Name]; !exists && slice.ContainsString(disruptiveFlags, flag.Name, nil) {
+			flags = append(flags, flag)
+		}
+	}
+	if len(flags) == 0 {
+		flags = nil
+	}
+	desiredSettings.DatabaseFlags = flags
+}
+
+// hasOperationSince indicates whether an operation of the specified type has been started on the CSQLP instance with the provided name at or after the specified time (in RFC 3339 format).
+func hasOperationSince(cloudsqlClient *cloudsqladmin.Service, projectID, instanceName, operationType, since string) (bool, error) {
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %q as a timestamp: %v", since, err)
+	}
+	// Grab the list of operations for the CSQLP instance.
+	ops, err := cloudsqlClient.Operations.List(projectID).Instance(instanceName).Do()
+	if err != nil {
+		return false, err
+	}
+	// Operations are sorted by reverse chronological order, so we can stop looking as soon as we find an operation started before the specified time.
+	for _, op := range ops.Items {
+		insertTime, err := time.Parse(time.RFC3339, op.InsertTime)
+		if err != nil {
+			return false, fmt.Errorf("failed to parse the insert time of operation %q (%q): %v", op.Name, op.InsertTime, err)
+		}
+		if insertTime.Before(t) {
+			return false, nil
+		}
+		if op.OperationType == operationType {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isOperationInProgressOrFailed indicates whether the last operation performed on the CSQLP instance with the provided name is still in progress, or has failed.
+func isOperationInProgressOrFailed(cloudsqlClient *cloudsqladmin.Service, projectID, instanceName string) (bool, string, string, string, string, error) {
 	// Grab the list of operations for the CSQLP instance.
-	ops, err := c.cloudsqlClient.Operations.List(c.projectID, postgresqlInstance.Spec.Name).Do()
+	ops, err := cloudsqlClient.Operations.List(projectID).Instance(instanceName).Do()
 	if err != nil {
 		return false, "", "", "", "", err
 	}
@@ -103,104 +355,267 @@ func (c *PostgresqlInstanceController) isOperationInProgressOrFailed(postgresqlI
 		return false, "", "", "", "", nil
 	}
 	// Operations are sorted by reverse chronological order, so the last (or current) operation is the first item in the slice.
-	lastOp := ops.Items[0]
-	// If the last operation's status is "DONE" and there are no errors, there's nothing else to check.
-	if lastOp.Status == constants.OperationStatusDone && (lastOp.Error == nil || len(lastOp.Error.Errors) == 0) {
-		return false, lastOp.Name, lastOp.OperationType, lastOp.Status, "", nil
+	inProgressOrFailed, id, operationType, status, errorMessage := isOperationInProgressOrFailedFromOperation(ops.Items[0])
+	return inProgressOrFailed, id, operationType, status, errorMessage, nil
+}
+
+// isOperationInProgressOrFailedFromOperation indicates whether the provided operation is still in progress, or has failed.
+func isOperationInProgressOrFailedFromOperation(op *cloudsqladmin.Operation) (bool, string, string, string, string) {
+	// If the operation's status is "DONE" and there are no errors, there's nothing else to check.
+	if op.Status == constants.OperationStatusDone && (op.Error == nil || len(op.Error.Errors) == 0) {
+		return false, op.Name, op.OperationType, op.Status, ""
 	}
 	// Check whether there are any errors, and, in case there are, build the error message by concatenating them.
 	errorMessage := ""
-	if lastOp.Error != nil && len(lastOp.Error.Errors) > 0 {
-		for _, err := range lastOp.Error.Errors {
+	if op.Error != nil && len(op.Error.Errors) > 0 {
+		for _, err := range op.Error.Errors {
 			errorMessage += fmt.Sprintf("%s; %q", err.Code, err.Message)
 		}
 	}
-	return true, lastOp.Name, lastOp.OperationType, lastOp.Status, errorMessage, nil
+	return true, op.Name, op.OperationType, op.Status, errorMessage
+}
+
+// joinAdoptionDifferences returns a human-readable list of the provided differences.
+func joinAdoptionDifferences(differences []string) string {
+	return strings.Join(differences, ", ")
+}
+
+// joinDisruptiveChanges returns a human-readable list of the provided changes.
+func joinDisruptiveChanges(changes []string) string {
+	return strings.Join(changes, ", ")
+}
+
+// lastScheduledActivation returns the last time within the specified interval (inclusive) at which the provided schedule activates, or the zero time if no such time exists.
+func lastScheduledActivation(schedule *cron.Schedule, from, to time.Time) time.Time {
+	var last time.Time
+	for t := schedule.Next(from.Add(-time.Minute)); !t.IsZero() && !t.After(to); t = schedule.Next(t) {
+		last = t
+	}
+	return last
+}
+
+// mayApplyDisruptiveChanges returns whether pending changes that require restarting the specified CSQLP instance may be applied at the specified time.
+func mayApplyDisruptiveChanges(postgresqlInstance *v1alpha1api.PostgresqlInstance, now time.Time) bool {
+	m := postgresqlInstance.Spec.Maintenance
+	if m == nil || m.DisruptiveUpdatePolicy == nil {
+		return true
+	}
+	switch *m.DisruptiveUpdatePolicy {
+	case v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow:
+		// Changes may be applied if and only if the current time falls within the one-hour maintenance window.
+		if m.Day == nil || m.Hour == nil || *m.Day == v1alpha1api.PostgresqlInstanceSpecMaintenanceDayAny {
+			return true
+		}
+		now = now.UTC()
+		day := int64(now.Weekday())
+		if day == 0 {
+			// Cloud SQL represents Sundays as 7 rather than 0.
+			day = 7
+		}
+		return day == m.Day.APIValue() && int64(now.Hour()) == m.Hour.APIValue()
+	case v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyOnApproval:
+		// Changes may be applied if and only if an approval has been given and not yet handled.
+		v, exists := postgresqlInstance.Annotations[constants.DisruptiveUpdateApprovedAtAnnotationKey]
+		return exists && v != postgresqlInstance.Status.LastDisruptiveUpdateApprovedAt
+	default:
+		return true
+	}
+}
+
+// nextMaintenanceWindow returns the time at which the next maintenance window of the specified CSQLP instance starts after the specified time.
+// It returns the zero time if changes that require restarting the CSQLP instance are not held back until a specific maintenance window.
+func nextMaintenanceWindow(postgresqlInstance *v1alpha1api.PostgresqlInstance, now time.Time) time.Time {
+	m := postgresqlInstance.Spec.Maintenance
+	if m == nil || m.DisruptiveUpdatePolicy == nil || *m.DisruptiveUpdatePolicy != v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow {
+		return time.Time{}
+	}
+	if m.Day == nil || m.Hour == nil || *m.Day == v1alpha1api.PostgresqlInstanceSpecMaintenanceDayAny {
+		return time.Time{}
+	}
+	now = now.UTC()
+	// Cloud SQL represents Sundays as 7 rather than 0.
+	day := int(m.Day.APIValue() % 7)
+	t := time.Date(now.Year(), now.Month(), now.Day(), int(m.Hour.APIValue()), 0, 0, 0, time.UTC).AddDate(0, 0, (day-int(now.Weekday())+7)%7)
+	if !t.After(now) {
+		t = t.AddDate(0, 0, 7)
+	}
+	return t
 }
 
-// updateDatabaseInstanceSettings updates the provided DatabaseInstance object according to the provided PostgresqlInstance resource.
-func (c *PostgresqlInstanceController) updateDatabaseInstanceSettings(postgresqlInstance *v1alpha1api.PostgresqlInstance, databaseInstance *cloudsqladmin.DatabaseInstance) (mustUpdate bool) {
-	// Compute the desired settings based on the provided PostgresqlInstance resource.
-	desiredSettings := buildDatabaseInstanceSettings(postgresqlInstance)
+// parsePostgresqlInstanceSchedule parses the start and stop cron expressions of the provided schedule in the schedule's time zone.
+func parsePostgresqlInstanceSchedule(schedule *v1alpha1api.PostgresqlInstanceSpecSchedule) (*cron.Schedule, *cron.Schedule, error) {
+	loc := time.UTC
+	if schedule.TimeZone != nil {
+		l, err := time.LoadLocation(*schedule.TimeZone)
+		if err != nil {
+			return nil, nil, err
+		}
+		loc = l
+	}
+	start, err := cron.ParseInLocation(schedule.Start, loc)
+	if err != nil {
+		return nil, nil, err
+	}
+	stop, err := cron.ParseInLocation(schedule.Stop, loc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return start, stop, nil
+}
+
+// updateDatabaseInstanceSettings updates the provided DatabaseInstance object according to the provided desired settings, computed from the provided PostgresqlInstance resource.
+// It returns the paths of the fields that have been updated.
+func (c *PostgresqlInstanceController) updateDatabaseInstanceSettings(postgresqlInstance *v1alpha1api.PostgresqlInstance, databaseInstance *cloudsqladmin.DatabaseInstance, desiredSettings *cloudsqladmin.Settings) (changes []string) {
 	// Update each field of the provided CSQLP instance that differs from the desired value.
+	if databaseInstance.Settings.ActivationPolicy != desiredSettings.ActivationPolicy {
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.activationPolicy must be updated")
+		databaseInstance.Settings.ActivationPolicy = desiredSettings.ActivationPolicy
+		changes = append(changes, ".settings.activationPolicy")
+	}
 	if databaseInstance.Settings.AvailabilityType != desiredSettings.AvailabilityType {
 		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.availabilityType must be updated")
 		databaseInstance.Settings.AvailabilityType = desiredSettings.AvailabilityType
-		mustUpdate = true
+		changes = append(changes, ".settings.availabilityType")
 	}
 	if databaseInstance.Settings.BackupConfiguration.Enabled != desiredSettings.BackupConfiguration.Enabled {
 		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.backupConfiguration.enabled must be updated")
 		databaseInstance.Settings.BackupConfiguration.Enabled = desiredSettings.BackupConfiguration.Enabled
-		mustUpdate = true
+		changes = append(changes, ".settings.backupConfiguration.enabled")
 	}
 	if databaseInstance.Settings.BackupConfiguration.StartTime != desiredSettings.BackupConfiguration.StartTime {
 		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.backupConfiguration.startTime must be updated")
 		databaseInstance.Settings.BackupConfiguration.StartTime = desiredSettings.BackupConfiguration.StartTime
-		mustUpdate = true
+		changes = append(changes, ".settings.backupConfiguration.startTime")
+	}
+	if desiredSettings.BackupConfiguration.Location != "" && databaseInstance.Settings.BackupConfiguration.Location != desiredSettings.BackupConfiguration.Location {
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.backupConfiguration.location must be updated")
+		databaseInstance.Settings.BackupConfiguration.Location = desiredSettings.BackupConfiguration.Location
+		changes = append(changes, ".settings.backupConfiguration.location")
+	}
+	if postgresqlInstance.Spec.Backups.PointInTimeRecovery != nil && databaseInstance.Settings.BackupConfiguration.PointInTimeRecoveryEnabled != desiredSettings.BackupConfiguration.PointInTimeRecoveryEnabled {
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.backupConfiguration.pointInTimeRecoveryEnabled must be updated")
+		databaseInstance.Settings.BackupConfiguration.PointInTimeRecoveryEnabled = desiredSettings.BackupConfiguration.PointInTimeRecoveryEnabled
+		changes = append(changes, ".settings.backupConfiguration.pointInTimeRecoveryEnabled")
+	}
+	if postgresqlInstance.Spec.Backups.PointInTimeRecovery != nil && databaseInstance.Settings.BackupConfiguration.TransactionLogRetentionDays != desiredSettings.BackupConfiguration.TransactionLogRetentionDays {
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.backupConfiguration.transactionLogRetentionDays must be updated")
+		databaseInstance.Settings.BackupConfiguration.TransactionLogRetentionDays = desiredSettings.BackupConfiguration.TransactionLogRetentionDays
+		changes = append(changes, ".settings.backupConfiguration.transactionLogRetentionDays")
+	}
+	if desiredSettings.BackupConfiguration.BackupRetentionSettings != nil && !reflect.DeepEqual(databaseInstance.Settings.BackupConfiguration.BackupRetentionSettings, desiredSettings.BackupConfiguration.BackupRetentionSettings) {
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.backupConfiguration.backupRetentionSettings must be updated")
+		databaseInstance.Settings.BackupConfiguration.BackupRetentionSettings = desiredSettings.BackupConfiguration.BackupRetentionSettings
+		changes = append(changes, ".settings.backupConfiguration.backupRetentionSettings")
 	}
 	if !reflect.DeepEqual(databaseInstance.Settings.DatabaseFlags, desiredSettings.DatabaseFlags) {
 		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.databaseFlags must be updated")
 		databaseInstance.Settings.DatabaseFlags = desiredSettings.DatabaseFlags
-		mustUpdate = true
+		changes = append(changes, ".settings.databaseFlags")
 	}
 	if databaseInstance.Settings.DataDiskSizeGb != desiredSettings.DataDiskSizeGb {
 		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.dataDiskSizeGb must be updated")
 		databaseInstance.Settings.DataDiskSizeGb = desiredSettings.DataDiskSizeGb
-		mustUpdate = true
+		changes = append(changes, ".settings.dataDiskSizeGb")
+	}
+	if postgresqlInstance.Spec.DeletionProtection != nil && databaseInstance.Settings.DeletionProtectionEnabled != desiredSettings.DeletionProtectionEnabled {
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.deletionProtectionEnabled must be updated")
+		databaseInstance.Settings.DeletionProtectionEnabled = desiredSettings.DeletionProtectionEnabled
+		changes = append(changes, ".settings.deletionProtectionEnabled")
+	}
+	if !reflect.DeepEqual(databaseInstance.Settings.DenyMaintenancePeriods, desiredSettings.DenyMaintenancePeriods) {
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.denyMaintenancePeriods must be updated")
+		databaseInstance.Settings.DenyMaintenancePeriods = desiredSettings.DenyMaintenancePeriods
+		changes = append(changes, ".settings.denyMaintenancePeriods")
 	}
 	if !reflect.DeepEqual(databaseInstance.Settings.IpConfiguration.AuthorizedNetworks, desiredSettings.IpConfiguration.AuthorizedNetworks) {
 		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.ipConfiguration.authorizedNetworks must be updated")
 		databaseInstance.Settings.IpConfiguration.AuthorizedNetworks = desiredSettings.IpConfiguration.AuthorizedNetworks
-		mustUpdate = true
+		changes = append(changes, ".settings.ipConfiguration.authorizedNetworks")
+	}
+	if desiredSettings.InsightsConfig != nil {
+		// Fields of ".settings.insightsConfig" which are not managed by the operator (e.g. "queryPlansPerMinute") are kept.
+		if databaseInstance.Settings.InsightsConfig == nil {
+			databaseInstance.Settings.InsightsConfig = &cloudsqladmin.InsightsConfig{}
+		}
+		if databaseInstance.Settings.InsightsConfig.QueryInsightsEnabled != desiredSettings.InsightsConfig.QueryInsightsEnabled {
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.insightsConfig.queryInsightsEnabled must be updated")
+			databaseInstance.Settings.InsightsConfig.QueryInsightsEnabled = desiredSettings.InsightsConfig.QueryInsightsEnabled
+			changes = append(changes, ".settings.insightsConfig.queryInsightsEnabled")
+		}
+		if databaseInstance.Settings.InsightsConfig.QueryStringLength != desiredSettings.InsightsConfig.QueryStringLength {
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.insightsConfig.queryStringLength must be updated")
+			databaseInstance.Settings.InsightsConfig.QueryStringLength = desiredSettings.InsightsConfig.QueryStringLength
+			changes = append(changes, ".settings.insightsConfig.queryStringLength")
+		}
+		if databaseInstance.Settings.InsightsConfig.RecordApplicationTags != desiredSettings.InsightsConfig.RecordApplicationTags {
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.insightsConfig.recordApplicationTags must be updated")
+			databaseInstance.Settings.InsightsConfig.RecordApplicationTags = desiredSettings.InsightsConfig.RecordApplicationTags
+			changes = append(changes, ".settings.insightsConfig.recordApplicationTags")
+		}
+		if databaseInstance.Settings.InsightsConfig.RecordClientAddress != desiredSettings.InsightsConfig.RecordClientAddress {
+			c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.insightsConfig.recordClientAddress must be updated")
+			databaseInstance.Settings.InsightsConfig.RecordClientAddress = desiredSettings.InsightsConfig.RecordClientAddress
+			changes = append(changes, ".settings.insightsConfig.recordClientAddress")
+		}
 	}
 	if databaseInstance.Settings.IpConfiguration.Ipv4Enabled != desiredSettings.IpConfiguration.Ipv4Enabled {
 		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.ipConfiguration.ipv4Enabled must be updated")
 		databaseInstance.Settings.IpConfiguration.Ipv4Enabled = desiredSettings.IpConfiguration.Ipv4Enabled
-		mustUpdate = true
+		changes = append(changes, ".settings.ipConfiguration.ipv4Enabled")
 	}
 	if databaseInstance.Settings.IpConfiguration.PrivateNetwork != desiredSettings.IpConfiguration.PrivateNetwork {
 		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.ipConfiguration.privateNetwork must be updated")
 		databaseInstance.Settings.IpConfiguration.PrivateNetwork = desiredSettings.IpConfiguration.PrivateNetwork
-		mustUpdate = true
+		changes = append(changes, ".settings.ipConfiguration.privateNetwork")
+	}
+	if databaseInstance.Settings.IpConfiguration.RequireSsl != desiredSettings.IpConfiguration.RequireSsl {
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.ipConfiguration.requireSsl must be updated")
+		databaseInstance.Settings.IpConfiguration.RequireSsl = desiredSettings.IpConfiguration.RequireSsl
+		changes = append(changes, ".settings.ipConfiguration.requireSsl")
 	}
 	if *postgresqlInstance.Spec.Location.Zone != v1alpha1api.PostgresqlInstanceSpecLocationZoneAny && databaseInstance.Settings.LocationPreference.Zone != desiredSettings.LocationPreference.Zone {
 		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.locationPreference.zone must be updated")
 		databaseInstance.Settings.LocationPreference.Zone = desiredSettings.LocationPreference.Zone
-		mustUpdate = true
+		changes = append(changes, ".settings.locationPreference.zone")
 	}
 	if databaseInstance.Settings.MaintenanceWindow.Day != desiredSettings.MaintenanceWindow.Day {
 		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.maintenanceWindow.day must be updated")
 		databaseInstance.Settings.MaintenanceWindow.Day = desiredSettings.MaintenanceWindow.Day
-		mustUpdate = true
+		changes = append(changes, ".settings.maintenanceWindow.day")
 	}
 	if databaseInstance.Settings.MaintenanceWindow.Hour != desiredSettings.MaintenanceWindow.Hour {
 		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.maintenanceWindow.hour must be updated")
 		databaseInstance.Settings.MaintenanceWindow.Hour = desiredSettings.MaintenanceWindow.Hour
-		mustUpdate = true
+		changes = append(changes, ".settings.maintenanceWindow.hour")
+	}
+	if desiredSettings.MaintenanceWindow.UpdateTrack != "" && databaseInstance.Settings.MaintenanceWindow.UpdateTrack != desiredSettings.MaintenanceWindow.UpdateTrack {
+		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.maintenanceWindow.updateTrack must be updated")
+		databaseInstance.Settings.MaintenanceWindow.UpdateTrack = desiredSettings.MaintenanceWindow.UpdateTrack
+		changes = append(changes, ".settings.maintenanceWindow.updateTrack")
 	}
 	if *databaseInstance.Settings.StorageAutoResize != *desiredSettings.StorageAutoResize {
 		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.storageAutoResize must be updated")
 		*databaseInstance.Settings.StorageAutoResize = *desiredSettings.StorageAutoResize
-		mustUpdate = true
+		changes = append(changes, ".settings.storageAutoResize")
 	}
 	if databaseInstance.Settings.StorageAutoResizeLimit != desiredSettings.StorageAutoResizeLimit {
 		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.storageAutoResizeLimit must be updated")
 		databaseInstance.Settings.StorageAutoResizeLimit = desiredSettings.StorageAutoResizeLimit
-		mustUpdate = true
+		changes = append(changes, ".settings.storageAutoResizeLimit")
 	}
 	if databaseInstance.Settings.Tier != desiredSettings.Tier {
 		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.tier must be updated")
 		databaseInstance.Settings.Tier = desiredSettings.Tier
-		mustUpdate = true
+		changes = append(changes, ".settings.tier")
 	}
 	if !reflect.DeepEqual(databaseInstance.Settings.UserLabels, desiredSettings.UserLabels) {
 		c.logger.WithField(logFieldName, postgresqlInstance.Name).Debug(".settings.userLabels must be updated")
 		databaseInstance.Settings.UserLabels = desiredSettings.UserLabels
-		mustUpdate = true
+		changes = append(changes, ".settings.userLabels")
 	}
 	// Force sending fields as required.
 	setForceSendFields(databaseInstance)
-	return mustUpdate
+	return changes
 }
 
 // patchPostgresqlInstance updates the provided PostgresqlInstance using patch semantics.
@@ -233,20 +648,44 @@ func (c *PostgresqlInstanceController) patchPostgresqlInstanceStatus(oldObj, new
 	return c.patchPostgresqlInstance(oldObj, newObj, "status")
 }
 
+// postgresqlInstanceFinalBackupFileURI returns the Google Cloud Storage URI of the export file for the next database to be exported as part of the final backup of the provided PostgresqlInstance resource.
+// Export files are written to "<destination>/<name>/<start time>/<database>.sql.gz".
+func postgresqlInstanceFinalBackupFileURI(postgresqlInstance *v1alpha1api.PostgresqlInstance) string {
+	fileType := v1alpha1api.PostgresqlExportSpecFileTypeSQL
+	b := postgresqlInstance.Status.FinalBackup
+	return fmt.Sprintf("%s/%s/%s/%s.%s",
+		strings.TrimSuffix(postgresqlInstance.Spec.FinalBackup.Destination, "/"),
+		postgresqlInstance.Spec.Name,
+		b.StartTime.UTC().Format("20060102T150405Z"),
+		b.Databases[len(b.Files)],
+		fileType.Extension())
+}
+
 // setForceSendFields updates the provided DatabaseInstance object in order to force sending fields that would otherwise be omitted from the JSON representation due to the presence of the "omitempty" tag.
 // This is required in order to, for example, be able to explicitly set ".settings.ipConfiguration.ipv4Enabled" to "false" or ".settings.maintenanceWindow.hour" to "0".
 func setForceSendFields(databaseInstance *cloudsqladmin.DatabaseInstance) {
 	databaseInstance.Settings.BackupConfiguration.ForceSendFields = []string{
 		"Enabled",
+		"PointInTimeRecoveryEnabled",
+	}
+	if databaseInstance.Settings.InsightsConfig != nil {
+		databaseInstance.Settings.InsightsConfig.ForceSendFields = []string{
+			"QueryInsightsEnabled",
+			"RecordApplicationTags",
+			"RecordClientAddress",
+		}
 	}
 	databaseInstance.Settings.IpConfiguration.ForceSendFields = []string{
 		"Ipv4Enabled",
 		"PrivateNetwork",
+		"RequireSsl",
 	}
 	databaseInstance.Settings.MaintenanceWindow.ForceSendFields = []string{
 		"Hour",
 	}
 	databaseInstance.Settings.ForceSendFields = []string{
+		"DeletionProtectionEnabled",
+		"DenyMaintenancePeriods",
 		"StorageAutoResizeLimit",
 	}
 }
@@ -282,7 +721,7 @@ func setPostgresqlInstanceCondition(postgresqlInstance *v1alpha1api.PostgresqlIn
 	postgresqlInstance.Status.Conditions = append(postgresqlInstance.Status.Conditions, newCondition)
 }
 
-// setPostgresqlInstanceConnectionNameAndIPs sets the connection name and the set of IPs of associated with the provided CSQLP instance.
+// setPostgresqlInstanceConnectionNameAndIPs sets the name, the connection name and the set of IPs of associated with the provided CSQLP instance.
 func setPostgresqlInstanceConnectionNameAndIPs(postgresqlInstance *v1alpha1api.PostgresqlInstance, databaseInstance *cloudsqladmin.DatabaseInstance) {
 	postgresqlInstance.Status.IPs = v1alpha1api.PostgresqlInstanceStatusIPAddresses{}
 	for _, ip := range databaseInstance.IpAddresses {
@@ -298,4 +737,5 @@ func setPostgresqlInstanceConnectionNameAndIPs(postgresqlInstance *v1alpha1api.P
 		}
 	}
 	postgresqlInstance.Status.ConnectionName = databaseInstance.ConnectionName
+	postgresqlInstance.Status.InstanceName = databaseInstance.Name
 }
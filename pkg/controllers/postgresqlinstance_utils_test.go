@@ -0,0 +1,662 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/cron"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/pointers"
+)
+
+// newMaintenancePostgresqlInstance returns a PostgresqlInstance resource with the specified maintenance window and disruptive update policy.
+func newMaintenancePostgresqlInstance(day v1alpha1api.PostgresqlInstanceSpecMaintenanceDay, hour v1alpha1api.PostgresqlInstanceSpecMaintenanceHour, policy v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicy) *v1alpha1api.PostgresqlInstance {
+	return &v1alpha1api.PostgresqlInstance{
+		Spec: v1alpha1api.PostgresqlInstanceSpec{
+			Maintenance: &v1alpha1api.PostgresqlInstanceSpecMaintenance{
+				Day:                    &day,
+				DisruptiveUpdatePolicy: &policy,
+				Hour:                   &hour,
+			},
+		},
+	}
+}
+
+// newSettings returns a Settings object with the specified tier, private network and database flags.
+func newSettings(tier, privateNetwork string, flags ...*cloudsqladmin.DatabaseFlags) *cloudsqladmin.Settings {
+	return &cloudsqladmin.Settings{
+		AvailabilityType: "ZONAL",
+		DatabaseFlags:    flags,
+		IpConfiguration: &cloudsqladmin.IpConfiguration{
+			Ipv4Enabled:    true,
+			PrivateNetwork: privateNetwork,
+		},
+		LocationPreference: &cloudsqladmin.LocationPreference{
+			Zone: "europe-west1-b",
+		},
+		Tier: tier,
+	}
+}
+
+func TestComputeDisruptiveChanges(t *testing.T) {
+	c := &PostgresqlInstanceController{}
+	tests := []struct {
+		description     string
+		currentSettings *cloudsqladmin.Settings
+		desiredSettings *cloudsqladmin.Settings
+		expectedChanges int
+	}{
+		{
+			description:     "no changes",
+			currentSettings: newSettings("db-custom-1-3840", ""),
+			desiredSettings: newSettings("db-custom-1-3840", ""),
+		},
+		{
+			description:     "instance type",
+			currentSettings: newSettings("db-custom-1-3840", ""),
+			desiredSettings: newSettings("db-custom-2-7680", ""),
+			expectedChanges: 1,
+		},
+		{
+			description:     "private network",
+			currentSettings: newSettings("db-custom-1-3840", ""),
+			desiredSettings: newSettings("db-custom-1-3840", "projects/p/global/networks/default"),
+			expectedChanges: 1,
+		},
+		{
+			description:     "non-disruptive change",
+			currentSettings: newSettings("db-custom-1-3840", ""),
+			desiredSettings: func() *cloudsqladmin.Settings {
+				s := newSettings("db-custom-1-3840", "")
+				s.IpConfiguration.RequireSsl = true
+				s.DataDiskSizeGb = 100
+				return s
+			}(),
+		},
+		{
+			description:     "zone",
+			currentSettings: newSettings("db-custom-1-3840", ""),
+			desiredSettings: func() *cloudsqladmin.Settings {
+				s := newSettings("db-custom-1-3840", "")
+				s.LocationPreference.Zone = "europe-west1-c"
+				return s
+			}(),
+			expectedChanges: 1,
+		},
+		{
+			description:     "any zone",
+			currentSettings: newSettings("db-custom-1-3840", ""),
+			desiredSettings: func() *cloudsqladmin.Settings {
+				s := newSettings("db-custom-1-3840", "")
+				s.LocationPreference.Zone = ""
+				return s
+			}(),
+		},
+	}
+	for _, test := range tests {
+		changes, flags, err := c.computeDisruptiveChanges(test.currentSettings, test.desiredSettings, "POSTGRES_9_6")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.description, err)
+			continue
+		}
+		if len(changes) != test.expectedChanges || len(flags) != 0 {
+			t.Errorf("%s: expected %d disruptive changes and no flags, got %q and %q", test.description, test.expectedChanges, changes, flags)
+		}
+	}
+}
+
+func TestHoldBackDisruptiveChanges(t *testing.T) {
+	currentSettings := newSettings("db-custom-1-3840", "",
+		&cloudsqladmin.DatabaseFlags{Name: "max_connections", Value: "100"},
+		&cloudsqladmin.DatabaseFlags{Name: "shared_buffers", Value: "1024"},
+		&cloudsqladmin.DatabaseFlags{Name: "log_min_duration_statement", Value: "1000"},
+	)
+	desiredSettings := newSettings("db-custom-2-7680", "projects/p/global/networks/default",
+		&cloudsqladmin.DatabaseFlags{Name: "max_connections", Value: "200"},
+		&cloudsqladmin.DatabaseFlags{Name: "log_min_duration_statement", Value: "500"},
+		&cloudsqladmin.DatabaseFlags{Name: "work_mem", Value: "8192"},
+	)
+	desiredSettings.AvailabilityType = "REGIONAL"
+	desiredSettings.IpConfiguration.RequireSsl = true
+	desiredSettings.LocationPreference.Zone = "europe-west1-c"
+	// "max_connections" is being changed and "shared_buffers" is being removed, both of which require a restart.
+	holdBackDisruptiveChanges(currentSettings, desiredSettings, []string{"max_connections", "shared_buffers"})
+
+	// Disruptive changes must be held back.
+	if desiredSettings.Tier != "db-custom-1-3840" {
+		t.Errorf("expected the change to the tier to be held back, got %q", desiredSettings.Tier)
+	}
+	if desiredSettings.AvailabilityType != "ZONAL" {
+		t.Errorf("expected the change to the availability type to be held back, got %q", desiredSettings.AvailabilityType)
+	}
+	if desiredSettings.IpConfiguration.PrivateNetwork != "" {
+		t.Errorf("expected the change to the private network to be held back, got %q", desiredSettings.IpConfiguration.PrivateNetwork)
+	}
+	if desiredSettings.LocationPreference.Zone != "europe-west1-b" {
+		t.Errorf("expected the change to the zone to be held back, got %q", desiredSettings.LocationPreference.Zone)
+	}
+	// Non-disruptive changes must still be applied.
+	if !desiredSettings.IpConfiguration.RequireSsl {
+		t.Errorf("expected the change to requireSsl to be applied")
+	}
+	expectedFlags := map[string]string{
+		"log_min_duration_statement": "500",
+		"max_connections":            "100",
+		"shared_buffers":             "1024",
+		"work_mem":                   "8192",
+	}
+	if flags := databaseFlagsMap(desiredSettings.DatabaseFlags); !reflect.DeepEqual(flags, expectedFlags) {
+		t.Errorf("expected the database flags to be %v, got %v", expectedFlags, flags)
+	}
+}
+
+func TestMayApplyDisruptiveChanges(t *testing.T) {
+	// 2019-06-03 is a Monday, and 2019-06-09 is a Sunday.
+	monday := time.Date(2019, 6, 3, 4, 30, 0, 0, time.UTC)
+	sunday := time.Date(2019, 6, 9, 4, 30, 0, 0, time.UTC)
+	approved := newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDayMonday, "04:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyOnApproval)
+	approved.Annotations = map[string]string{constants.DisruptiveUpdateApprovedAtAnnotationKey: "2019-06-01T00:00:00Z"}
+	handled := approved.DeepCopy()
+	handled.Status.LastDisruptiveUpdateApprovedAt = "2019-06-01T00:00:00Z"
+	tests := []struct {
+		description        string
+		postgresqlInstance *v1alpha1api.PostgresqlInstance
+		now                time.Time
+		expected           bool
+	}{
+		{
+			description:        "no maintenance settings",
+			postgresqlInstance: &v1alpha1api.PostgresqlInstance{},
+			now:                monday,
+			expected:           true,
+		},
+		{
+			description:        "immediate",
+			postgresqlInstance: newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDayMonday, "04:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyImmediate),
+			now:                sunday,
+			expected:           true,
+		},
+		{
+			description:        "within the maintenance window",
+			postgresqlInstance: newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDayMonday, "04:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow),
+			now:                monday,
+			expected:           true,
+		},
+		{
+			description:        "outside the maintenance window (hour)",
+			postgresqlInstance: newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDayMonday, "05:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow),
+			now:                monday,
+			expected:           false,
+		},
+		{
+			description:        "outside the maintenance window (day)",
+			postgresqlInstance: newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDayMonday, "04:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow),
+			now:                sunday,
+			expected:           false,
+		},
+		{
+			description:        "within the maintenance window on sundays",
+			postgresqlInstance: newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDaySunday, "04:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow),
+			now:                sunday,
+			expected:           true,
+		},
+		{
+			description:        "within the maintenance window in another time zone",
+			postgresqlInstance: newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDayMonday, "04:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow),
+			now:                monday.In(time.FixedZone("UTC+10", 10*60*60)),
+			expected:           true,
+		},
+		{
+			description:        "any maintenance day",
+			postgresqlInstance: newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDayAny, "04:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow),
+			now:                sunday,
+			expected:           true,
+		},
+		{
+			description:        "not approved",
+			postgresqlInstance: newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDayMonday, "04:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyOnApproval),
+			now:                monday,
+			expected:           false,
+		},
+		{
+			description:        "approved",
+			postgresqlInstance: approved,
+			now:                sunday,
+			expected:           true,
+		},
+		{
+			description:        "approval already handled",
+			postgresqlInstance: handled,
+			now:                monday,
+			expected:           false,
+		},
+	}
+	for _, test := range tests {
+		if r := mayApplyDisruptiveChanges(test.postgresqlInstance, test.now); r != test.expected {
+			t.Errorf("%s: expected %t, got %t", test.description, test.expected, r)
+		}
+	}
+}
+
+func TestNextMaintenanceWindow(t *testing.T) {
+	tests := []struct {
+		description        string
+		postgresqlInstance *v1alpha1api.PostgresqlInstance
+		now                time.Time
+		expected           time.Time
+	}{
+		{
+			description:        "no maintenance settings",
+			postgresqlInstance: &v1alpha1api.PostgresqlInstance{},
+			now:                time.Date(2019, 6, 3, 0, 0, 0, 0, time.UTC),
+			expected:           time.Time{},
+		},
+		{
+			description:        "changes held back until approved",
+			postgresqlInstance: newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDayMonday, "04:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyOnApproval),
+			now:                time.Date(2019, 6, 3, 0, 0, 0, 0, time.UTC),
+			expected:           time.Time{},
+		},
+		{
+			description:        "any maintenance day",
+			postgresqlInstance: newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDayAny, "04:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow),
+			now:                time.Date(2019, 6, 3, 0, 0, 0, 0, time.UTC),
+			expected:           time.Time{},
+		},
+		{
+			description:        "later on the same day",
+			postgresqlInstance: newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDayMonday, "04:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow),
+			now:                time.Date(2019, 6, 3, 1, 0, 0, 0, time.UTC),
+			expected:           time.Date(2019, 6, 3, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			description:        "already started on the same day",
+			postgresqlInstance: newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDayMonday, "04:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow),
+			now:                time.Date(2019, 6, 3, 4, 0, 0, 0, time.UTC),
+			expected:           time.Date(2019, 6, 10, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			description:        "later in the week",
+			postgresqlInstance: newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDayFriday, "23:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow),
+			now:                time.Date(2019, 6, 3, 12, 0, 0, 0, time.UTC),
+			expected:           time.Date(2019, 6, 7, 23, 0, 0, 0, time.UTC),
+		},
+		{
+			description:        "sunday in the next month",
+			postgresqlInstance: newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDaySunday, "00:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow),
+			now:                time.Date(2019, 6, 30, 0, 30, 0, 0, time.UTC),
+			expected:           time.Date(2019, 7, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			description:        "current time in another time zone",
+			postgresqlInstance: newMaintenancePostgresqlInstance(v1alpha1api.PostgresqlInstanceSpecMaintenanceDayMonday, "04:00", v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow),
+			// This is Monday 2019-06-03 at 01:00 UTC, but still Sunday in the specified time zone.
+			now:      time.Date(2019, 6, 2, 22, 0, 0, 0, time.FixedZone("UTC-3", -3*60*60)),
+			expected: time.Date(2019, 6, 3, 4, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, test := range tests {
+		if r := nextMaintenanceWindow(test.postgresqlInstance, test.now); !r.Equal(test.expected) {
+			t.Errorf("%s: expected %s, got %s", test.description, test.expected, r)
+		}
+	}
+}
+
+func TestLastScheduledActivation(t *testing.T) {
+	tests := []struct {
+		description string
+		expr        string
+		from        time.Time
+		to          time.Time
+		expected    time.Time
+	}{
+		{
+			description: "no activation within the interval",
+			expr:        "0 8 * * *",
+			from:        time.Date(2019, 6, 3, 9, 0, 0, 0, time.UTC),
+			to:          time.Date(2019, 6, 3, 18, 0, 0, 0, time.UTC),
+			expected:    time.Time{},
+		},
+		{
+			description: "single activation within the interval",
+			expr:        "0 8 * * *",
+			from:        time.Date(2019, 6, 3, 0, 0, 0, 0, time.UTC),
+			to:          time.Date(2019, 6, 3, 18, 0, 0, 0, time.UTC),
+			expected:    time.Date(2019, 6, 3, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "last of several activations within the interval",
+			expr:        "0 8 * * *",
+			from:        time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC),
+			to:          time.Date(2019, 6, 3, 18, 0, 0, 0, time.UTC),
+			expected:    time.Date(2019, 6, 3, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "activation at the start of the interval",
+			expr:        "0 8 * * *",
+			from:        time.Date(2019, 6, 3, 8, 0, 0, 0, time.UTC),
+			to:          time.Date(2019, 6, 3, 18, 0, 0, 0, time.UTC),
+			expected:    time.Date(2019, 6, 3, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "activation at the end of the interval",
+			expr:        "0 18 * * *",
+			from:        time.Date(2019, 6, 3, 8, 0, 0, 0, time.UTC),
+			to:          time.Date(2019, 6, 3, 18, 0, 0, 0, time.UTC),
+			expected:    time.Date(2019, 6, 3, 18, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, test := range tests {
+		s, err := cron.Parse(test.expr)
+		if err != nil {
+			t.Errorf("%s: unexpected error parsing %q: %v", test.description, test.expr, err)
+			continue
+		}
+		if r := lastScheduledActivation(s, test.from, test.to); !r.Equal(test.expected) {
+			t.Errorf("%s: expected %s, got %s", test.description, test.expected, r)
+		}
+	}
+}
+
+// newDefaultPostgresqlInstance returns a PostgresqlInstance resource having the default settings.
+func newDefaultPostgresqlInstance() *v1alpha1api.PostgresqlInstance {
+	availabilityType := v1alpha1api.PostgresqlInstanceSpecAvailabilityTypeZonal
+	day := v1alpha1api.PostgresqlInstanceSpecMaintenanceDayAny
+	diskType := v1alpha1api.PostgresqlInstanceSpecResourceDiskTypeSSD
+	zone := v1alpha1api.PostgresqlInstanceSpecLocationZoneAny
+	return &v1alpha1api.PostgresqlInstance{
+		Spec: v1alpha1api.PostgresqlInstanceSpec{
+			Availability: &v1alpha1api.PostgresqlInstanceSpecAvailability{Type: &availabilityType},
+			Backups: &v1alpha1api.PostgresqlInstanceSpecBackups{
+				Daily: &v1alpha1api.PostgresqlInstancSpecBackupsDaily{Enabled: pointers.NewBool(true), StartTime: pointers.NewString("00:00")},
+			},
+			Location:    &v1alpha1api.PostgresqlInstanceSpecLocation{Zone: &zone},
+			Maintenance: &v1alpha1api.PostgresqlInstanceSpecMaintenance{Day: &day},
+			Networking: &v1alpha1api.PostgresqlInstanceSpecNetworking{
+				PrivateIP: &v1alpha1api.PostgresqlInstanceSpecNetworkingPrivateIP{Enabled: pointers.NewBool(false)},
+				PublicIP:  &v1alpha1api.PostgresqlInstanceSpecNetworkingPublicIP{Enabled: pointers.NewBool(false)},
+			},
+			Resources: &v1alpha1api.PostgresqlInstanceSpecResources{
+				Disk:         &v1alpha1api.PostgresqlInstanceSpecResourcesDisk{SizeMaximumGb: pointers.NewInt32(0), SizeMinimumGb: pointers.NewInt32(10), Type: &diskType},
+				InstanceType: pointers.NewString("db-custom-1-3840"),
+			},
+		},
+	}
+}
+
+func TestUpdateDatabaseInstanceSettingsBackups(t *testing.T) {
+	c := &PostgresqlInstanceController{
+		genericController: newGenericController(postgresqlInstanceControllerName, postgresqlInstanceControllerThreadiness),
+	}
+	// newPostgresqlInstance returns a PostgresqlInstance resource having the default settings and the specified backup settings.
+	newPostgresqlInstance := func(location *string, retainedBackups *int32, pitr *v1alpha1api.PostgresqlInstanceSpecBackupsPointInTimeRecovery) *v1alpha1api.PostgresqlInstance {
+		p := newDefaultPostgresqlInstance()
+		p.Spec.Backups.Location = location
+		p.Spec.Backups.PointInTimeRecovery = pitr
+		p.Spec.Backups.RetainedBackups = retainedBackups
+		return p
+	}
+	// newDatabaseInstance returns a DatabaseInstance object matching the default settings, and whose backup configuration is the specified one.
+	newDatabaseInstance := func(backupConfiguration *cloudsqladmin.BackupConfiguration) *cloudsqladmin.DatabaseInstance {
+		settings := buildDatabaseInstanceSettings(newDefaultPostgresqlInstance())
+		settings.BackupConfiguration = backupConfiguration
+		return &cloudsqladmin.DatabaseInstance{Settings: settings}
+	}
+	current := &cloudsqladmin.BackupConfiguration{
+		BackupRetentionSettings:     &cloudsqladmin.BackupRetentionSettings{RetainedBackups: 7, RetentionUnit: constants.BackupRetentionSettingsRetentionUnitCount},
+		Enabled:                     true,
+		Location:                    "eu",
+		PointInTimeRecoveryEnabled:  false,
+		StartTime:                   "00:00",
+		TransactionLogRetentionDays: 7,
+	}
+	tests := []struct {
+		description     string
+		obj             *v1alpha1api.PostgresqlInstance
+		expectedChanges []string
+	}{
+		{
+			description: "unset backup settings are kept",
+			obj:         newPostgresqlInstance(nil, nil, nil),
+		},
+		{
+			description: "matching backup settings",
+			obj: newPostgresqlInstance(pointers.NewString("eu"), pointers.NewInt32(7), &v1alpha1api.PostgresqlInstanceSpecBackupsPointInTimeRecovery{
+				Enabled:                     pointers.NewBool(false),
+				TransactionLogRetentionDays: pointers.NewInt32(7),
+			}),
+		},
+		{
+			description: "differing backup settings",
+			obj: newPostgresqlInstance(pointers.NewString("us"), pointers.NewInt32(14), &v1alpha1api.PostgresqlInstanceSpecBackupsPointInTimeRecovery{
+				Enabled:                     pointers.NewBool(true),
+				TransactionLogRetentionDays: pointers.NewInt32(3),
+			}),
+			expectedChanges: []string{
+				".settings.backupConfiguration.location",
+				".settings.backupConfiguration.pointInTimeRecoveryEnabled",
+				".settings.backupConfiguration.transactionLogRetentionDays",
+				".settings.backupConfiguration.backupRetentionSettings",
+			},
+		},
+	}
+	for _, test := range tests {
+		b := *current
+		r := *current.BackupRetentionSettings
+		b.BackupRetentionSettings = &r
+		databaseInstance := newDatabaseInstance(&b)
+		changes := c.updateDatabaseInstanceSettings(test.obj, databaseInstance, buildDatabaseInstanceSettings(test.obj))
+		if !reflect.DeepEqual(changes, test.expectedChanges) {
+			t.Errorf("%s: expected changes %q, got %q", test.description, test.expectedChanges, changes)
+		}
+	}
+}
+
+func TestUpdateDatabaseInstanceSettingsInsights(t *testing.T) {
+	c := &PostgresqlInstanceController{
+		genericController: newGenericController(postgresqlInstanceControllerName, postgresqlInstanceControllerThreadiness),
+	}
+	// newPostgresqlInstance returns a PostgresqlInstance resource having the default settings and the specified Query Insights settings.
+	newPostgresqlInstance := func(insights *v1alpha1api.PostgresqlInstanceSpecInsights) *v1alpha1api.PostgresqlInstance {
+		p := newDefaultPostgresqlInstance()
+		p.Spec.Insights = insights
+		return p
+	}
+	tests := []struct {
+		description     string
+		obj             *v1alpha1api.PostgresqlInstance
+		current         *cloudsqladmin.InsightsConfig
+		expectedChanges []string
+	}{
+		{
+			description: "unset query insights settings are kept",
+			obj:         newPostgresqlInstance(nil),
+			current:     &cloudsqladmin.InsightsConfig{QueryInsightsEnabled: true, QueryStringLength: 4500},
+		},
+		{
+			description: "matching query insights settings",
+			obj: newPostgresqlInstance(&v1alpha1api.PostgresqlInstanceSpecInsights{
+				Enabled:               pointers.NewBool(true),
+				QueryStringLength:     pointers.NewInt32(1024),
+				RecordApplicationTags: pointers.NewBool(false),
+				RecordClientAddress:   pointers.NewBool(true),
+			}),
+			current: &cloudsqladmin.InsightsConfig{QueryInsightsEnabled: true, QueryPlansPerMinute: 5, QueryStringLength: 1024, RecordClientAddress: true},
+		},
+		{
+			description: "query insights enabled out-of-band",
+			obj: newPostgresqlInstance(&v1alpha1api.PostgresqlInstanceSpecInsights{
+				Enabled:               pointers.NewBool(false),
+				QueryStringLength:     pointers.NewInt32(1024),
+				RecordApplicationTags: pointers.NewBool(false),
+				RecordClientAddress:   pointers.NewBool(false),
+			}),
+			current: &cloudsqladmin.InsightsConfig{QueryInsightsEnabled: true, QueryStringLength: 4500, RecordApplicationTags: true, RecordClientAddress: true},
+			expectedChanges: []string{
+				".settings.insightsConfig.queryInsightsEnabled",
+				".settings.insightsConfig.queryStringLength",
+				".settings.insightsConfig.recordApplicationTags",
+				".settings.insightsConfig.recordClientAddress",
+			},
+		},
+		{
+			description: "query insights never configured",
+			obj: newPostgresqlInstance(&v1alpha1api.PostgresqlInstanceSpecInsights{
+				Enabled:               pointers.NewBool(true),
+				QueryStringLength:     pointers.NewInt32(1024),
+				RecordApplicationTags: pointers.NewBool(false),
+				RecordClientAddress:   pointers.NewBool(false),
+			}),
+			expectedChanges: []string{
+				".settings.insightsConfig.queryInsightsEnabled",
+				".settings.insightsConfig.queryStringLength",
+			},
+		},
+	}
+	for _, test := range tests {
+		settings := buildDatabaseInstanceSettings(newDefaultPostgresqlInstance())
+		if test.current != nil {
+			ic := *test.current
+			settings.InsightsConfig = &ic
+		}
+		databaseInstance := &cloudsqladmin.DatabaseInstance{Settings: settings}
+		changes := c.updateDatabaseInstanceSettings(test.obj, databaseInstance, buildDatabaseInstanceSettings(test.obj))
+		if !reflect.DeepEqual(changes, test.expectedChanges) {
+			t.Errorf("%s: expected changes %q, got %q", test.description, test.expectedChanges, changes)
+		}
+		// Settings of Query Insights which are not managed by the operator must be kept.
+		if test.current != nil && databaseInstance.Settings.InsightsConfig.QueryPlansPerMinute != test.current.QueryPlansPerMinute {
+			t.Errorf("%s: expected the number of query plans per minute to be kept, got %d", test.description, databaseInstance.Settings.InsightsConfig.QueryPlansPerMinute)
+		}
+	}
+}
+
+func TestUpdateDatabaseInstanceSettingsDenyMaintenancePeriods(t *testing.T) {
+	c := &PostgresqlInstanceController{
+		genericController: newGenericController(postgresqlInstanceControllerName, postgresqlInstanceControllerThreadiness),
+	}
+	denyPeriods := v1alpha1api.PostgresqlInstanceSpecMaintenanceDenyPeriodList{
+		{StartDate: "11-15", EndDate: "01-15", Time: pointers.NewString("00:00:00")},
+	}
+	tests := []struct {
+		description     string
+		denyPeriods     v1alpha1api.PostgresqlInstanceSpecMaintenanceDenyPeriodList
+		current         []*cloudsqladmin.DenyMaintenancePeriod
+		expectedChanges []string
+	}{
+		{
+			description: "no deny periods",
+		},
+		{
+			description: "matching deny periods",
+			denyPeriods: denyPeriods,
+			current:     []*cloudsqladmin.DenyMaintenancePeriod{{StartDate: "11-15", EndDate: "01-15", Time: "00:00:00"}},
+		},
+		{
+			description:     "deny period added",
+			denyPeriods:     denyPeriods,
+			expectedChanges: []string{".settings.denyMaintenancePeriods"},
+		},
+		{
+			description:     "deny period changed out-of-band",
+			denyPeriods:     denyPeriods,
+			current:         []*cloudsqladmin.DenyMaintenancePeriod{{StartDate: "11-01", EndDate: "01-15", Time: "00:00:00"}},
+			expectedChanges: []string{".settings.denyMaintenancePeriods"},
+		},
+		{
+			description:     "deny period added out-of-band",
+			current:         []*cloudsqladmin.DenyMaintenancePeriod{{StartDate: "11-01", EndDate: "01-15", Time: "00:00:00"}},
+			expectedChanges: []string{".settings.denyMaintenancePeriods"},
+		},
+	}
+	for _, test := range tests {
+		p := newDefaultPostgresqlInstance()
+		p.Spec.Maintenance.DenyPeriods = test.denyPeriods
+		settings := buildDatabaseInstanceSettings(newDefaultPostgresqlInstance())
+		settings.DenyMaintenancePeriods = test.current
+		databaseInstance := &cloudsqladmin.DatabaseInstance{Settings: settings}
+		changes := c.updateDatabaseInstanceSettings(p, databaseInstance, buildDatabaseInstanceSettings(p))
+		if !reflect.DeepEqual(changes, test.expectedChanges) {
+			t.Errorf("%s: expected changes %q, got %q", test.description, test.expectedChanges, changes)
+		}
+	}
+}
+
+func TestUpdateDatabaseInstanceSettingsDeletionProtection(t *testing.T) {
+	c := &PostgresqlInstanceController{
+		genericController: newGenericController(postgresqlInstanceControllerName, postgresqlInstanceControllerThreadiness),
+	}
+	tests := []struct {
+		description        string
+		deletionProtection *bool
+		allowDeletion      string
+		current            bool
+		expectedChanges    []string
+	}{
+		{
+			description: "deletion protection not set",
+			current:     true,
+		},
+		{
+			description:        "deletion protection enabled",
+			deletionProtection: pointers.NewBool(true),
+			expectedChanges:    []string{".settings.deletionProtectionEnabled"},
+		},
+		{
+			description:        "deletion protection already enabled",
+			deletionProtection: pointers.NewBool(true),
+			current:            true,
+		},
+		{
+			description:        "deletion protection disabled",
+			deletionProtection: pointers.NewBool(false),
+			current:            true,
+			expectedChanges:    []string{".settings.deletionProtectionEnabled"},
+		},
+		{
+			description:        "deletion protection lifted since deletion is allowed",
+			deletionProtection: pointers.NewBool(true),
+			allowDeletion:      v1alpha1api.True,
+			current:            true,
+			expectedChanges:    []string{".settings.deletionProtectionEnabled"},
+		},
+		{
+			description:        "deletion protection kept since deletion is not allowed",
+			deletionProtection: pointers.NewBool(true),
+			allowDeletion:      v1alpha1api.False,
+			current:            true,
+		},
+	}
+	for _, test := range tests {
+		p := newDefaultPostgresqlInstance()
+		p.Annotations = map[string]string{constants.AllowDeletionAnnotationKey: test.allowDeletion}
+		p.Spec.DeletionProtection = test.deletionProtection
+		settings := buildDatabaseInstanceSettings(newDefaultPostgresqlInstance())
+		settings.DeletionProtectionEnabled = test.current
+		databaseInstance := &cloudsqladmin.DatabaseInstance{Settings: settings}
+		changes := c.updateDatabaseInstanceSettings(p, databaseInstance, buildDatabaseInstanceSettings(p))
+		if !reflect.DeepEqual(changes, test.expectedChanges) {
+			t.Errorf("%s: expected changes %q, got %q", test.description, test.expectedChanges, changes)
+		}
+	}
+}
@@ -0,0 +1,430 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/util/slice"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	v1alpha1client "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/clientset/versioned"
+	v1alpha1informers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/informers/externalversions/cloudsql/v1alpha1"
+	v1alpha1listers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/listers/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/configuration"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/google"
+)
+
+const (
+	// postgresqlReplicaControllerName is the name of the controller for PostgresqlReplica resources.
+	postgresqlReplicaControllerName = "postgresqlreplica-controller"
+	// postgresqlReplicaControllerThreadiness is the number of workers controller for PostgresqlReplica resource will use to process items from its work queue.
+	postgresqlReplicaControllerThreadiness = 1
+)
+
+// PostgresqlReplicaController is the controller for PostgresqlReplica resources.
+type PostgresqlReplicaController struct {
+	// PostgresqlReplicaController is based-off of a generic controller.
+	*genericController
+	// cloudsqlClient is a client for the Cloud SQL Admin API.
+	cloudsqlClient *cloudsqladmin.Service
+	// er is an EventRecorder through which we can emit events associated with PostgresqlReplica resources.
+	er record.EventRecorder
+	// postgresqlInstanceLister is a lister for PostgresqlInstance resources.
+	postgresqlInstanceLister v1alpha1listers.PostgresqlInstanceLister
+	// postgresqlReplicaLister is a lister for PostgresqlReplica resources.
+	postgresqlReplicaLister v1alpha1listers.PostgresqlReplicaLister
+	// projectID is the ID of the GCP project where cloudsql-postgres-operator is managing CSQLP instances.
+	projectID string
+	// selfClient is a client to the "cloudsql.travelaudience.com" API.
+	selfClient v1alpha1client.Interface
+}
+
+// NewPostgresqlReplicaController creates a new instance of the controller for PostgresqlReplica resources.
+func NewPostgresqlReplicaController(config configuration.Configuration, selfClient v1alpha1client.Interface, er record.EventRecorder, postgresqlReplicaInformer v1alpha1informers.PostgresqlReplicaInformer, postgresqlInstanceInformer v1alpha1informers.PostgresqlInstanceInformer, cloudsqlClient *cloudsqladmin.Service) *PostgresqlReplicaController {
+	// Create a new instance of the controller for PostgresqlReplica resources using the specified name and threadiness.
+	c := &PostgresqlReplicaController{
+		cloudsqlClient:           cloudsqlClient,
+		genericController:        newGenericController(postgresqlReplicaControllerName, postgresqlReplicaControllerThreadiness),
+		er:                       er,
+		postgresqlInstanceLister: postgresqlInstanceInformer.Lister(),
+		postgresqlReplicaLister:  postgresqlReplicaInformer.Lister(),
+		projectID:                config.GCP.ProjectID,
+		selfClient:               selfClient,
+	}
+	// Make the controller wait for the caches to sync.
+	c.hasSyncedFuncs = []cache.InformerSynced{
+		postgresqlInstanceInformer.Informer().HasSynced,
+		postgresqlReplicaInformer.Informer().HasSynced,
+	}
+	// Make "processQueueItem" the handler for items popped out of the work queue.
+	c.syncHandler = c.processQueueItem
+
+	// Setup an event handler to inform us when PostgresqlReplica resources change.
+	postgresqlReplicaInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			c.enqueue(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+	})
+
+	// Return the instance of the controller for PostgresqlReplica resources created above.
+	return c
+}
+
+// processQueueItem attempts to reconcile the state of the PostgresqlReplica resource pointed at by the specified key.
+func (c *PostgresqlReplicaController) processQueueItem(key string) (err error) {
+	// Grab the name of the PostgresqlReplica resource from the specified key.
+	// NOTE: PostgresqlReplica is cluster-scoped, and hence there is no associated namespace.
+	_, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("invalid resource key %q", key))
+		return nil
+	}
+
+	// Get the PostgresqlReplica resource with the specified name.
+	r, err := c.postgresqlReplicaLister.Get(name)
+	if err != nil {
+		// The PostgresqlReplica may no longer exist, in which case we stop processing.
+		if kubeerrors.IsNotFound(err) {
+			c.logger.WithField(logFieldName, name).Debug("postgresqlreplica resource in work queue no longer exists")
+			return nil
+		}
+		return err
+	}
+	// Create a deep copy of the PostgresqlReplica resource so we don't possibly mutate the cache.
+	p := r.DeepCopy()
+
+	// Check whether the PostgresqlReplica resource is being deleted (indicated by a non-zero deletion timestamp).
+	if p.DeletionTimestamp.IsZero() {
+		// The PostgresqlReplica resource is not being deleted, so we must add the finalizer in case it is not already present.
+		if !slice.ContainsString(p.Finalizers, constants.CleanupFinalizer, nil) {
+			p.Finalizers = append(p.Finalizers, constants.CleanupFinalizer)
+			if p, err = c.patchPostgresqlReplica(r, p); err != nil {
+				return err
+			}
+		}
+	} else {
+		// The PostgresqlReplica resource is being deleted, so we must delete the read replica and remove the finalizer.
+		if slice.ContainsString(p.Finalizers, constants.CleanupFinalizer, nil) {
+			if err := c.deleteReplica(p); err != nil {
+				return err
+			}
+			p.Finalizers = slice.RemoveString(p.Finalizers, constants.CleanupFinalizer, nil)
+			if _, err = c.patchPostgresqlReplica(r, p); err != nil {
+				return err
+			}
+		}
+		// The finalizer has finished, so there is nothing else to do.
+		return nil
+	}
+
+	// If the PostgresqlReplica resource is marked as being paused, stop processing immediately.
+	if p.Spec.Paused {
+		c.logger.WithField(logFieldName, name).Warn("skipping paused postgresqlreplica")
+		return nil
+	}
+
+	// Make sure that the PostgresqlReplica resource's ".status" field is always updated as the last processing step.
+	// If an error occurs during the update, it is aggregated with the error we would be returning (if any).
+	defer func() {
+		if _, patchErr := c.patchPostgresqlReplicaStatus(r, p); patchErr != nil {
+			err = utilerrors.NewAggregate([]error{patchErr, err})
+		}
+	}()
+
+	// Check whether a read replica with the specified ".spec.name" already exists, and create it if necessary.
+	c.logger.WithField(logFieldName, name).Debugf("checking whether a read replica with name %q already exists", p.Spec.Name)
+	instance, err := c.cloudsqlClient.Instances.Get(c.projectID, p.Spec.Name).Do()
+	if err != nil {
+		// If we've got an error other than "404 NOT FOUND", we stop processing and propagate it.
+		if !google.IsNotFound(err) {
+			c.logger.WithField(logFieldName, name).Debugf("failed to check if a read replica with name %q exists: %v", p.Spec.Name, err)
+			return fmt.Errorf("failed to check if a read replica with name %q exists: %v", p.Spec.Name, err)
+		}
+		// At this point we know that the read replica must be created.
+		// Grab the PostgresqlInstance resource that represents the primary CSQLP instance.
+		i, err := c.postgresqlInstanceLister.Get(p.Spec.Primary)
+		if err != nil {
+			// If we've got an error other than "404 NOT FOUND", we stop processing and propagate it.
+			if !kubeerrors.IsNotFound(err) {
+				return err
+			}
+			// At this point we know that the PostgresqlInstance resource does not exist, so we report it and skip further processing (but don't error).
+			message := fmt.Sprintf("postgresqlinstance %q does not exist", p.Spec.Primary)
+			setPostgresqlReplicaCondition(p, v1alpha1api.PostgresqlReplicaStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+			c.er.Event(p, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+			c.logger.WithField(logFieldName, name).Infof("skipping sync because %s", message)
+			return nil
+		}
+		// Creation can only happen once the primary CSQLP instance is ready, so we skip further processing (but don't error) otherwise.
+		if cdn := getPostgresqlInstanceCondition(i, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady); cdn == nil || cdn.Status != corev1.ConditionTrue {
+			message := fmt.Sprintf("postgresqlinstance %q is not ready", p.Spec.Primary)
+			setPostgresqlReplicaCondition(p, v1alpha1api.PostgresqlReplicaStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+			c.er.Event(p, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+			c.logger.WithField(logFieldName, name).Infof("skipping sync because %s", message)
+			return nil
+		}
+		if instance, err = c.createReplica(p, i); err != nil {
+			// Creation of the read replica failed with a transient error.
+			return err
+		} else if instance == nil {
+			// Creation of the read replica failed with a permanent error.
+			return nil
+		}
+	}
+
+	// Check whether the read replica has any pending or failed operations, in which case we skip further processing (but don't error).
+	operationInProgressOrFailed, lastOperationID, lastOperationType, lastOperationStatus, lastOperationErrorMessage, err := isOperationInProgressOrFailed(c.cloudsqlClient, c.projectID, p.Spec.Name)
+	switch {
+	case err != nil:
+		message := fmt.Sprintf("failed to understand if the read replica has any pending operations")
+		setPostgresqlReplicaCondition(p, v1alpha1api.PostgresqlReplicaStatusConditionTypeReady, corev1.ConditionUnknown, ReasonUnexpectedError, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonUnexpectedError, message)
+		return err
+	case operationInProgressOrFailed && lastOperationErrorMessage == "":
+		message := fmt.Sprintf("the read replica has an ongoing operation (id: %q, type: %q, status: %q)", lastOperationID, lastOperationType, lastOperationStatus)
+		setPostgresqlReplicaCondition(p, v1alpha1api.PostgresqlReplicaStatusConditionTypeReady, corev1.ConditionFalse, ReasonOperationInProgress, message)
+		c.er.Event(p, corev1.EventTypeNormal, ReasonOperationInProgress, message)
+		c.logger.WithField(logFieldName, name).Infof("skipping sync because %s", message)
+		return nil
+	case operationInProgressOrFailed && lastOperationErrorMessage != "":
+		message := fmt.Sprintf("the last operation on the read replica has failed (id: %q, type: %q, status: %q, errors: %q)", lastOperationID, lastOperationType, lastOperationStatus, lastOperationErrorMessage)
+		setPostgresqlReplicaCondition(p, v1alpha1api.PostgresqlReplicaStatusConditionTypeReady, corev1.ConditionFalse, ReasonUnexpectedError, message)
+		// If the failed operation was a promotion, report the failure in the "Promoted" condition as well.
+		if lastOperationType == constants.OperationTypePromoteReplica {
+			setPostgresqlReplicaCondition(p, v1alpha1api.PostgresqlReplicaStatusConditionTypePromoted, corev1.ConditionFalse, ReasonReplicaPromotionFailed, message)
+		}
+		c.er.Event(p, corev1.EventTypeWarning, ReasonUnexpectedError, message)
+		c.logger.WithField(logFieldName, name).Infof("skipping sync because %s", message)
+		return nil
+	}
+
+	// Report the connection name, the set of IP addresses and the replication state of the read replica regardless of its state.
+	setPostgresqlReplicaStatusFields(p, instance)
+
+	// Check whether the read replica is in a state other than "RUNNABLE", in which case we skip further processing (but don't error).
+	if instance.State != constants.DatabaseInstanceStateRunnable {
+		message := fmt.Sprintf("the read replica is in the %q state", instance.State)
+		setPostgresqlReplicaCondition(p, v1alpha1api.PostgresqlReplicaStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+		c.logger.WithField(logFieldName, name).Infof("skipping sync because the read replica is in the %q state", instance.State)
+		return nil
+	}
+
+	// Promote the read replica to a standalone CSQLP instance if requested.
+	// If a promotion has just been started, we skip further processing (but don't error) until it finishes.
+	if promoting, err := c.maybePromoteReplica(p, instance); err != nil || promoting {
+		return err
+	}
+
+	// Update the PostgresqlReplica resource's conditions to indicate readiness.
+	message := "the read replica is running and ready"
+	setPostgresqlReplicaCondition(p, v1alpha1api.PostgresqlReplicaStatusConditionTypeReady, corev1.ConditionTrue, ReasonReplicaReady, message)
+	c.er.Event(p, corev1.EventTypeNormal, ReasonReplicaReady, message)
+
+	// Update the read replica's settings if necessary.
+	instance, err = c.maybeUpdateReplica(p, instance)
+	if err != nil || instance == nil {
+		return err
+	}
+
+	// Update the connection name, the set of IP addresses and the replication state of the read replica and return.
+	setPostgresqlReplicaStatusFields(p, instance)
+	return nil
+}
+
+// createReplica attempts to create a read replica based on the specified PostgresqlReplica resource.
+func (c *PostgresqlReplicaController) createReplica(postgresqlReplica *v1alpha1api.PostgresqlReplica, postgresqlInstance *v1alpha1api.PostgresqlInstance) (*cloudsqladmin.DatabaseInstance, error) {
+	c.logger.WithField(logFieldName, postgresqlReplica.Name).Info("creating read replica")
+	// Build the DatabaseInstance object based on the specified PostgresqlReplica resource.
+	instance := buildReplicaDatabaseInstance(postgresqlReplica, postgresqlInstance)
+	// Attempt to create the DatabaseInstance object.
+	_, err := c.cloudsqlClient.Instances.Insert(c.projectID, instance).Do()
+	if err != nil {
+		if google.IsConflict(err) {
+			// We've been told that the read replica needs to be created, but the Cloud SQL Admin API is reporting a conflict
+			// This most probably means that a CSQLP instance with ".spec.name" as its name had previously existed but has been recently deleted.
+			// Hence, we log but do not propagate the error, since subsequent attempts to create the read replica are likely to fail as well until ".spec.name" becomes available again.
+			message := fmt.Sprintf("the name %q seems to be unavailable - has an instance with such a name been deleted recently?", instance.Name)
+			setPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypeCreated, corev1.ConditionFalse, ReasonNameUnavailable, message)
+			c.er.Event(postgresqlReplica, corev1.EventTypeWarning, ReasonNameUnavailable, message)
+			c.logger.WithField(logFieldName, postgresqlReplica.Name).Error(message)
+			return nil, nil
+		}
+		if google.IsBadRequest(err) {
+			// We've been told that the read replica's specification is invalid.
+			// This most probably means that the user has specified an invalid value for some field under ".spec".
+			// Hence, we log but do not propagate the error, since subsequent attempts to create the read replica are likely to fail as well until ".spec" is fixed.
+			message := fmt.Sprintf("the read replica's specification is invalid: %v", err)
+			setPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypeCreated, corev1.ConditionFalse, ReasonInvalidSpec, message)
+			c.er.Event(postgresqlReplica, corev1.EventTypeWarning, ReasonInvalidSpec, message)
+			c.logger.WithField(logFieldName, postgresqlReplica.Name).Error(message)
+			return nil, nil
+		}
+		// The Cloud SQL Admin API returned a different error, which we propagate so that creation may be retried.
+		setPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypeCreated, corev1.ConditionFalse, ReasonUnexpectedError, err.Error())
+		c.er.Event(postgresqlReplica, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+		return nil, err
+	}
+	// Update the PostgresqlReplica resource's conditions.
+	message := "the read replica has been created"
+	setPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypeCreated, corev1.ConditionTrue, ReasonReplicaCreated, message)
+	c.er.Event(postgresqlReplica, corev1.EventTypeNormal, ReasonReplicaCreated, message)
+	// Grab and return the most up-to-date representation of the read replica.
+	return c.cloudsqlClient.Instances.Get(c.projectID, postgresqlReplica.Spec.Name).Do()
+}
+
+// deleteReplica attempts to delete the read replica associated with the specified PostgresqlReplica resource.
+func (c *PostgresqlReplicaController) deleteReplica(postgresqlReplica *v1alpha1api.PostgresqlReplica) error {
+	c.logger.WithField(logFieldName, postgresqlReplica.Name).Debug("checking whether the read replica needs to be deleted")
+	// Before issuing a delete request (which can result in a "409 CONFLICT" response in case the read replica has already and recently been deleted), make sure the read replica is still listed.
+	if _, err := c.cloudsqlClient.Instances.Get(c.projectID, postgresqlReplica.Spec.Name).Do(); err != nil {
+		if google.IsNotFound(err) {
+			c.logger.WithField(logFieldName, postgresqlReplica.Name).Debug("the read replica has already been deleted")
+			return nil
+		}
+		return err
+	}
+	c.logger.WithField(logFieldName, postgresqlReplica.Name).Infof("deleting read replica %q", postgresqlReplica.Spec.Name)
+	// At this point we know the read replica already exists, so we issue the delete request.
+	if _, err := c.cloudsqlClient.Instances.Delete(c.projectID, postgresqlReplica.Spec.Name).Do(); err != nil {
+		return err
+	}
+	c.logger.WithField(logFieldName, postgresqlReplica.Name).Debugf("read replica %q has been deleted", postgresqlReplica.Spec.Name)
+	return nil
+}
+
+// maybePromoteReplica checks whether the read replica must be promoted to a standalone CSQLP instance, and starts the promotion if necessary.
+// It returns a boolean value indicating whether a promotion has been started.
+func (c *PostgresqlReplicaController) maybePromoteReplica(postgresqlReplica *v1alpha1api.PostgresqlReplica, databaseInstance *cloudsqladmin.DatabaseInstance) (bool, error) {
+	cdn := getPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypePromoted)
+	// If promotion has not been requested, there is nothing to do.
+	// If a previous promotion attempt has failed, we reset the "Promoted" condition so that promotion is attempted again once it is requested again.
+	if v := postgresqlReplica.Annotations[constants.PromoteReplicaAnnotationKey]; v != v1alpha1api.True {
+		if cdn != nil && cdn.Reason == ReasonReplicaPromotionFailed {
+			message := "the promotion of the read replica is no longer requested"
+			setPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypePromoted, corev1.ConditionFalse, ReasonReplicaNotPromoted, message)
+			c.er.Event(postgresqlReplica, corev1.EventTypeNormal, ReasonReplicaNotPromoted, message)
+		}
+		return false, nil
+	}
+	// If the read replica no longer has a primary CSQLP instance, it has already been promoted.
+	// If it was previously being promoted, we report that the promotion has finished.
+	if databaseInstance.MasterInstanceName == "" {
+		if cdn == nil || cdn.Status != corev1.ConditionTrue {
+			message := "the read replica has been promoted to a standalone instance"
+			setPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypePromoted, corev1.ConditionTrue, ReasonReplicaPromoted, message)
+			c.er.Event(postgresqlReplica, corev1.EventTypeNormal, ReasonReplicaPromoted, message)
+			c.logger.WithField(logFieldName, postgresqlReplica.Name).Info(message)
+		}
+		return false, nil
+	}
+	// If a previous promotion attempt has been refused, we do not retry until the "cloudsql.travelaudience.com/promote" annotation is toggled.
+	if cdn != nil && cdn.Status == corev1.ConditionFalse && cdn.Reason == ReasonReplicaPromotionFailed {
+		c.logger.WithField(logFieldName, postgresqlReplica.Name).Debugf("not retrying the promotion of read replica %q: %s", databaseInstance.Name, cdn.Message)
+		return false, nil
+	}
+	// At this point we know we have to promote the read replica.
+	c.logger.WithField(logFieldName, postgresqlReplica.Name).Infof("promoting read replica %q", databaseInstance.Name)
+	if _, err := c.cloudsqlClient.Instances.PromoteReplica(c.projectID, databaseInstance.Name).Do(); err != nil {
+		if google.IsBadRequest(err) {
+			// We've been told that the promotion cannot be performed.
+			// Hence, we log but do not propagate the error, since subsequent attempts to promote the read replica are likely to fail as well.
+			// Further attempts are skipped until the "cloudsql.travelaudience.com/promote" annotation is toggled.
+			message := fmt.Sprintf("the read replica cannot be promoted: %v", err)
+			setPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypePromoted, corev1.ConditionFalse, ReasonReplicaPromotionFailed, message)
+			c.er.Event(postgresqlReplica, corev1.EventTypeWarning, ReasonReplicaPromotionFailed, message)
+			c.logger.WithField(logFieldName, postgresqlReplica.Name).Error(message)
+			return false, nil
+		}
+		// The Cloud SQL Admin API returned a different error, which we propagate so that the promotion may be retried.
+		setPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypePromoted, corev1.ConditionFalse, ReasonUnexpectedError, err.Error())
+		c.er.Event(postgresqlReplica, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+		return false, err
+	}
+	// Update the PostgresqlReplica resource's conditions.
+	message := "the read replica is being promoted to a standalone instance"
+	setPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypePromoted, corev1.ConditionFalse, ReasonReplicaPromoting, message)
+	setPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypeReady, corev1.ConditionFalse, ReasonReplicaPromoting, message)
+	c.er.Event(postgresqlReplica, corev1.EventTypeNormal, ReasonReplicaPromoting, message)
+	return true, nil
+}
+
+// maybeUpdateReplica checks whether the settings for the read replica must be updated, and updates it if necessary.
+// It returns a nil DatabaseInstance object if the update failed with a permanent error.
+func (c *PostgresqlReplicaController) maybeUpdateReplica(postgresqlReplica *v1alpha1api.PostgresqlReplica, databaseInstance *cloudsqladmin.DatabaseInstance) (*cloudsqladmin.DatabaseInstance, error) {
+	c.logger.WithField(logFieldName, postgresqlReplica.Name).Debug("checking whether the read replica's settings must be updated")
+	// Compute the settings that must be updated according to the PostgresqlReplica resource.
+	settings, mustUpdate := c.computeReplicaSettingsUpdate(postgresqlReplica, databaseInstance)
+	if !mustUpdate {
+		// No differences have been detected, so there is nothing to do.
+		message := "the read replica's settings are up-to-date"
+		setPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypeUpToDate, corev1.ConditionTrue, ReasonReplicaUpToDate, message)
+		c.er.Event(postgresqlReplica, corev1.EventTypeNormal, ReasonReplicaUpToDate, message)
+		c.logger.WithField(logFieldName, postgresqlReplica.Name).Debug("the read replica's settings are up-to-date")
+		return databaseInstance, nil
+	}
+	// At this point we know we have to update the read replica's settings.
+	c.logger.WithField(logFieldName, postgresqlReplica.Name).Debug("the read replica's settings must be updated")
+	_, err := c.cloudsqlClient.Instances.Patch(c.projectID, databaseInstance.Name, &cloudsqladmin.DatabaseInstance{
+		Settings: settings,
+	}).Do()
+	if err != nil {
+		if google.IsConflict(err) {
+			// The Cloud SQL Admin API is reporting a conflict.
+			// This most probably means that an update is already in progress, in which case we must wait.
+			message := fmt.Sprintf("conflict reported while trying to update the read replica's settings - maybe another update is currently in progress? %v", err)
+			setPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypeUpToDate, corev1.ConditionFalse, ReasonConflict, message)
+			c.er.Event(postgresqlReplica, corev1.EventTypeWarning, ReasonConflict, message)
+			c.logger.WithField(logFieldName, postgresqlReplica.Name).Error(message)
+			return nil, nil
+		}
+		if google.IsBadRequest(err) {
+			// We've been told that the read replica's specification is invalid.
+			// Hence, we log but do not propagate the error, since subsequent attempts to update the read replica are likely to fail as well until ".spec" is fixed.
+			message := fmt.Sprintf("the read replica's settings are invalid: %v", err)
+			setPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypeUpToDate, corev1.ConditionFalse, ReasonInvalidSpec, message)
+			c.er.Event(postgresqlReplica, corev1.EventTypeWarning, ReasonInvalidSpec, message)
+			c.logger.WithField(logFieldName, postgresqlReplica.Name).Error(message)
+			return nil, nil
+		}
+		// The Cloud SQL Admin API returned a different error, which we propagate so that the update may be retried.
+		setPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypeUpToDate, corev1.ConditionFalse, ReasonUnexpectedError, err.Error())
+		c.er.Event(postgresqlReplica, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+		return nil, err
+	}
+	// Update the PostgresqlReplica resource's conditions.
+	message := "the read replica has been updated"
+	setPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypeUpToDate, corev1.ConditionTrue, ReasonReplicaUpdated, message)
+	c.er.Event(postgresqlReplica, corev1.EventTypeNormal, ReasonReplicaUpdated, message)
+	// Grab and return the most up-to-date representation of the read replica.
+	return c.cloudsqlClient.Instances.Get(c.projectID, postgresqlReplica.Spec.Name).Do()
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/pointers"
+)
+
+// buildReplicaDatabaseInstance builds the DatabaseInstance object that corresponds to the specified PostgresqlReplica resource.
+// Settings which are not configurable in PostgresqlReplica resources (such as networking and disk settings) are copied from the PostgresqlInstance resource that represents the primary CSQLP instance.
+func buildReplicaDatabaseInstance(postgresqlReplica *v1alpha1api.PostgresqlReplica, postgresqlInstance *v1alpha1api.PostgresqlInstance) *cloudsqladmin.DatabaseInstance {
+	r := &cloudsqladmin.DatabaseInstance{
+		DatabaseVersion:    postgresqlInstance.Spec.Version.APIValue(),
+		MasterInstanceName: postgresqlInstance.Spec.Name,
+		Name:               postgresqlReplica.Spec.Name,
+		Region:             *postgresqlReplica.Spec.Location.Region,
+		Settings: &cloudsqladmin.Settings{
+			DatabaseFlags:  v1alpha1api.DatabaseFlagsAPIValue(postgresqlReplica.Spec.Flags, postgresqlReplica.Spec.DatabaseFlags),
+			DataDiskSizeGb: int64(*postgresqlInstance.Spec.Resources.Disk.SizeMinimumGb),
+			DataDiskType:   postgresqlInstance.Spec.Resources.Disk.Type.APIValue(),
+			IpConfiguration: &cloudsqladmin.IpConfiguration{
+				AuthorizedNetworks: postgresqlInstance.Spec.Networking.PublicIP.AuthorizedNetworks.APIValue(),
+				Ipv4Enabled:        *postgresqlInstance.Spec.Networking.PublicIP.Enabled,
+				ForceSendFields: []string{
+					"Ipv4Enabled",
+				},
+			},
+			LocationPreference: &cloudsqladmin.LocationPreference{
+				Zone: postgresqlReplica.Spec.Location.Zone.APIValue(),
+			},
+			Tier:       *postgresqlReplica.Spec.InstanceType,
+			UserLabels: postgresqlInstance.Spec.Labels,
+		},
+	}
+	if *postgresqlInstance.Spec.Networking.PrivateIP.Enabled {
+		r.Settings.IpConfiguration.PrivateNetwork = *postgresqlInstance.Spec.Networking.PrivateIP.Network
+	}
+	if *postgresqlInstance.Spec.Resources.Disk.SizeMaximumGb == *postgresqlInstance.Spec.Resources.Disk.SizeMinimumGb {
+		r.Settings.StorageAutoResize = pointers.NewBool(false)
+	} else {
+		r.Settings.StorageAutoResize = pointers.NewBool(true)
+		r.Settings.StorageAutoResizeLimit = int64(*postgresqlInstance.Spec.Resources.Disk.SizeMaximumGb)
+	}
+	return r
+}
+
+// computeReplicaSettingsUpdate compares the settings of the provided read replica with the ones specified in the provided PostgresqlReplica resource.
+// It returns a Settings object containing only the settings that must be updated, and a boolean value indicating whether an update is required.
+func (c *PostgresqlReplicaController) computeReplicaSettingsUpdate(postgresqlReplica *v1alpha1api.PostgresqlReplica, databaseInstance *cloudsqladmin.DatabaseInstance) (settings *cloudsqladmin.Settings, mustUpdate bool) {
+	settings = &cloudsqladmin.Settings{}
+	// Compute the desired values based on the provided PostgresqlReplica resource.
+	desiredFlags := v1alpha1api.DatabaseFlagsAPIValue(postgresqlReplica.Spec.Flags, postgresqlReplica.Spec.DatabaseFlags)
+	desiredZone := postgresqlReplica.Spec.Location.Zone.APIValue()
+	desiredTier := *postgresqlReplica.Spec.InstanceType
+	// Include each setting that differs from the desired value.
+	if !reflect.DeepEqual(databaseInstance.Settings.DatabaseFlags, desiredFlags) {
+		c.logger.WithField(logFieldName, postgresqlReplica.Name).Debug(".settings.databaseFlags must be updated")
+		settings.DatabaseFlags = desiredFlags
+		// Force sending the (possibly empty) list of flags so that existing flags can be removed.
+		settings.ForceSendFields = append(settings.ForceSendFields, "DatabaseFlags")
+		mustUpdate = true
+	}
+	if *postgresqlReplica.Spec.Location.Zone != v1alpha1api.PostgresqlInstanceSpecLocationZoneAny && (databaseInstance.Settings.LocationPreference == nil || databaseInstance.Settings.LocationPreference.Zone != desiredZone) {
+		c.logger.WithField(logFieldName, postgresqlReplica.Name).Debug(".settings.locationPreference.zone must be updated")
+		settings.LocationPreference = &cloudsqladmin.LocationPreference{
+			Zone: desiredZone,
+		}
+		mustUpdate = true
+	}
+	if databaseInstance.Settings.Tier != desiredTier {
+		c.logger.WithField(logFieldName, postgresqlReplica.Name).Debug(".settings.tier must be updated")
+		settings.Tier = desiredTier
+		mustUpdate = true
+	}
+	return settings, mustUpdate
+}
+
+// getPostgresqlReplicaCondition returns the condition of the provided type associated with the provided PostgresqlReplica resource, or nil if no such condition exists.
+func getPostgresqlReplicaCondition(postgresqlReplica *v1alpha1api.PostgresqlReplica, conditionType v1alpha1api.PostgresqlReplicaStatusConditionType) *v1alpha1api.PostgresqlReplicaStatusCondition {
+	for idx := range postgresqlReplica.Status.Conditions {
+		if postgresqlReplica.Status.Conditions[idx].Type == conditionType {
+			return &postgresqlReplica.Status.Conditions[idx]
+		}
+	}
+	return nil
+}
+
+// isPostgresqlReplicaPromoted returns whether the provided PostgresqlReplica resource reports that the read replica it represents has been promoted to a standalone CSQLP instance.
+func isPostgresqlReplicaPromoted(postgresqlReplica *v1alpha1api.PostgresqlReplica) bool {
+	cdn := getPostgresqlReplicaCondition(postgresqlReplica, v1alpha1api.PostgresqlReplicaStatusConditionTypePromoted)
+	return cdn != nil && cdn.Status == corev1.ConditionTrue
+}
+
+// patchPostgresqlReplica updates the provided PostgresqlReplica using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlReplicaController) patchPostgresqlReplica(oldObj, newObj *v1alpha1api.PostgresqlReplica, subresources ...string) (*v1alpha1api.PostgresqlReplica, error) {
+	// Return if there are no changes to be made.
+	if reflect.DeepEqual(oldObj, newObj) {
+		return newObj, nil
+	}
+	// Prepare the patch to apply based on the provided objects.
+	oldBytes, err := json.Marshal(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newBytes, err := json.Marshal(newObj)
+	if err != nil {
+		return nil, err
+	}
+	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldBytes, newBytes, &v1alpha1api.PostgresqlReplica{})
+	if err != nil {
+		return nil, err
+	}
+	// Apply the patch.
+	return c.selfClient.CloudsqlV1alpha1().PostgresqlReplicas().Patch(oldObj.Name, types.MergePatchType, patchBytes, subresources...)
+}
+
+// patchPostgresqlReplicaStatus updates the status of the provided PostgresqlReplica using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlReplicaController) patchPostgresqlReplicaStatus(oldObj, newObj *v1alpha1api.PostgresqlReplica) (*v1alpha1api.PostgresqlReplica, error) {
+	return c.patchPostgresqlReplica(oldObj, newObj, "status")
+}
+
+// setPostgresqlReplicaCondition sets a condition on the provided PostgresqlReplica resource according to the following rules:
+// 1. If no condition of the provided type exists, the condition is inserted with its last transition time set to the current time.
+// 2. If a condition of the provided type and state exists, the condition is updated but its last transition time is not modified.
+// 3. If a condition of the provided type but different state exists, the condition is updated and its last transition time is set to the current time.
+func setPostgresqlReplicaCondition(postgresqlReplica *v1alpha1api.PostgresqlReplica, conditionType v1alpha1api.PostgresqlReplicaStatusConditionType, conditionStatus corev1.ConditionStatus, conditionReason string, conditionMessage string) {
+	// Create the new condition.
+	newCondition := v1alpha1api.PostgresqlReplicaStatusCondition{
+		LastTransitionTime: v1.NewTime(time.Now()),
+		Message:            conditionMessage,
+		Reason:             conditionReason,
+		Status:             conditionStatus,
+		Type:               conditionType,
+	}
+	// Search through existing conditions in order to understand if we need to insert the new condition or not.
+	for idx, cdn := range postgresqlReplica.Status.Conditions {
+		// If the current condition's type is different from the one we will be inserting, skip it.
+		if cdn.Type != newCondition.Type {
+			continue
+		}
+		// If the status is the same, we should not update the condition's last transition time.
+		if cdn.Status == newCondition.Status {
+			newCondition.LastTransitionTime = cdn.LastTransitionTime
+		}
+		// Overwrite the existing condition and return.
+		postgresqlReplica.Status.Conditions[idx] = newCondition
+		return
+	}
+	// At this point we know that there is no existing condition with this type, so we just append it to the set of conditions.
+	postgresqlReplica.Status.Conditions = append(postgresqlReplica.Status.Conditions, newCondition)
+}
+
+// setPostgresqlReplicaStatusFields sets the connection name, the set of IPs and the replication state associated with the provided read replica.
+func setPostgresqlReplicaStatusFields(postgresqlReplica *v1alpha1api.PostgresqlReplica, databaseInstance *cloudsqladmin.DatabaseInstance) {
+	postgresqlReplica.Status.IPs = v1alpha1api.PostgresqlInstanceStatusIPAddresses{}
+	for _, ip := range databaseInstance.IpAddresses {
+		if ip != nil {
+			switch ip.Type {
+			case constants.DatabaseInstanceIPAddressTypePrivate:
+				postgresqlReplica.Status.IPs.PrivateIP = ip.IpAddress
+			case constants.DatabaseInstanceIPAddressTypePublic:
+				postgresqlReplica.Status.IPs.PublicIP = ip.IpAddress
+			default:
+				continue
+			}
+		}
+	}
+	postgresqlReplica.Status.ConnectionName = databaseInstance.ConnectionName
+	if databaseInstance.Settings != nil && databaseInstance.Settings.DatabaseReplicationEnabled {
+		postgresqlReplica.Status.ReplicationState = v1alpha1api.PostgresqlReplicaStatusReplicationStateRunning
+	} else {
+		postgresqlReplica.Status.ReplicationState = v1alpha1api.PostgresqlReplicaStatusReplicationStateStopped
+	}
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	v1alpha1client "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/clientset/versioned"
+	v1alpha1informers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/informers/externalversions/cloudsql/v1alpha1"
+	v1alpha1listers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/listers/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/configuration"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/google"
+)
+
+const (
+	// postgresqlRestoreControllerName is the name of the controller for PostgresqlRestore resources.
+	postgresqlRestoreControllerName = "postgresqlrestore-controller"
+	// postgresqlRestoreControllerThreadiness is the number of workers controller for PostgresqlRestore resource will use to process items from its work queue.
+	postgresqlRestoreControllerThreadiness = 1
+)
+
+// PostgresqlRestoreController is the controller for PostgresqlRestore resources.
+type PostgresqlRestoreController struct {
+	// PostgresqlRestoreController is based-off of a generic controller.
+	*genericController
+	// cloudsqlClient is a client for the Cloud SQL Admin API.
+	cloudsqlClient *cloudsqladmin.Service
+	// er is an EventRecorder through which we can emit events associated with PostgresqlRestore resources.
+	er record.EventRecorder
+	// postgresqlBackupLister is a lister for PostgresqlBackup resources.
+	postgresqlBackupLister v1alpha1listers.PostgresqlBackupLister
+	// postgresqlInstanceLister is a lister for PostgresqlInstance resources.
+	postgresqlInstanceLister v1alpha1listers.PostgresqlInstanceLister
+	// postgresqlRestoreLister is a lister for PostgresqlRestore resources.
+	postgresqlRestoreLister v1alpha1listers.PostgresqlRestoreLister
+	// projectID is the ID of the GCP project where cloudsql-postgres-operator is managing CSQLP instances.
+	projectID string
+	// selfClient is a client to the "cloudsql.travelaudience.com" API.
+	selfClient v1alpha1client.Interface
+}
+
+// NewPostgresqlRestoreController creates a new instance of the controller for PostgresqlRestore resources.
+func NewPostgresqlRestoreController(config configuration.Configuration, selfClient v1alpha1client.Interface, er record.EventRecorder, postgresqlRestoreInformer v1alpha1informers.PostgresqlRestoreInformer, postgresqlBackupInformer v1alpha1informers.PostgresqlBackupInformer, postgresqlInstanceInformer v1alpha1informers.PostgresqlInstanceInformer, cloudsqlClient *cloudsqladmin.Service) *PostgresqlRestoreController {
+	// Create a new instance of the controller for PostgresqlRestore resources using the specified name and threadiness.
+	c := &PostgresqlRestoreController{
+		cloudsqlClient:           cloudsqlClient,
+		genericController:        newGenericController(postgresqlRestoreControllerName, postgresqlRestoreControllerThreadiness),
+		er:                       er,
+		postgresqlBackupLister:   postgresqlBackupInformer.Lister(),
+		postgresqlInstanceLister: postgresqlInstanceInformer.Lister(),
+		postgresqlRestoreLister:  postgresqlRestoreInformer.Lister(),
+		projectID:                config.GCP.ProjectID,
+		selfClient:               selfClient,
+	}
+	// Make the controller wait for the caches to sync.
+	c.hasSyncedFuncs = []cache.InformerSynced{
+		postgresqlBackupInformer.Informer().HasSynced,
+		postgresqlInstanceInformer.Informer().HasSynced,
+		postgresqlRestoreInformer.Informer().HasSynced,
+	}
+	// Make "processQueueItem" the handler for items popped out of the work queue.
+	c.syncHandler = c.processQueueItem
+
+	// Setup an event handler to inform us when PostgresqlRestore resources change.
+	postgresqlRestoreInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			c.enqueue(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+	})
+
+	// Return the instance of the controller for PostgresqlRestore resources created above.
+	return c
+}
+
+// processQueueItem attempts to reconcile the state of the PostgresqlRestore resource pointed at by the specified key.
+func (c *PostgresqlRestoreController) processQueueItem(key string) (err error) {
+	// Grab the name of the PostgresqlRestore resource from the specified key.
+	// NOTE: PostgresqlRestore is cluster-scoped, and hence there is no associated namespace.
+	_, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("invalid resource key %q", key))
+		return nil
+	}
+
+	// Get the PostgresqlRestore resource with the specified name.
+	r, err := c.postgresqlRestoreLister.Get(name)
+	if err != nil {
+		// The PostgresqlRestore may no longer exist, in which case we stop processing.
+		if kubeerrors.IsNotFound(err) {
+			c.logger.WithField(logFieldName, name).Debug("postgresqlrestore resource in work queue no longer exists")
+			return nil
+		}
+		return err
+	}
+	// If the PostgresqlRestore resource is being deleted there is nothing to do, as deleting it has no effect on the target CSQLP instance.
+	if !r.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	// Create a deep copy of the PostgresqlRestore resource so we don't possibly mutate the cache.
+	p := r.DeepCopy()
+
+	// Make sure that the PostgresqlRestore resource's ".status" field is always updated as the last processing step.
+	// If an error occurs during the update, it is aggregated with the error we would be returning (if any).
+	defer func() {
+		if _, patchErr := c.patchPostgresqlRestoreStatus(r, p); patchErr != nil {
+			err = utilerrors.NewAggregate([]error{patchErr, err})
+		}
+	}()
+
+	// If the restore operation has already finished (either successfully or not), there is nothing else to do.
+	if cdn := getPostgresqlRestoreCondition(p, v1alpha1api.PostgresqlRestoreStatusConditionTypeCompleted); cdn != nil && (cdn.Reason == ReasonRestoreCompleted || cdn.Reason == ReasonRestoreFailed) {
+		c.logger.WithField(logFieldName, name).Debug("the restore operation has already finished")
+		return nil
+	}
+
+	// Check whether the restore operation has already been started, and start it if necessary.
+	if p.Status.OperationID == "" {
+		// Grab the PostgresqlInstance resources that represent the source and target CSQLP instances.
+		s, err := c.getPostgresqlInstance(p, p.Spec.Source)
+		if s == nil || err != nil {
+			return err
+		}
+		t, err := c.getPostgresqlInstance(p, p.Spec.Target)
+		if t == nil || err != nil {
+			return err
+		}
+		// Determine the ID of the backup run to restore.
+		backupRunID, err := c.getBackupRunID(p)
+		if backupRunID == 0 || err != nil {
+			return err
+		}
+		// Backup runs can only be restored into a CSQLP instance that is ready, so we skip further processing (but don't error) otherwise.
+		if cdn := getPostgresqlInstanceCondition(t, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady); cdn == nil || cdn.Status != corev1.ConditionTrue {
+			message := fmt.Sprintf("postgresqlinstance %q is not ready", p.Spec.Target)
+			setPostgresqlRestoreCondition(p, v1alpha1api.PostgresqlRestoreStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+			c.er.Event(p, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+			c.logger.WithField(logFieldName, name).Infof("skipping sync because %s", message)
+			return nil
+		}
+		// Start the restore operation.
+		// Any permanent error has already been reported, so we stop processing in that case.
+		if started, err := c.startRestore(p, s, t, backupRunID); !started || err != nil {
+			return err
+		}
+	}
+
+	// Grab the operation that performs the restore in order to check whether it has finished.
+	c.logger.WithField(logFieldName, name).Debugf("checking the status of operation %q", p.Status.OperationID)
+	op, err := c.cloudsqlClient.Operations.Get(c.projectID, p.Status.OperationID).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get operation %q: %v", p.Status.OperationID, err)
+	}
+
+	// Check whether the operation is still in progress or has failed.
+	// If it is still in progress, we skip further processing (but don't error), and the status of the operation will be checked again after the controller's resync period elapses.
+	operationInProgressOrFailed, operationID, _, operationStatus, operationErrorMessage := isOperationInProgressOrFailedFromOperation(op)
+	switch {
+	case operationInProgressOrFailed && operationErrorMessage == "":
+		message := fmt.Sprintf("the restore operation is in progress (operation: %q, status: %q)", operationID, operationStatus)
+		setPostgresqlRestoreCondition(p, v1alpha1api.PostgresqlRestoreStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonInstanceRestoring, message)
+		c.logger.WithField(logFieldName, name).Debug(message)
+		return nil
+	case operationInProgressOrFailed && operationErrorMessage != "":
+		message := fmt.Sprintf("the restore operation has failed (operation: %q, errors: %q)", operationID, operationErrorMessage)
+		setPostgresqlRestoreCondition(p, v1alpha1api.PostgresqlRestoreStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonRestoreFailed, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonRestoreFailed, message)
+		c.logger.WithField(logFieldName, name).Error(message)
+		return nil
+	}
+
+	// Update the PostgresqlRestore resource's conditions to indicate completion.
+	message := fmt.Sprintf("the restore operation has completed (operation: %q)", op.Name)
+	setPostgresqlRestoreCondition(p, v1alpha1api.PostgresqlRestoreStatusConditionTypeCompleted, corev1.ConditionTrue, ReasonRestoreCompleted, message)
+	c.er.Event(p, corev1.EventTypeNormal, ReasonRestoreCompleted, message)
+	c.logger.WithField(logFieldName, name).Info(message)
+	return nil
+}
+
+// getPostgresqlInstance returns the PostgresqlInstance resource with the specified name.
+// In case the PostgresqlInstance resource does not exist, the condition is reported on the specified PostgresqlRestore resource and nil is returned.
+func (c *PostgresqlRestoreController) getPostgresqlInstance(postgresqlRestore *v1alpha1api.PostgresqlRestore, name string) (*v1alpha1api.PostgresqlInstance, error) {
+	i, err := c.postgresqlInstanceLister.Get(name)
+	if err != nil {
+		// If we've got an error other than "404 NOT FOUND", we stop processing and propagate it.
+		if !kubeerrors.IsNotFound(err) {
+			return nil, err
+		}
+		// At this point we know that the PostgresqlInstance resource does not exist, so we report it and skip further processing (but don't error).
+		message := fmt.Sprintf("postgresqlinstance %q does not exist", name)
+		setPostgresqlRestoreCondition(postgresqlRestore, v1alpha1api.PostgresqlRestoreStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+		c.er.Event(postgresqlRestore, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+		c.logger.WithField(logFieldName, postgresqlRestore.Name).Infof("skipping sync because %s", message)
+		return nil, nil
+	}
+	return i, nil
+}
+
+// getBackupRunID returns the ID of the backup run to restore based on the specified PostgresqlRestore resource.
+// In case the referenced PostgresqlBackup resource does not exist or has not completed successfully, the condition is reported on the specified PostgresqlRestore resource and zero is returned.
+func (c *PostgresqlRestoreController) getBackupRunID(postgresqlRestore *v1alpha1api.PostgresqlRestore) (int64, error) {
+	// If the ID of the backup run has been specified directly, use it.
+	if postgresqlRestore.Spec.Backup == "" {
+		return postgresqlRestore.Spec.BackupRunID, nil
+	}
+	// Grab the PostgresqlBackup resource that represents the backup run to restore.
+	b, err := c.postgresqlBackupLister.Get(postgresqlRestore.Spec.Backup)
+	if err != nil {
+		// If we've got an error other than "404 NOT FOUND", we stop processing and propagate it.
+		if !kubeerrors.IsNotFound(err) {
+			return 0, err
+		}
+		// At this point we know that the PostgresqlBackup resource does not exist, so we report it and skip further processing (but don't error).
+		message := fmt.Sprintf("postgresqlbackup %q does not exist", postgresqlRestore.Spec.Backup)
+		setPostgresqlRestoreCondition(postgresqlRestore, v1alpha1api.PostgresqlRestoreStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonBackupNotCompleted, message)
+		c.er.Event(postgresqlRestore, corev1.EventTypeWarning, ReasonBackupNotCompleted, message)
+		c.logger.WithField(logFieldName, postgresqlRestore.Name).Infof("skipping sync because %s", message)
+		return 0, nil
+	}
+	// Only backup runs that have completed successfully can be restored, so we skip further processing (but don't error) otherwise.
+	if cdn := getPostgresqlBackupCondition(b, v1alpha1api.PostgresqlBackupStatusConditionTypeCompleted); cdn == nil || cdn.Status != corev1.ConditionTrue || b.Status.ID == 0 {
+		message := fmt.Sprintf("postgresqlbackup %q has not completed", postgresqlRestore.Spec.Backup)
+		setPostgresqlRestoreCondition(postgresqlRestore, v1alpha1api.PostgresqlRestoreStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonBackupNotCompleted, message)
+		c.er.Event(postgresqlRestore, corev1.EventTypeWarning, ReasonBackupNotCompleted, message)
+		c.logger.WithField(logFieldName, postgresqlRestore.Name).Infof("skipping sync because %s", message)
+		return 0, nil
+	}
+	return b.Status.ID, nil
+}
+
+// startRestore attempts to restore the specified backup run of the source CSQLP instance into the target CSQLP instance.
+// It returns a boolean value indicating whether the restore operation has been started.
+func (c *PostgresqlRestoreController) startRestore(postgresqlRestore *v1alpha1api.PostgresqlRestore, source, target *v1alpha1api.PostgresqlInstance, backupRunID int64) (bool, error) {
+	c.logger.WithField(logFieldName, postgresqlRestore.Name).Infof("restoring backup run %d of %q into %q", backupRunID, source.Spec.Name, target.Spec.Name)
+	// Attempt to start the restore operation.
+	op, err := c.cloudsqlClient.Instances.RestoreBackup(c.projectID, target.Spec.Name, &cloudsqladmin.InstancesRestoreBackupRequest{
+		RestoreBackupContext: &cloudsqladmin.RestoreBackupContext{
+			BackupRunId: backupRunID,
+			InstanceId:  source.Spec.Name,
+		},
+	}).Do()
+	if err != nil {
+		if google.IsBadRequest(err) {
+			// We've been told that the restore operation cannot be started.
+			// This most probably means that the backup run does not exist or cannot be restored into the target CSQLP instance (e.g. because of a version mismatch).
+			// Hence, we log but do not propagate the error, and mark the restore operation as failed since subsequent attempts are likely to fail as well.
+			message := fmt.Sprintf("the restore operation cannot be started: %v", err)
+			setPostgresqlRestoreCondition(postgresqlRestore, v1alpha1api.PostgresqlRestoreStatusConditionTypeStarted, corev1.ConditionFalse, ReasonInvalidSpec, message)
+			setPostgresqlRestoreCondition(postgresqlRestore, v1alpha1api.PostgresqlRestoreStatusConditionTypeCompleted, corev1.ConditionFalse, ReasonRestoreFailed, message)
+			c.er.Event(postgresqlRestore, corev1.EventTypeWarning, ReasonInvalidSpec, message)
+			c.logger.WithField(logFieldName, postgresqlRestore.Name).Error(message)
+			return false, nil
+		}
+		// The Cloud SQL Admin API returned a different error, which we propagate so that the restore operation may be retried.
+		setPostgresqlRestoreCondition(postgresqlRestore, v1alpha1api.PostgresqlRestoreStatusConditionTypeStarted, corev1.ConditionFalse, ReasonUnexpectedError, err.Error())
+		c.er.Event(postgresqlRestore, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+		return false, err
+	}
+	// Record the ID of the operation so that its status can be tracked.
+	postgresqlRestore.Status.OperationID = op.Name
+	// Update the PostgresqlRestore resource's conditions.
+	message := fmt.Sprintf("the restore operation has been started (operation: %q)", op.Name)
+	setPostgresqlRestoreCondition(postgresqlRestore, v1alpha1api.PostgresqlRestoreStatusConditionTypeStarted, corev1.ConditionTrue, ReasonRestoreStarted, message)
+	c.er.Event(postgresqlRestore, corev1.EventTypeNormal, ReasonRestoreStarted, message)
+	// Mark the target CSQLP instance as not ready right away rather than waiting for the controller for PostgresqlInstance resources to pick up the operation.
+	if err := c.markPostgresqlInstanceRestoring(target, op); err != nil {
+		c.logger.WithField(logFieldName, postgresqlRestore.Name).Warnf("failed to update the status of postgresqlinstance %q: %v", target.Name, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+)
+
+// getPostgresqlRestoreCondition returns the condition of the provided type associated with the provided PostgresqlRestore resource, or nil if no such condition exists.
+func getPostgresqlRestoreCondition(postgresqlRestore *v1alpha1api.PostgresqlRestore, conditionType v1alpha1api.PostgresqlRestoreStatusConditionType) *v1alpha1api.PostgresqlRestoreStatusCondition {
+	for idx := range postgresqlRestore.Status.Conditions {
+		if postgresqlRestore.Status.Conditions[idx].Type == conditionType {
+			return &postgresqlRestore.Status.Conditions[idx]
+		}
+	}
+	return nil
+}
+
+// markPostgresqlInstanceRestoring updates the status of the provided PostgresqlInstance resource in order to indicate that the CSQLP instance it represents is being restored by the provided operation.
+func (c *PostgresqlRestoreController) markPostgresqlInstanceRestoring(postgresqlInstance *v1alpha1api.PostgresqlInstance, op *cloudsqladmin.Operation) error {
+	// Create a deep copy of the PostgresqlInstance resource so we don't possibly mutate the cache.
+	p := postgresqlInstance.DeepCopy()
+	message := fmt.Sprintf("the instance is being restored from a backup (id: %q, status: %q)", op.Name, op.Status)
+	setPostgresqlInstanceCondition(p, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceRestoring, message)
+	c.er.Event(p, corev1.EventTypeNormal, ReasonInstanceRestoring, message)
+	// Prepare the patch to apply based on the provided objects.
+	oldBytes, err := json.Marshal(postgresqlInstance)
+	if err != nil {
+		return err
+	}
+	newBytes, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldBytes, newBytes, &v1alpha1api.PostgresqlInstance{})
+	if err != nil {
+		return err
+	}
+	// Apply the patch.
+	_, err = c.selfClient.CloudsqlV1alpha1().PostgresqlInstances().Patch(postgresqlInstance.Name, types.MergePatchType, patchBytes, "status")
+	return err
+}
+
+// patchPostgresqlRestore updates the provided PostgresqlRestore using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlRestoreController) patchPostgresqlRestore(oldObj, newObj *v1alpha1api.PostgresqlRestore, subresources ...string) (*v1alpha1api.PostgresqlRestore, error) {
+	// Return if there are no changes to be made.
+	if reflect.DeepEqual(oldObj, newObj) {
+		return newObj, nil
+	}
+	// Prepare the patch to apply based on the provided objects.
+	oldBytes, err := json.Marshal(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newBytes, err := json.Marshal(newObj)
+	if err != nil {
+		return nil, err
+	}
+	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldBytes, newBytes, &v1alpha1api.PostgresqlRestore{})
+	if err != nil {
+		return nil, err
+	}
+	// Apply the patch.
+	return c.selfClient.CloudsqlV1alpha1().PostgresqlRestores().Patch(oldObj.Name, types.MergePatchType, patchBytes, subresources...)
+}
+
+// patchPostgresqlRestoreStatus updates the status of the provided PostgresqlRestore using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlRestoreController) patchPostgresqlRestoreStatus(oldObj, newObj *v1alpha1api.PostgresqlRestore) (*v1alpha1api.PostgresqlRestore, error) {
+	return c.patchPostgresqlRestore(oldObj, newObj, "status")
+}
+
+// setPostgresqlRestoreCondition sets a condition on the provided PostgresqlRestore resource according to the following rules:
+// 1. If no condition of the provided type exists, the condition is inserted with its last transition time set to the current time.
+// 2. If a condition of the provided type and state exists, the condition is updated but its last transition time is not modified.
+// 3. If a condition of the provided type but different state exists, the condition is updated and its last transition time is set to the current time.
+func setPostgresqlRestoreCondition(postgresqlRestore *v1alpha1api.PostgresqlRestore, conditionType v1alpha1api.PostgresqlRestoreStatusConditionType, conditionStatus corev1.ConditionStatus, conditionReason string, conditionMessage string) {
+	// Create the new condition.
+	newCondition := v1alpha1api.PostgresqlRestoreStatusCondition{
+		LastTransitionTime: v1.NewTime(time.Now()),
+		Message:            conditionMessage,
+		Reason:             conditionReason,
+		Status:             conditionStatus,
+		Type:               conditionType,
+	}
+	// Search through existing conditions in order to understand if we need to insert the new condition or not.
+	for idx, cdn := range postgresqlRestore.Status.Conditions {
+		// If the current condition's type is different from the one we will be inserting, skip it.
+		if cdn.Type != newCondition.Type {
+			continue
+		}
+		// If the status is the same, we should not update the condition's last transition time.
+		if cdn.Status == newCondition.Status {
+			newCondition.LastTransitionTime = cdn.LastTransitionTime
+		}
+		// Overwrite the existing condition and return.
+		postgresqlRestore.Status.Conditions[idx] = newCondition
+		return
+	}
+	// At this point we know that there is no existing condition with this type, so we just append it to the set of conditions.
+	postgresqlRestore.Status.Conditions = append(postgresqlRestore.Status.Conditions, newCondition)
+}
@@ -0,0 +1,358 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/util/slice"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	v1alpha1client "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/clientset/versioned"
+	v1alpha1informers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/informers/externalversions/cloudsql/v1alpha1"
+	v1alpha1listers "github.com/travelaudience/cloudsql-postgres-operator/pkg/client/listers/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/configuration"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/crds"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/google"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/pointers"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/util/strings"
+)
+
+const (
+	// postgresqlUserControllerName is the name of the controller for PostgresqlUser resources.
+	postgresqlUserControllerName = "postgresqluser-controller"
+	// postgresqlUserControllerThreadiness is the number of workers controller for PostgresqlUser resource will use to process items from its work queue.
+	postgresqlUserControllerThreadiness = 1
+)
+
+// PostgresqlUserController is the controller for PostgresqlUser resources.
+type PostgresqlUserController struct {
+	// PostgresqlUserController is based-off of a generic controller.
+	*genericController
+	// cloudsqlClient is a client for the Cloud SQL Admin API.
+	cloudsqlClient *cloudsqladmin.Service
+	// er is an EventRecorder through which we can emit events associated with PostgresqlUser resources.
+	er record.EventRecorder
+	// kubeClient is a client to the Kubernetes API.
+	kubeClient kubernetes.Interface
+	// postgresqlInstanceLister is a lister for PostgresqlInstance resources.
+	postgresqlInstanceLister v1alpha1listers.PostgresqlInstanceLister
+	// postgresqlUserLister is a lister for PostgresqlUser resources.
+	postgresqlUserLister v1alpha1listers.PostgresqlUserLister
+	// projectID is the ID of the GCP project where cloudsql-postgres-operator is managing CSQLP instances.
+	projectID string
+	// selfClient is a client to the "cloudsql.travelaudience.com" API.
+	selfClient v1alpha1client.Interface
+}
+
+// NewPostgresqlUserController creates a new instance of the controller for PostgresqlUser resources.
+func NewPostgresqlUserController(config configuration.Configuration, kubeClient kubernetes.Interface, selfClient v1alpha1client.Interface, er record.EventRecorder, postgresqlUserInformer v1alpha1informers.PostgresqlUserInformer, postgresqlInstanceInformer v1alpha1informers.PostgresqlInstanceInformer, cloudsqlClient *cloudsqladmin.Service) *PostgresqlUserController {
+	// Create a new instance of the controller for PostgresqlUser resources using the specified name and threadiness.
+	c := &PostgresqlUserController{
+		cloudsqlClient:           cloudsqlClient,
+		genericController:        newGenericController(postgresqlUserControllerName, postgresqlUserControllerThreadiness),
+		er:                       er,
+		kubeClient:               kubeClient,
+		postgresqlInstanceLister: postgresqlInstanceInformer.Lister(),
+		postgresqlUserLister:     postgresqlUserInformer.Lister(),
+		projectID:                config.GCP.ProjectID,
+		selfClient:               selfClient,
+	}
+	// Make the controller wait for the caches to sync.
+	c.hasSyncedFuncs = []cache.InformerSynced{
+		postgresqlInstanceInformer.Informer().HasSynced,
+		postgresqlUserInformer.Informer().HasSynced,
+	}
+	// Make "processQueueItem" the handler for items popped out of the work queue.
+	c.syncHandler = c.processQueueItem
+
+	// Setup an event handler to inform us when PostgresqlUser resources change.
+	postgresqlUserInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			c.enqueue(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueue(obj)
+		},
+	})
+
+	// Return the instance of the controller for PostgresqlUser resources created above.
+	return c
+}
+
+// processQueueItem attempts to reconcile the state of the PostgresqlUser resource pointed at by the specified key.
+func (c *PostgresqlUserController) processQueueItem(key string) (err error) {
+	// Grab the namespace and name of the PostgresqlUser resource from the specified key.
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("invalid resource key %q", key))
+		return nil
+	}
+
+	// Get the PostgresqlUser resource with the specified namespace and name.
+	u, err := c.postgresqlUserLister.PostgresqlUsers(namespace).Get(name)
+	if err != nil {
+		// The PostgresqlUser may no longer exist, in which case we stop processing.
+		if kubeerrors.IsNotFound(err) {
+			c.logger.WithField(logFieldName, key).Debug("postgresqluser resource in work queue no longer exists")
+			return nil
+		}
+		return err
+	}
+	// Create a deep copy of the PostgresqlUser resource so we don't possibly mutate the cache.
+	p := u.DeepCopy()
+
+	// Check whether the PostgresqlUser resource is being deleted (indicated by a non-zero deletion timestamp).
+	if p.DeletionTimestamp.IsZero() {
+		// The PostgresqlUser resource is not being deleted, so we must add the finalizer in case it is not already present.
+		if !slice.ContainsString(p.Finalizers, constants.CleanupFinalizer, nil) {
+			p.Finalizers = append(p.Finalizers, constants.CleanupFinalizer)
+			if p, err = c.patchPostgresqlUser(u, p); err != nil {
+				return err
+			}
+		}
+	} else {
+		// The PostgresqlUser resource is being deleted, so we must delete the user and remove the finalizer.
+		// The secret containing the user's credentials is owned by the PostgresqlUser resource, and hence is garbage-collected afterwards.
+		if slice.ContainsString(p.Finalizers, constants.CleanupFinalizer, nil) {
+			if err := c.deleteUser(p); err != nil {
+				return err
+			}
+			p.Finalizers = slice.RemoveString(p.Finalizers, constants.CleanupFinalizer, nil)
+			if _, err = c.patchPostgresqlUser(u, p); err != nil {
+				return err
+			}
+		}
+		// The finalizer has finished, so there is nothing else to do.
+		return nil
+	}
+
+	// Make sure that the PostgresqlUser resource's ".status" field is always updated as the last processing step.
+	// If an error occurs during the update, it is aggregated with the error we would be returning (if any).
+	defer func() {
+		if _, patchErr := c.patchPostgresqlUserStatus(u, p); patchErr != nil {
+			err = utilerrors.NewAggregate([]error{patchErr, err})
+		}
+	}()
+
+	// Grab the PostgresqlInstance resource that represents the CSQLP instance in which the user is to be created.
+	i, err := c.postgresqlInstanceLister.Get(p.Spec.Instance)
+	if err != nil {
+		// If we've got an error other than "404 NOT FOUND", we stop processing and propagate it.
+		if !kubeerrors.IsNotFound(err) {
+			return err
+		}
+		// At this point we know that the PostgresqlInstance resource does not exist, so we report it and skip further processing (but don't error).
+		message := fmt.Sprintf("postgresqlinstance %q does not exist", p.Spec.Instance)
+		setPostgresqlUserCondition(p, v1alpha1api.PostgresqlUserStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+		c.logger.WithField(logFieldName, key).Infof("skipping sync because %s", message)
+		return nil
+	}
+
+	// Check whether the CSQLP instance is ready, in which case we skip further processing (but don't error).
+	if cdn := getPostgresqlInstanceCondition(i, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady); cdn == nil || cdn.Status != corev1.ConditionTrue {
+		message := fmt.Sprintf("postgresqlinstance %q is not ready", p.Spec.Instance)
+		setPostgresqlUserCondition(p, v1alpha1api.PostgresqlUserStatusConditionTypeReady, corev1.ConditionFalse, ReasonInstanceNotReady, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonInstanceNotReady, message)
+		c.logger.WithField(logFieldName, key).Infof("skipping sync because %s", message)
+		return nil
+	}
+
+	// Create the secret associated with the current PostgresqlUser resource, if necessary.
+	s, err := c.kubeClient.CoreV1().Secrets(p.Namespace).Get(*p.Spec.SecretName, metav1.GetOptions{})
+	if err != nil {
+		// If we've got an error other than "404 NOT FOUND", we stop processing and propagate it.
+		if !kubeerrors.IsNotFound(err) {
+			c.logger.WithField(logFieldName, key).Debugf("failed to check if the secret associated with the resource already exists: %v", err)
+			return err
+		}
+		// At this point we know that the secret associated with the current PostgresqlUser resource must be created.
+		if s, err = c.createUserSecret(p); err != nil {
+			c.logger.WithField(logFieldName, key).Debugf("failed to create the secret associated with the resource: %v", err)
+			return err
+		}
+	}
+	// Make sure that the secret is owned by the current PostgresqlUser resource, as writing the user's credentials to a pre-existing secret would overwrite its contents.
+	if !metav1.IsControlledBy(s, p) {
+		message := fmt.Sprintf("secret %q already exists and is not owned by the resource", s.Name)
+		setPostgresqlUserCondition(p, v1alpha1api.PostgresqlUserStatusConditionTypeReady, corev1.ConditionFalse, ReasonSecretUnavailable, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonSecretUnavailable, message)
+		c.logger.WithField(logFieldName, key).Error(message)
+		return nil
+	}
+
+	// Check whether the user already exists in the CSQLP instance.
+	user, err := c.getUser(i.Spec.Name, p.Spec.Name)
+	if err != nil {
+		c.logger.WithField(logFieldName, key).Debugf("failed to check if a user with name %q exists: %v", p.Spec.Name, err)
+		return fmt.Errorf("failed to check if a user with name %q exists: %v", p.Spec.Name, err)
+	}
+	// If a user with the specified ".spec.name" exists but has not been created by us (e.g. it has been created out-of-band after the PostgresqlUser resource has been admitted), we must not touch it.
+	// Otherwise, its password would be reset and handed over to whoever created the PostgresqlUser resource, and the user would be dropped when the PostgresqlUser resource is deleted.
+	if user != nil && !isPostgresqlUserCreated(p) {
+		message := fmt.Sprintf("the name %q is already in use by a user which has not been created by %s", p.Spec.Name, constants.ApplicationName)
+		setPostgresqlUserCondition(p, v1alpha1api.PostgresqlUserStatusConditionTypeCreated, corev1.ConditionFalse, ReasonNameUnavailable, message)
+		setPostgresqlUserCondition(p, v1alpha1api.PostgresqlUserStatusConditionTypeReady, corev1.ConditionFalse, ReasonNameUnavailable, message)
+		c.er.Event(p, corev1.EventTypeWarning, ReasonNameUnavailable, message)
+		c.logger.WithField(logFieldName, key).Error(message)
+		return nil
+	}
+	// Create the user or (re)set its password if it does not exist yet, or if its password has not yet been written to the secret.
+	if password, exists := s.Data[constants.PostgresqlUserPasswordKey]; user == nil || !exists || len(password) == 0 {
+		if err := c.setUserPassword(p, i, user != nil, s); err != nil {
+			setPostgresqlUserCondition(p, v1alpha1api.PostgresqlUserStatusConditionTypeCreated, corev1.ConditionFalse, ReasonUnexpectedError, err.Error())
+			c.er.Event(p, corev1.EventTypeWarning, ReasonUnexpectedError, err.Error())
+			c.logger.WithField(logFieldName, key).Debugf("failed to set user password: %v", err)
+			return err
+		}
+		if user == nil {
+			message := "the user has been created"
+			setPostgresqlUserCondition(p, v1alpha1api.PostgresqlUserStatusConditionTypeCreated, corev1.ConditionTrue, ReasonUserCreated, message)
+			c.er.Event(p, corev1.EventTypeNormal, ReasonUserCreated, message)
+		}
+	}
+
+	// Update the PostgresqlUser resource's conditions to indicate readiness.
+	message := "the user is ready"
+	setPostgresqlUserCondition(p, v1alpha1api.PostgresqlUserStatusConditionTypeReady, corev1.ConditionTrue, ReasonUserReady, message)
+	c.er.Event(p, corev1.EventTypeNormal, ReasonUserReady, message)
+	return nil
+}
+
+// createUserSecret creates the (initially empty) secret associated with the specified PostgresqlUser resource.
+func (c *PostgresqlUserController) createUserSecret(postgresqlUser *v1alpha1api.PostgresqlUser) (*corev1.Secret, error) {
+	return c.kubeClient.CoreV1().Secrets(postgresqlUser.Namespace).Create(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				constants.LabelAppKey: constants.ApplicationName,
+			},
+			Name:      *postgresqlUser.Spec.SecretName,
+			Namespace: postgresqlUser.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         v1alpha1api.SchemeGroupVersion.String(),
+					Kind:               crds.PostgresqlUserKind,
+					Name:               postgresqlUser.Name,
+					UID:                postgresqlUser.UID,
+					Controller:         pointers.NewBool(true),
+					BlockOwnerDeletion: pointers.NewBool(true),
+				},
+			},
+		},
+	})
+}
+
+// deleteUser attempts to delete the user associated with the specified PostgresqlUser resource.
+func (c *PostgresqlUserController) deleteUser(postgresqlUser *v1alpha1api.PostgresqlUser) error {
+	c.logger.WithField(logFieldName, postgresqlUser.Name).Debug("checking whether the user needs to be deleted")
+	// If the user has not been created by us, it must not be dropped (e.g. because it belongs to another PostgresqlUser resource or has been created out-of-band).
+	if !isPostgresqlUserCreated(postgresqlUser) {
+		c.logger.WithField(logFieldName, postgresqlUser.Name).Debug("the user has not been created by the current resource")
+		return nil
+	}
+	// Grab the PostgresqlInstance resource that represents the CSQLP instance in which the user was created.
+	// If it no longer exists, the user has been deleted together with the CSQLP instance.
+	i, err := c.postgresqlInstanceLister.Get(postgresqlUser.Spec.Instance)
+	if err != nil {
+		if kubeerrors.IsNotFound(err) {
+			c.logger.WithField(logFieldName, postgresqlUser.Name).Debug("the instance has already been deleted")
+			return nil
+		}
+		return err
+	}
+	// Before issuing a delete request, make sure the user is still listed.
+	user, err := c.getUser(i.Spec.Name, postgresqlUser.Spec.Name)
+	if err != nil {
+		if google.IsNotFound(err) {
+			c.logger.WithField(logFieldName, postgresqlUser.Name).Debug("the instance has already been deleted")
+			return nil
+		}
+		return err
+	}
+	if user == nil {
+		c.logger.WithField(logFieldName, postgresqlUser.Name).Debug("the user has already been deleted")
+		return nil
+	}
+	c.logger.WithField(logFieldName, postgresqlUser.Name).Infof("deleting user %q", postgresqlUser.Spec.Name)
+	// At this point we know the user already exists, so we issue the delete request.
+	if _, err := c.cloudsqlClient.Users.Delete(c.projectID, i.Spec.Name).Host(user.Host).Name(user.Name).Do(); err != nil {
+		return err
+	}
+	c.logger.WithField(logFieldName, postgresqlUser.Name).Debugf("user %q has been deleted", postgresqlUser.Spec.Name)
+	return nil
+}
+
+// getUser returns the user with the specified name in the specified CSQLP instance, or nil if no such user exists.
+func (c *PostgresqlUserController) getUser(instanceName, userName string) (*cloudsqladmin.User, error) {
+	users, err := c.cloudsqlClient.Users.List(c.projectID, instanceName).Do()
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users.Items {
+		if user != nil && user.Name == userName {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+// setUserPassword generates a random password for the user associated with the specified PostgresqlUser resource, creating or updating the user as required, and writes it to the specified secret.
+// The user is only ever updated if it has been created by cloudsql-postgres-operator for the specified PostgresqlUser resource.
+func (c *PostgresqlUserController) setUserPassword(postgresqlUser *v1alpha1api.PostgresqlUser, postgresqlInstance *v1alpha1api.PostgresqlInstance, exists bool, secret *corev1.Secret) error {
+	c.logger.WithField(logFieldName, postgresqlUser.Name).Debugf("setting the %q user's password", postgresqlUser.Spec.Name)
+	// Create a User object representing the user and having a randomly-generated password.
+	u := &cloudsqladmin.User{
+		Name:     postgresqlUser.Spec.Name,
+		Password: strings.RandomStringWithLength(passwordLength, passwordAlphabet),
+	}
+	// Create the user or update its password, depending on whether it already exists.
+	var err error
+	if exists {
+		if !isPostgresqlUserCreated(postgresqlUser) {
+			return fmt.Errorf("refusing to set the password of user %q as it has not been created by %s", postgresqlUser.Spec.Name, constants.ApplicationName)
+		}
+		_, err = c.cloudsqlClient.Users.Update(c.projectID, postgresqlInstance.Spec.Name, u).Name(u.Name).Do()
+	} else {
+		c.logger.WithField(logFieldName, postgresqlUser.Name).Infof("creating user %q", postgresqlUser.Spec.Name)
+		_, err = c.cloudsqlClient.Users.Insert(c.projectID, postgresqlInstance.Spec.Name, u).Do()
+	}
+	if err != nil {
+		return err
+	}
+	// Update the secret with the user's username and password.
+	if secret.StringData == nil {
+		secret.StringData = make(map[string]string, 2)
+	}
+	secret.StringData[constants.PostgresqlUserUsernameKey] = u.Name
+	secret.StringData[constants.PostgresqlUserPasswordKey] = u.Password
+	_, err = c.kubeClient.CoreV1().Secrets(secret.Namespace).Update(secret)
+	return err
+}
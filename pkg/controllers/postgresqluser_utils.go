@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+
+	v1alpha1api "github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+)
+
+// getPostgresqlUserCondition returns the condition of the provided type associated with the provided PostgresqlUser resource, or nil if no such condition exists.
+func getPostgresqlUserCondition(postgresqlUser *v1alpha1api.PostgresqlUser, conditionType v1alpha1api.PostgresqlUserStatusConditionType) *v1alpha1api.PostgresqlUserStatusCondition {
+	for idx := range postgresqlUser.Status.Conditions {
+		if postgresqlUser.Status.Conditions[idx].Type == conditionType {
+			return &postgresqlUser.Status.Conditions[idx]
+		}
+	}
+	return nil
+}
+
+// isPostgresqlUserCreated returns whether the user represented by the provided PostgresqlUser resource has been created by cloudsql-postgres-operator.
+func isPostgresqlUserCreated(postgresqlUser *v1alpha1api.PostgresqlUser) bool {
+	cdn := getPostgresqlUserCondition(postgresqlUser, v1alpha1api.PostgresqlUserStatusConditionTypeCreated)
+	return cdn != nil && cdn.Status == corev1.ConditionTrue
+}
+
+// patchPostgresqlUser updates the provided PostgresqlUser using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlUserController) patchPostgresqlUser(oldObj, newObj *v1alpha1api.PostgresqlUser, subresources ...string) (*v1alpha1api.PostgresqlUser, error) {
+	// Return if there are no changes to be made.
+	if reflect.DeepEqual(oldObj, newObj) {
+		return newObj, nil
+	}
+	// Prepare the patch to apply based on the provided objects.
+	oldBytes, err := json.Marshal(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newBytes, err := json.Marshal(newObj)
+	if err != nil {
+		return nil, err
+	}
+	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldBytes, newBytes, &v1alpha1api.PostgresqlUser{})
+	if err != nil {
+		return nil, err
+	}
+	// Apply the patch.
+	return c.selfClient.CloudsqlV1alpha1().PostgresqlUsers(oldObj.Namespace).Patch(oldObj.Name, types.MergePatchType, patchBytes, subresources...)
+}
+
+// patchPostgresqlUserStatus updates the status of the provided PostgresqlUser using patch semantics.
+// If there are no changes to be made, no patch is performed.
+func (c *PostgresqlUserController) patchPostgresqlUserStatus(oldObj, newObj *v1alpha1api.PostgresqlUser) (*v1alpha1api.PostgresqlUser, error) {
+	return c.patchPostgresqlUser(oldObj, newObj, "status")
+}
+
+// setPostgresqlUserCondition sets a condition on the provided PostgresqlUser resource according to the following rules:
+// 1. If no condition of the provided type exists, the condition is inserted with its last transition time set to the current time.
+// 2. If a condition of the provided type and state exists, the condition is updated but its last transition time is not modified.
+// 3. If a condition of the provided type but different state exists, the condition is updated and its last transition time is set to the current time.
+func setPostgresqlUserCondition(postgresqlUser *v1alpha1api.PostgresqlUser, conditionType v1alpha1api.PostgresqlUserStatusConditionType, conditionStatus corev1.ConditionStatus, conditionReason string, conditionMessage string) {
+	// Create the new condition.
+	newCondition := v1alpha1api.PostgresqlUserStatusCondition{
+		LastTransitionTime: v1.NewTime(time.Now()),
+		Message:            conditionMessage,
+		Reason:             conditionReason,
+		Status:             conditionStatus,
+		Type:               conditionType,
+	}
+	// Search through existing conditions in order to understand if we need to insert the new condition or not.
+	for idx, cdn := range postgresqlUser.Status.Conditions {
+		// If the current condition's type is different from the one we will be inserting, skip it.
+		if cdn.Type != newCondition.Type {
+			continue
+		}
+		// If the status is the same, we should not update the condition's last transition time.
+		if cdn.Status == newCondition.Status {
+			newCondition.LastTransitionTime = cdn.LastTransitionTime
+		}
+		// Overwrite the existing condition and return.
+		postgresqlUser.Status.Conditions[idx] = newCondition
+		return
+	}
+	// At this point we know that there is no existing condition with this type, so we just append it to the set of conditions.
+	postgresqlUser.Status.Conditions = append(postgresqlUser.Status.Conditions, newCondition)
+}
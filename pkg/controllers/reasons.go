@@ -17,24 +17,154 @@ limitations under the License.
 package controllers
 
 const (
+	// ReasonAdoptionPending is the reason used in conditions and events that indicate that the adoption of a pre-existing CSQLP instance is pending until differences between its settings and the specification are resolved.
+	ReasonAdoptionPending = "AdoptionPending"
+	// ReasonBackupCompleted is the reason used in conditions and events that indicate that a backup run has completed successfully.
+	ReasonBackupCompleted = "BackupCompleted"
+	// ReasonBackupCreated is the reason used in conditions and events that indicate that a backup run has been created.
+	ReasonBackupCreated = "BackupCreated"
+	// ReasonBackupFailed is the reason used in conditions and events that indicate that a backup run has failed.
+	ReasonBackupFailed = "BackupFailed"
+	// ReasonBackupNotCompleted is the reason used in conditions and events that indicate that a backup run has not completed successfully.
+	ReasonBackupNotCompleted = "BackupNotCompleted"
+	// ReasonClientCertificateCreated is the reason used in conditions and events that indicate that a client certificate has been issued.
+	ReasonClientCertificateCreated = "ClientCertificateCreated"
+	// ReasonClientCertificateReady is the reason used in conditions and events that indicate that a client certificate is ready.
+	ReasonClientCertificateReady = "ClientCertificateReady"
+	// ReasonCloneFailed is the reason used in conditions and events that indicate that the creation of a CSQLP instance as a clone of an existing one has failed.
+	ReasonCloneFailed = "CloneFailed"
 	// ReasonConflict is the reason used in conditions and events that indicate that a conflict was found while updating a CSQLP instance.
 	ReasonConflict = "Conflict"
+	// ReasonDatabaseCreated is the reason used in conditions and events that indicate that a database has been created.
+	ReasonDatabaseCreated = "DatabaseCreated"
+	// ReasonDatabaseReady is the reason used in conditions and events that indicate that a database is ready.
+	ReasonDatabaseReady = "DatabaseReady"
+	// ReasonDeletionProtectionEnabled is the reason used in events that indicate that a CSQLP instance cannot be deleted since deletion protection is enabled.
+	ReasonDeletionProtectionEnabled = "DeletionProtectionEnabled"
+	// ReasonDeletionProtectionLifted is the reason used in events that indicate that deletion protection is being lifted from a CSQLP instance so that it can be deleted.
+	ReasonDeletionProtectionLifted = "DeletionProtectionLifted"
+	// ReasonDisruptiveUpdateApplied is the reason used in conditions and events that indicate that changes which require restarting a CSQLP instance have been applied.
+	ReasonDisruptiveUpdateApplied = "DisruptiveUpdateApplied"
+	// ReasonDisruptiveUpdateHeldBack is the reason used in conditions that indicate that a CSQLP instance is not up-to-date because changes which require restarting it are being held back.
+	ReasonDisruptiveUpdateHeldBack = "DisruptiveUpdateHeldBack"
+	// ReasonDisruptiveUpdatePending is the reason used in conditions and events that indicate that changes which require restarting a CSQLP instance are pending.
+	ReasonDisruptiveUpdatePending = "DisruptiveUpdatePending"
+	// ReasonExportCompleted is the reason used in conditions and events that indicate that an export run has completed successfully.
+	ReasonExportCompleted = "ExportCompleted"
+	// ReasonExportFailed is the reason used in conditions and events that indicate that an export run has failed.
+	ReasonExportFailed = "ExportFailed"
+	// ReasonExportInProgress is the reason used in conditions and events that indicate that an export run is in progress.
+	ReasonExportInProgress = "ExportInProgress"
+	// ReasonExportStarted is the reason used in conditions and events that indicate that an export run has been started.
+	ReasonExportStarted = "ExportStarted"
+	// ReasonFinalBackupCompleted is the reason used in conditions and events that indicate that the final backup of a CSQLP instance has completed successfully.
+	ReasonFinalBackupCompleted = "FinalBackupCompleted"
+	// ReasonFinalBackupFailed is the reason used in conditions and events that indicate that the final backup of a CSQLP instance has failed.
+	ReasonFinalBackupFailed = "FinalBackupFailed"
+	// ReasonFinalBackupInProgress is the reason used in conditions and events that indicate that the final backup of a CSQLP instance is in progress.
+	ReasonFinalBackupInProgress = "FinalBackupInProgress"
+	// ReasonFinalBackupSkipped is the reason used in conditions and events that indicate that the final backup of a CSQLP instance has been skipped.
+	ReasonFinalBackupSkipped = "FinalBackupSkipped"
+	// ReasonFinalBackupStarted is the reason used in conditions and events that indicate that the final backup of a CSQLP instance has been started.
+	ReasonFinalBackupStarted = "FinalBackupStarted"
+	// ReasonImportCompleted is the reason used in conditions and events that indicate that an import operation has completed successfully.
+	ReasonImportCompleted = "ImportCompleted"
+	// ReasonImportFailed is the reason used in conditions and events that indicate that an import operation has failed.
+	ReasonImportFailed = "ImportFailed"
+	// ReasonImportInProgress is the reason used in conditions and events that indicate that an import operation is in progress.
+	ReasonImportInProgress = "ImportInProgress"
+	// ReasonImportStarted is the reason used in conditions and events that indicate that an import operation has been started.
+	ReasonImportStarted = "ImportStarted"
+	// ReasonInstanceAdopted is the reason used in conditions and events that indicate that a pre-existing CSQLP instance has been adopted.
+	ReasonInstanceAdopted = "InstanceAdopted"
+	// ReasonInstanceCloning is the reason used in conditions and events that indicate that a CSQLP instance is being created as a clone of an existing one.
+	ReasonInstanceCloning = "InstanceCloning"
 	// ReasonInstanceCreated is the reason used in conditions and events that indicate that a CSQLP instance has been created.
 	ReasonInstanceCreated = "InstanceCreated"
+	// ReasonInstanceFailingOver is the reason used in conditions and events that indicate that a CSQLP instance is failing over to its standby.
+	ReasonInstanceFailingOver = "InstanceFailingOver"
+	// ReasonInstanceFailoverFailed is the reason used in events that indicate that a requested failover of a CSQLP instance could not be performed.
+	ReasonInstanceFailoverFailed = "InstanceFailoverFailed"
 	// ReasonInstanceNotReady is the reason used in conditions and events that indicate that a CSQLP instance is not ready.
 	ReasonInstanceNotReady = "InstanceNotReady"
 	// v is the reason used in conditions and events that indicate that a CSQLP instance is ready.
 	ReasonInstanceReady = "InstanceReady"
+	// ReasonInstanceRestartFailed is the reason used in events that indicate that a requested restart of a CSQLP instance could not be performed.
+	ReasonInstanceRestartFailed = "InstanceRestartFailed"
+	// ReasonInstanceRestarting is the reason used in conditions and events that indicate that a CSQLP instance is being restarted.
+	ReasonInstanceRestarting = "InstanceRestarting"
+	// ReasonInstanceRestoring is the reason used in conditions and events that indicate that a CSQLP instance is being restored from a backup run.
+	ReasonInstanceRestoring = "InstanceRestoring"
+	// ReasonInstanceRetained is the reason used in events that indicate that a CSQLP instance has been left untouched upon deletion of the PostgresqlInstance resource that represents it.
+	ReasonInstanceRetained = "InstanceRetained"
+	// ReasonInstanceScheduledStart is the reason used in events that indicate that a CSQLP instance is being started according to its schedule.
+	ReasonInstanceScheduledStart = "InstanceScheduledStart"
+	// ReasonInstanceScheduledStop is the reason used in events that indicate that a CSQLP instance is being stopped according to its schedule.
+	ReasonInstanceScheduledStop = "InstanceScheduledStop"
+	// ReasonInstanceStarting is the reason used in conditions and events that indicate that a CSQLP instance is being started.
+	ReasonInstanceStarting = "InstanceStarting"
+	// ReasonInstanceStopped is the reason used in conditions and events that indicate that a CSQLP instance is stopped as requested.
+	ReasonInstanceStopped = "InstanceStopped"
+	// ReasonInstanceStopping is the reason used in conditions and events that indicate that a CSQLP instance is being stopped.
+	ReasonInstanceStopping = "InstanceStopping"
 	// ReasonInstanceUpdated is the reason used in conditions and events that indicate that a CSQLP instance has been updated.
 	ReasonInstanceUpdated = "InstanceUpdated"
+	// ReasonInstanceUpgraded is the reason used in conditions and events that indicate that a CSQLP instance has been upgraded to a newer major version.
+	ReasonInstanceUpgraded = "InstanceUpgraded"
+	// ReasonInstanceUpgrading is the reason used in conditions and events that indicate that a CSQLP instance is being upgraded to a newer major version.
+	ReasonInstanceUpgrading = "InstanceUpgrading"
 	// ReasonInstanceUpToDate is the reason used in conditions and events that indicate that a CSQLP instance is up-to-date.
 	ReasonInstanceUpToDate = "InstanceUpToDate"
 	// ReasonInvalidSpec is the reason used in conditions and events that indicate that an invalid specification was provided for a CSQLP instance.
 	ReasonInvalidSpec = "InvalidSpec"
 	// ReasonNameUnavailable is the reason used in conditions and events that indicate that the chosen name for a CSQLP instance is unavailable.
 	ReasonNameUnavailable = "NameUnavailable"
+	// ReasonNoDisruptiveUpdatePending is the reason used in conditions that indicate that no changes which require restarting a CSQLP instance are pending.
+	ReasonNoDisruptiveUpdatePending = "NoDisruptiveUpdatePending"
 	// ReasonOperationInProgress is the reason used in conditions and events that indicate that an operation is still in progress for a CSQLP instance.
 	ReasonOperationInProgress = "OperationInProgress"
+	// ReasonPasswordNotReset is the reason used in conditions and events that indicate that the password of the "postgres" user of an adopted CSQLP instance has not been reset.
+	ReasonPasswordNotReset = "PasswordNotReset"
+	// ReasonReplicaCreated is the reason used in conditions and events that indicate that a read replica has been created.
+	ReasonReplicaCreated = "ReplicaCreated"
+	// ReasonReplicaNotPromoted is the reason used in conditions and events that indicate that the promotion of a read replica is no longer requested after having failed.
+	ReasonReplicaNotPromoted = "ReplicaNotPromoted"
+	// ReasonReplicaPromoted is the reason used in conditions and events that indicate that a read replica has been promoted to a standalone CSQLP instance.
+	ReasonReplicaPromoted = "ReplicaPromoted"
+	// ReasonReplicaPromoting is the reason used in conditions and events that indicate that a read replica is being promoted to a standalone CSQLP instance.
+	ReasonReplicaPromoting = "ReplicaPromoting"
+	// ReasonReplicaPromotionFailed is the reason used in conditions and events that indicate that the promotion of a read replica to a standalone CSQLP instance has failed.
+	ReasonReplicaPromotionFailed = "ReplicaPromotionFailed"
+	// ReasonReplicaReady is the reason used in conditions and events that indicate that a read replica is ready.
+	ReasonReplicaReady = "ReplicaReady"
+	// ReasonReplicaUpdated is the reason used in conditions and events that indicate that a read replica has been updated.
+	ReasonReplicaUpdated = "ReplicaUpdated"
+	// ReasonReplicaUpToDate is the reason used in conditions and events that indicate that a read replica is up-to-date.
+	ReasonReplicaUpToDate = "ReplicaUpToDate"
+	// ReasonRestoreCompleted is the reason used in conditions and events that indicate that a restore operation has completed successfully.
+	ReasonRestoreCompleted = "RestoreCompleted"
+	// ReasonRestoreFailed is the reason used in conditions and events that indicate that a restore operation has failed.
+	ReasonRestoreFailed = "RestoreFailed"
+	// ReasonRestoreStarted is the reason used in conditions and events that indicate that a restore operation has been started.
+	ReasonRestoreStarted = "RestoreStarted"
+	// ReasonSecretUnavailable is the reason used in conditions and events that indicate that a secret cannot be used as it is not owned by the resource it is meant for.
+	ReasonSecretUnavailable = "SecretUnavailable"
+	// ReasonServerCAAdding is the reason used in events that indicate that an upcoming server CA certificate is being added to a CSQLP instance.
+	ReasonServerCAAdding = "ServerCAAdding"
+	// ReasonServerCABundleUpdated is the reason used in events that indicate that the server CA bundles distributed to namespace-local secrets have been updated.
+	ReasonServerCABundleUpdated = "ServerCABundleUpdated"
+	// ReasonServerCAExpiring is the reason used in conditions and events that indicate that the server CA certificate of a CSQLP instance is about to expire.
+	ReasonServerCAExpiring = "ServerCAExpiring"
+	// ReasonServerCARotating is the reason used in conditions and events that indicate that the server CA certificate of a CSQLP instance is being rotated.
+	ReasonServerCARotating = "ServerCARotating"
+	// ReasonServerCAValid is the reason used in conditions that indicate that the server CA certificate of a CSQLP instance is not about to expire.
+	ReasonServerCAValid = "ServerCAValid"
 	// ReasonUnexpectedError is the reason used in conditions and events that indicate that an unexpected error occurred while managing a CSQLP instance.
 	ReasonUnexpectedError = "UnexpectedError"
+	// ReasonUpgradeFailed is the reason used in conditions and events that indicate that the upgrade of a CSQLP instance to a newer major version has failed.
+	ReasonUpgradeFailed = "UpgradeFailed"
+	// ReasonUserCreated is the reason used in conditions and events that indicate that a PostgreSQL user has been created.
+	ReasonUserCreated = "UserCreated"
+	// ReasonUserReady is the reason used in conditions and events that indicate that a PostgreSQL user is ready.
+	ReasonUserReady = "UserReady"
 )
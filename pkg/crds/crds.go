@@ -27,20 +27,322 @@ import (
 )
 
 const (
+	// PostgresqlBackupKind is the value used as ".spec.names.kind" when registering the PostgresqlBackup CRD.
+	PostgresqlBackupKind = "PostgresqlBackup"
+	// PostgresqlBackupPlural is the value used as ".spec.names.plural" when registering the PostgresqlBackup CRD.
+	PostgresqlBackupPlural = "postgresqlbackups"
+	// PostgresqlClientCertificateKind is the value used as ".spec.names.kind" when registering the PostgresqlClientCertificate CRD.
+	PostgresqlClientCertificateKind = "PostgresqlClientCertificate"
+	// PostgresqlClientCertificatePlural is the value used as ".spec.names.plural" when registering the PostgresqlClientCertificate CRD.
+	PostgresqlClientCertificatePlural = "postgresqlclientcertificates"
+	// PostgresqlDatabaseKind is the value used as ".spec.names.kind" when registering the PostgresqlDatabase CRD.
+	PostgresqlDatabaseKind = "PostgresqlDatabase"
+	// PostgresqlDatabasePlural is the value used as ".spec.names.plural" when registering the PostgresqlDatabase CRD.
+	PostgresqlDatabasePlural = "postgresqldatabases"
+	// PostgresqlExportKind is the value used as ".spec.names.kind" when registering the PostgresqlExport CRD.
+	PostgresqlExportKind = "PostgresqlExport"
+	// PostgresqlExportPlural is the value used as ".spec.names.plural" when registering the PostgresqlExport CRD.
+	PostgresqlExportPlural = "postgresqlexports"
+	// PostgresqlImportKind is the value used as ".spec.names.kind" when registering the PostgresqlImport CRD.
+	PostgresqlImportKind = "PostgresqlImport"
+	// PostgresqlImportPlural is the value used as ".spec.names.plural" when registering the PostgresqlImport CRD.
+	PostgresqlImportPlural = "postgresqlimports"
 	// PostgresqlInstanceKind is the value used as ".spec.names.kind" when registering the PostgresqlInstance CRD.
 	PostgresqlInstanceKind = "PostgresqlInstance"
 	// PostgresqlInstancePlural is the value used as ".spec.names.plural" when registering the PostgresqlInstance CRD.
 	PostgresqlInstancePlural = "postgresqlinstances"
+	// PostgresqlReplicaKind is the value used as ".spec.names.kind" when registering the PostgresqlReplica CRD.
+	PostgresqlReplicaKind = "PostgresqlReplica"
+	// PostgresqlReplicaPlural is the value used as ".spec.names.plural" when registering the PostgresqlReplica CRD.
+	PostgresqlReplicaPlural = "postgresqlreplicas"
+	// PostgresqlRestoreKind is the value used as ".spec.names.kind" when registering the PostgresqlRestore CRD.
+	PostgresqlRestoreKind = "PostgresqlRestore"
+	// PostgresqlRestorePlural is the value used as ".spec.names.plural" when registering the PostgresqlRestore CRD.
+	PostgresqlRestorePlural = "postgresqlrestores"
+	// PostgresqlUserKind is the value used as ".spec.names.kind" when registering the PostgresqlUser CRD.
+	PostgresqlUserKind = "PostgresqlUser"
+	// PostgresqlUserPlural is the value used as ".spec.names.plural" when registering the PostgresqlUser CRD.
+	PostgresqlUserPlural = "postgresqlusers"
 )
 
 var (
+	// postgresqlBackupCRDName is the value used as ".metadata.name" when registering the PostgresqlBackup CRD.
+	postgresqlBackupCRDName = fmt.Sprintf("%s.%s", PostgresqlBackupPlural, v1alpha1.SchemeGroupVersion.Group)
+	// postgresqlClientCertificateCRDName is the value used as ".metadata.name" when registering the PostgresqlClientCertificate CRD.
+	postgresqlClientCertificateCRDName = fmt.Sprintf("%s.%s", PostgresqlClientCertificatePlural, v1alpha1.SchemeGroupVersion.Group)
+	// postgresqlDatabaseCRDName is the value used as ".metadata.name" when registering the PostgresqlDatabase CRD.
+	postgresqlDatabaseCRDName = fmt.Sprintf("%s.%s", PostgresqlDatabasePlural, v1alpha1.SchemeGroupVersion.Group)
+	// postgresqlExportCRDName is the value used as ".metadata.name" when registering the PostgresqlExport CRD.
+	postgresqlExportCRDName = fmt.Sprintf("%s.%s", PostgresqlExportPlural, v1alpha1.SchemeGroupVersion.Group)
+	// postgresqlImportCRDName is the value used as ".metadata.name" when registering the PostgresqlImport CRD.
+	postgresqlImportCRDName = fmt.Sprintf("%s.%s", PostgresqlImportPlural, v1alpha1.SchemeGroupVersion.Group)
 	// postgresqlInstanceCRDName is the value used as ".metadata.name" when registering the PostgresqlInstance CRD.
 	postgresqlInstanceCRDName = fmt.Sprintf("%s.%s", PostgresqlInstancePlural, v1alpha1.SchemeGroupVersion.Group)
+	// postgresqlReplicaCRDName is the value used as ".metadata.name" when registering the PostgresqlReplica CRD.
+	postgresqlReplicaCRDName = fmt.Sprintf("%s.%s", PostgresqlReplicaPlural, v1alpha1.SchemeGroupVersion.Group)
+	// postgresqlRestoreCRDName is the value used as ".metadata.name" when registering the PostgresqlRestore CRD.
+	postgresqlRestoreCRDName = fmt.Sprintf("%s.%s", PostgresqlRestorePlural, v1alpha1.SchemeGroupVersion.Group)
+	// postgresqlUserCRDName is the value used as ".metadata.name" when registering the PostgresqlUser CRD.
+	postgresqlUserCRDName = fmt.Sprintf("%s.%s", PostgresqlUserPlural, v1alpha1.SchemeGroupVersion.Group)
 )
 
 var (
 	// crds is a mapping between kinds and actual CustomResourceDefinition resources.
 	crds = map[string]*extsv1beta1.CustomResourceDefinition{
+		PostgresqlBackupKind: {
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					constants.LabelAppKey: constants.ApplicationName,
+				},
+				Name: postgresqlBackupCRDName,
+			},
+			Spec: extsv1beta1.CustomResourceDefinitionSpec{
+				Group: v1alpha1.SchemeGroupVersion.Group,
+				Names: extsv1beta1.CustomResourceDefinitionNames{
+					Plural: PostgresqlBackupPlural,
+					Kind:   PostgresqlBackupKind,
+				},
+				Scope: extsv1beta1.ClusterScoped,
+				Subresources: &extsv1beta1.CustomResourceSubresources{
+					Status: &extsv1beta1.CustomResourceSubresourceStatus{},
+				},
+				Versions: []extsv1beta1.CustomResourceDefinitionVersion{
+					{
+						Name:    v1alpha1.SchemeGroupVersion.Version,
+						Served:  true,
+						Storage: true,
+					},
+				},
+				AdditionalPrinterColumns: []extsv1beta1.CustomResourceColumnDefinition{
+					{
+						Name:        "Instance",
+						Type:        "string",
+						Description: "The name of the PostgresqlInstance resource representing the Cloud SQL for PostgreSQL instance.",
+						JSONPath:    ".spec.instance",
+					},
+					{
+						Name:        "Backup ID",
+						Type:        "string",
+						Description: "The ID of the backup run.",
+						JSONPath:    ".status.id",
+					},
+					{
+						Name:        "State",
+						Type:        "string",
+						Description: "The state of the backup run.",
+						JSONPath:    ".status.state",
+					},
+					{
+						Name:        "Age",
+						Type:        "date",
+						Description: "Time elapsed since the resource was created.",
+						JSONPath:    ".metadata.creationTimestamp",
+					},
+				},
+			},
+		},
+		PostgresqlClientCertificateKind: {
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					constants.LabelAppKey: constants.ApplicationName,
+				},
+				Name: postgresqlClientCertificateCRDName,
+			},
+			Spec: extsv1beta1.CustomResourceDefinitionSpec{
+				Group: v1alpha1.SchemeGroupVersion.Group,
+				Names: extsv1beta1.CustomResourceDefinitionNames{
+					Plural: PostgresqlClientCertificatePlural,
+					Kind:   PostgresqlClientCertificateKind,
+				},
+				Scope: extsv1beta1.NamespaceScoped,
+				Subresources: &extsv1beta1.CustomResourceSubresources{
+					Status: &extsv1beta1.CustomResourceSubresourceStatus{},
+				},
+				Versions: []extsv1beta1.CustomResourceDefinitionVersion{
+					{
+						Name:    v1alpha1.SchemeGroupVersion.Version,
+						Served:  true,
+						Storage: true,
+					},
+				},
+				AdditionalPrinterColumns: []extsv1beta1.CustomResourceColumnDefinition{
+					{
+						Name:        "Instance",
+						Type:        "string",
+						Description: "The name of the PostgresqlInstance resource representing the Cloud SQL for PostgreSQL instance.",
+						JSONPath:    ".spec.instance",
+					},
+					{
+						Name:        "Secret",
+						Type:        "string",
+						Description: "The name of the secret containing the client certificate.",
+						JSONPath:    ".spec.secretName",
+					},
+					{
+						Name:        "Expiration",
+						Type:        "date",
+						Description: "The time at which the client certificate expires.",
+						JSONPath:    ".status.expirationTime",
+					},
+					{
+						Name:        "Age",
+						Type:        "date",
+						Description: "Time elapsed since the resource was created.",
+						JSONPath:    ".metadata.creationTimestamp",
+					},
+				},
+			},
+		},
+		PostgresqlDatabaseKind: {
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					constants.LabelAppKey: constants.ApplicationName,
+				},
+				Name: postgresqlDatabaseCRDName,
+			},
+			Spec: extsv1beta1.CustomResourceDefinitionSpec{
+				Group: v1alpha1.SchemeGroupVersion.Group,
+				Names: extsv1beta1.CustomResourceDefinitionNames{
+					Plural: PostgresqlDatabasePlural,
+					Kind:   PostgresqlDatabaseKind,
+				},
+				Scope: extsv1beta1.ClusterScoped,
+				Subresources: &extsv1beta1.CustomResourceSubresources{
+					Status: &extsv1beta1.CustomResourceSubresourceStatus{},
+				},
+				Versions: []extsv1beta1.CustomResourceDefinitionVersion{
+					{
+						Name:    v1alpha1.SchemeGroupVersion.Version,
+						Served:  true,
+						Storage: true,
+					},
+				},
+				AdditionalPrinterColumns: []extsv1beta1.CustomResourceColumnDefinition{
+					{
+						Name:        "Instance",
+						Type:        "string",
+						Description: "The name of the PostgresqlInstance resource representing the Cloud SQL for PostgreSQL instance.",
+						JSONPath:    ".spec.instance",
+					},
+					{
+						Name:        "Database name",
+						Type:        "string",
+						Description: "The name of the database.",
+						JSONPath:    ".spec.name",
+					},
+					{
+						Name:        "Age",
+						Type:        "date",
+						Description: "Time elapsed since the resource was created.",
+						JSONPath:    ".metadata.creationTimestamp",
+					},
+				},
+			},
+		},
+		PostgresqlExportKind: {
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					constants.LabelAppKey: constants.ApplicationName,
+				},
+				Name: postgresqlExportCRDName,
+			},
+			Spec: extsv1beta1.CustomResourceDefinitionSpec{
+				Group: v1alpha1.SchemeGroupVersion.Group,
+				Names: extsv1beta1.CustomResourceDefinitionNames{
+					Plural: PostgresqlExportPlural,
+					Kind:   PostgresqlExportKind,
+				},
+				Scope: extsv1beta1.ClusterScoped,
+				Subresources: &extsv1beta1.CustomResourceSubresources{
+					Status: &extsv1beta1.CustomResourceSubresourceStatus{},
+				},
+				Versions: []extsv1beta1.CustomResourceDefinitionVersion{
+					{
+						Name:    v1alpha1.SchemeGroupVersion.Version,
+						Served:  true,
+						Storage: true,
+					},
+				},
+				AdditionalPrinterColumns: []extsv1beta1.CustomResourceColumnDefinition{
+					{
+						Name:        "Instance",
+						Type:        "string",
+						Description: "The name of the PostgresqlInstance resource representing the Cloud SQL for PostgreSQL instance.",
+						JSONPath:    ".spec.instance",
+					},
+					{
+						Name:        "Schedule",
+						Type:        "string",
+						Description: "The schedule of the export runs.",
+						JSONPath:    ".spec.schedule",
+					},
+					{
+						Name:        "Last Export",
+						Type:        "string",
+						Description: "The last export file successfully written.",
+						JSONPath:    ".status.lastExportFile",
+					},
+					{
+						Name:        "Age",
+						Type:        "date",
+						Description: "Time elapsed since the resource was created.",
+						JSONPath:    ".metadata.creationTimestamp",
+					},
+				},
+			},
+		},
+		PostgresqlImportKind: {
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					constants.LabelAppKey: constants.ApplicationName,
+				},
+				Name: postgresqlImportCRDName,
+			},
+			Spec: extsv1beta1.CustomResourceDefinitionSpec{
+				Group: v1alpha1.SchemeGroupVersion.Group,
+				Names: extsv1beta1.CustomResourceDefinitionNames{
+					Plural: PostgresqlImportPlural,
+					Kind:   PostgresqlImportKind,
+				},
+				Scope: extsv1beta1.ClusterScoped,
+				Subresources: &extsv1beta1.CustomResourceSubresources{
+					Status: &extsv1beta1.CustomResourceSubresourceStatus{},
+				},
+				Versions: []extsv1beta1.CustomResourceDefinitionVersion{
+					{
+						Name:    v1alpha1.SchemeGroupVersion.Version,
+						Served:  true,
+						Storage: true,
+					},
+				},
+				AdditionalPrinterColumns: []extsv1beta1.CustomResourceColumnDefinition{
+					{
+						Name:        "Instance",
+						Type:        "string",
+						Description: "The name of the PostgresqlInstance resource representing the Cloud SQL for PostgreSQL instance.",
+						JSONPath:    ".spec.instance",
+					},
+					{
+						Name:        "Database",
+						Type:        "string",
+						Description: "The name of the database into which data is imported.",
+						JSONPath:    ".spec.database",
+					},
+					{
+						Name:        "Source",
+						Type:        "string",
+						Description: "The Google Cloud Storage URI of the file to import.",
+						JSONPath:    ".spec.source",
+					},
+					{
+						Name:        "Age",
+						Type:        "date",
+						Description: "Time elapsed since the resource was created.",
+						JSONPath:    ".metadata.creationTimestamp",
+					},
+				},
+			},
+		},
 		PostgresqlInstanceKind: {
 			ObjectMeta: metav1.ObjectMeta{
 				Labels: map[string]string{
@@ -87,5 +389,155 @@ var (
 				},
 			},
 		},
+		PostgresqlReplicaKind: {
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					constants.LabelAppKey: constants.ApplicationName,
+				},
+				Name: postgresqlReplicaCRDName,
+			},
+			Spec: extsv1beta1.CustomResourceDefinitionSpec{
+				Group: v1alpha1.SchemeGroupVersion.Group,
+				Names: extsv1beta1.CustomResourceDefinitionNames{
+					Plural: PostgresqlReplicaPlural,
+					Kind:   PostgresqlReplicaKind,
+				},
+				Scope: extsv1beta1.ClusterScoped,
+				Subresources: &extsv1beta1.CustomResourceSubresources{
+					Status: &extsv1beta1.CustomResourceSubresourceStatus{},
+				},
+				Versions: []extsv1beta1.CustomResourceDefinitionVersion{
+					{
+						Name:    v1alpha1.SchemeGroupVersion.Version,
+						Served:  true,
+						Storage: true,
+					},
+				},
+				AdditionalPrinterColumns: []extsv1beta1.CustomResourceColumnDefinition{
+					{
+						Name:        "Primary",
+						Type:        "string",
+						Description: "The name of the PostgresqlInstance resource representing the primary Cloud SQL for PostgreSQL instance.",
+						JSONPath:    ".spec.primary",
+					},
+					{
+						Name:        "Replica name",
+						Type:        "string",
+						Description: "The name of the Cloud SQL for PostgreSQL read replica.",
+						JSONPath:    ".spec.name",
+					},
+					{
+						Name:        "Replication",
+						Type:        "string",
+						Description: "The state of replication from the primary Cloud SQL for PostgreSQL instance.",
+						JSONPath:    ".status.replicationState",
+					},
+					{
+						Name:        "Age",
+						Type:        "date",
+						Description: "Time elapsed since the resource was created.",
+						JSONPath:    ".metadata.creationTimestamp",
+					},
+				},
+			},
+		},
+		PostgresqlRestoreKind: {
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					constants.LabelAppKey: constants.ApplicationName,
+				},
+				Name: postgresqlRestoreCRDName,
+			},
+			Spec: extsv1beta1.CustomResourceDefinitionSpec{
+				Group: v1alpha1.SchemeGroupVersion.Group,
+				Names: extsv1beta1.CustomResourceDefinitionNames{
+					Plural: PostgresqlRestorePlural,
+					Kind:   PostgresqlRestoreKind,
+				},
+				Scope: extsv1beta1.ClusterScoped,
+				Subresources: &extsv1beta1.CustomResourceSubresources{
+					Status: &extsv1beta1.CustomResourceSubresourceStatus{},
+				},
+				Versions: []extsv1beta1.CustomResourceDefinitionVersion{
+					{
+						Name:    v1alpha1.SchemeGroupVersion.Version,
+						Served:  true,
+						Storage: true,
+					},
+				},
+				AdditionalPrinterColumns: []extsv1beta1.CustomResourceColumnDefinition{
+					{
+						Name:        "Source",
+						Type:        "string",
+						Description: "The name of the PostgresqlInstance resource representing the Cloud SQL for PostgreSQL instance to which the backup run belongs.",
+						JSONPath:    ".spec.source",
+					},
+					{
+						Name:        "Target",
+						Type:        "string",
+						Description: "The name of the PostgresqlInstance resource representing the Cloud SQL for PostgreSQL instance into which the backup run is restored.",
+						JSONPath:    ".spec.target",
+					},
+					{
+						Name:        "Age",
+						Type:        "date",
+						Description: "Time elapsed since the resource was created.",
+						JSONPath:    ".metadata.creationTimestamp",
+					},
+				},
+			},
+		},
+		PostgresqlUserKind: {
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					constants.LabelAppKey: constants.ApplicationName,
+				},
+				Name: postgresqlUserCRDName,
+			},
+			Spec: extsv1beta1.CustomResourceDefinitionSpec{
+				Group: v1alpha1.SchemeGroupVersion.Group,
+				Names: extsv1beta1.CustomResourceDefinitionNames{
+					Plural: PostgresqlUserPlural,
+					Kind:   PostgresqlUserKind,
+				},
+				Scope: extsv1beta1.NamespaceScoped,
+				Subresources: &extsv1beta1.CustomResourceSubresources{
+					Status: &extsv1beta1.CustomResourceSubresourceStatus{},
+				},
+				Versions: []extsv1beta1.CustomResourceDefinitionVersion{
+					{
+						Name:    v1alpha1.SchemeGroupVersion.Version,
+						Served:  true,
+						Storage: true,
+					},
+				},
+				AdditionalPrinterColumns: []extsv1beta1.CustomResourceColumnDefinition{
+					{
+						Name:        "Instance",
+						Type:        "string",
+						Description: "The name of the PostgresqlInstance resource representing the Cloud SQL for PostgreSQL instance.",
+						JSONPath:    ".spec.instance",
+					},
+					{
+						Name:        "User name",
+						Type:        "string",
+						Description: "The name of the user.",
+						JSONPath:    ".spec.name",
+					},
+					{
+						Name:        "Secret",
+						Type:        "string",
+						Description: "The name of the secret containing the credentials of the user.",
+						JSONPath:    ".spec.secretName",
+					},
+					{
+						Name:        "Age",
+						Type:        "date",
+						Description: "Time elapsed since the resource was created.",
+						JSONPath:    ".metadata.creationTimestamp",
+					},
+				},
+			},
+		},
 	}
 )
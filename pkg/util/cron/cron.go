@@ -0,0 +1,209 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxLookahead is the maximum amount of time into the future that is searched for the next activation of a schedule.
+	maxLookahead = 5 * 365 * 24 * time.Hour
+)
+
+// field describes the range of values accepted by one of the fields of a cron expression.
+type field struct {
+	// name is the name of the field, used in error messages.
+	name string
+	// min is the minimum value accepted by the field.
+	min int
+	// max is the maximum value accepted by the field.
+	max int
+}
+
+var (
+	// fields is the list of fields of a cron expression, in the order in which they must be specified.
+	fields = []field{
+		{name: "minute", min: 0, max: 59},
+		{name: "hour", min: 0, max: 23},
+		{name: "day of month", min: 1, max: 31},
+		{name: "month", min: 1, max: 12},
+		{name: "day of week", min: 0, max: 7},
+	}
+)
+
+// Schedule represents a parsed cron expression.
+type Schedule struct {
+	// location is the time zone in which the cron expression is interpreted.
+	location *time.Location
+	// minute is the set of minutes at which the schedule activates.
+	minute uint64
+	// hour is the set of hours at which the schedule activates.
+	hour uint64
+	// dayOfMonth is the set of days of the month at which the schedule activates.
+	dayOfMonth uint64
+	// month is the set of months at which the schedule activates.
+	month uint64
+	// dayOfWeek is the set of days of the week at which the schedule activates.
+	dayOfWeek uint64
+	// dayOfMonthRestricted indicates whether the day of month field starts with something other than "*".
+	dayOfMonthRestricted bool
+	// dayOfWeekRestricted indicates whether the day of week field starts with something other than "*".
+	dayOfWeekRestricted bool
+}
+
+// Parse parses the specified cron expression, which is interpreted in UTC.
+// The expression must consist of five space-separated fields (minute, hour, day of month, month and day of week), each of which may be "*", a single value, a range ("a-b"), a step ("*/n" or "a-b/n"), or a comma-separated list of these.
+// Both 0 and 7 may be used to denote Sunday in the day of week field.
+func Parse(expr string) (*Schedule, error) {
+	return ParseInLocation(expr, time.UTC)
+}
+
+// ParseInLocation parses the specified cron expression, which is interpreted in the specified time zone.
+func ParseInLocation(expr string, loc *time.Location) (*Schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(fields), len(parts))
+	}
+	values := make([]uint64, len(fields))
+	for idx, f := range fields {
+		v, err := parseField(parts[idx], f)
+		if err != nil {
+			return nil, err
+		}
+		values[idx] = v
+	}
+	// Fold Sunday specified as 7 into Sunday specified as 0.
+	if has(values[4], 7) {
+		values[4] = values[4]&^(1<<7) | 1
+	}
+	return &Schedule{
+		location:             loc,
+		minute:               values[0],
+		hour:                 values[1],
+		dayOfMonth:           values[2],
+		month:                values[3],
+		dayOfWeek:            values[4],
+		dayOfMonthRestricted: !strings.HasPrefix(parts[2], "*"),
+		dayOfWeekRestricted:  !strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// parseField parses the value of a single field of a cron expression into a bit set.
+func parseField(v string, f field) (uint64, error) {
+	var r uint64
+	for _, item := range strings.Split(v, ",") {
+		// Split the step from the range, if present.
+		step := 1
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			s, err := strconv.Atoi(item[idx+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field %q", f.name, v)
+			}
+			step = s
+			item = item[:idx]
+		}
+		// Parse the range.
+		var (
+			lo, hi int
+			err    error
+		)
+		switch idx := strings.Index(item, "-"); {
+		case item == "*":
+			lo, hi = f.min, f.max
+		case idx >= 0:
+			if lo, err = strconv.Atoi(item[:idx]); err != nil {
+				return 0, fmt.Errorf("invalid range in %s field %q", f.name, v)
+			}
+			if hi, err = strconv.Atoi(item[idx+1:]); err != nil {
+				return 0, fmt.Errorf("invalid range in %s field %q", f.name, v)
+			}
+		default:
+			if lo, err = strconv.Atoi(item); err != nil {
+				return 0, fmt.Errorf("invalid value in %s field %q", f.name, v)
+			}
+			hi = lo
+		}
+		if lo < f.min || hi > f.max || lo > hi {
+			return 0, fmt.Errorf("%s field %q must be within %d-%d", f.name, v, f.min, f.max)
+		}
+		for i := lo; i <= hi; i += step {
+			r |= 1 << uint(i)
+		}
+	}
+	return r, nil
+}
+
+// Next returns the first time after the specified one at which the schedule activates, or the zero time if no such time exists.
+// Wall clock times that do not exist because the clocks are moved forward (e.g. when daylight saving time starts) are skipped, and wall clock times that happen twice because the clocks are turned back only activate the schedule once.
+func (s *Schedule) Next(t time.Time) time.Time {
+	// Start at the beginning of the minute following the specified time.
+	t = t.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxLookahead)
+	for t.Before(limit) {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			// Move to the beginning of the next hour by adding minutes rather than using "time.Date", as the latter is ambiguous when the clocks are turned back.
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+			continue
+		}
+		if !has(s.minute, t.Minute()) || isRepeatedWallClock(t) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay indicates whether the schedule activates on the day of the specified time.
+// Following the usual cron semantics, if both the day of month and the day of week fields are restricted (i.e. neither of them starts with "*"), the schedule activates when either of them matches.
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dom := has(s.dayOfMonth, t.Day())
+	dow := has(s.dayOfWeek, int(t.Weekday()))
+	if s.dayOfMonthRestricted && s.dayOfWeekRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// isRepeatedWallClock indicates whether the wall clock time of the specified time has already been seen earlier because the clocks were turned back (e.g. when daylight saving time ends).
+// This prevents the schedule from activating twice at the same wall clock time.
+func isRepeatedWallClock(t time.Time) bool {
+	_, offset := t.Zone()
+	_, prevOffset := t.Add(-2 * time.Hour).Zone()
+	if prevOffset <= offset {
+		return false
+	}
+	p := t.Add(-time.Duration(prevOffset-offset) * time.Second)
+	return p.Day() == t.Day() && p.Hour() == t.Hour() && p.Minute() == t.Minute()
+}
+
+// has indicates whether the specified value is present in the specified bit set.
+func has(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
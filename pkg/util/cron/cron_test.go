@@ -0,0 +1,237 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		description string
+		expr        string
+		expectError bool
+	}{
+		{description: "every minute", expr: "* * * * *"},
+		{description: "single values", expr: "30 2 15 6 3"},
+		{description: "ranges, steps and lists", expr: "0-30/10 */2 1,15 1-6 1-5"},
+		{description: "sunday as 0", expr: "0 0 * * 0"},
+		{description: "sunday as 7", expr: "0 0 * * 7"},
+		{description: "range ending on sunday as 7", expr: "0 0 * * 5-7"},
+		{description: "too few fields", expr: "* * * *", expectError: true},
+		{description: "too many fields", expr: "* * * * * *", expectError: true},
+		{description: "minute out of range", expr: "60 * * * *", expectError: true},
+		{description: "hour out of range", expr: "* 24 * * *", expectError: true},
+		{description: "day of month out of range", expr: "* * 0 * *", expectError: true},
+		{description: "month out of range", expr: "* * * 13 *", expectError: true},
+		{description: "day of week out of range", expr: "* * * * 8", expectError: true},
+		{description: "inverted range", expr: "30-10 * * * *", expectError: true},
+		{description: "zero step", expr: "*/0 * * * *", expectError: true},
+		{description: "invalid step", expr: "*/a * * * *", expectError: true},
+		{description: "invalid value", expr: "a * * * *", expectError: true},
+		{description: "invalid range", expr: "1-a * * * *", expectError: true},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.expr)
+		if test.expectError && err == nil {
+			t.Errorf("%s: expected an error parsing %q", test.description, test.expr)
+		}
+		if !test.expectError && err != nil {
+			t.Errorf("%s: unexpected error parsing %q: %v", test.description, test.expr, err)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("failed to load time zone: %v", err)
+	}
+	tests := []struct {
+		description string
+		expr        string
+		location    *time.Location
+		from        time.Time
+		expected    time.Time
+	}{
+		{
+			description: "next minute",
+			expr:        "* * * * *",
+			location:    time.UTC,
+			from:        time.Date(2019, 6, 1, 10, 15, 30, 0, time.UTC),
+			expected:    time.Date(2019, 6, 1, 10, 16, 0, 0, time.UTC),
+		},
+		{
+			description: "activation time is excluded",
+			expr:        "15 10 * * *",
+			location:    time.UTC,
+			from:        time.Date(2019, 6, 1, 10, 15, 0, 0, time.UTC),
+			expected:    time.Date(2019, 6, 2, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			description: "step within hour",
+			expr:        "*/20 * * * *",
+			location:    time.UTC,
+			from:        time.Date(2019, 6, 1, 10, 41, 0, 0, time.UTC),
+			expected:    time.Date(2019, 6, 1, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "day rollover",
+			expr:        "0 8 * * *",
+			location:    time.UTC,
+			from:        time.Date(2019, 6, 1, 20, 0, 0, 0, time.UTC),
+			expected:    time.Date(2019, 6, 2, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "month rollover",
+			expr:        "0 0 1 * *",
+			location:    time.UTC,
+			from:        time.Date(2019, 1, 31, 12, 0, 0, 0, time.UTC),
+			expected:    time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "year rollover",
+			expr:        "0 0 1 1 *",
+			location:    time.UTC,
+			from:        time.Date(2019, 12, 31, 23, 59, 0, 0, time.UTC),
+			expected:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "day of month missing from some months",
+			expr:        "0 0 31 * *",
+			location:    time.UTC,
+			from:        time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC),
+			expected:    time.Date(2019, 5, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "leap day",
+			expr:        "0 0 29 2 *",
+			location:    time.UTC,
+			from:        time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC),
+			expected:    time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "day of week",
+			expr:        "0 9 * * 1-5",
+			location:    time.UTC,
+			// 2019-06-01 is a Saturday.
+			from:     time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC),
+			expected: time.Date(2019, 6, 3, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "sunday as 7",
+			expr:        "0 9 * * 7",
+			location:    time.UTC,
+			from:        time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC),
+			expected:    time.Date(2019, 6, 2, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "union of day of month and day of week when both are restricted",
+			expr:        "0 0 13 * 5",
+			location:    time.UTC,
+			// 2019-06-07 is a Friday, and comes before the 13th.
+			from:     time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2019, 6, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "union of day of month and day of week when both are restricted (day of month first)",
+			expr:        "0 0 13 * 5",
+			location:    time.UTC,
+			// 2019-06-13 is a Thursday.
+			from:     time.Date(2019, 6, 8, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2019, 6, 13, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "day of week starting with a wildcard does not restrict the day of month",
+			expr:        "0 0 13 * */1",
+			location:    time.UTC,
+			from:        time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC),
+			expected:    time.Date(2019, 6, 13, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "day of month starting with a wildcard does not restrict the day of week",
+			expr:        "0 0 */1 * 5",
+			location:    time.UTC,
+			// 2019-06-07 is the first Friday after 2019-06-01.
+			from:     time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2019, 6, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "time zone",
+			expr:        "0 8 * * *",
+			location:    berlin,
+			from:        time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC),
+			expected:    time.Date(2019, 6, 1, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "non-existent wall clock time when daylight saving time starts is skipped",
+			expr:        "30 2 * * *",
+			location:    berlin,
+			// Clocks in Berlin are moved from 02:00 to 03:00 on 2019-03-31.
+			from:     time.Date(2019, 3, 30, 12, 0, 0, 0, berlin),
+			expected: time.Date(2019, 4, 1, 2, 30, 0, 0, berlin),
+		},
+		{
+			description: "hourly schedule when daylight saving time starts",
+			expr:        "0 * * * *",
+			location:    berlin,
+			from:        time.Date(2019, 3, 31, 1, 30, 0, 0, berlin),
+			expected:    time.Date(2019, 3, 31, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "repeated wall clock time when daylight saving time ends activates once (first occurrence)",
+			expr:        "30 2 * * *",
+			location:    berlin,
+			// Clocks in Berlin are turned back from 03:00 to 02:00 on 2019-10-27.
+			from:     time.Date(2019, 10, 27, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2019, 10, 27, 0, 30, 0, 0, time.UTC),
+		},
+		{
+			description: "repeated wall clock time when daylight saving time ends activates once (second occurrence)",
+			expr:        "30 2 * * *",
+			location:    berlin,
+			from:        time.Date(2019, 10, 27, 0, 30, 0, 0, time.UTC),
+			expected:    time.Date(2019, 10, 28, 1, 30, 0, 0, time.UTC),
+		},
+		{
+			description: "hourly schedule when daylight saving time ends",
+			expr:        "0 * * * *",
+			location:    berlin,
+			// 02:00 CEST is 00:00 UTC, and 02:00 CET (which is skipped) is 01:00 UTC.
+			from:     time.Date(2019, 10, 27, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2019, 10, 27, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "no activation",
+			expr:        "0 0 30 2 *",
+			location:    time.UTC,
+			from:        time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+			expected:    time.Time{},
+		},
+	}
+	for _, test := range tests {
+		s, err := ParseInLocation(test.expr, test.location)
+		if err != nil {
+			t.Errorf("%s: unexpected error parsing %q: %v", test.description, test.expr, err)
+			continue
+		}
+		if next := s.Next(test.from); !next.Equal(test.expected) {
+			t.Errorf("%s: expected the next activation of %q after %s to be %s, got %s", test.description, test.expr, test.from, test.expected, next)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package google
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	sqladmin "google.golang.org/api/sqladmin/v1beta4"
+)
+
+const (
+	// databaseFlagsCatalogTTL is the amount of time during which the catalog of database flags is cached before being read again from the Cloud SQL Admin API.
+	databaseFlagsCatalogTTL = 1 * time.Hour
+)
+
+// DatabaseFlagsCatalog caches the catalog of database flags supported by each version of Cloud SQL so that inspecting database flags does not require calling the Cloud SQL Admin API every time.
+type DatabaseFlagsCatalog struct {
+	// cloudsqlClient is a client to the Cloud SQL Admin API.
+	cloudsqlClient *sqladmin.Service
+	// entries holds the catalog of database flags supported by each database version, keyed by database version (e.g. "POSTGRES_9_6").
+	entries map[string]*databaseFlagsCatalogEntry
+	// mutex protects access to the fields above.
+	mutex sync.Mutex
+}
+
+// databaseFlagsCatalogEntry holds the catalog of database flags supported by a single database version.
+type databaseFlagsCatalogEntry struct {
+	// flags is the catalog of database flags, keyed by flag name.
+	flags map[string]*sqladmin.Flag
+	// lastRefreshTime is the time at which the catalog of database flags was last read from the Cloud SQL Admin API.
+	lastRefreshTime time.Time
+}
+
+// NewDatabaseFlagsCatalog creates a new, empty, catalog of database flags.
+func NewDatabaseFlagsCatalog(cloudsqlClient *sqladmin.Service) *DatabaseFlagsCatalog {
+	return &DatabaseFlagsCatalog{
+		cloudsqlClient: cloudsqlClient,
+		entries:        make(map[string]*databaseFlagsCatalogEntry),
+	}
+}
+
+// Get returns the catalog of database flags supported by the specified database version (e.g. "POSTGRES_9_6"), reading it from the Cloud SQL Admin API if it has not been read yet or if it has expired.
+// In case the catalog cannot be read but a previous (expired) copy is available, the previous copy is returned.
+func (c *DatabaseFlagsCatalog) Get(databaseVersion string) (map[string]*sqladmin.Flag, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Return the cached catalog if it is still fresh.
+	entry, exists := c.entries[databaseVersion]
+	if exists && time.Since(entry.lastRefreshTime) < databaseFlagsCatalogTTL {
+		return entry.flags, nil
+	}
+	// Read the catalog for the specified database version from the Cloud SQL Admin API.
+	res, err := c.cloudsqlClient.Flags.List().DatabaseVersion(databaseVersion).Do()
+	if err != nil {
+		if exists {
+			log.Warnf("failed to refresh the catalog of database flags for %s, using the cached one: %v", databaseVersion, err)
+			return entry.flags, nil
+		}
+		return nil, fmt.Errorf("failed to read the catalog of database flags for %s: %v", databaseVersion, err)
+	}
+	flags := make(map[string]*sqladmin.Flag, len(res.Items))
+	for _, flag := range res.Items {
+		// Skip flags which do not apply to the specified database version, in case the Cloud SQL Admin API returns them anyway.
+		if len(flag.AppliesTo) > 0 && !appliesTo(flag, databaseVersion) {
+			continue
+		}
+		flags[flag.Name] = flag
+	}
+	c.entries[databaseVersion] = &databaseFlagsCatalogEntry{
+		flags:           flags,
+		lastRefreshTime: time.Now(),
+	}
+	return flags, nil
+}
+
+// appliesTo indicates whether the specified database flag applies to the specified database version.
+func appliesTo(flag *sqladmin.Flag, databaseVersion string) bool {
+	for _, v := range flag.AppliesTo {
+		if v == databaseVersion {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package google
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sqladmin "google.golang.org/api/sqladmin/v1beta4"
+)
+
+func TestDatabaseFlagsCatalogGet(t *testing.T) {
+	// Serve a catalog of database flags in which "jit" only applies to "POSTGRES_11", and record the database versions for which the catalog is requested.
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := r.URL.Query().Get("databaseVersion")
+		requested = append(requested, v)
+		res := &sqladmin.FlagsListResponse{
+			Items: []*sqladmin.Flag{
+				{Name: "max_connections", AppliesTo: []string{"POSTGRES_9_6", "POSTGRES_11"}, RequiresRestart: true},
+				{Name: "jit", AppliesTo: []string{"POSTGRES_11"}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	defer srv.Close()
+	cloudsqlClient, err := sqladmin.New(srv.Client())
+	if err != nil {
+		t.Fatalf("failed to create the cloud sql admin api client: %v", err)
+	}
+	cloudsqlClient.BasePath = srv.URL + "/"
+	c := NewDatabaseFlagsCatalog(cloudsqlClient)
+
+	// Flags which do not apply to the requested database version must not be part of its catalog.
+	flags, err := c.Get("POSTGRES_9_6")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, exists := flags["max_connections"]; !exists {
+		t.Errorf("expected %q to be supported by %s", "max_connections", "POSTGRES_9_6")
+	}
+	if _, exists := flags["jit"]; exists {
+		t.Errorf("expected %q not to be supported by %s", "jit", "POSTGRES_9_6")
+	}
+	flags, err = c.Get("POSTGRES_11")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, exists := flags["jit"]; !exists {
+		t.Errorf("expected %q to be supported by %s", "jit", "POSTGRES_11")
+	}
+
+	// The catalog of each database version must be read once and then cached.
+	if _, err := c.Get("POSTGRES_9_6"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(requested) != 2 || requested[0] != "POSTGRES_9_6" || requested[1] != "POSTGRES_11" {
+		t.Errorf("expected the catalog to be requested once for each database version, got %q", requested)
+	}
+}
@@ -21,16 +21,21 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sort"
+	"strings"
 
 	goauth "golang.org/x/oauth2/google"
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 	sqladmin "google.golang.org/api/sqladmin/v1beta4"
+	storage "google.golang.org/api/storage/v1"
 )
 
 const (
 	// adminScope is the Google Cloud Platform scope required for making calls to the Cloud SQL Admin API.
 	adminScope = "https://www.googleapis.com/auth/sqlservice.admin"
+	// storageScope is the Google Cloud Platform scope required for managing objects in Google Cloud Storage.
+	storageScope = "https://www.googleapis.com/auth/devstorage.read_write"
 )
 
 // IsBadRequest indicates whether the specified error is the result of the Cloud SQL Admin API replying with http.StatusBadRequest.
@@ -62,15 +67,37 @@ func IsNotFound(err error) bool {
 
 // NewCloudSQLAdminClient creates a client to the Cloud SQL Admin API that uses the specified IAM service account credentials file for authentication.
 func NewCloudSQLAdminClient(keyPath string) (*sqladmin.Service, error) {
-	c, err := newHTTPClient(keyPath)
+	c, err := newHTTPClient(keyPath, adminScope)
 	if err != nil {
 		return nil, err
 	}
 	return sqladmin.NewService(context.Background(), option.WithHTTPClient(c))
 }
 
-// newHTTPClient returns an HTTP client that uses the specified IAM service account credentials file for authentication.
-func newHTTPClient(keyPath string) (*http.Client, error) {
+// NewStorageClient creates a client to the Google Cloud Storage API that uses the specified IAM service account credentials file for authentication.
+func NewStorageClient(keyPath string) (*storage.Service, error) {
+	c, err := newHTTPClient(keyPath, storageScope)
+	if err != nil {
+		return nil, err
+	}
+	return storage.NewService(context.Background(), option.WithHTTPClient(c))
+}
+
+// ServerCABundle builds a bundle containing the specified server CA certificates in PEM format, in a stable order.
+// The bundle is meant to be used by clients in order to verify the server certificate of a CSQLP instance both before and after its server CA certificate is rotated.
+func ServerCABundle(certs []*sqladmin.SslCert) string {
+	p := make([]string, 0, len(certs))
+	for _, cert := range certs {
+		if cert != nil && cert.Cert != "" {
+			p = append(p, strings.TrimSpace(cert.Cert)+"\n")
+		}
+	}
+	sort.Strings(p)
+	return strings.Join(p, "")
+}
+
+// newHTTPClient returns an HTTP client that uses the specified IAM service account credentials file for authentication with the specified scope.
+func newHTTPClient(keyPath, scope string) (*http.Client, error) {
 	if keyPath == "" {
 		return nil, fmt.Errorf("the path to the \"admin\" iam service account key must be specified")
 	}
@@ -78,7 +105,7 @@ func newHTTPClient(keyPath string) (*http.Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	c, err := goauth.JWTConfigFromJSON(b, adminScope)
+	c, err := goauth.JWTConfigFromJSON(b, scope)
 	if err != nil {
 		return nil, err
 	}
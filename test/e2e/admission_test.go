@@ -21,6 +21,7 @@ package e2e_test
 import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/travelaudience/cloudsql-postgres-operator/pkg/admission"
@@ -58,15 +59,19 @@ var _ = Describe("PostgresqlInstance", func() {
 		Expect(obj.Annotations).To(HaveKeyWithValue(constants.AllowDeletionAnnotationKey, v1alpha1.False))
 
 		// Make sure that all fields have the expected values.
+		Expect(*obj.Spec.ActivationPolicy).To(Equal(admission.PostgresqlInstanceSpecActivationPolicyDefault))
 		Expect(*obj.Spec.Availability.Type).To(Equal(admission.PostgresqlInstanceSpecAvailabilityTypeDefault))
 		Expect(*obj.Spec.Backups.Daily.Enabled).To(Equal(admission.PostgresqlInstanceSpecBackupsDailyEnabledDefault))
 		Expect(*obj.Spec.Backups.Daily.StartTime).To(Equal(admission.PostgresqlInstanceSpecBackupsDailyStartTimeDefault))
+		Expect(*obj.Spec.DeletionPolicy).To(Equal(admission.PostgresqlInstanceSpecDeletionPolicyDefault))
 		Expect(obj.Spec.Flags).To(HaveLen(0))
 		Expect(obj.Spec.Labels).To(HaveKeyWithValue(admission.PostgresqlInstanceSpecLabelsOwnerName, constants.ApplicationName))
 		Expect(*obj.Spec.Location.Region).To(Equal(admission.PostgresqlInstanceSpecLocationRegionDefault))
 		Expect(*obj.Spec.Location.Zone).To(Equal(admission.PostgresqlInstanceSpecLocationZoneDefault))
 		Expect(*obj.Spec.Maintenance.Day).To(Equal(admission.PostgresqlInstanceSpecMaintenanceDayDefault))
+		Expect(*obj.Spec.Maintenance.DisruptiveUpdatePolicy).To(Equal(admission.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyDefault))
 		Expect(*obj.Spec.Maintenance.Hour).To(Equal(admission.PostgresqlInstanceSpecMaintenanceHourDefault(*obj.Spec.Maintenance.Day)))
+		Expect(*obj.Spec.Maintenance.UpdateTrack).To(Equal(admission.PostgresqlInstanceSpecMaintenanceUpdateTrackDefault))
 		Expect(*obj.Spec.Networking.PrivateIP.Enabled).To(Equal(admission.PostgresqlInstanceSpecNetworkingPrivateIPEnabledDefault))
 		Expect(*obj.Spec.Networking.PrivateIP.Network).To(Equal(admission.PostgresqlInstanceSpecNetworkingPrivateIPNetworkDefault))
 		Expect(*obj.Spec.Networking.PublicIP.Enabled).To(BeTrue()) // NOTE: We have explicitly set ".spec.networking.publicIp.enabled" to "true" above.
@@ -82,6 +87,38 @@ var _ = Describe("PostgresqlInstance", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	framework.AdmissionIt("is given a unique name based on the name prefix upon creation", func() {
+		var (
+			err error
+			obj *v1alpha1.PostgresqlInstance
+		)
+
+		// Create a minimal PostgresqlInstance resource specifying ".spec.namePrefix" instead of ".spec.name".
+		obj, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Create(&v1alpha1.PostgresqlInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlInstanceMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlInstanceSpec{
+				NamePrefix: "cloudsql-psql",
+				Networking: &v1alpha1.PostgresqlInstanceSpecNetworking{
+					PublicIP: &v1alpha1.PostgresqlInstanceSpecNetworkingPublicIP{
+						Enabled: pointers.NewBool(true),
+					},
+				},
+				Paused: true,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Make sure that ".spec.name" has been generated based on ".spec.namePrefix".
+		Expect(obj.Spec.Name).To(MatchRegexp(`^cloudsql-psql-[a-z0-9]{8}$`))
+		Expect(obj.Spec.NamePrefix).To(Equal("cloudsql-psql"))
+
+		// Delete the PostgresqlInstance resource.
+		err = f.DeletePostgresqlInstanceByName(obj.Name)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
 	framework.AdmissionIt("cannot be deleted unless \"cloudsql.travelaudience.com/allow-deletion\" is \"true\"", func() {
 		var (
 			err error
@@ -128,6 +165,37 @@ var _ = Describe("PostgresqlInstance", func() {
 			errorMessageRegex string
 			fn                func(*v1alpha1.PostgresqlInstance)
 		}{
+			{
+				errorMessageRegex: `the value of the "cloudsql\.travelaudience\.com/restart-requested-at" annotation must be a timestamp in rfc 3339 format \(got "now"\)`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					instance.Annotations = map[string]string{
+						constants.RestartRequestedAtAnnotationKey: "now",
+					}
+				},
+			},
+			{
+				errorMessageRegex: `a failover can only be requested for instances whose availability type is "Regional"`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					instance.Annotations = map[string]string{
+						constants.FailoverRequestedAtAnnotationKey: "2019-06-01T12:00:00Z",
+					}
+				},
+			},
+			{
+				errorMessageRegex: `the instance cannot be adopted unless the "cloudsql\.travelaudience\.com/confirm-adoption" annotation is set to "true"`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					instance.Spec.Adopt = true
+				},
+			},
+			{
+				errorMessageRegex: `the instance "[a-z0-9-]+" cannot be adopted as it does not exist`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					instance.Annotations = map[string]string{
+						constants.ConfirmAdoptionAnnotationKey: v1alpha1.True,
+					}
+					instance.Spec.Adopt = true
+				},
+			},
 			{
 				errorMessageRegex: `the availability type of the instance must be one of "Regional" or "Zonal" \(got "foo"\)`,
 				fn: func(instance *v1alpha1.PostgresqlInstance) {
@@ -148,6 +216,30 @@ var _ = Describe("PostgresqlInstance", func() {
 					}
 				},
 			},
+			{
+				errorMessageRegex: `the deletion policy of the instance must be one of "BackupThenDelete", "Delete" or "Retain" \(got "foo"\)`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					p := v1alpha1.PostgresqlInstanceSpecDeletionPolicy("foo")
+					instance.Spec.DeletionPolicy = &p
+				},
+			},
+			{
+				errorMessageRegex: `the destination of the final backup must be specified when the deletion policy of the instance is "BackupThenDelete"`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					p := v1alpha1.PostgresqlInstanceSpecDeletionPolicyBackupThenDelete
+					instance.Spec.DeletionPolicy = &p
+				},
+			},
+			{
+				errorMessageRegex: `the destination of the final backup must be a google cloud storage uri in the "gs://<bucket>/<path>" format \(got "my-bucket"\)`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					p := v1alpha1.PostgresqlInstanceSpecDeletionPolicyBackupThenDelete
+					instance.Spec.DeletionPolicy = &p
+					instance.Spec.FinalBackup = &v1alpha1.PostgresqlInstanceSpecFinalBackup{
+						Destination: "my-bucket",
+					}
+				},
+			},
 			{
 				errorMessageRegex: `flags must be specified in the "<name>=<value>" format \(got "foo-bar"\)`,
 				fn: func(instance *v1alpha1.PostgresqlInstance) {
@@ -156,6 +248,41 @@ var _ = Describe("PostgresqlInstance", func() {
 					}
 				},
 			},
+			{
+				errorMessageRegex: `the "autovacuum" flag cannot be specified more than once`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					instance.Spec.DatabaseFlags = v1alpha1.PostgresqlInstanceSpecDatabaseFlags{
+						"autovacuum": "off",
+					}
+					instance.Spec.Flags = []string{
+						"autovacuum=on",
+					}
+				},
+			},
+			{
+				errorMessageRegex: `the "foo_bar" flag is not supported by cloud sql`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					instance.Spec.DatabaseFlags = v1alpha1.PostgresqlInstanceSpecDatabaseFlags{
+						"foo_bar": "on",
+					}
+				},
+			},
+			{
+				errorMessageRegex: `the value of the "autovacuum" flag must be one of \["on" "off"\] \(got "maybe"\)`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					instance.Spec.DatabaseFlags = v1alpha1.PostgresqlInstanceSpecDatabaseFlags{
+						"autovacuum": "maybe",
+					}
+				},
+			},
+			{
+				errorMessageRegex: `the value of the "max_connections" flag must be an integer \(got "foo"\)`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					instance.Spec.DatabaseFlags = v1alpha1.PostgresqlInstanceSpecDatabaseFlags{
+						"max_connections": "foo",
+					}
+				},
+			},
 			{
 				errorMessageRegex: `the day of the week for periodic maintenance must be "Any" or a valid weekday \(got "foo"\)`,
 				fn: func(instance *v1alpha1.PostgresqlInstance) {
@@ -174,6 +301,42 @@ var _ = Describe("PostgresqlInstance", func() {
 					}
 				},
 			},
+			{
+				errorMessageRegex: `the update track for periodic maintenance must be either "Canary" or "Stable" \(got "foo"\)`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					t := v1alpha1.PostgresqlInstanceSpecMaintenanceUpdateTrack("foo")
+					instance.Spec.Maintenance = &v1alpha1.PostgresqlInstanceSpecMaintenance{
+						UpdateTrack: &t,
+					}
+				},
+			},
+			{
+				errorMessageRegex: `the update track for periodic maintenance must be either "Canary" or "Stable" \(got "canary"\)`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					t := v1alpha1.PostgresqlInstanceSpecMaintenanceUpdateTrack("canary")
+					instance.Spec.Maintenance = &v1alpha1.PostgresqlInstanceSpecMaintenance{
+						UpdateTrack: &t,
+					}
+				},
+			},
+			{
+				errorMessageRegex: `the policy for disruptive updates must be one of "Immediate", "MaintenanceWindow" or "OnApproval" \(got "foo"\)`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					p := v1alpha1.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicy("foo")
+					instance.Spec.Maintenance = &v1alpha1.PostgresqlInstanceSpecMaintenance{
+						DisruptiveUpdatePolicy: &p,
+					}
+				},
+			},
+			{
+				errorMessageRegex: `disruptive updates can only be applied in the maintenance window if the day of the week for periodic maintenance is not "Any"`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					p := v1alpha1.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyMaintenanceWindow
+					instance.Spec.Maintenance = &v1alpha1.PostgresqlInstanceSpecMaintenance{
+						DisruptiveUpdatePolicy: &p,
+					}
+				},
+			},
 			{
 				errorMessageRegex: `the name of the instance cannot be empty`,
 				fn: func(instance *v1alpha1.PostgresqlInstance) {
@@ -192,6 +355,19 @@ var _ = Describe("PostgresqlInstance", func() {
 					instance.Spec.Name = "very-long-name-very-long-name-very-long-name-very-long-name-very-long-name-very-long-name-very-long-name-very-long-name-very-long-name-very-long-name"
 				},
 			},
+			{
+				errorMessageRegex: `the name and the name prefix of the instance cannot be specified at the same time`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					instance.Spec.NamePrefix = "cloudsql-psql"
+				},
+			},
+			{
+				errorMessageRegex: `the name prefix of the instance must match the ".*" regular expression \(got "Cloud\$ql"\)`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					instance.Spec.Name = ""
+					instance.Spec.NamePrefix = "Cloud$ql"
+				},
+			},
 			{
 				errorMessageRegex: `at least one of private or public ip access to the instance must be enabled`,
 				fn: func(instance *v1alpha1.PostgresqlInstance) {
@@ -240,7 +416,26 @@ var _ = Describe("PostgresqlInstance", func() {
 				},
 			},
 			{
-				errorMessageRegex: `the version of the instance must be "9\.6" \(got "foo"\)`,
+				errorMessageRegex: `the start schedule of the instance must be a valid cron expression \(got "foo"\)`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					instance.Spec.Schedule = &v1alpha1.PostgresqlInstanceSpecSchedule{
+						Start: "foo",
+						Stop:  "0 18 * * 1-5",
+					}
+				},
+			},
+			{
+				errorMessageRegex: `the time zone of the schedule must be a valid iana time zone name \(got "Europe/Foo"\)`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					instance.Spec.Schedule = &v1alpha1.PostgresqlInstanceSpecSchedule{
+						Start:    "0 8 * * 1-5",
+						Stop:     "0 18 * * 1-5",
+						TimeZone: pointers.NewString("Europe/Foo"),
+					}
+				},
+			},
+			{
+				errorMessageRegex: `the version of the instance must be one of "9\.6", "10", "11", "12", "13", "14", "15", "16", "17" \(got "foo"\)`,
 				fn: func(instance *v1alpha1.PostgresqlInstance) {
 					v := v1alpha1.PostgresqlInstanceSpecVersion("foo")
 					instance.Spec.Version = &v
@@ -326,6 +521,12 @@ var _ = Describe("PostgresqlInstance", func() {
 					instance.Spec.Name = "new-name"
 				},
 			},
+			{
+				errorMessageRegex: `the name prefix of the instance cannot be changed \(had "", got "new-prefix"\)`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					instance.Spec.NamePrefix = "new-prefix"
+				},
+			},
 			{
 				errorMessageRegex: `private ip access to the instance cannot be disabled after having been enabled`,
 				fn: func(instance *v1alpha1.PostgresqlInstance) {
@@ -351,6 +552,28 @@ var _ = Describe("PostgresqlInstance", func() {
 					instance.Spec.Resources.Disk.Type = &newSpecResourcesDiskType
 				},
 			},
+			{
+				errorMessageRegex: `the version of the instance cannot be upgraded unless the "cloudsql.travelaudience.com/allow-major-version-upgrade" annotation is set to "true"`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					newSpecVersion := v1alpha1.PostgresqlInstanceSpecVersion10
+					instance.Spec.Version = &newSpecVersion
+				},
+			},
+			{
+				errorMessageRegex: `the source of the instance cannot be changed`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					instance.Spec.Source = &v1alpha1.PostgresqlInstanceSpecSource{
+						Instance: "foo",
+					}
+				},
+			},
+			{
+				errorMessageRegex: `the update track for periodic maintenance must be either "Canary" or "Stable" \(got "foo"\)`,
+				fn: func(instance *v1alpha1.PostgresqlInstance) {
+					t := v1alpha1.PostgresqlInstanceSpecMaintenanceUpdateTrack("foo")
+					instance.Spec.Maintenance.UpdateTrack = &t
+				},
+			},
 		}
 
 		// Create a clone of the original PostgresqlInstance resource so we can perform the required changes on a fresh, valid source.
@@ -363,8 +586,793 @@ var _ = Describe("PostgresqlInstance", func() {
 			Expect(err.Error()).To(MatchRegexp(test.errorMessageRegex))
 		}
 
+		// Make sure that the update track can be changed to another valid value.
+		updatedObj := obj.DeepCopy()
+		t := v1alpha1.PostgresqlInstanceSpecMaintenanceUpdateTrackCanary
+		updatedObj.Spec.Maintenance.UpdateTrack = &t
+		updatedObj, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Update(updatedObj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*updatedObj.Spec.Maintenance.UpdateTrack).To(Equal(v1alpha1.PostgresqlInstanceSpecMaintenanceUpdateTrackCanary))
+
 		// Delete the PostgresqlInstance resource.
 		err = f.DeletePostgresqlInstanceByName(obj.Name)
 		Expect(err).NotTo(HaveOccurred())
 	})
 })
+
+var _ = Describe("PostgresqlDatabase", func() {
+	framework.AdmissionIt("is mutated with default values upon creation and cannot be updated", func() {
+		var (
+			err      error
+			instance *v1alpha1.PostgresqlInstance
+			obj      *v1alpha1.PostgresqlDatabase
+		)
+
+		// Make sure that a PostgresqlDatabase resource referencing a non-existing PostgresqlInstance resource cannot be created.
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlDatabases().Create(&v1alpha1.PostgresqlDatabase{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlDatabaseMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlDatabaseSpec{
+				Instance: "non-existing",
+				Name:     "foo",
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`postgresqlinstance "non-existing" does not exist`))
+
+		// Create a minimal PostgresqlInstance resource.
+		instance, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Create(&v1alpha1.PostgresqlInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlInstanceMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlInstanceSpec{
+				Name: f.NewRandomPostgresqlInstanceSpecName(),
+				Networking: &v1alpha1.PostgresqlInstanceSpecNetworking{
+					PublicIP: &v1alpha1.PostgresqlInstanceSpecNetworkingPublicIP{
+						Enabled: pointers.NewBool(true),
+					},
+				},
+				Paused: true,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Create a minimal PostgresqlDatabase resource.
+		obj, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlDatabases().Create(&v1alpha1.PostgresqlDatabase{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlDatabaseMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlDatabaseSpec{
+				Instance: instance.Name,
+				Name:     "foo",
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Make sure that all fields have the expected values.
+		Expect(obj.Annotations).To(HaveKeyWithValue(constants.AllowDeletionAnnotationKey, v1alpha1.False))
+		Expect(*obj.Spec.Charset).To(Equal(admission.PostgresqlDatabaseSpecCharsetDefault))
+		Expect(*obj.Spec.Collation).To(Equal(admission.PostgresqlDatabaseSpecCollationDefault))
+
+		// Make sure that a PostgresqlDatabase resource claiming the same database as an existing PostgresqlDatabase resource cannot be created.
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlDatabases().Create(&v1alpha1.PostgresqlDatabase{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlDatabaseMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlDatabaseSpec{
+				Instance: instance.Name,
+				Name:     "foo",
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`the name "foo" is already claimed by postgresqldatabase "` + obj.Name + `"`))
+
+		tests := []struct {
+			errorMessageRegex string
+			fn                func(database *v1alpha1.PostgresqlDatabase)
+		}{
+			{
+				errorMessageRegex: `the charset of the database cannot be changed \(had "UTF8", got "LATIN1"\)`,
+				fn: func(database *v1alpha1.PostgresqlDatabase) {
+					database.Spec.Charset = pointers.NewString("LATIN1")
+				},
+			},
+			{
+				errorMessageRegex: `the name of the database cannot be changed \(had "foo", got "bar"\)`,
+				fn: func(database *v1alpha1.PostgresqlDatabase) {
+					database.Spec.Name = "bar"
+				},
+			},
+		}
+
+		// Create a clone of the original PostgresqlDatabase resource so we can perform the required changes on a fresh, valid source.
+		// Then, do apply the required changes and make sure that the expected error message is returned.
+		for _, test := range tests {
+			updatedObj := obj.DeepCopy()
+			test.fn(updatedObj)
+			_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlDatabases().Update(updatedObj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp(test.errorMessageRegex))
+		}
+
+		// Delete the PostgresqlDatabase and PostgresqlInstance resources.
+		err = f.DeletePostgresqlDatabaseByName(obj.Name)
+		Expect(err).NotTo(HaveOccurred())
+		err = f.DeletePostgresqlInstanceByName(instance.Name)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+var _ = Describe("PostgresqlUser", func() {
+	framework.AdmissionIt("cannot claim a user already claimed by another resource and cannot be updated", func() {
+		var (
+			err      error
+			instance *v1alpha1.PostgresqlInstance
+			obj      *v1alpha1.PostgresqlUser
+		)
+
+		// Create a minimal PostgresqlInstance resource.
+		instance, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Create(&v1alpha1.PostgresqlInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlInstanceMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlInstanceSpec{
+				Name: f.NewRandomPostgresqlInstanceSpecName(),
+				Networking: &v1alpha1.PostgresqlInstanceSpecNetworking{
+					PublicIP: &v1alpha1.PostgresqlInstanceSpecNetworkingPublicIP{
+						Enabled: pointers.NewBool(true),
+					},
+				},
+				Paused: true,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Create a minimal PostgresqlUser resource in the "default" namespace.
+		obj, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlUsers(metav1.NamespaceDefault).Create(&v1alpha1.PostgresqlUser{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlUserMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlUserSpec{
+				Instance:   instance.Name,
+				Name:       "foo",
+				SecretName: pointers.NewString("foo-credentials"),
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Make sure that all fields have the expected values.
+		Expect(obj.Annotations).To(HaveKeyWithValue(constants.AllowDeletionAnnotationKey, v1alpha1.False))
+
+		// Make sure that a PostgresqlUser resource claiming the same user from a different namespace cannot be created.
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlUsers(f.Namespace).Create(&v1alpha1.PostgresqlUser{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlUserMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlUserSpec{
+				Instance: instance.Name,
+				Name:     "foo",
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`the name "foo" is already claimed by postgresqluser "default/` + obj.Name + `"`))
+
+		tests := []struct {
+			errorMessageRegex string
+			fn                func(user *v1alpha1.PostgresqlUser)
+		}{
+			{
+				errorMessageRegex: `the name of the user cannot be changed \(had "foo", got "bar"\)`,
+				fn: func(user *v1alpha1.PostgresqlUser) {
+					user.Spec.Name = "bar"
+				},
+			},
+			{
+				errorMessageRegex: `the name of the secret cannot be changed \(had "foo-credentials", got "bar"\)`,
+				fn: func(user *v1alpha1.PostgresqlUser) {
+					user.Spec.SecretName = pointers.NewString("bar")
+				},
+			},
+		}
+
+		// Create a clone of the original PostgresqlUser resource so we can perform the required changes on a fresh, valid source.
+		// Then, do apply the required changes and make sure that the expected error message is returned.
+		for _, test := range tests {
+			updatedObj := obj.DeepCopy()
+			test.fn(updatedObj)
+			_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlUsers(metav1.NamespaceDefault).Update(updatedObj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp(test.errorMessageRegex))
+		}
+
+		// Delete the PostgresqlUser and PostgresqlInstance resources.
+		err = f.DeletePostgresqlUserByName(metav1.NamespaceDefault, obj.Name)
+		Expect(err).NotTo(HaveOccurred())
+		err = f.DeletePostgresqlInstanceByName(instance.Name)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+var _ = Describe("PostgresqlClientCertificate", func() {
+	framework.AdmissionIt("is mutated with default values upon creation and cannot be updated", func() {
+		var (
+			err      error
+			instance *v1alpha1.PostgresqlInstance
+			obj      *v1alpha1.PostgresqlClientCertificate
+		)
+
+		// Make sure that a PostgresqlClientCertificate resource referencing a non-existing PostgresqlInstance resource cannot be created.
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlClientCertificates(f.Namespace).Create(&v1alpha1.PostgresqlClientCertificate{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlClientCertificateMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlClientCertificateSpec{
+				Instance: "foo",
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`postgresqlinstance "foo" does not exist`))
+
+		// Create a minimal PostgresqlInstance resource.
+		instance, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Create(&v1alpha1.PostgresqlInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlInstanceMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlInstanceSpec{
+				Name: f.NewRandomPostgresqlInstanceSpecName(),
+				Networking: &v1alpha1.PostgresqlInstanceSpecNetworking{
+					PublicIP: &v1alpha1.PostgresqlInstanceSpecNetworkingPublicIP{
+						Enabled: pointers.NewBool(true),
+					},
+				},
+				Paused: true,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Create a minimal PostgresqlClientCertificate resource.
+		obj, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlClientCertificates(f.Namespace).Create(&v1alpha1.PostgresqlClientCertificate{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlClientCertificateMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlClientCertificateSpec{
+				Instance: instance.Name,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Make sure that all fields have the expected values.
+		Expect(obj.Annotations).To(HaveKeyWithValue(constants.AllowDeletionAnnotationKey, v1alpha1.False))
+		Expect(*obj.Spec.SecretName).To(Equal(obj.Name))
+
+		tests := []struct {
+			errorMessageRegex string
+			fn                func(certificate *v1alpha1.PostgresqlClientCertificate)
+		}{
+			{
+				errorMessageRegex: `the instance of the client certificate cannot be changed \(had "` + instance.Name + `", got "bar"\)`,
+				fn: func(certificate *v1alpha1.PostgresqlClientCertificate) {
+					certificate.Spec.Instance = "bar"
+				},
+			},
+			{
+				errorMessageRegex: `the name of the secret cannot be changed \(had "` + obj.Name + `", got "bar"\)`,
+				fn: func(certificate *v1alpha1.PostgresqlClientCertificate) {
+					certificate.Spec.SecretName = pointers.NewString("bar")
+				},
+			},
+		}
+
+		// Create a clone of the original PostgresqlClientCertificate resource so we can perform the required changes on a fresh, valid source.
+		// Then, do apply the required changes and make sure that the expected error message is returned.
+		for _, test := range tests {
+			updatedObj := obj.DeepCopy()
+			test.fn(updatedObj)
+			_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlClientCertificates(f.Namespace).Update(updatedObj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp(test.errorMessageRegex))
+		}
+
+		// Make sure that the PostgresqlClientCertificate resource cannot be deleted unless "cloudsql.travelaudience.com/allow-deletion" is "true".
+		err = f.SelfClient.CloudsqlV1alpha1().PostgresqlClientCertificates(f.Namespace).Delete(obj.Name, metav1.NewDeleteOptions(0))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`the resource cannot be deleted unless the "cloudsql.travelaudience.com/allow-deletion" annotation is set to "true"`))
+
+		// Delete the PostgresqlClientCertificate and PostgresqlInstance resources.
+		err = f.DeletePostgresqlClientCertificateByName(f.Namespace, obj.Name)
+		Expect(err).NotTo(HaveOccurred())
+		err = f.DeletePostgresqlInstanceByName(instance.Name)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+var _ = Describe("PostgresqlReplica", func() {
+	framework.AdmissionIt("is mutated with default values upon creation and cannot be updated with invalid values", func() {
+		var (
+			err      error
+			instance *v1alpha1.PostgresqlInstance
+			obj      *v1alpha1.PostgresqlReplica
+		)
+
+		// Make sure that a PostgresqlReplica resource referencing a non-existing PostgresqlInstance resource cannot be created.
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlReplicas().Create(&v1alpha1.PostgresqlReplica{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlReplicaMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlReplicaSpec{
+				Name:    f.NewRandomPostgresqlInstanceSpecName(),
+				Primary: "non-existing",
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`postgresqlinstance "non-existing" does not exist`))
+
+		// Create a minimal PostgresqlInstance resource.
+		instance, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Create(&v1alpha1.PostgresqlInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlInstanceMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlInstanceSpec{
+				Name: f.NewRandomPostgresqlInstanceSpecName(),
+				Networking: &v1alpha1.PostgresqlInstanceSpecNetworking{
+					PublicIP: &v1alpha1.PostgresqlInstanceSpecNetworkingPublicIP{
+						Enabled: pointers.NewBool(true),
+					},
+				},
+				Paused: true,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Create a minimal PostgresqlReplica resource.
+		obj, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlReplicas().Create(&v1alpha1.PostgresqlReplica{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlReplicaMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlReplicaSpec{
+				Name:    f.NewRandomPostgresqlInstanceSpecName(),
+				Paused:  true,
+				Primary: instance.Name,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Make sure that all fields have the expected values.
+		Expect(obj.Annotations).To(HaveKeyWithValue(constants.AllowDeletionAnnotationKey, v1alpha1.False))
+		Expect(obj.Spec.Flags).To(BeEmpty())
+		Expect(*obj.Spec.InstanceType).To(Equal(admission.PostgresqlInstanceSpecResourcesInstanceTypeDefault))
+		Expect(*obj.Spec.Location.Region).To(Equal(*instance.Spec.Location.Region))
+		Expect(*obj.Spec.Location.Zone).To(Equal(admission.PostgresqlInstanceSpecLocationZoneDefault))
+
+		tests := []struct {
+			errorMessageRegex string
+			fn                func(replica *v1alpha1.PostgresqlReplica)
+		}{
+			{
+				errorMessageRegex: `flags must be specified in the "<name>=<value>" format \(got "foo"\)`,
+				fn: func(replica *v1alpha1.PostgresqlReplica) {
+					replica.Spec.Flags = []string{"foo"}
+				},
+			},
+			{
+				errorMessageRegex: `the "foo_bar" flag is not supported by cloud sql`,
+				fn: func(replica *v1alpha1.PostgresqlReplica) {
+					replica.Spec.DatabaseFlags = v1alpha1.PostgresqlInstanceSpecDatabaseFlags{
+						"foo_bar": "on",
+					}
+				},
+			},
+			{
+				errorMessageRegex: `the region where the read replica is located cannot be changed`,
+				fn: func(replica *v1alpha1.PostgresqlReplica) {
+					replica.Spec.Location.Region = pointers.NewString("us-east1")
+				},
+			},
+			{
+				errorMessageRegex: `the name of the read replica cannot be changed`,
+				fn: func(replica *v1alpha1.PostgresqlReplica) {
+					replica.Spec.Name = "bar"
+				},
+			},
+			{
+				errorMessageRegex: `the primary instance of the read replica cannot be changed`,
+				fn: func(replica *v1alpha1.PostgresqlReplica) {
+					replica.Spec.Primary = "bar"
+				},
+			},
+		}
+
+		// Create a clone of the original PostgresqlReplica resource so we can perform the required changes on a fresh, valid source.
+		// Then, do apply the required changes and make sure that the expected error message is returned.
+		for _, test := range tests {
+			updatedObj := obj.DeepCopy()
+			test.fn(updatedObj)
+			_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlReplicas().Update(updatedObj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp(test.errorMessageRegex))
+		}
+
+		// Request the promotion of the read replica, and make sure that the "cloudsql.travelaudience.com/promote" annotation cannot be unset afterwards.
+		obj.Annotations[constants.PromoteReplicaAnnotationKey] = v1alpha1.True
+		obj, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlReplicas().Update(obj)
+		Expect(err).NotTo(HaveOccurred())
+		updatedObj := obj.DeepCopy()
+		updatedObj.Annotations[constants.PromoteReplicaAnnotationKey] = v1alpha1.False
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlReplicas().Update(updatedObj)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`the "cloudsql.travelaudience.com/promote" annotation cannot be changed after having been set to "true"`))
+
+		// Report a failed promotion, and make sure that the "cloudsql.travelaudience.com/promote" annotation can now be toggled.
+		obj.Status.Conditions = []v1alpha1.PostgresqlReplicaStatusCondition{
+			{
+				Type:               v1alpha1.PostgresqlReplicaStatusConditionTypePromoted,
+				Status:             corev1.ConditionFalse,
+				LastTransitionTime: metav1.Now(),
+				Reason:             "ReplicaPromotionFailed",
+			},
+		}
+		obj, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlReplicas().UpdateStatus(obj)
+		Expect(err).NotTo(HaveOccurred())
+		obj.Annotations[constants.PromoteReplicaAnnotationKey] = v1alpha1.False
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlReplicas().Update(obj)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Delete the PostgresqlReplica and PostgresqlInstance resources.
+		err = f.DeletePostgresqlReplicaByName(obj.Name)
+		Expect(err).NotTo(HaveOccurred())
+		err = f.DeletePostgresqlInstanceByName(instance.Name)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+var _ = Describe("PostgresqlBackup", func() {
+	framework.AdmissionIt("is mutated with default values upon creation and cannot be updated with invalid values", func() {
+		var (
+			err      error
+			instance *v1alpha1.PostgresqlInstance
+			obj      *v1alpha1.PostgresqlBackup
+		)
+
+		// Make sure that a PostgresqlBackup resource referencing a non-existing PostgresqlInstance resource cannot be created.
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlBackups().Create(&v1alpha1.PostgresqlBackup{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlBackupMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlBackupSpec{
+				Instance: "non-existing",
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`postgresqlinstance "non-existing" does not exist`))
+
+		// Create a minimal PostgresqlInstance resource.
+		instance, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Create(&v1alpha1.PostgresqlInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlInstanceMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlInstanceSpec{
+				Name: f.NewRandomPostgresqlInstanceSpecName(),
+				Networking: &v1alpha1.PostgresqlInstanceSpecNetworking{
+					PublicIP: &v1alpha1.PostgresqlInstanceSpecNetworkingPublicIP{
+						Enabled: pointers.NewBool(true),
+					},
+				},
+				Paused: true,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Create a minimal PostgresqlBackup resource.
+		obj, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlBackups().Create(&v1alpha1.PostgresqlBackup{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlBackupMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlBackupSpec{
+				Instance: instance.Name,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Make sure that all fields have the expected values.
+		Expect(obj.Annotations).To(HaveKeyWithValue(constants.AllowDeletionAnnotationKey, v1alpha1.False))
+
+		// Make sure that ".spec.instance" cannot be changed.
+		updatedObj := obj.DeepCopy()
+		updatedObj.Spec.Instance = "bar"
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlBackups().Update(updatedObj)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`the instance of the backup cannot be changed`))
+
+		// Make sure that the PostgresqlBackup resource cannot be deleted without the "cloudsql.travelaudience.com/allow-deletion" annotation being set to "true".
+		err = f.SelfClient.CloudsqlV1alpha1().PostgresqlBackups().Delete(obj.Name, metav1.NewDeleteOptions(0))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`the resource cannot be deleted unless the "cloudsql.travelaudience.com/allow-deletion" annotation is set to "true"`))
+
+		// Delete the PostgresqlBackup and PostgresqlInstance resources.
+		err = f.DeletePostgresqlBackupByName(obj.Name)
+		Expect(err).NotTo(HaveOccurred())
+		err = f.DeletePostgresqlInstanceByName(instance.Name)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+var _ = Describe("PostgresqlRestore", func() {
+	framework.AdmissionIt("requires confirmation upon creation and cannot be updated", func() {
+		var (
+			err      error
+			instance *v1alpha1.PostgresqlInstance
+			obj      *v1alpha1.PostgresqlRestore
+		)
+
+		// Make sure that a PostgresqlRestore resource referencing a non-existing PostgresqlInstance resource cannot be created.
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlRestores().Create(&v1alpha1.PostgresqlRestore{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					constants.ConfirmRestoreAnnotationKey: v1alpha1.True,
+				},
+				GenerateName: framework.PostgresqlRestoreMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlRestoreSpec{
+				BackupRunID: 1,
+				Target:      "non-existing",
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`postgresqlinstance "non-existing" does not exist`))
+
+		// Create a minimal PostgresqlInstance resource.
+		instance, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Create(&v1alpha1.PostgresqlInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlInstanceMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlInstanceSpec{
+				Name: f.NewRandomPostgresqlInstanceSpecName(),
+				Networking: &v1alpha1.PostgresqlInstanceSpecNetworking{
+					PublicIP: &v1alpha1.PostgresqlInstanceSpecNetworkingPublicIP{
+						Enabled: pointers.NewBool(true),
+					},
+				},
+				Paused: true,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Make sure that a PostgresqlRestore resource cannot be created without the "cloudsql.travelaudience.com/confirm-restore" annotation being set to "true".
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlRestores().Create(&v1alpha1.PostgresqlRestore{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlRestoreMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlRestoreSpec{
+				BackupRunID: 1,
+				Target:      instance.Name,
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`the resource cannot be created unless the "cloudsql.travelaudience.com/confirm-restore" annotation is set to "true"`))
+
+		// Make sure that a PostgresqlRestore resource specifying neither ".spec.backup" nor ".spec.backupRunID" cannot be created.
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlRestores().Create(&v1alpha1.PostgresqlRestore{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					constants.ConfirmRestoreAnnotationKey: v1alpha1.True,
+				},
+				GenerateName: framework.PostgresqlRestoreMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlRestoreSpec{
+				Target: instance.Name,
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`exactly one of the backup and the id of the backup run to restore must be specified`))
+
+		// Create a minimal PostgresqlRestore resource.
+		obj, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlRestores().Create(&v1alpha1.PostgresqlRestore{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					constants.ConfirmRestoreAnnotationKey: v1alpha1.True,
+				},
+				GenerateName: framework.PostgresqlRestoreMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlRestoreSpec{
+				BackupRunID: 1,
+				Target:      instance.Name,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Make sure that all fields have the expected values.
+		Expect(obj.Spec.Source).To(Equal(instance.Name))
+
+		// Make sure that ".spec.target" cannot be changed.
+		updatedObj := obj.DeepCopy()
+		updatedObj.Spec.Target = "bar"
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlRestores().Update(updatedObj)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`the target instance of the restore cannot be changed`))
+
+		// Make sure that ".spec.backupRunID" cannot be changed.
+		updatedObj = obj.DeepCopy()
+		updatedObj.Spec.BackupRunID = 2
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlRestores().Update(updatedObj)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`the id of the backup run to restore cannot be changed`))
+
+		// Delete the PostgresqlRestore and PostgresqlInstance resources.
+		err = f.DeletePostgresqlRestoreByName(obj.Name)
+		Expect(err).NotTo(HaveOccurred())
+		err = f.DeletePostgresqlInstanceByName(instance.Name)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+var _ = Describe("PostgresqlExport", func() {
+	framework.AdmissionIt("is validated and defaulted upon creation and update", func() {
+		var (
+			err      error
+			instance *v1alpha1.PostgresqlInstance
+			obj      *v1alpha1.PostgresqlExport
+		)
+
+		// Create a minimal PostgresqlInstance resource.
+		instance, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Create(&v1alpha1.PostgresqlInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlInstanceMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlInstanceSpec{
+				Name: f.NewRandomPostgresqlInstanceSpecName(),
+				Networking: &v1alpha1.PostgresqlInstanceSpecNetworking{
+					PublicIP: &v1alpha1.PostgresqlInstanceSpecNetworkingPublicIP{
+						Enabled: pointers.NewBool(true),
+					},
+				},
+				Paused: true,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Make sure that a PostgresqlExport resource with an invalid destination cannot be created.
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlExports().Create(&v1alpha1.PostgresqlExport{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlExportMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlExportSpec{
+				Databases:   []string{"foo"},
+				Destination: "s3://bucket/path",
+				Instance:    instance.Name,
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`the destination of the export must be a google cloud storage uri`))
+
+		// Make sure that a PostgresqlExport resource with an invalid schedule cannot be created.
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlExports().Create(&v1alpha1.PostgresqlExport{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlExportMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlExportSpec{
+				Databases:   []string{"foo"},
+				Destination: "gs://bucket/path",
+				Instance:    instance.Name,
+				Schedule:    "every day",
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`the schedule of the export must be a valid cron expression`))
+
+		// Create a minimal PostgresqlExport resource.
+		obj, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlExports().Create(&v1alpha1.PostgresqlExport{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlExportMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlExportSpec{
+				Databases:   []string{"foo"},
+				Destination: "gs://bucket/path",
+				Instance:    instance.Name,
+				Schedule:    "0 3 * * *",
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Make sure that all fields have the expected values.
+		Expect(*obj.Spec.FileType).To(Equal(admission.PostgresqlExportSpecFileTypeDefault))
+		Expect(*obj.Spec.Retention).To(Equal(admission.PostgresqlExportSpecRetentionDefault))
+
+		// Make sure that ".spec.instance" cannot be changed.
+		updatedObj := obj.DeepCopy()
+		updatedObj.Spec.Instance = "bar"
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlExports().Update(updatedObj)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`the instance of the export cannot be changed`))
+
+		// Delete the PostgresqlExport and PostgresqlInstance resources.
+		err = f.DeletePostgresqlExportByName(obj.Name)
+		Expect(err).NotTo(HaveOccurred())
+		err = f.DeletePostgresqlInstanceByName(instance.Name)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+var _ = Describe("PostgresqlImport", func() {
+	framework.AdmissionIt("is validated and defaulted upon creation and cannot be updated", func() {
+		var (
+			err      error
+			instance *v1alpha1.PostgresqlInstance
+			obj      *v1alpha1.PostgresqlImport
+		)
+
+		// Make sure that a PostgresqlImport resource referencing a non-existing PostgresqlInstance resource cannot be created.
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlImports().Create(&v1alpha1.PostgresqlImport{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlImportMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlImportSpec{
+				Database: "foo",
+				Instance: "non-existing",
+				Source:   "gs://bucket/dump.sql.gz",
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`postgresqlinstance "non-existing" does not exist`))
+
+		// Create a minimal PostgresqlInstance resource.
+		instance, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Create(&v1alpha1.PostgresqlInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlInstanceMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlInstanceSpec{
+				Name: f.NewRandomPostgresqlInstanceSpecName(),
+				Networking: &v1alpha1.PostgresqlInstanceSpecNetworking{
+					PublicIP: &v1alpha1.PostgresqlInstanceSpecNetworkingPublicIP{
+						Enabled: pointers.NewBool(true),
+					},
+				},
+				Paused: true,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Make sure that a PostgresqlImport resource with an invalid source cannot be created.
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlImports().Create(&v1alpha1.PostgresqlImport{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlImportMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlImportSpec{
+				Database: "foo",
+				Instance: instance.Name,
+				Source:   "gs://bucket",
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`the source of the import must be a google cloud storage uri`))
+
+		// Create a minimal PostgresqlImport resource.
+		obj, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlImports().Create(&v1alpha1.PostgresqlImport{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlImportMetadataNamePrefix,
+			},
+			Spec: v1alpha1.PostgresqlImportSpec{
+				Database: "foo",
+				Instance: instance.Name,
+				Source:   "gs://bucket/dump.sql.gz",
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Make sure that all fields have the expected values.
+		Expect(*obj.Spec.FileType).To(Equal(admission.PostgresqlImportSpecFileTypeDefault))
+
+		// Make sure that ".spec" cannot be changed.
+		updatedObj := obj.DeepCopy()
+		updatedObj.Spec.Database = "bar"
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlImports().Update(updatedObj)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`the specification of the import cannot be changed`))
+
+		// Delete the PostgresqlImport and PostgresqlInstance resources.
+		err = f.DeletePostgresqlImportByName(obj.Name)
+		Expect(err).NotTo(HaveOccurred())
+		err = f.DeletePostgresqlInstanceByName(instance.Name)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
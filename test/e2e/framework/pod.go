@@ -78,6 +78,41 @@ func (f *Framework) CreatePostgresqlTestPod(postgresqlInstance *v1alpha1api.Post
 	})
 }
 
+// CreatePostgresqlClientCertificateTestPod creates a pod requesting the client certificate associated with the provided PostgresqlClientCertificate resource.
+// The test pod is garbage-collected once the provided PostgresqlClientCertificate resource is deleted.
+func (f *Framework) CreatePostgresqlClientCertificateTestPod(postgresqlClientCertificate *v1alpha1api.PostgresqlClientCertificate) (*corev1.Pod, error) {
+	return f.KubeClient.CoreV1().Pods(postgresqlClientCertificate.Namespace).Create(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				constants.PostgresqlClientCertificateNameAnnotationKey: postgresqlClientCertificate.Name,
+			},
+			GenerateName: fmt.Sprintf("%s-e2e-", constants.ApplicationName),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         v1alpha1api.SchemeGroupVersion.String(),
+					Kind:               crds.PostgresqlClientCertificateKind,
+					Name:               postgresqlClientCertificate.Name,
+					UID:                postgresqlClientCertificate.UID,
+					Controller:         pointers.NewBool(true),
+					BlockOwnerDeletion: pointers.NewBool(true),
+				},
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  "postgres",
+					Image: "postgres:9.6",
+					Command: []string{
+						"sleep",
+						"3600",
+					},
+				},
+			},
+		},
+	})
+}
+
 // WaitUntilPodLogLineMatches waits until a line in the logs for the first container of the provided pod matches the provided regular expression.
 func (f *Framework) WaitUntilPodLogLineMatches(ctx context.Context, pod *corev1.Pod, regex string) error {
 	req := f.KubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
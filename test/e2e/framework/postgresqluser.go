@@ -0,0 +1,44 @@
+// +build e2e
+
+/*
+Copyright 2019 The cloudsql-postgres-operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/apis/cloudsql/v1alpha1"
+	"github.com/travelaudience/cloudsql-postgres-operator/pkg/constants"
+)
+
+const (
+	// PostgresqlUserMetadataNamePrefix is the prefix used when generating random values for the ".metadata.name" field of PostgresqlUser objects.
+	PostgresqlUserMetadataNamePrefix = "postgresqluser-"
+)
+
+// DeletePostgresqlUserByName deletes the provided PostgresqlUser resource.
+func (f *Framework) DeletePostgresqlUserByName(namespace, metadataName string) error {
+	t, err := f.SelfClient.CloudsqlV1alpha1().PostgresqlUsers(namespace).Get(metadataName, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	t.Annotations[constants.AllowDeletionAnnotationKey] = v1alpha1.True
+	if _, err := f.SelfClient.CloudsqlV1alpha1().PostgresqlUsers(namespace).Update(t); err != nil {
+		return err
+	}
+	return f.SelfClient.CloudsqlV1alpha1().PostgresqlUsers(namespace).Delete(t.Name, metav1.NewDeleteOptions(0))
+}
@@ -30,6 +30,7 @@ import (
 	. "github.com/onsi/gomega"
 	cloudsqladmin "google.golang.org/api/sqladmin/v1beta4"
 	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/travelaudience/cloudsql-postgres-operator/pkg/admission"
@@ -39,8 +40,12 @@ import (
 )
 
 const (
+	// checkPasswordNotResetDuration is the amount of time during which the password of the "postgres" user of an adopted CSQLP instance is checked not to have been reset.
+	checkPasswordNotResetDuration = 30 * time.Second
 	// cloudsqladminUser is the name of the Cloud SQL Admin user which we lookup in the test pod's logs to understand if connection was successful.
 	cloudsqladminUser = "cloudsqladmin"
+	// outOfBandDatabaseName is the name of the database created directly using the Cloud SQL Admin API.
+	outOfBandDatabaseName = "out-of-band"
 	// postgresqlDriverName is the name of the SQL driver to use when connecting to PostgreSQL.
 	postgresqlDriverName = "postgres"
 	// postgresqlConnectionStringFormat is a format string used to build the connection string to use when connecting to PostgreSQL.
@@ -58,21 +63,24 @@ const (
 	waitUntilPodRunningTimeout = 2 * time.Minute
 	// waitUntilPodLogLineMatchesTimeout is the timeout used while waiting for the logs of a given pod to match a given regular expression.
 	waitUntilPodLogLineMatchesTimeout = 15 * time.Second
+	// waitUntilOutOfBandDatabaseCreatedTimeout is the timeout used while waiting for a database created directly using the Cloud SQL Admin API to be listed.
+	waitUntilOutOfBandDatabaseCreatedTimeout = 2 * time.Minute
 )
 
 var _ = Describe("CSQLP instances", func() {
 	framework.LifecycleIt("are created, updated, used and deleted as expected", func() {
 		var (
-			databaseInstance         *cloudsqladmin.DatabaseInstance
-			db                       *sql.DB
-			err                      error
-			username                 string
-			password                 string
-			pod                      *corev1.Pod
-			postgresqlInstance       *v1alpha1api.PostgresqlInstance
-			postgresqlInstanceSecret *corev1.Secret
-			publicIp                 string
-			selectCurrentUserValue   string
+			databaseInstance            *cloudsqladmin.DatabaseInstance
+			db                          *sql.DB
+			err                         error
+			username                    string
+			password                    string
+			pod                         *corev1.Pod
+			postgresqlClientCertificate *v1alpha1api.PostgresqlClientCertificate
+			postgresqlInstance          *v1alpha1api.PostgresqlInstance
+			postgresqlInstanceSecret    *corev1.Secret
+			publicIp                    string
+			selectCurrentUserValue      string
 		)
 
 		By("creating a PostgresqlInstance resource with public IP disabled")
@@ -182,7 +190,7 @@ var _ = Describe("CSQLP instances", func() {
 		Expect(databaseInstance.Settings.AvailabilityType).To(Equal(postgresqlInstance.Spec.Availability.Type.APIValue()))
 		Expect(databaseInstance.Settings.BackupConfiguration.Enabled).To(Equal(*postgresqlInstance.Spec.Backups.Daily.Enabled))
 		Expect(databaseInstance.Settings.BackupConfiguration.StartTime).To(Equal(*postgresqlInstance.Spec.Backups.Daily.StartTime))
-		Expect(databaseInstance.Settings.DatabaseFlags).To(Equal(postgresqlInstance.Spec.Flags.APIValue()))
+		Expect(databaseInstance.Settings.DatabaseFlags).To(Equal(v1alpha1api.DatabaseFlagsAPIValue(postgresqlInstance.Spec.Flags, postgresqlInstance.Spec.DatabaseFlags)))
 		Expect(databaseInstance.Settings.DataDiskSizeGb).To(Equal(int64(*postgresqlInstance.Spec.Resources.Disk.SizeMinimumGb)))
 		Expect(databaseInstance.Settings.DataDiskType).To(Equal(postgresqlInstance.Spec.Resources.Disk.Type.APIValue()))
 		Expect(databaseInstance.Settings.IpConfiguration.Ipv4Enabled).To(Equal(*postgresqlInstance.Spec.Networking.PublicIP.Enabled))
@@ -356,5 +364,270 @@ var _ = Describe("CSQLP instances", func() {
 		defer fn10()
 		err = f.WaitUntilPodLogLineMatches(ctx10, pod, cloudsqladminUser)
 		Expect(err).NotTo(HaveOccurred())
+
+		By(`creating a PostgresqlClientCertificate resource and waiting for the client certificate to be issued`)
+
+		postgresqlClientCertificate, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlClientCertificates(f.Namespace).Create(&v1alpha1api.PostgresqlClientCertificate{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlClientCertificateMetadataNamePrefix,
+			},
+			Spec: v1alpha1api.PostgresqlClientCertificateSpec{
+				Instance: postgresqlInstance.Name,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() (corev1.ConditionStatus, error) {
+			postgresqlClientCertificate, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlClientCertificates(f.Namespace).Get(postgresqlClientCertificate.Name, metav1.GetOptions{})
+			if err != nil {
+				return corev1.ConditionUnknown, err
+			}
+			for _, cdn := range postgresqlClientCertificate.Status.Conditions {
+				if cdn.Type == v1alpha1api.PostgresqlClientCertificateStatusConditionTypeReady {
+					return cdn.Status, nil
+				}
+			}
+			return corev1.ConditionUnknown, nil
+		}, waitUntilPostgresqlInstanceStatusConditionTimeout, time.Second).Should(Equal(corev1.ConditionTrue))
+
+		By(`checking that the client certificate has been issued and written to a secret owned by the PostgresqlClientCertificate resource`)
+
+		Expect(postgresqlClientCertificate.Status.SHA1Fingerprint).NotTo(BeEmpty())
+		_, err = f.CloudSQLClient.SslCerts.Get(f.ProjectId, postgresqlInstance.Spec.Name, postgresqlClientCertificate.Status.SHA1Fingerprint).Do()
+		Expect(err).NotTo(HaveOccurred())
+		clientCertificateSecret, err := f.KubeClient.CoreV1().Secrets(f.Namespace).Get(*postgresqlClientCertificate.Spec.SecretName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metav1.IsControlledBy(clientCertificateSecret, postgresqlClientCertificate)).To(BeTrue())
+		Expect(clientCertificateSecret.Data).To(HaveKey(constants.ClientCertificateKey))
+		Expect(clientCertificateSecret.Data).To(HaveKey(constants.ClientPrivateKeyKey))
+		Expect(clientCertificateSecret.Data).To(HaveKey(constants.ServerCACertificateKey))
+
+		By(`launching a test pod requesting the client certificate, and verifying it has been injected with the "PGSSL*" variables but not with the Cloud SQL proxy sidecar container`)
+
+		pod, err = f.CreatePostgresqlClientCertificateTestPod(postgresqlClientCertificate)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pod.Annotations).NotTo(HaveKey(constants.ProxyInjectedAnnotationKey))
+		Expect(pod.Spec.Containers).To(HaveLen(1))
+		envvarNames := make([]string, len(pod.Spec.Containers[0].Env))
+		for _, envvar := range pod.Spec.Containers[0].Env {
+			envvarNames = append(envvarNames, envvar.Name)
+		}
+		Expect(envvarNames).To(ContainElement(admission.PgsslcertEnvVarName))
+		Expect(envvarNames).To(ContainElement(admission.PgsslkeyEnvVarName))
+		Expect(envvarNames).To(ContainElement(admission.PgsslmodeEnvVarName))
+		Expect(envvarNames).To(ContainElement(admission.PgsslrootcertEnvVarName))
+
+		By(`deleting the PostgresqlClientCertificate resource and making sure that the client certificate is deleted`)
+
+		err = f.DeletePostgresqlClientCertificateByName(f.Namespace, postgresqlClientCertificate.Name)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() error {
+			_, err := f.CloudSQLClient.SslCerts.Get(f.ProjectId, postgresqlInstance.Spec.Name, postgresqlClientCertificate.Status.SHA1Fingerprint).Do()
+			return err
+		}, waitUntilPostgresqlInstanceStatusConditionTimeout, time.Second).Should(HaveOccurred())
+
+		By(`creating a database out-of-band and making sure that a PostgresqlDatabase resource cannot claim it`)
+
+		// Create a database directly using the Cloud SQL Admin API, and wait until it is listed.
+		_, err = f.CloudSQLClient.Databases.Insert(f.ProjectId, postgresqlInstance.Spec.Name, &cloudsqladmin.Database{
+			Name: outOfBandDatabaseName,
+		}).Do()
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() error {
+			_, err := f.CloudSQLClient.Databases.Get(f.ProjectId, postgresqlInstance.Spec.Name, outOfBandDatabaseName).Do()
+			return err
+		}, waitUntilOutOfBandDatabaseCreatedTimeout, time.Second).Should(Succeed())
+
+		// Make sure that a PostgresqlDatabase resource having the name of the database created above cannot be created.
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlDatabases().Create(&v1alpha1api.PostgresqlDatabase{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlDatabaseMetadataNamePrefix,
+			},
+			Spec: v1alpha1api.PostgresqlDatabaseSpec{
+				Instance: postgresqlInstance.Name,
+				Name:     outOfBandDatabaseName,
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`the name "` + outOfBandDatabaseName + `" is already in use by a database in instance ".*"`))
+
+		By(`stopping the CSQLP instance while a change requiring a restart is held back, and making sure that the change is not applied`)
+
+		// Require approval for disruptive changes, and change the instance type while stopping the CSQLP instance.
+		var (
+			activationPolicy       = v1alpha1api.PostgresqlInstanceSpecActivationPolicyNever
+			disruptiveUpdatePolicy = v1alpha1api.PostgresqlInstanceSpecMaintenanceDisruptiveUpdatePolicyOnApproval
+			newInstanceType        = "db-custom-1-3840"
+		)
+		postgresqlInstance, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Get(postgresqlInstance.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		postgresqlInstance.Spec.ActivationPolicy = &activationPolicy
+		postgresqlInstance.Spec.Maintenance.DisruptiveUpdatePolicy = &disruptiveUpdatePolicy
+		postgresqlInstance.Spec.Resources.InstanceType = &newInstanceType
+		postgresqlInstance, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Update(postgresqlInstance)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Wait until the CSQLP instance has been stopped, and make sure that its instance type has not been changed.
+		Eventually(func() (string, error) {
+			databaseInstance, err = f.CloudSQLClient.Instances.Get(f.ProjectId, postgresqlInstance.Spec.Name).Do()
+			if err != nil {
+				return "", err
+			}
+			return databaseInstance.Settings.ActivationPolicy, nil
+		}, waitUntilPostgresqlInstanceStatusConditionTimeout, time.Second).Should(Equal(constants.DatabaseInstanceActivationPolicyNever))
+		Expect(databaseInstance.Settings.Tier).To(Equal(instanceType))
+
+		By(`checking that the "UpToDate" condition reports that the change is being held back`)
+
+		Eventually(func() (string, error) {
+			postgresqlInstance, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Get(postgresqlInstance.Name, metav1.GetOptions{})
+			if err != nil {
+				return "", err
+			}
+			cdn, err := framework.GetPostgresqlInstanceCondition(postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeUpToDate)
+			if err != nil || cdn.Status != corev1.ConditionFalse {
+				return "", err
+			}
+			return cdn.Reason, nil
+		}, waitUntilPostgresqlInstanceStatusConditionTimeout, time.Second).Should(Equal("DisruptiveUpdateHeldBack"))
+	})
+
+	framework.LifecycleIt("keep the password of the \"postgres\" user when adopted unless a reset is allowed", func() {
+		var (
+			adoptedPostgresqlInstance *v1alpha1api.PostgresqlInstance
+			err                       error
+			password                  string
+			postgresqlInstance        *v1alpha1api.PostgresqlInstance
+			postgresqlInstanceSecret  *corev1.Secret
+		)
+
+		By("creating a PostgresqlInstance resource with a deletion policy of \"Retain\"")
+
+		var (
+			deletionPolicy   = v1alpha1api.PostgresqlInstanceSpecDeletionPolicyRetain
+			privateIpEnabled = true
+			privateIpNetwork = f.BuildPrivateNetworkResourceLink(network)
+			region           = region
+		)
+		postgresqlInstance, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Create(&v1alpha1api.PostgresqlInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: framework.PostgresqlInstanceMetadataNamePrefix,
+			},
+			Spec: v1alpha1api.PostgresqlInstanceSpec{
+				DeletionPolicy: &deletionPolicy,
+				Location: &v1alpha1api.PostgresqlInstanceSpecLocation{
+					Region: &region,
+				},
+				Name: f.NewRandomPostgresqlInstanceSpecName(),
+				Networking: &v1alpha1api.PostgresqlInstanceSpecNetworking{
+					PrivateIP: &v1alpha1api.PostgresqlInstanceSpecNetworkingPrivateIP{
+						Enabled: &privateIpEnabled,
+						Network: &privateIpNetwork,
+					},
+				},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(postgresqlInstance).NotTo(BeNil())
+
+		By(`waiting for the "Ready" condition to be "True"`)
+
+		ctx1, fn1 := context.WithTimeout(context.Background(), waitUntilPostgresqlInstanceStatusConditionTimeout)
+		defer fn1()
+		err = f.WaitUntilPostgresqlInstanceStatusCondition(ctx1, postgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionTrue)
+		Expect(err).NotTo(HaveOccurred())
+
+		By(`recording the password of the "postgres" user`)
+
+		postgresqlInstanceSecret, err = f.KubeClient.CoreV1().Secrets(f.Namespace).Get(postgresqlInstance.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		password = string(postgresqlInstanceSecret.Data[constants.PostgresqlInstancePasswordKey])
+		Expect(password).NotTo(BeEmpty())
+
+		By("deleting the PostgresqlInstance resource while retaining the CSQLP instance")
+
+		postgresqlInstance, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Get(postgresqlInstance.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		err = f.DeletePostgresqlInstanceByName(postgresqlInstance.Name)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() bool {
+			_, err := f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Get(postgresqlInstance.Name, metav1.GetOptions{})
+			return kubeerrors.IsNotFound(err)
+		}, waitUntilPostgresqlInstanceStatusConditionTimeout, time.Second).Should(BeTrue())
+
+		By("adopting the CSQLP instance using a PostgresqlInstance resource having the same specification")
+
+		deletionPolicy = v1alpha1api.PostgresqlInstanceSpecDeletionPolicyDelete
+		adoptedPostgresqlInstance = &v1alpha1api.PostgresqlInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					constants.ConfirmAdoptionAnnotationKey: v1alpha1api.True,
+				},
+				GenerateName: framework.PostgresqlInstanceMetadataNamePrefix,
+			},
+			Spec: *postgresqlInstance.Spec.DeepCopy(),
+		}
+		adoptedPostgresqlInstance.Spec.Adopt = true
+		adoptedPostgresqlInstance.Spec.DeletionPolicy = &deletionPolicy
+		adoptedPostgresqlInstance, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Create(adoptedPostgresqlInstance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(adoptedPostgresqlInstance).NotTo(BeNil())
+
+		defer func() {
+			By("deleting the PostgresqlInstance resource")
+
+			err = f.DeletePostgresqlInstanceByName(adoptedPostgresqlInstance.Name)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		By(`waiting for the "Ready" condition to be "True"`)
+
+		ctx2, fn2 := context.WithTimeout(context.Background(), waitUntilPostgresqlInstanceStatusConditionTimeout)
+		defer fn2()
+		err = f.WaitUntilPostgresqlInstanceStatusCondition(ctx2, adoptedPostgresqlInstance, v1alpha1api.PostgresqlInstanceStatusConditionTypeReady, corev1.ConditionTrue)
+		Expect(err).NotTo(HaveOccurred())
+
+		By(`checking that the password of the "postgres" user has not been reset`)
+
+		Consistently(func() (map[string][]byte, error) {
+			postgresqlInstanceSecret, err = f.KubeClient.CoreV1().Secrets(f.Namespace).Get(adoptedPostgresqlInstance.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return postgresqlInstanceSecret.Data, nil
+		}, checkPasswordNotResetDuration, time.Second).ShouldNot(HaveKey(constants.PostgresqlInstancePasswordKey))
+		Expect(string(postgresqlInstanceSecret.Data[constants.PostgresqlInstanceUsernameKey])).To(Equal(constants.PostgresqlInstanceUsernameValue))
+
+		By(`writing the password of the "postgres" user to the secret and checking that it is kept`)
+
+		postgresqlInstanceSecret.StringData = map[string]string{
+			constants.PostgresqlInstancePasswordKey: password,
+		}
+		_, err = f.KubeClient.CoreV1().Secrets(f.Namespace).Update(postgresqlInstanceSecret)
+		Expect(err).NotTo(HaveOccurred())
+		Consistently(func() (string, error) {
+			postgresqlInstanceSecret, err = f.KubeClient.CoreV1().Secrets(f.Namespace).Get(adoptedPostgresqlInstance.Name, metav1.GetOptions{})
+			if err != nil {
+				return "", err
+			}
+			return string(postgresqlInstanceSecret.Data[constants.PostgresqlInstancePasswordKey]), nil
+		}, checkPasswordNotResetDuration, time.Second).Should(Equal(password))
+
+		By(`allowing the password of the "postgres" user to be reset and checking that it is reset`)
+
+		adoptedPostgresqlInstance, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Get(adoptedPostgresqlInstance.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		adoptedPostgresqlInstance.Annotations[constants.AllowPasswordResetAnnotationKey] = v1alpha1api.True
+		_, err = f.SelfClient.CloudsqlV1alpha1().PostgresqlInstances().Update(adoptedPostgresqlInstance)
+		Expect(err).NotTo(HaveOccurred())
+		delete(postgresqlInstanceSecret.Data, constants.PostgresqlInstancePasswordKey)
+		_, err = f.KubeClient.CoreV1().Secrets(f.Namespace).Update(postgresqlInstanceSecret)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() (string, error) {
+			postgresqlInstanceSecret, err = f.KubeClient.CoreV1().Secrets(f.Namespace).Get(adoptedPostgresqlInstance.Name, metav1.GetOptions{})
+			if err != nil {
+				return "", err
+			}
+			return string(postgresqlInstanceSecret.Data[constants.PostgresqlInstancePasswordKey]), nil
+		}, waitUntilPostgresqlInstanceStatusConditionTimeout, time.Second).ShouldNot(Or(BeEmpty(), Equal(password)))
 	})
 })